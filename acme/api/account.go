@@ -17,7 +17,7 @@ type AccountService service
 func (a *AccountService) New(ctx context.Context, req acme.Account) (acme.ExtendedAccount, error) {
 	var account acme.Account
 
-	resp, err := a.core.post(ctx, a.core.GetDirectory().NewAccountURL, req, &account)
+	resp, err := a.core.post(sender.WithEndpoint(ctx, sender.EndpointNewAccount), a.core.GetDirectory().NewAccountURL, req, &account)
 
 	location := sender.GetLocation(resp)
 
@@ -55,7 +55,7 @@ func (a *AccountService) Get(ctx context.Context, accountURL string) (acme.Accou
 
 	var account acme.Account
 
-	_, err := a.core.postAsGet(ctx, accountURL, &account)
+	_, err := a.core.postAsGet(sender.WithEndpoint(ctx, sender.EndpointAccount), accountURL, &account)
 	if err != nil {
 		return acme.Account{}, err
 	}
@@ -73,7 +73,7 @@ func (a *AccountService) Update(ctx context.Context, accountURL string, req acme
 
 	var account acme.Account
 
-	_, err := a.core.post(ctx, accountURL, req, &account)
+	_, err := a.core.post(sender.WithEndpoint(ctx, sender.EndpointAccount), accountURL, req, &account)
 	if err != nil {
 		return acme.Account{}, err
 	}
@@ -89,7 +89,7 @@ func (a *AccountService) Deactivate(ctx context.Context, accountURL string) erro
 	}
 
 	req := acme.Account{Status: acme.StatusDeactivated}
-	_, err := a.core.post(ctx, accountURL, req, nil)
+	_, err := a.core.post(sender.WithEndpoint(ctx, sender.EndpointAccount), accountURL, req, nil)
 
 	return err
 }
@@ -104,7 +104,7 @@ func (a *AccountService) KeyChange(ctx context.Context, newKey crypto.Signer) er
 		return err
 	}
 
-	_, err = a.core.retrievablePost(ctx, uri, []byte(eabJWS.FullSerialize()), nil)
+	_, err = a.core.retrievablePost(sender.WithEndpoint(ctx, sender.EndpointKeyChange), uri, []byte(eabJWS.FullSerialize()), nil)
 	if err != nil {
 		return err
 	}
@@ -173,6 +173,14 @@ func (a *Core) GetKeyAuthorization(token string) (string, error) {
 	return a.signer().GetKeyAuthorization(token)
 }
 
+// GetKid Gets the key identifier (account URI).
+func (a *Core) GetKid() string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	return a.kid
+}
+
 func (a *Core) GetDirectory() acme.Directory {
 	return a.directory
 }
@@ -180,7 +188,7 @@ func (a *Core) GetDirectory() acme.Directory {
 func getDirectory(ctx context.Context, do *sender.Doer, caDirURL string) (acme.Directory, error) {
 	var dir acme.Directory
 
-	_, err := do.Get(ctx, caDirURL, &dir)
+	_, err := do.Get(sender.WithEndpoint(ctx, sender.EndpointDirectory), caDirURL, &dir)
 	if err != nil {
 		return dir, fmt.Errorf("get directory at '%s': %w", caDirURL, err)
 	}
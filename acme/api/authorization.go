@@ -3,12 +3,57 @@ package api
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/go-acme/lego/v5/acme"
+	"github.com/go-acme/lego/v5/acme/api/internal/sender"
 )
 
+// ErrNoNewAuthz is returned when the server does not advertise a newAuthz endpoint (pre-authorization).
+var ErrNoNewAuthz = errors.New("authorization[new]: server does not advertise a newAuthz endpoint")
+
 type AuthorizationService service
 
+// New Creates an authorization for an identifier (pre-authorization).
+//
+// Note: the pre-authorization is optional, not all ACME servers implement it.
+// This method will return api.ErrNoNewAuthz if the server does not advertise a newAuthz endpoint.
+// The pre-authorization cannot be used for wildcard domains.
+//
+// https://www.rfc-editor.org/rfc/rfc8555.html#section-7.4.1
+func (c *AuthorizationService) New(ctx context.Context, domain string) (acme.ExtendedAuthorization, error) {
+	if c.core.GetDirectory().NewAuthzURL == "" {
+		return acme.ExtendedAuthorization{}, ErrNoNewAuthz
+	}
+
+	if domain == "" {
+		return acme.ExtendedAuthorization{}, errors.New("authorization[new]: empty domain")
+	}
+
+	if strings.HasPrefix(domain, "*.") {
+		return acme.ExtendedAuthorization{}, fmt.Errorf("authorization[new]: the pre-authorization cannot be used for a wildcard domain: %s", domain)
+	}
+
+	req := struct {
+		Identifier acme.Identifier `json:"identifier"`
+	}{
+		Identifier: createIdentifiers([]string{domain})[0],
+	}
+
+	var authz acme.Authorization
+
+	resp, err := c.core.post(sender.WithEndpoint(ctx, sender.EndpointNewAuthz), c.core.GetDirectory().NewAuthzURL, req, &authz)
+	if err != nil {
+		return acme.ExtendedAuthorization{}, err
+	}
+
+	return acme.ExtendedAuthorization{
+		Authorization: authz,
+		Location:      sender.GetLocation(resp),
+	}, nil
+}
+
 // Get Gets an authorization.
 func (c *AuthorizationService) Get(ctx context.Context, authzURL string) (acme.Authorization, error) {
 	if authzURL == "" {
@@ -17,7 +62,7 @@ func (c *AuthorizationService) Get(ctx context.Context, authzURL string) (acme.A
 
 	var authz acme.Authorization
 
-	_, err := c.core.postAsGet(ctx, authzURL, &authz)
+	_, err := c.core.postAsGet(sender.WithEndpoint(ctx, sender.EndpointAuthorization), authzURL, &authz)
 	if err != nil {
 		return acme.Authorization{}, err
 	}
@@ -33,7 +78,7 @@ func (c *AuthorizationService) Deactivate(ctx context.Context, authzURL string)
 
 	var disabledAuth acme.Authorization
 
-	_, err := c.core.post(ctx, authzURL, acme.Authorization{Status: acme.StatusDeactivated}, &disabledAuth)
+	_, err := c.core.post(sender.WithEndpoint(ctx, sender.EndpointAuthorization), authzURL, acme.Authorization{Status: acme.StatusDeactivated}, &disabledAuth)
 
 	return err
 }
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/go-acme/lego/v5/acme"
+	"github.com/go-acme/lego/v5/internal/tester"
+	"github.com/go-acme/lego/v5/internal/tester/servermock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthorizationService_New(t *testing.T) {
+	// small value keeps test fast
+	privateKey, errK := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, errK, "Could not generate test key")
+
+	server := tester.MockACMEServer().
+		Route("POST /newAuthz",
+			http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				body, err := readSignedBody(req, privateKey)
+				if err != nil {
+					http.Error(rw, err.Error(), http.StatusBadRequest)
+					return
+				}
+
+				var payload struct {
+					Identifier acme.Identifier `json:"identifier"`
+				}
+
+				err = json.Unmarshal(body, &payload)
+				if err != nil {
+					http.Error(rw, err.Error(), http.StatusBadRequest)
+					return
+				}
+
+				rw.Header().Set("Location", "https://example.com/authz/123")
+				rw.WriteHeader(http.StatusCreated)
+
+				servermock.JSONEncode(acme.Authorization{
+					Status:     acme.StatusPending,
+					Identifier: payload.Identifier,
+				}).ServeHTTP(rw, req)
+			})).
+		BuildHTTPS(t)
+
+	core, err := New(server.Client(), "lego-test", server.URL+"/dir", "", privateKey)
+	require.NoError(t, err)
+
+	authz, err := core.Authorizations.New(t.Context(), "example.com")
+	require.NoError(t, err)
+
+	expected := acme.ExtendedAuthorization{
+		Authorization: acme.Authorization{
+			Status:     acme.StatusPending,
+			Identifier: acme.Identifier{Type: "dns", Value: "example.com"},
+		},
+		Location: "https://example.com/authz/123",
+	}
+
+	assert.Equal(t, expected, authz)
+}
+
+func TestAuthorizationService_New_errors(t *testing.T) {
+	// small value keeps test fast
+	privateKey, errK := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, errK, "Could not generate test key")
+
+	server := tester.MockACMEServer().BuildHTTPS(t)
+
+	core, err := New(server.Client(), "lego-test", server.URL+"/dir", "", privateKey)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		desc     string
+		domain   string
+		expected string
+	}{
+		{
+			desc:     "empty domain",
+			expected: "authorization[new]: empty domain",
+		},
+		{
+			desc:     "wildcard",
+			domain:   "*.example.com",
+			expected: "authorization[new]: the pre-authorization cannot be used for a wildcard domain: *.example.com",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := core.Authorizations.New(t.Context(), test.domain)
+			require.EqualError(t, err, test.expected)
+		})
+	}
+}
@@ -56,7 +56,7 @@ func (c *CertificateService) GetAll(ctx context.Context, certURL string, bundle
 
 // Revoke Revokes a certificate.
 func (c *CertificateService) Revoke(ctx context.Context, req acme.RevokeCertMessage) error {
-	_, err := c.core.post(ctx, c.core.GetDirectory().RevokeCertURL, req, nil)
+	_, err := c.core.post(sender.WithEndpoint(ctx, sender.EndpointRevokeCert), c.core.GetDirectory().RevokeCertURL, req, nil)
 	return err
 }
 
@@ -66,7 +66,7 @@ func (c *CertificateService) get(ctx context.Context, certURL string, bundle boo
 		return nil, nil, errors.New("certificate[get]: empty URL")
 	}
 
-	resp, err := c.core.postAsGet(ctx, certURL, nil)
+	resp, err := c.core.postAsGet(sender.WithEndpoint(ctx, sender.EndpointCertificate), certURL, nil)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -33,7 +33,7 @@ func (c *CertificateService) GetRenewalInfo(ctx context.Context, certID string)
 
 	info := new(acme.ExtendedRenewalInfo)
 
-	resp, err := c.core.doer.Get(ctx, c.core.GetDirectory().RenewalInfo+"/"+certID, info)
+	resp, err := c.core.doer.Get(sender.WithEndpoint(ctx, sender.EndpointRenewalInfo), c.core.GetDirectory().RenewalInfo+"/"+certID, info)
 	if err != nil {
 		return nil, err
 	}
@@ -20,7 +20,7 @@ func (c *ChallengeService) New(ctx context.Context, chlgURL string) (acme.Extend
 	// We use an empty struct instance as the postJSON payload here to achieve this result.
 	var chlng acme.ExtendedChallenge
 
-	resp, err := c.core.post(ctx, chlgURL, struct{}{}, &chlng)
+	resp, err := c.core.post(sender.WithEndpoint(ctx, sender.EndpointChallenge), chlgURL, struct{}{}, &chlng)
 	if err != nil {
 		return acme.ExtendedChallenge{}, err
 	}
@@ -39,7 +39,7 @@ func (c *ChallengeService) Get(ctx context.Context, chlgURL string) (acme.Extend
 
 	var chlng acme.ExtendedChallenge
 
-	resp, err := c.core.postAsGet(ctx, chlgURL, &chlng)
+	resp, err := c.core.postAsGet(sender.WithEndpoint(ctx, sender.EndpointChallenge), chlgURL, &chlng)
 	if err != nil {
 		return acme.ExtendedChallenge{}, err
 	}
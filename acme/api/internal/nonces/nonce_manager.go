@@ -56,7 +56,7 @@ func (n *Manager) getNonce(ctx context.Context) (string, error) {
 		return nonce, nil
 	}
 
-	resp, err := n.do.Head(ctx, n.nonceURL)
+	resp, err := n.do.Head(sender.WithEndpoint(ctx, sender.EndpointNewNonce), n.nonceURL)
 	if err != nil {
 		return "", fmt.Errorf("failed to get nonce from HTTP HEAD: %w", err)
 	}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/go-acme/lego/v5/acme"
 	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/cryptosigner"
 )
 
 // Signer Represents a Signer.
@@ -33,7 +34,7 @@ func (j *Signer) SignContent(ns jose.NonceSource, url string, content []byte) (*
 	signKey := jose.SigningKey{
 		Algorithm: signatureAlgorithm(j.privKey),
 		Key: jose.JSONWebKey{
-			Key:   j.privKey,
+			Key:   signingKey(j.privKey),
 			KeyID: j.kid,
 		},
 	}
@@ -51,9 +52,9 @@ func (j *Signer) SignContent(ns jose.NonceSource, url string, content []byte) (*
 
 // SignEAB Signs an external account binding with the Signer.
 func (j *Signer) SignEAB(url, kid string, hmac []byte) (*jose.JSONWebSignature, error) {
-	jwk := jose.JSONWebKey{Key: j.privKey}
+	jwk := jose.JSONWebKey{Key: j.privKey.Public()}
 
-	jwkJSON, err := jwk.Public().MarshalJSON()
+	jwkJSON, err := jwk.MarshalJSON()
 	if err != nil {
 		return nil, fmt.Errorf("acme: error encoding eab jwk key: %w", err)
 	}
@@ -102,9 +103,9 @@ func (j *Signer) SignKeyChange(url string, newKey crypto.Signer) (*jose.JSONWebS
 		return nil, errors.New("missing kid")
 	}
 
-	oldKeyJWS := jose.JSONWebKey{Key: j.privKey}
+	oldKeyJWS := jose.JSONWebKey{Key: j.privKey.Public()}
 
-	oldKeyJSON, err := oldKeyJWS.Public().MarshalJSON()
+	oldKeyJSON, err := oldKeyJWS.MarshalJSON()
 	if err != nil {
 		return nil, err
 	}
@@ -116,7 +117,7 @@ func (j *Signer) SignKeyChange(url string, newKey crypto.Signer) (*jose.JSONWebS
 
 	signKey := jose.SigningKey{
 		Algorithm: signatureAlgorithm(newKey),
-		Key:       newKey,
+		Key:       signingKey(newKey),
 	}
 
 	options := &jose.SignerOptions{
@@ -148,6 +149,17 @@ func sign(content []byte, signKey jose.SigningKey, options *jose.SignerOptions)
 	return signed, nil
 }
 
+// signingKey returns the key to use with jose.
+// The keys that are not in memory (ex: HSM) are used through an opaque signer.
+func signingKey(privKey crypto.Signer) any {
+	switch privKey.(type) {
+	case *rsa.PrivateKey, *ecdsa.PrivateKey:
+		return privKey
+	default:
+		return cryptosigner.Opaque(privKey)
+	}
+}
+
 func signatureAlgorithm(privKey crypto.Signer) jose.SignatureAlgorithm {
 	var alg jose.SignatureAlgorithm
 
@@ -1,6 +1,7 @@
 package secure
 
 import (
+	"crypto"
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
@@ -33,6 +34,28 @@ func TestSigner_SignContent(t *testing.T) {
 	check(t, content)
 }
 
+// externalSigner simulates a key that is not in memory (ex: HSM).
+type externalSigner struct {
+	crypto.Signer
+}
+
+func TestSigner_SignContent_externalSigner(t *testing.T) {
+	privKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	signer := NewSigner(&externalSigner{Signer: privKey}, "")
+
+	content, err := signer.SignContent(&MockNonceSource{}, "https://foo.example", []byte("{}"))
+	require.NoError(t, err)
+
+	check(t, content)
+
+	payload, err := content.Verify(&privKey.PublicKey)
+	require.NoError(t, err)
+
+	assert.Equal(t, "{}", string(payload))
+}
+
 func TestSigner_SignEAB(t *testing.T) {
 	privKey, err := rsa.GenerateKey(rand.Reader, 2048)
 	require.NoError(t, err)
@@ -0,0 +1,39 @@
+package sender
+
+import "context"
+
+// Names of the ACME endpoints, used by the metrics.
+const (
+	EndpointDirectory     = "directory"
+	EndpointNewNonce      = "newNonce"
+	EndpointNewAccount    = "newAccount"
+	EndpointAccount       = "account"
+	EndpointKeyChange     = "keyChange"
+	EndpointNewOrder      = "newOrder"
+	EndpointOrder         = "order"
+	EndpointNewAuthz      = "newAuthz"
+	EndpointFinalize      = "finalize"
+	EndpointAuthorization = "authz"
+	EndpointChallenge     = "challenge"
+	EndpointCertificate   = "certificate"
+	EndpointRevokeCert    = "revokeCert"
+	EndpointRenewalInfo   = "renewalInfo"
+
+	endpointUnknown = "unknown"
+)
+
+type endpointKey struct{}
+
+// WithEndpoint sets the name of the ACME endpoint of the requests.
+func WithEndpoint(ctx context.Context, endpoint string) context.Context {
+	return context.WithValue(ctx, endpointKey{}, endpoint)
+}
+
+func endpointFromContext(ctx context.Context) string {
+	endpoint, ok := ctx.Value(endpointKey{}).(string)
+	if !ok {
+		return endpointUnknown
+	}
+
+	return endpoint
+}
@@ -3,14 +3,18 @@ package sender
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/go-acme/lego/v5/acme"
 	"github.com/go-acme/lego/v5/internal/errutils"
+	"github.com/go-acme/lego/v5/internal/tracing"
+	"github.com/go-acme/lego/v5/metrics"
 )
 
 type RequestOption func(*http.Request) error
@@ -29,7 +33,7 @@ type Doer struct {
 
 // NewDoer Creates a new Doer.
 func NewDoer(client *http.Client, userAgent string) *Doer {
-	client.Transport = newHTTPSOnly(client)
+	client.Transport = tracing.WrapTransport(newHTTPSOnly(client))
 
 	return &Doer{
 		httpClient: client,
@@ -89,12 +93,25 @@ func (d *Doer) newRequest(ctx context.Context, method, uri string, body io.Reade
 }
 
 func (d *Doer) do(req *http.Request, response any) (*http.Response, error) {
+	endpoint := endpointFromContext(req.Context())
+
+	start := time.Now()
+
 	resp, err := d.httpClient.Do(req)
 	if err != nil {
+		metrics.Default().ObserveRequest(endpoint, req.Method, 0, time.Since(start))
+
 		return nil, errutils.NewHTTPDoError(req, err)
 	}
 
+	metrics.Default().ObserveRequest(endpoint, req.Method, resp.StatusCode, time.Since(start))
+
 	if err = checkError(req, resp); err != nil {
+		var rateLimitedErr *acme.RateLimitedError
+		if errors.As(err, &rateLimitedErr) {
+			metrics.Default().IncRateLimited(endpoint)
+		}
+
 		return resp, err
 	}
 
@@ -3,13 +3,16 @@ package sender
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-acme/lego/v5/acme"
+	"github.com/go-acme/lego/v5/metrics"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -148,3 +151,44 @@ func errorAs[T error](t *testing.T, err error) {
 	var zero T
 	assert.ErrorAs(t, err, &zero)
 }
+
+func TestDo_metrics(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		rw.Header().Set("Content-Type", "application/problem+json")
+		rw.WriteHeader(http.StatusTooManyRequests)
+		_, _ = rw.Write([]byte(`{"type":"urn:ietf:params:acme:error:rateLimited","detail":"too many requests"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	recorder := &fakeRecorder{}
+
+	metrics.SetDefault(recorder)
+	t.Cleanup(func() { metrics.SetDefault(nil) })
+
+	doer := NewDoer(server.Client(), "")
+
+	_, err := doer.Get(WithEndpoint(t.Context(), EndpointNewOrder), server.URL, nil)
+
+	var rateLimitedErr *acme.RateLimitedError
+	require.ErrorAs(t, err, &rateLimitedErr)
+
+	_, _ = doer.Get(t.Context(), server.URL, nil)
+
+	assert.Equal(t, []string{"newOrder GET 429", "unknown GET 429"}, recorder.requests)
+	assert.Equal(t, []string{EndpointNewOrder, "unknown"}, recorder.rateLimited)
+}
+
+type fakeRecorder struct {
+	metrics.Noop
+
+	requests    []string
+	rateLimited []string
+}
+
+func (r *fakeRecorder) ObserveRequest(endpoint, method string, statusCode int, _ time.Duration) {
+	r.requests = append(r.requests, fmt.Sprintf("%s %s %d", endpoint, method, statusCode))
+}
+
+func (r *fakeRecorder) IncRateLimited(endpoint string) {
+	r.rateLimited = append(r.rateLimited, endpoint)
+}
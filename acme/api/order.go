@@ -54,7 +54,7 @@ func (o *OrderService) New(ctx context.Context, domains []string, opts *OrderOpt
 
 	var order acme.Order
 
-	resp, err := o.core.post(ctx, o.core.GetDirectory().NewOrderURL, orderReq, &order)
+	resp, err := o.core.post(sender.WithEndpoint(ctx, sender.EndpointNewOrder), o.core.GetDirectory().NewOrderURL, orderReq, &order)
 	if err != nil {
 		are := &acme.AlreadyReplacedError{}
 		if !errors.As(err, &are) {
@@ -66,7 +66,7 @@ func (o *OrderService) New(ctx context.Context, domains []string, opts *OrderOpt
 		// https://www.rfc-editor.org/rfc/rfc9773.html#section-5
 		orderReq.Replaces = ""
 
-		resp, err = o.core.post(ctx, o.core.GetDirectory().NewOrderURL, orderReq, &order)
+		resp, err = o.core.post(sender.WithEndpoint(ctx, sender.EndpointNewOrder), o.core.GetDirectory().NewOrderURL, orderReq, &order)
 		if err != nil {
 			return acme.ExtendedOrder{}, err
 		}
@@ -106,7 +106,7 @@ func (o *OrderService) Get(ctx context.Context, orderURL string) (acme.ExtendedO
 
 	var order acme.Order
 
-	_, err := o.core.postAsGet(ctx, orderURL, &order)
+	_, err := o.core.postAsGet(sender.WithEndpoint(ctx, sender.EndpointOrder), orderURL, &order)
 	if err != nil {
 		return acme.ExtendedOrder{}, err
 	}
@@ -122,7 +122,7 @@ func (o *OrderService) UpdateForCSR(ctx context.Context, orderURL string, csr []
 
 	var order acme.Order
 
-	_, err := o.core.post(ctx, orderURL, csrMsg, &order)
+	_, err := o.core.post(sender.WithEndpoint(ctx, sender.EndpointFinalize), orderURL, csrMsg, &order)
 	if err != nil {
 		return acme.ExtendedOrder{}, err
 	}
@@ -167,8 +167,15 @@ func CreateCSR(privateKey crypto.Signer, opts CSROptions) ([]byte, error) {
 	return x509.CreateCertificateRequest(rand.Reader, &template, privateKey)
 }
 
+// PEMEncode encodes the data to PEM.
+// It returns nil if the type of the data is not supported (ex: a private key held by an HSM).
 func PEMEncode(data any) []byte {
-	return pem.EncodeToMemory(PEMBlock(data))
+	pemBlock := PEMBlock(data)
+	if pemBlock == nil {
+		return nil
+	}
+
+	return pem.EncodeToMemory(pemBlock)
 }
 
 func PEMBlock(data any) *pem.Block {
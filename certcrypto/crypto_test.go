@@ -143,6 +143,16 @@ func TestPEMEncode(t *testing.T) {
 	assert.Empty(t, p.Headers)
 }
 
+func TestPEMEncode_unsupported(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err, "Error generating private key")
+
+	// A private key that cannot be exported (ex: HSM).
+	signer := struct{ crypto.Signer }{Signer: key}
+
+	assert.Nil(t, PEMEncode(signer))
+}
+
 func TestParsePEMCertificate(t *testing.T) {
 	privateKey, err := GeneratePrivateKey(RSA2048)
 	require.NoError(t, err, "Error generating private key")
@@ -4,27 +4,319 @@ import (
 	"context"
 	"errors"
 	"log/slog"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/go-acme/lego/v5/acme"
+	"github.com/go-acme/lego/v5/acme/api"
+	"github.com/go-acme/lego/v5/challenge"
+	"github.com/go-acme/lego/v5/event"
+	"github.com/go-acme/lego/v5/internal/tracing"
 	"github.com/go-acme/lego/v5/log"
 )
 
-func (c *Certifier) getAuthorizations(ctx context.Context, order acme.ExtendedOrder) ([]acme.Authorization, error) {
-	resc, errc := make(chan acme.Authorization), make(chan error)
+// Authorization is an authorization of the account.
+type Authorization struct {
+	acme.Authorization
+
+	URL string `json:"url"`
+}
+
+// ListAuthorizations returns the authorizations of an order.
+//
+// ACME doesn't provide a way to list the authorizations of an account,
+// so the authorizations are found from the URL of a known order (ex: the order of an issued certificate).
+// The listing doesn't create an order, and doesn't modify the authorizations.
+func (c *Certifier) ListAuthorizations(ctx context.Context, orderURL string) ([]Authorization, error) {
+	if orderURL == "" {
+		return nil, errors.New("no order URL to list the authorizations from")
+	}
+
+	err := c.limiter.Wait(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := c.core.Orders.Get(ctx, orderURL)
+	if err != nil {
+		return nil, err
+	}
 
-	delay := time.Second / time.Duration(c.overallRequestLimit)
+	var authorizations []Authorization
 
 	for _, authzURL := range order.Authorizations {
-		time.Sleep(delay)
+		authz, err := c.GetAuthorization(ctx, authzURL)
+		if err != nil {
+			return nil, err
+		}
+
+		authorizations = append(authorizations, authz)
+	}
+
+	return authorizations, nil
+}
+
+// GetAuthorization returns an authorization.
+func (c *Certifier) GetAuthorization(ctx context.Context, authzURL string) (Authorization, error) {
+	err := c.limiter.Wait(ctx)
+	if err != nil {
+		return Authorization{}, err
+	}
+
+	authz, err := c.core.Authorizations.Get(ctx, authzURL)
+	if err != nil {
+		return Authorization{}, err
+	}
+
+	return Authorization{Authorization: authz, URL: authzURL}, nil
+}
+
+// DeactivateAuthorization deactivates an authorization.
+// A deactivated authorization cannot be reused by the server.
+// See https://www.rfc-editor.org/rfc/rfc8555.html#section-7.5.2.
+func (c *Certifier) DeactivateAuthorization(ctx context.Context, authzURL string) error {
+	err := c.limiter.Wait(ctx)
+	if err != nil {
+		return err
+	}
+
+	return c.core.Authorizations.Deactivate(ctx, authzURL)
+}
+
+// PreAuthorize validates the domains ahead of the issuance (pre-authorization):
+// the server reuses the valid authorizations for the next orders of the account,
+// as long as the authorizations are not expired.
+//
+// The authorizations are created with the newAuthz endpoint.
+// When the server doesn't advertise it (or for the wildcard domains),
+// an order is created (and not finalized) to validate the authorizations.
+// See https://www.rfc-editor.org/rfc/rfc8555.html#section-7.4.1.
+//
+// The authorizations are returned with the status reported by the server after the validation.
+func (c *Certifier) PreAuthorize(ctx context.Context, domains []string) ([]Authorization, error) {
+	if len(domains) == 0 {
+		return nil, errors.New("no domains to pre-authorize")
+	}
+
+	domains = sanitizeDomain(domains)
+
+	reservation, err := c.options.AuthorizationCache.reserve(ctx, domains)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = c.options.AuthorizationCache.withPropagation(ctx)
+
+	var validated []acme.Authorization
+
+	defer func() { c.options.AuthorizationCache.release(reservation, validated) }()
+
+	authorizations, remaining, err := c.newAuthorizations(ctx, domains)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(remaining) > 0 {
+		authz, err := c.preAuthorizeOrder(ctx, remaining)
+		if err != nil {
+			c.deactivatePendingAuthorizations(ctx, authorizations)
+			return nil, err
+		}
+
+		authorizations = append(authorizations, authz...)
+	}
+
+	for _, authz := range authorizations {
+		if authz.Status != acme.StatusValid {
+			log.Warn("The authorization is not valid after the pre-authorization.",
+				log.DomainAttr(challenge.GetTargetedDomain(authz.Authorization)),
+				slog.String("status", authz.Status),
+				slog.String("url", authz.URL),
+			)
+
+			continue
+		}
+
+		validated = append(validated, authz.Authorization)
+	}
+
+	return authorizations, nil
+}
+
+// newAuthorizations creates the authorizations of the domains with the newAuthz endpoint, and solves their challenges.
+// Returns the domains that require an order: the wildcard domains,
+// or all the domains when the server doesn't advertise the newAuthz endpoint.
+func (c *Certifier) newAuthorizations(ctx context.Context, domains []string) ([]Authorization, []string, error) {
+	var (
+		authorizations []Authorization
+		remaining      []string
+	)
+
+	for _, domain := range domains {
+		if strings.HasPrefix(domain, "*.") {
+			remaining = append(remaining, domain)
+			continue
+		}
+
+		authz, err := c.newAuthorization(ctx, domain)
+		if errors.Is(err, api.ErrNoNewAuthz) {
+			log.Info("The server doesn't support the pre-authorization (newAuthz); using an order instead.",
+				log.DomainsAttr(domains),
+			)
+
+			c.deactivatePendingAuthorizations(ctx, authorizations)
+
+			return nil, domains, nil
+		}
+
+		if err != nil {
+			c.deactivatePendingAuthorizations(ctx, authorizations)
+			return nil, nil, err
+		}
+
+		authorizations = append(authorizations, authz)
+	}
+
+	if len(authorizations) == 0 {
+		return nil, remaining, nil
+	}
+
+	authz := make([]acme.Authorization, 0, len(authorizations))
+
+	for _, a := range authorizations {
+		authz = append(authz, a.Authorization)
+	}
+
+	reportReusedAuthorizations(authz)
+
+	err := c.resolver.Solve(ctx, authz)
+	if err != nil {
+		c.deactivatePendingAuthorizations(ctx, authorizations)
+		return nil, nil, err
+	}
+
+	// The status of the authorizations after the validation of the challenges.
+	for i, a := range authorizations {
+		authorizations[i], err = c.GetAuthorization(ctx, a.URL)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return authorizations, remaining, nil
+}
+
+// newAuthorization creates an authorization with the newAuthz endpoint.
+func (c *Certifier) newAuthorization(ctx context.Context, domain string) (Authorization, error) {
+	err := c.limiter.Wait(ctx)
+	if err != nil {
+		return Authorization{}, err
+	}
+
+	ctx, span := tracing.Start(ctx, "lego.authorization.create", tracing.AttrDomain.String(domain))
+
+	authz, err := c.core.Authorizations.New(ctx, domain)
+
+	tracing.End(span, err)
+
+	if err != nil {
+		return Authorization{}, err
+	}
+
+	eventType := event.AuthorizationPending
+	if authz.Status == acme.StatusValid {
+		eventType = event.AuthorizationReused
+	}
+
+	c.options.Events.Publish(ctx, event.Event{
+		Type:             eventType,
+		Domain:           authz.Identifier.Value,
+		AuthorizationURL: authz.Location,
+	})
+
+	return Authorization{Authorization: authz.Authorization, URL: authz.Location}, nil
+}
+
+// preAuthorizeOrder creates an order (not finalized) and solves the challenges of its authorizations.
+func (c *Certifier) preAuthorizeOrder(ctx context.Context, domains []string) ([]Authorization, error) {
+	order, authz, err := c.createOrder(ctx, domains, nil, false)
+	if err != nil {
+		return nil, err
+	}
+
+	reportReusedAuthorizations(authz)
+
+	err = c.resolver.Solve(ctx, authz)
+	if err != nil {
+		c.deactivateAuthorizations(ctx, order, false)
+		return nil, err
+	}
+
+	authorizations := make([]Authorization, 0, len(authz))
+
+	for _, authzURL := range order.Authorizations {
+		a, err := c.GetAuthorization(ctx, authzURL)
+		if err != nil {
+			return nil, err
+		}
+
+		authorizations = append(authorizations, a)
+	}
+
+	return authorizations, nil
+}
+
+// deactivatePendingAuthorizations deactivates the authorizations not valid before the pre-authorization.
+func (c *Certifier) deactivatePendingAuthorizations(ctx context.Context, authorizations []Authorization) {
+	for _, authz := range authorizations {
+		if authz.Status == acme.StatusValid {
+			continue
+		}
+
+		log.Info("Deactivating authorization.", slog.String("url", authz.URL))
+
+		if c.DeactivateAuthorization(ctx, authz.URL) != nil {
+			log.Warn("Unable to deactivate the authorization.", slog.String("url", authz.URL))
+		}
+	}
+}
 
+func (c *Certifier) getAuthorizations(ctx context.Context, order acme.ExtendedOrder) ([]acme.Authorization, error) {
+	resc, errc := make(chan acme.Authorization), make(chan error)
+
+	for _, authzURL := range order.Authorizations {
 		go func(authzURL string) {
+			err := c.limiter.Wait(ctx)
+			if err != nil {
+				errc <- err
+				return
+			}
+
 			authz, err := c.core.Authorizations.Get(ctx, authzURL)
 			if err != nil {
 				errc <- err
 				return
 			}
 
+			switch authz.Status {
+			case acme.StatusPending:
+				c.options.Events.Publish(ctx, event.Event{
+					Type:             event.AuthorizationPending,
+					Domain:           authz.Identifier.Value,
+					OrderURL:         order.Location,
+					AuthorizationURL: authzURL,
+				})
+
+			case acme.StatusValid:
+				c.options.Events.Publish(ctx, event.Event{
+					Type:             event.AuthorizationReused,
+					Domain:           authz.Identifier.Value,
+					OrderURL:         order.Location,
+					AuthorizationURL: authzURL,
+				})
+			}
+
 			resc <- authz
 		}(authzURL)
 	}
@@ -57,7 +349,7 @@ func (c *Certifier) getAuthorizations(ctx context.Context, order acme.ExtendedOr
 
 func (c *Certifier) deactivateAuthorizations(ctx context.Context, order acme.ExtendedOrder, force bool) {
 	for _, authzURL := range order.Authorizations {
-		auth, err := c.core.Authorizations.Get(ctx, authzURL)
+		auth, err := c.GetAuthorization(ctx, authzURL)
 		if err != nil {
 			log.Warn("Unable to get the authorization.",
 				slog.String("url", authzURL),
@@ -75,8 +367,186 @@ func (c *Certifier) deactivateAuthorizations(ctx context.Context, order acme.Ext
 
 		log.Info("Deactivating authorization.", slog.String("url", authzURL))
 
-		if c.core.Authorizations.Deactivate(ctx, authzURL) != nil {
+		if c.DeactivateAuthorization(ctx, authzURL) != nil {
 			log.Warn("Unable to deactivate the authorization.", slog.String("url", authzURL))
 		}
 	}
 }
+
+// deactivateExpiringAuthorizations deactivates the valid authorizations of the order expiring within minValidity.
+func (c *Certifier) deactivateExpiringAuthorizations(ctx context.Context, order acme.ExtendedOrder, minValidity time.Duration) {
+	for _, authzURL := range order.Authorizations {
+		authz, err := c.GetAuthorization(ctx, authzURL)
+		if err != nil {
+			log.Warn("Unable to get the authorization.",
+				slog.String("url", authzURL),
+				log.ErrorAttr(err),
+			)
+
+			continue
+		}
+
+		if !isExpiring(authz.Authorization, minValidity) {
+			continue
+		}
+
+		log.Info("Deactivating the authorization expiring soon; the challenge will be solved again.",
+			log.DomainAttr(challenge.GetTargetedDomain(authz.Authorization)),
+			slog.String("url", authzURL),
+			slog.Time("expires", authz.Expires),
+		)
+
+		err = c.DeactivateAuthorization(ctx, authzURL)
+		if err != nil {
+			log.Warn("Unable to deactivate the authorization.",
+				slog.String("url", authzURL),
+				log.ErrorAttr(err),
+			)
+		}
+	}
+}
+
+// hasExpiringAuthorizations returns true if at least one valid authorization expires within minValidity.
+// A zero minValidity disables the check.
+func hasExpiringAuthorizations(authorizations []acme.Authorization, minValidity time.Duration) bool {
+	if minValidity <= 0 {
+		return false
+	}
+
+	return slices.ContainsFunc(authorizations, func(authz acme.Authorization) bool {
+		return isExpiring(authz, minValidity)
+	})
+}
+
+func isExpiring(authz acme.Authorization, minValidity time.Duration) bool {
+	return authz.Status == acme.StatusValid && !authz.Expires.IsZero() && time.Until(authz.Expires) < minValidity
+}
+
+// reportReusedAuthorizations logs the valid authorizations reused by the server.
+func reportReusedAuthorizations(authorizations []acme.Authorization) {
+	for _, authz := range authorizations {
+		if authz.Status != acme.StatusValid {
+			continue
+		}
+
+		log.Info("Reusing a valid authorization.",
+			log.DomainAttr(challenge.GetTargetedDomain(authz)),
+			slog.Time("expires", authz.Expires),
+		)
+	}
+}
+
+// validAuthorizations returns the authorizations with the valid status:
+// the authorizations are validated when all the challenges are solved.
+func validAuthorizations(authorizations []acme.Authorization) []acme.Authorization {
+	result := make([]acme.Authorization, 0, len(authorizations))
+
+	for _, authz := range authorizations {
+		authz.Status = acme.StatusValid
+
+		result = append(result, authz)
+	}
+
+	return result
+}
+
+// skipChallenges removes the skipped challenge types from the authorizations.
+func skipChallenges(authorizations []acme.Authorization, skipped map[string][]challenge.Type) []acme.Authorization {
+	if len(skipped) == 0 {
+		return authorizations
+	}
+
+	result := make([]acme.Authorization, 0, len(authorizations))
+
+	for _, authz := range authorizations {
+		types := skipped[challenge.GetTargetedDomain(authz)]
+
+		if len(types) > 0 {
+			authz.Challenges = slices.DeleteFunc(slices.Clone(authz.Challenges), func(chlg acme.Challenge) bool {
+				return slices.Contains(types, challenge.Type(chlg.Type))
+			})
+		}
+
+		result = append(result, authz)
+	}
+
+	return result
+}
+
+// addFailedChallenges adds the failed challenge types to the skipped challenge types.
+// Returns true if at least one new challenge type has been added.
+func addFailedChallenges(err error, skipped map[string][]challenge.Type) bool {
+	var added bool
+
+	for _, solveErr := range collectSolveErrors(err) {
+		if solveErr.Type == "" || slices.Contains(skipped[solveErr.Domain], solveErr.Type) {
+			continue
+		}
+
+		log.Info("Skipping the failed challenge type for the next attempt.",
+			log.DomainAttr(solveErr.Domain),
+			slog.String("type", solveErr.Type.String()),
+		)
+
+		skipped[solveErr.Domain] = append(skipped[solveErr.Domain], solveErr.Type)
+		added = true
+	}
+
+	return added
+}
+
+// hasRemainingChallenges returns true if all the failed authorizations have another challenge type
+// offered by the server and available in the resolver.
+func (c *Certifier) hasRemainingChallenges(authorizations []acme.Authorization, skipped map[string][]challenge.Type) bool {
+	r, hasTypes := c.resolver.(challengeTypesResolver)
+
+	for _, authz := range authorizations {
+		domain := challenge.GetTargetedDomain(authz)
+
+		if authz.Status == acme.StatusValid || len(skipped[domain]) == 0 {
+			continue
+		}
+
+		var available []challenge.Type
+		if hasTypes {
+			available = r.ChallengeTypes(domain)
+		}
+
+		remaining := slices.ContainsFunc(authz.Challenges, func(chlg acme.Challenge) bool {
+			chlgType := challenge.Type(chlg.Type)
+
+			return !slices.Contains(skipped[domain], chlgType) && (!hasTypes || slices.Contains(available, chlgType))
+		})
+
+		if !remaining {
+			log.Info("No other challenge type available; not retrying.", log.DomainAttr(domain))
+
+			return false
+		}
+	}
+
+	return true
+}
+
+// collectSolveErrors returns all the SolveError contained in the error tree.
+func collectSolveErrors(err error) []*challenge.SolveError {
+	switch e := err.(type) {
+	case *challenge.SolveError:
+		return []*challenge.SolveError{e}
+
+	case interface{ Unwrap() []error }:
+		var result []*challenge.SolveError
+
+		for _, err := range e.Unwrap() {
+			result = append(result, collectSolveErrors(err)...)
+		}
+
+		return result
+
+	case interface{ Unwrap() error }:
+		return collectSolveErrors(e.Unwrap())
+
+	default:
+		return nil
+	}
+}
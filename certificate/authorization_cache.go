@@ -0,0 +1,160 @@
+package certificate
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-acme/lego/v5/acme"
+	"github.com/go-acme/lego/v5/challenge"
+	"github.com/go-acme/lego/v5/challenge/dns01"
+	"github.com/go-acme/lego/v5/log"
+)
+
+// AuthorizationCache shares the authorizations of an account between the orders of several certificates.
+//
+// An order is created only when the same identifiers are not being solved by another order,
+// so the ACME server can reuse the authorizations validated by the other order instead of creating new ones,
+// and the challenges (and the DNS propagation) of an identifier are not solved twice at the same time.
+//
+// The apex domain and the wildcard domain (ex: `example.com` and `*.example.com`) share the same challenge record,
+// so they are not solved at the same time either,
+// and the propagation of the record is shared between the orders (see dns01.PropagationCache).
+//
+// An AuthorizationCache can be shared by the clients of the same account (see lego.CertificateConfig).
+type AuthorizationCache struct {
+	mu sync.Mutex
+
+	// valid are the identifiers with a valid authorization, with the expiration date of the authorization.
+	valid map[string]time.Time
+
+	// pending are the challenge record names being solved, with the channel closed at the end of the resolution.
+	pending map[string]chan struct{}
+
+	// propagation is the propagation of the DNS challenge records.
+	propagation *dns01.PropagationCache
+}
+
+// NewAuthorizationCache creates a new AuthorizationCache.
+func NewAuthorizationCache() *AuthorizationCache {
+	return &AuthorizationCache{
+		valid:       make(map[string]time.Time),
+		pending:     make(map[string]chan struct{}),
+		propagation: dns01.NewPropagationCache(),
+	}
+}
+
+// withPropagation returns a copy of the context with the propagation cache of the DNS challenge records.
+// The challenges share the propagation of the DNS records with the other orders.
+func (a *AuthorizationCache) withPropagation(ctx context.Context) context.Context {
+	if a == nil {
+		return ctx
+	}
+
+	return dns01.WithPropagationCache(ctx, a.propagation)
+}
+
+// authorizationReservation is the set of identifiers reserved by an order.
+type authorizationReservation struct {
+	domains []string
+	done    chan struct{}
+}
+
+// reserve waits until the domains (and the domains sharing their challenge record) are not being solved by another order,
+// then reserves the domains without a valid authorization.
+// A nil cache reserves nothing.
+func (a *AuthorizationCache) reserve(ctx context.Context, domains []string) (*authorizationReservation, error) {
+	if a == nil {
+		return nil, nil
+	}
+
+	for {
+		a.mu.Lock()
+
+		wait, domain := a.findPending(domains)
+		if wait == nil {
+			reservation := &authorizationReservation{done: make(chan struct{})}
+
+			for _, domain := range domains {
+				if a.isValid(domain) {
+					log.Debug("Authorization already validated by another order.", log.DomainAttr(domain))
+
+					continue
+				}
+
+				a.pending[challengeRecordName(domain)] = reservation.done
+				reservation.domains = append(reservation.domains, domain)
+			}
+
+			a.mu.Unlock()
+
+			return reservation, nil
+		}
+
+		a.mu.Unlock()
+
+		log.Info("Authorization being solved by another order; waiting.", log.DomainAttr(domain))
+
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// release releases the reserved domains, and keeps the valid authorizations.
+func (a *AuthorizationCache) release(reservation *authorizationReservation, authorizations []acme.Authorization) {
+	if a == nil || reservation == nil {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for _, domain := range reservation.domains {
+		delete(a.pending, challengeRecordName(domain))
+	}
+
+	for _, authz := range authorizations {
+		if authz.Status != acme.StatusValid || authz.Expires.IsZero() {
+			continue
+		}
+
+		a.valid[challenge.GetTargetedDomain(authz)] = authz.Expires
+	}
+
+	close(reservation.done)
+}
+
+func (a *AuthorizationCache) findPending(domains []string) (chan struct{}, string) {
+	for _, domain := range domains {
+		if wait, ok := a.pending[challengeRecordName(domain)]; ok {
+			return wait, domain
+		}
+	}
+
+	return nil, ""
+}
+
+func (a *AuthorizationCache) isValid(domain string) bool {
+	expires, ok := a.valid[domain]
+	if !ok {
+		return false
+	}
+
+	if time.Now().Before(expires) {
+		return true
+	}
+
+	delete(a.valid, domain)
+
+	return false
+}
+
+// challengeRecordName returns the name of the challenge record of the domain:
+// the apex domain and the wildcard domain share the same record.
+func challengeRecordName(domain string) string {
+	return strings.TrimPrefix(domain, "*.")
+}
@@ -0,0 +1,112 @@
+package certificate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v5/acme"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthorizationCache_reserve(t *testing.T) {
+	cache := NewAuthorizationCache()
+
+	first, err := cache.reserve(t.Context(), []string{"example.com", "*.example.com"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"example.com", "*.example.com"}, first.domains)
+
+	reserved := make(chan *authorizationReservation)
+
+	go func() {
+		reservation, errR := cache.reserve(t.Context(), []string{"example.org", "example.com"})
+		assert.NoError(t, errR)
+
+		reserved <- reservation
+	}()
+
+	// The domain is being solved by the first order.
+	select {
+	case <-reserved:
+		t.Fatal("the domain is already reserved")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	cache.release(first, []acme.Authorization{
+		{Status: acme.StatusValid, Identifier: acme.Identifier{Value: "example.com"}, Expires: time.Now().Add(time.Hour)},
+		{Status: acme.StatusValid, Identifier: acme.Identifier{Value: "example.com"}, Wildcard: true, Expires: time.Now().Add(time.Hour)},
+	})
+
+	second := <-reserved
+
+	// The domain with a valid authorization is not reserved.
+	assert.Equal(t, []string{"example.org"}, second.domains)
+
+	cache.release(second, nil)
+
+	assert.Empty(t, cache.pending)
+}
+
+func TestAuthorizationCache_reserve_expired(t *testing.T) {
+	cache := NewAuthorizationCache()
+
+	first, err := cache.reserve(t.Context(), []string{"example.com", "example.org"})
+	require.NoError(t, err)
+
+	cache.release(first, []acme.Authorization{
+		{Status: acme.StatusValid, Identifier: acme.Identifier{Value: "example.com"}, Expires: time.Now().Add(-time.Hour)},
+		{Status: acme.StatusPending, Identifier: acme.Identifier{Value: "example.org"}, Expires: time.Now().Add(time.Hour)},
+	})
+
+	second, err := cache.reserve(t.Context(), []string{"example.com", "example.org"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"example.com", "example.org"}, second.domains)
+}
+
+func TestAuthorizationCache_reserve_sharedRecord(t *testing.T) {
+	cache := NewAuthorizationCache()
+
+	first, err := cache.reserve(t.Context(), []string{"example.com"})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+
+	// The wildcard domain shares the challenge record of the apex domain.
+	_, err = cache.reserve(ctx, []string{"*.example.com"})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	cache.release(first, nil)
+
+	second, err := cache.reserve(t.Context(), []string{"*.example.com"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"*.example.com"}, second.domains)
+}
+
+func TestAuthorizationCache_reserve_canceled(t *testing.T) {
+	cache := NewAuthorizationCache()
+
+	_, err := cache.reserve(t.Context(), []string{"example.com"})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err = cache.reserve(ctx, []string{"example.com"})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestAuthorizationCache_nil(t *testing.T) {
+	var cache *AuthorizationCache
+
+	reservation, err := cache.reserve(t.Context(), []string{"example.com"})
+	require.NoError(t, err)
+
+	assert.Nil(t, reservation)
+
+	cache.release(reservation, nil)
+}
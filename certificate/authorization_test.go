@@ -0,0 +1,170 @@
+package certificate
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v5/acme"
+	"github.com/go-acme/lego/v5/challenge"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_addFailedChallenges(t *testing.T) {
+	skipped := make(map[string][]challenge.Type)
+
+	err := fmt.Errorf("resolver: %w", errors.Join(
+		&challenge.SolveError{Domain: "example.com", Type: challenge.HTTP01, Err: errors.New("oops")},
+		&challenge.SolveError{Domain: "*.example.com", Type: challenge.DNS01, Err: errors.New("oops")},
+		errors.New("prober: could not determine solvers"),
+	))
+
+	assert.True(t, addFailedChallenges(err, skipped))
+
+	expected := map[string][]challenge.Type{
+		"example.com":   {challenge.HTTP01},
+		"*.example.com": {challenge.DNS01},
+	}
+
+	assert.Equal(t, expected, skipped)
+
+	// Already skipped.
+	assert.False(t, addFailedChallenges(err, skipped))
+
+	assert.False(t, addFailedChallenges(errors.New("oops"), skipped))
+}
+
+func TestCertifier_hasRemainingChallenges(t *testing.T) {
+	authorizations := []acme.Authorization{
+		{
+			Status:     acme.StatusPending,
+			Identifier: acme.Identifier{Value: "example.com"},
+			Challenges: []acme.Challenge{{Type: "http-01"}, {Type: "dns-01"}},
+		},
+		{
+			Status:     acme.StatusValid,
+			Identifier: acme.Identifier{Value: "example.org"},
+			Challenges: []acme.Challenge{{Type: "http-01"}},
+		},
+	}
+
+	testCases := []struct {
+		desc     string
+		resolver resolver
+		skipped  map[string][]challenge.Type
+		expected bool
+	}{
+		{
+			desc:     "another solver available",
+			resolver: &challengeTypesResolverMock{types: []challenge.Type{challenge.DNS01, challenge.HTTP01}},
+			skipped:  map[string][]challenge.Type{"example.com": {challenge.HTTP01}},
+			expected: true,
+		},
+		{
+			desc:     "no other solver available",
+			resolver: &challengeTypesResolverMock{types: []challenge.Type{challenge.HTTP01}},
+			skipped:  map[string][]challenge.Type{"example.com": {challenge.HTTP01}},
+		},
+		{
+			desc:     "no other challenge offered",
+			resolver: &challengeTypesResolverMock{types: []challenge.Type{challenge.DNS01, challenge.HTTP01, challenge.TLSALPN01}},
+			skipped:  map[string][]challenge.Type{"example.com": {challenge.HTTP01, challenge.DNS01}},
+		},
+		{
+			desc:     "resolver without challenge types",
+			resolver: &resolverMock{},
+			skipped:  map[string][]challenge.Type{"example.com": {challenge.HTTP01}},
+			expected: true,
+		},
+		{
+			desc:     "valid authorization",
+			resolver: &challengeTypesResolverMock{types: []challenge.Type{challenge.HTTP01}},
+			skipped:  map[string][]challenge.Type{"example.org": {challenge.HTTP01}},
+			expected: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			certifier := &Certifier{resolver: test.resolver}
+
+			assert.Equal(t, test.expected, certifier.hasRemainingChallenges(authorizations, test.skipped))
+		})
+	}
+}
+
+func Test_skipChallenges(t *testing.T) {
+	authorizations := []acme.Authorization{
+		{
+			Identifier: acme.Identifier{Value: "example.com"},
+			Challenges: []acme.Challenge{{Type: "http-01"}, {Type: "dns-01"}},
+		},
+		{
+			Identifier: acme.Identifier{Value: "example.com"},
+			Wildcard:   true,
+			Challenges: []acme.Challenge{{Type: "dns-01"}},
+		},
+	}
+
+	skipped := map[string][]challenge.Type{
+		"example.com": {challenge.HTTP01},
+	}
+
+	result := skipChallenges(authorizations, skipped)
+
+	assert.Equal(t, []acme.Challenge{{Type: "dns-01"}}, result[0].Challenges)
+	assert.Equal(t, []acme.Challenge{{Type: "dns-01"}}, result[1].Challenges)
+
+	// The original authorizations are not modified.
+	assert.Equal(t, []acme.Challenge{{Type: "http-01"}, {Type: "dns-01"}}, authorizations[0].Challenges)
+}
+
+func Test_hasExpiringAuthorizations(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		authorizations []acme.Authorization
+		minValidity    time.Duration
+		expected       bool
+	}{
+		{
+			desc: "disabled",
+			authorizations: []acme.Authorization{
+				{Status: acme.StatusValid, Expires: time.Now().Add(time.Hour)},
+			},
+		},
+		{
+			desc: "expiring",
+			authorizations: []acme.Authorization{
+				{Status: acme.StatusPending, Expires: time.Now().Add(time.Hour)},
+				{Status: acme.StatusValid, Expires: time.Now().Add(time.Hour)},
+			},
+			minValidity: 24 * time.Hour,
+			expected:    true,
+		},
+		{
+			desc: "not expiring",
+			authorizations: []acme.Authorization{
+				{Status: acme.StatusValid, Expires: time.Now().Add(48 * time.Hour)},
+			},
+			minValidity: 24 * time.Hour,
+		},
+		{
+			desc: "pending",
+			authorizations: []acme.Authorization{
+				{Status: acme.StatusPending, Expires: time.Now().Add(time.Hour)},
+			},
+			minValidity: 24 * time.Hour,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, hasExpiringAuthorizations(test.authorizations, test.minValidity))
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package certificate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+
+	"github.com/go-acme/lego/v5/challenge"
+	"github.com/go-acme/lego/v5/challenge/dns01"
+	"github.com/go-acme/lego/v5/internal/errutils"
+	"github.com/go-acme/lego/v5/log"
+	"github.com/miekg/dns"
+)
+
+// CAA property tags.
+// - https://www.rfc-editor.org/rfc/rfc8659.html#section-4
+const (
+	caaTagIssue     = "issue"
+	caaTagIssueWild = "issuewild"
+	caaTagIodef     = "iodef"
+)
+
+// CAA parameters.
+// - https://www.rfc-editor.org/rfc/rfc8657.html#section-3
+const (
+	caaParamAccountURI        = "accounturi"
+	caaParamValidationMethods = "validationmethods"
+)
+
+// caaFlagCritical is the "Issuer Critical" flag of the CAA records.
+const caaFlagCritical = 128
+
+// challengeTypesResolver is implemented by the resolvers able to list the challenge types available for a domain.
+type challengeTypesResolver interface {
+	ChallengeTypes(domain string) []challenge.Type
+}
+
+// challengeTypeResolver is implemented by the resolvers able to tell the challenge type chosen for a domain.
+type challengeTypeResolver interface {
+	ChallengeType(domain string) challenge.Type
+}
+
+// caaPolicy contains the information about the issuance, compared with the CAA records.
+type caaPolicy struct {
+	// identities are the issuer domain names of the CA (acme.Meta.CaaIdentities).
+	identities []string
+
+	// accountURI is the URI of the ACME account.
+	accountURI string
+
+	// challengeType is the type of the challenge chosen for the domain.
+	// If empty, the validation methods are not evaluated.
+	challengeType challenge.Type
+}
+
+// checkCAA checks the CAA records of the domains before to create an order.
+// - https://www.rfc-editor.org/rfc/rfc8659.html
+// - https://www.rfc-editor.org/rfc/rfc8657.html
+func (c *Certifier) checkCAA(ctx context.Context, domains []string) error {
+	identities := c.core.GetDirectory().Meta.CaaIdentities
+	if len(identities) == 0 {
+		log.Warn("The ACME server doesn't provide CAA identities; skipping the CAA check.", log.DomainsAttr(domains))
+
+		return nil
+	}
+
+	failures := errutils.NewDomainsError("CAA")
+
+	for _, domain := range domains {
+		// CAA records are not applicable to IP addresses.
+		if net.ParseIP(domain) != nil {
+			continue
+		}
+
+		policy := caaPolicy{
+			identities: identities,
+			accountURI: c.core.GetKid(),
+		}
+
+		if r, ok := c.resolver.(challengeTypeResolver); ok {
+			policy.challengeType = r.ChallengeType(domain)
+		}
+
+		records, owner, err := dns01.DefaultClient().LookupCAA(ctx, strings.TrimPrefix(domain, "*."))
+		if err != nil {
+			failures.Add(domain, fmt.Errorf("lookup: %w", err))
+
+			continue
+		}
+
+		err = policy.evaluate(records, strings.HasPrefix(domain, "*."))
+		if err != nil {
+			failures.Add(domain, fmt.Errorf("%s: %w", owner, err))
+		}
+	}
+
+	return failures.Join()
+}
+
+// evaluate checks if the relevant CAA RRset allows the issuance.
+func (p caaPolicy) evaluate(records []*dns.CAA, wildcard bool) error {
+	tag := caaTagIssue
+
+	for _, record := range records {
+		switch strings.ToLower(record.Tag) {
+		case caaTagIssue, caaTagIodef:
+			// Known tags.
+
+		case caaTagIssueWild:
+			if wildcard {
+				// The issuewild properties take precedence over the issue properties for a wildcard domain.
+				tag = caaTagIssueWild
+			}
+
+		default:
+			if record.Flag&caaFlagCritical != 0 {
+				return fmt.Errorf("unknown critical property %q", record.Tag)
+			}
+		}
+	}
+
+	var errs []error
+
+	for _, record := range records {
+		if !strings.EqualFold(record.Tag, tag) {
+			continue
+		}
+
+		err := p.match(record.Value)
+		if err == nil {
+			return nil
+		}
+
+		errs = append(errs, fmt.Errorf("%s %q: %w", tag, record.Value, err))
+	}
+
+	// No issue (or issuewild) property: the issuance is not restricted.
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return errors.Join(errs...)
+}
+
+// match checks if the value of an issue (or issuewild) property allows the issuance.
+func (p caaPolicy) match(value string) error {
+	issuer, params, err := parseCAAValue(value)
+	if err != nil {
+		return err
+	}
+
+	if issuer == "" {
+		return errors.New("no CA is allowed")
+	}
+
+	if !slices.ContainsFunc(p.identities, func(identity string) bool { return strings.EqualFold(identity, issuer) }) {
+		return fmt.Errorf("the CA is not allowed (%s)", strings.Join(p.identities, ", "))
+	}
+
+	if uri, ok := params[caaParamAccountURI]; ok && uri != p.accountURI {
+		return fmt.Errorf("the account is not allowed (%s)", p.accountURI)
+	}
+
+	if methods, ok := params[caaParamValidationMethods]; ok && p.challengeType != "" {
+		if !slices.Contains(strings.Split(methods, ","), string(p.challengeType)) {
+			return fmt.Errorf("the challenge type is not allowed (%s)", p.challengeType)
+		}
+	}
+
+	return nil
+}
+
+// parseCAAValue parses the value of an issue (or issuewild) property.
+// - https://www.rfc-editor.org/rfc/rfc8659.html#section-4.2
+func parseCAAValue(value string) (string, map[string]string, error) {
+	parts := strings.Split(value, ";")
+
+	params := make(map[string]string)
+
+	for _, part := range parts[1:] {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		key, val, ok := strings.Cut(part, "=")
+		if !ok {
+			return "", nil, fmt.Errorf("invalid parameter: %q", part)
+		}
+
+		params[strings.TrimSpace(key)] = strings.TrimSpace(val)
+	}
+
+	return strings.TrimSpace(parts[0]), params, nil
+}
+
+func joinChallengeTypes(types []challenge.Type, sep string) string {
+	var values []string
+	for _, t := range types {
+		values = append(values, string(t))
+	}
+
+	return strings.Join(values, sep)
+}
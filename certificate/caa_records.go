@@ -0,0 +1,236 @@
+package certificate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+
+	"github.com/go-acme/lego/v5/challenge"
+	"github.com/go-acme/lego/v5/challenge/dns01"
+	"github.com/miekg/dns"
+)
+
+// DefaultCAATTL is the TTL of the new CAA records.
+const DefaultCAATTL = 3600
+
+// CAAChange is the change of the CAA RRset of a domain.
+type CAAChange struct {
+	// FQDN is the owner name of the CAA RRset.
+	FQDN string
+
+	// Current is the current CAA RRset of the FQDN.
+	// It is empty when the CAA records are inherited from a parent domain.
+	Current []*dns.CAA
+
+	// Inherited is the CAA RRset of the parent domain applicable to the FQDN, when the FQDN has no CAA RRset.
+	// The desired RRset is based on it: once created, the RRset of the FQDN replaces the inherited RRset.
+	Inherited []*dns.CAA
+
+	// Desired is the CAA RRset allowing the issuance by the CA, for the ACME account, and the available challenge types.
+	Desired []*dns.CAA
+}
+
+// HasChanges returns true if the desired RRset differs from the current RRset.
+func (c CAAChange) HasChanges() bool {
+	current := caaValues(c.Current)
+	slices.Sort(current)
+
+	desired := caaValues(c.Desired)
+	slices.Sort(desired)
+
+	return !slices.Equal(current, desired)
+}
+
+// Diff returns the differences between the current RRset and the desired RRset, one record per line.
+// The removed records are prefixed by `-`, the added records are prefixed by `+`.
+func (c CAAChange) Diff() string {
+	current := caaValues(c.Current)
+	desired := caaValues(c.Desired)
+
+	buffer := new(strings.Builder)
+
+	_, _ = fmt.Fprintf(buffer, "%s CAA\n", c.FQDN)
+
+	if len(c.Inherited) > 0 {
+		_, _ = fmt.Fprintf(buffer, "; WARNING: the records are inherited from %s, the new RRset replaces them\n", c.Inherited[0].Hdr.Name)
+
+		for _, value := range caaValues(c.Inherited) {
+			_, _ = fmt.Fprintf(buffer, ";   %s\n", value)
+		}
+	}
+
+	for _, value := range current {
+		if slices.Contains(desired, value) {
+			_, _ = fmt.Fprintf(buffer, "  %s\n", value)
+		} else {
+			_, _ = fmt.Fprintf(buffer, "- %s\n", value)
+		}
+	}
+
+	for _, value := range desired {
+		if !slices.Contains(current, value) {
+			_, _ = fmt.Fprintf(buffer, "+ %s\n", value)
+		}
+	}
+
+	return buffer.String()
+}
+
+// Apply replaces the CAA RRset of the FQDN with the desired RRset.
+func (c CAAChange) Apply(ctx context.Context, provider dns01.RecordProvider) error {
+	records := make([]dns.RR, 0, len(c.Desired))
+	for _, record := range c.Desired {
+		records = append(records, record)
+	}
+
+	return provider.SetRecords(ctx, c.FQDN, dns.TypeCAA, records)
+}
+
+// CAAChanges computes the CAA RRsets allowing the issuance of a certificate for the domains
+// by the CA (acme.Meta.CaaIdentities), for the ACME account (accounturi), and with the available challenge types (validationmethods).
+//
+// The `issuewild` property is used for the wildcard domains, the `issue` property for the other domains.
+// The properties related to other CAs, and the other properties (ex: iodef) are kept.
+// When the CAA records are inherited from a parent domain, the desired RRset is based on the inherited records.
+// - https://www.rfc-editor.org/rfc/rfc8659.html
+// - https://www.rfc-editor.org/rfc/rfc8657.html
+func (c *Certifier) CAAChanges(ctx context.Context, domains []string) ([]CAAChange, error) {
+	identities := c.core.GetDirectory().Meta.CaaIdentities
+	if len(identities) == 0 {
+		return nil, errors.New("the ACME server doesn't provide CAA identities")
+	}
+
+	accountURI := c.core.GetKid()
+	if accountURI == "" {
+		return nil, errors.New("the account is not registered")
+	}
+
+	// FQDN -> tag -> challenge types.
+	properties := make(map[string]map[string][]challenge.Type)
+
+	var fqdns []string
+
+	for _, domain := range sanitizeDomain(domains) {
+		// CAA records are not applicable to IP addresses.
+		if net.ParseIP(domain) != nil {
+			continue
+		}
+
+		tag := caaTagIssue
+		if strings.HasPrefix(domain, "*.") {
+			tag = caaTagIssueWild
+		}
+
+		fqdn := dns.Fqdn(strings.ToLower(strings.TrimPrefix(domain, "*.")))
+
+		if _, ok := properties[fqdn]; !ok {
+			properties[fqdn] = make(map[string][]challenge.Type)
+			fqdns = append(fqdns, fqdn)
+		}
+
+		var types []challenge.Type
+		if r, ok := c.resolver.(challengeTypesResolver); ok {
+			types = r.ChallengeTypes(domain)
+		}
+
+		properties[fqdn][tag] = append(properties[fqdn][tag], types...)
+	}
+
+	var changes []CAAChange
+
+	for _, fqdn := range fqdns {
+		records, owner, err := dns01.DefaultClient().LookupCAA(ctx, fqdn)
+		if err != nil {
+			return nil, fmt.Errorf("%s: lookup: %w", fqdn, err)
+		}
+
+		change := CAAChange{FQDN: fqdn}
+
+		base := records
+
+		if owner == fqdn {
+			change.Current = records
+		} else {
+			change.Inherited = records
+			base = renameCAARecords(records, fqdn)
+		}
+
+		change.Desired = desiredCAARecords(fqdn, base, properties[fqdn], identities, accountURI)
+
+		changes = append(changes, change)
+	}
+
+	return changes, nil
+}
+
+// desiredCAARecords replaces the properties (issue or issuewild) related to the CA by the properties related to the account.
+func desiredCAARecords(fqdn string, current []*dns.CAA, properties map[string][]challenge.Type, identities []string, accountURI string) []*dns.CAA {
+	ttl := uint32(DefaultCAATTL)
+
+	var desired []*dns.CAA
+
+	for _, record := range current {
+		ttl = record.Hdr.Ttl
+
+		if _, ok := properties[strings.ToLower(record.Tag)]; ok {
+			issuer, _, err := parseCAAValue(record.Value)
+			if err == nil && slices.ContainsFunc(identities, func(identity string) bool { return strings.EqualFold(identity, issuer) }) {
+				continue
+			}
+		}
+
+		desired = append(desired, record)
+	}
+
+	for _, tag := range []string{caaTagIssue, caaTagIssueWild} {
+		types, ok := properties[tag]
+		if !ok {
+			continue
+		}
+
+		slices.Sort(types)
+		types = slices.Compact(types)
+
+		for _, identity := range identities {
+			value := fmt.Sprintf("%s; %s=%s", identity, caaParamAccountURI, accountURI)
+
+			if len(types) > 0 {
+				value += fmt.Sprintf("; %s=%s", caaParamValidationMethods, joinChallengeTypes(types, ","))
+			}
+
+			desired = append(desired, &dns.CAA{
+				Hdr:   dns.RR_Header{Name: fqdn, Rrtype: dns.TypeCAA, Class: dns.ClassINET, Ttl: ttl},
+				Tag:   tag,
+				Value: value,
+			})
+		}
+	}
+
+	return desired
+}
+
+// renameCAARecords returns copies of the records with the owner name fqdn.
+func renameCAARecords(records []*dns.CAA, fqdn string) []*dns.CAA {
+	renamed := make([]*dns.CAA, 0, len(records))
+
+	for _, record := range records {
+		record = dns.Copy(record).(*dns.CAA)
+		record.Hdr.Name = fqdn
+
+		renamed = append(renamed, record)
+	}
+
+	return renamed
+}
+
+func caaValues(records []*dns.CAA) []string {
+	var values []string
+	for _, record := range records {
+		values = append(values, fmt.Sprintf("%d %s %q", record.Flag, record.Tag, record.Value))
+	}
+
+	return values
+}
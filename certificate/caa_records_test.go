@@ -0,0 +1,191 @@
+package certificate
+
+import (
+	"testing"
+
+	"github.com/go-acme/lego/v5/challenge"
+	"github.com/go-acme/lego/v5/internal/tester/dnsmock"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_desiredCAARecords(t *testing.T) {
+	identities := []string{"letsencrypt.org"}
+	accountURI := "https://example.com/acct/123"
+
+	testCases := []struct {
+		desc       string
+		current    []*dns.CAA
+		properties map[string][]challenge.Type
+		expected   []string
+	}{
+		{
+			desc:       "no records",
+			properties: map[string][]challenge.Type{"issue": {challenge.DNS01}},
+			expected: []string{
+				`0 issue "letsencrypt.org; accounturi=https://example.com/acct/123; validationmethods=dns-01"`,
+			},
+		},
+		{
+			desc:       "no challenge types",
+			properties: map[string][]challenge.Type{"issue": nil},
+			expected: []string{
+				`0 issue "letsencrypt.org; accounturi=https://example.com/acct/123"`,
+			},
+		},
+		{
+			desc: "issue and issuewild",
+			properties: map[string][]challenge.Type{
+				"issue":     {challenge.HTTP01, challenge.DNS01},
+				"issuewild": {challenge.DNS01, challenge.DNS01},
+			},
+			expected: []string{
+				`0 issue "letsencrypt.org; accounturi=https://example.com/acct/123; validationmethods=dns-01,http-01"`,
+				`0 issuewild "letsencrypt.org; accounturi=https://example.com/acct/123; validationmethods=dns-01"`,
+			},
+		},
+		{
+			desc: "keep the other CAs and properties",
+			current: []*dns.CAA{
+				fakeCAA("issue", "example.net"),
+				fakeCAA("issue", "LetsEncrypt.org; accounturi=https://example.com/acct/456"),
+				fakeCAA("issuewild", "letsencrypt.org"),
+				fakeCAA("iodef", "mailto:security@example.com"),
+			},
+			properties: map[string][]challenge.Type{"issue": {challenge.DNS01}},
+			expected: []string{
+				`0 issue "example.net"`,
+				`0 issuewild "letsencrypt.org"`,
+				`0 iodef "mailto:security@example.com"`,
+				`0 issue "letsencrypt.org; accounturi=https://example.com/acct/123; validationmethods=dns-01"`,
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			records := desiredCAARecords("example.com.", test.current, test.properties, identities, accountURI)
+
+			assert.Equal(t, test.expected, caaValues(records))
+		})
+	}
+}
+
+func TestCAAChange_Diff(t *testing.T) {
+	change := CAAChange{
+		FQDN: "example.com.",
+		Current: []*dns.CAA{
+			fakeCAA("issue", "letsencrypt.org"),
+			fakeCAA("iodef", "mailto:security@example.com"),
+		},
+		Desired: []*dns.CAA{
+			fakeCAA("iodef", "mailto:security@example.com"),
+			fakeCAA("issue", "letsencrypt.org; accounturi=https://example.com/acct/123"),
+		},
+	}
+
+	assert.True(t, change.HasChanges())
+
+	expected := `example.com. CAA
+- 0 issue "letsencrypt.org"
+  0 iodef "mailto:security@example.com"
++ 0 issue "letsencrypt.org; accounturi=https://example.com/acct/123"
+`
+
+	assert.Equal(t, expected, change.Diff())
+}
+
+func TestCAAChange_Diff_inherited(t *testing.T) {
+	change := CAAChange{
+		FQDN: "www.example.com.",
+		Inherited: []*dns.CAA{
+			fakeCAA("issue", "example.net"),
+		},
+		Desired: []*dns.CAA{
+			fakeCAA("issue", "example.net"),
+			fakeCAA("issue", "letsencrypt.org; accounturi=https://example.com/acct/123"),
+		},
+	}
+
+	assert.True(t, change.HasChanges())
+
+	expected := `www.example.com. CAA
+; WARNING: the records are inherited from example.com., the new RRset replaces them
+;   0 issue "example.net"
++ 0 issue "example.net"
++ 0 issue "letsencrypt.org; accounturi=https://example.com/acct/123"
+`
+
+	assert.Equal(t, expected, change.Diff())
+}
+
+func TestCAAChange_HasChanges(t *testing.T) {
+	change := CAAChange{
+		FQDN:    "example.com.",
+		Current: []*dns.CAA{fakeCAA("issue", "letsencrypt.org"), fakeCAA("iodef", "mailto:security@example.com")},
+		Desired: []*dns.CAA{fakeCAA("iodef", "mailto:security@example.com"), fakeCAA("issue", "letsencrypt.org")},
+	}
+
+	assert.False(t, change.HasChanges())
+}
+
+func TestCertifier_CAAChanges(t *testing.T) {
+	mockDefaultDNSClient(t,
+		dnsmock.NewServer().
+			Query("example.com. CAA", dnsmock.Answer(fakeCAA("issue", "example.net"))).
+			Query("www.example.com. CAA", dnsmock.Error(dns.RcodeNameError)),
+	)
+
+	resolver := &challengeTypesResolverMock{types: []challenge.Type{challenge.DNS01}}
+
+	certifier := NewCertifier(newCAACore(t, "https://example.com/acct/123"), resolver, CertifierOptions{})
+
+	changes, err := certifier.CAAChanges(t.Context(), []string{"example.com", "*.example.com", "www.example.com", "192.0.2.1"})
+	require.NoError(t, err)
+
+	require.Len(t, changes, 2)
+
+	assert.Equal(t, "example.com.", changes[0].FQDN)
+	assert.Equal(t, []string{`0 issue "example.net"`}, caaValues(changes[0].Current))
+	assert.Equal(t, []string{
+		`0 issue "example.net"`,
+		`0 issue "letsencrypt.org; accounturi=https://example.com/acct/123; validationmethods=dns-01"`,
+		`0 issuewild "letsencrypt.org; accounturi=https://example.com/acct/123; validationmethods=dns-01"`,
+	}, caaValues(changes[0].Desired))
+
+	// The records of the parent domain are inherited.
+	assert.Equal(t, "www.example.com.", changes[1].FQDN)
+	assert.Empty(t, changes[1].Current)
+	assert.Equal(t, []string{`0 issue "example.net"`}, caaValues(changes[1].Inherited))
+	assert.Equal(t, []string{
+		`0 issue "example.net"`,
+		`0 issue "letsencrypt.org; accounturi=https://example.com/acct/123; validationmethods=dns-01"`,
+	}, caaValues(changes[1].Desired))
+
+	for _, record := range changes[1].Desired {
+		assert.Equal(t, "www.example.com.", record.Hdr.Name)
+	}
+
+	// The inherited records are not modified.
+	assert.Equal(t, "example.com.", changes[1].Inherited[0].Hdr.Name)
+}
+
+func TestCertifier_CAAChanges_notRegistered(t *testing.T) {
+	certifier := NewCertifier(newCAACore(t, ""), &resolverMock{}, CertifierOptions{})
+
+	_, err := certifier.CAAChanges(t.Context(), []string{"example.com"})
+	require.EqualError(t, err, "the account is not registered")
+}
+
+type challengeTypesResolverMock struct {
+	resolverMock
+
+	types []challenge.Type
+}
+
+func (r *challengeTypesResolverMock) ChallengeTypes(_ string) []challenge.Type {
+	return r.types
+}
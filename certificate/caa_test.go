@@ -0,0 +1,249 @@
+package certificate
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v5/acme"
+	"github.com/go-acme/lego/v5/acme/api"
+	"github.com/go-acme/lego/v5/certcrypto"
+	"github.com/go-acme/lego/v5/challenge"
+	"github.com/go-acme/lego/v5/challenge/dns01"
+	"github.com/go-acme/lego/v5/internal/tester/dnsmock"
+	"github.com/go-acme/lego/v5/internal/tester/servermock"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_caaPolicy_evaluate(t *testing.T) {
+	policy := caaPolicy{
+		identities:    []string{"letsencrypt.org"},
+		accountURI:    "https://example.com/acct/123",
+		challengeType: challenge.DNS01,
+	}
+
+	testCases := []struct {
+		desc     string
+		records  []*dns.CAA
+		wildcard bool
+		expected string
+	}{
+		{
+			desc: "no records",
+		},
+		{
+			desc:    "no issue property",
+			records: []*dns.CAA{fakeCAA("iodef", "mailto:security@example.com")},
+		},
+		{
+			desc:    "allowed",
+			records: []*dns.CAA{fakeCAA("issue", "example.net"), fakeCAA("issue", "LetsEncrypt.org")},
+		},
+		{
+			desc:     "not allowed",
+			records:  []*dns.CAA{fakeCAA("issue", "example.net")},
+			expected: `issue "example.net": the CA is not allowed (letsencrypt.org)`,
+		},
+		{
+			desc:     "no CA allowed",
+			records:  []*dns.CAA{fakeCAA("issue", ";")},
+			expected: `issue ";": no CA is allowed`,
+		},
+		{
+			desc:     "wildcard: issuewild",
+			records:  []*dns.CAA{fakeCAA("issue", "letsencrypt.org"), fakeCAA("issuewild", "example.net")},
+			wildcard: true,
+			expected: `issuewild "example.net": the CA is not allowed (letsencrypt.org)`,
+		},
+		{
+			desc:     "wildcard: fallback to issue",
+			records:  []*dns.CAA{fakeCAA("issue", "letsencrypt.org")},
+			wildcard: true,
+		},
+		{
+			desc:    "issuewild ignored for non-wildcard",
+			records: []*dns.CAA{fakeCAA("issue", "letsencrypt.org"), fakeCAA("issuewild", ";")},
+		},
+		{
+			desc:    "accounturi: allowed",
+			records: []*dns.CAA{fakeCAA("issue", "letsencrypt.org; accounturi=https://example.com/acct/123")},
+		},
+		{
+			desc:     "accounturi: not allowed",
+			records:  []*dns.CAA{fakeCAA("issue", "letsencrypt.org; accounturi=https://example.com/acct/456")},
+			expected: `issue "letsencrypt.org; accounturi=https://example.com/acct/456": the account is not allowed (https://example.com/acct/123)`,
+		},
+		{
+			desc:    "validationmethods: allowed",
+			records: []*dns.CAA{fakeCAA("issue", "letsencrypt.org; validationmethods=http-01,dns-01")},
+		},
+		{
+			desc:     "validationmethods: not allowed",
+			records:  []*dns.CAA{fakeCAA("issue", "letsencrypt.org; validationmethods=http-01")},
+			expected: `issue "letsencrypt.org; validationmethods=http-01": the challenge type is not allowed (dns-01)`,
+		},
+		{
+			desc:     "invalid parameter",
+			records:  []*dns.CAA{fakeCAA("issue", "letsencrypt.org; foo")},
+			expected: `issue "letsencrypt.org; foo": invalid parameter: "foo"`,
+		},
+		{
+			desc: "unknown critical property",
+			records: []*dns.CAA{
+				fakeCAA("issue", "letsencrypt.org"),
+				{Hdr: dns.RR_Header{Name: "example.com.", Rrtype: dns.TypeCAA}, Flag: 128, Tag: "tbs", Value: "foo"},
+			},
+			expected: `unknown critical property "tbs"`,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			err := policy.evaluate(test.records, test.wildcard)
+			if test.expected == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, test.expected)
+			}
+		})
+	}
+}
+
+func TestCertifier_Obtain_checkCAA(t *testing.T) {
+	mockDefaultDNSClient(t,
+		dnsmock.NewServer().
+			Query("example.com. CAA", dnsmock.Answer(fakeCAA("issue", "letsencrypt.org"))).
+			Query("www.example.org. CAA", dnsmock.Error(dns.RcodeNameError)).
+			Query("example.org. CAA", dnsmock.Answer(fakeCAA("issue", "example.net"))),
+	)
+
+	certifier := NewCertifier(newCAACore(t, ""), &resolverMock{}, CertifierOptions{})
+
+	_, err := certifier.Obtain(t.Context(), ObtainRequest{
+		Domains:  []string{"example.com", "www.example.org"},
+		CheckCAA: true,
+	})
+	require.EqualError(t, err,
+		`CAA: one or more domains had a problem: [www.example.org: example.org.: issue "example.net": the CA is not allowed (letsencrypt.org)]`)
+}
+
+func TestCertifier_Renew_checkCAA(t *testing.T) {
+	mockDefaultDNSClient(t,
+		dnsmock.NewServer().
+			Query("www.example.org. CAA", dnsmock.Error(dns.RcodeNameError)).
+			Query("example.org. CAA", dnsmock.Answer(fakeCAA("issue", "example.net"))),
+	)
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "www.example.org"},
+		DNSNames:     []string{"www.example.org"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, privateKey.Public(), privateKey)
+	require.NoError(t, err)
+
+	csrDER, err := certcrypto.CreateCSR(privateKey, certcrypto.CSROptions{Domain: "www.example.org"})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		desc    string
+		certRes Resource
+	}{
+		{
+			desc: "domains",
+			certRes: Resource{
+				Certificate: certcrypto.PEMEncode(certcrypto.DERCertificateBytes(certDER)),
+				PrivateKey:  certcrypto.PEMEncode(privateKey),
+			},
+		},
+		{
+			desc: "CSR",
+			certRes: Resource{
+				Certificate: certcrypto.PEMEncode(certcrypto.DERCertificateBytes(certDER)),
+				CSR:         certcrypto.PEMEncode(&x509.CertificateRequest{Raw: csrDER}),
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			certifier := NewCertifier(newCAACore(t, ""), &resolverMock{}, CertifierOptions{})
+
+			_, err := certifier.Renew(t.Context(), test.certRes, &RenewOptions{CheckCAA: true})
+			require.EqualError(t, err,
+				`CAA: one or more domains had a problem: [www.example.org: example.org.: issue "example.net": the CA is not allowed (letsencrypt.org)]`)
+		})
+	}
+}
+
+// newCAACore creates a core with a directory providing CAA identities.
+func newCAACore(t *testing.T, kid string) *api.Core {
+	t.Helper()
+
+	server := servermock.NewBuilder(
+		func(server *httptest.Server) (*httptest.Server, error) {
+			return server, nil
+		}).
+		Route("GET /dir", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			serverURL := fmt.Sprintf("https://%s", req.Context().Value(http.LocalAddrContextKey))
+
+			servermock.JSONEncode(acme.Directory{
+				NewNonceURL:   serverURL + "/nonce",
+				NewAccountURL: serverURL + "/account",
+				NewOrderURL:   serverURL + "/newOrder",
+				RevokeCertURL: serverURL + "/revokeCert",
+				KeyChangeURL:  serverURL + "/keyChange",
+				Meta:          acme.Meta{CaaIdentities: []string{"letsencrypt.org"}},
+			}).ServeHTTP(rw, req)
+		})).
+		BuildHTTPS(t)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	core, err := api.New(server.Client(), "lego-test", server.URL+"/dir", kid, key)
+	require.NoError(t, err)
+
+	return core
+}
+
+func mockDefaultDNSClient(t *testing.T, builder *dnsmock.Builder) {
+	t.Helper()
+
+	addr := builder.Build(t)
+
+	backup := dns01.DefaultClient()
+
+	t.Cleanup(func() {
+		dns01.SetDefaultClient(backup)
+	})
+
+	dns01.SetDefaultClient(dns01.NewClient(&dns01.Options{
+		RecursiveNameservers: []string{addr.String()},
+		NetworkStack:         challenge.IPv4Only,
+	}))
+}
+
+func fakeCAA(tag, value string) *dns.CAA {
+	return &dns.CAA{
+		Hdr:   dns.RR_Header{Name: "example.com.", Rrtype: dns.TypeCAA, Class: dns.ClassINET, Ttl: 3600},
+		Tag:   tag,
+		Value: value,
+	}
+}
@@ -18,11 +18,14 @@ import (
 	"github.com/go-acme/lego/v5/acme/api"
 	"github.com/go-acme/lego/v5/certcrypto"
 	"github.com/go-acme/lego/v5/challenge"
+	"github.com/go-acme/lego/v5/event"
 	"github.com/go-acme/lego/v5/internal/errutils"
+	"github.com/go-acme/lego/v5/internal/tracing"
 	"github.com/go-acme/lego/v5/internal/wait"
 	"github.com/go-acme/lego/v5/log"
 	"golang.org/x/crypto/ocsp"
 	"golang.org/x/net/idna"
+	"golang.org/x/time/rate"
 )
 
 const (
@@ -42,6 +45,7 @@ const maxBodySize = 1024 * 1024
 // Resource represents a CA issued certificate.
 // PrivateKey, Certificate and IssuerCertificate are all
 // already PEM encoded and can be directly written to disk.
+// PrivateKey is nil if the private key cannot be exported (ex: a private key held by an HSM).
 // Certificate may be a certificate bundle,
 // depending on the options supplied to create it.
 type Resource struct {
@@ -56,6 +60,10 @@ type Resource struct {
 	CertURL       string `json:"certUrl"`
 	CertStableURL string `json:"certStableUrl"`
 
+	// OrderURL is the URL of the order of the certificate.
+	// The authorizations of the order can be listed from it.
+	OrderURL string `json:"orderUrl,omitempty"`
+
 	PrivateKey        []byte `json:"-"`
 	Certificate       []byte `json:"-"`
 	IssuerCertificate []byte `json:"-"`
@@ -75,6 +83,18 @@ type Resource struct {
 //
 // If `AlwaysDeactivateAuthorizations` is true, the authorizations are also relinquished if the obtain request was successful.
 // See https://datatracker.ietf.org/doc/html/rfc8555#section-7.5.2.
+//
+// If `ChallengeFallback` is true, when the challenge of an authorization fails,
+// a new order is created and the challenge is solved with the next available solver.
+//
+// If `CheckCAA` is true, the CAA records of the domains are checked before to create the order.
+// See https://www.rfc-editor.org/rfc/rfc8659.html and https://www.rfc-editor.org/rfc/rfc8657.html.
+//
+// If `MinAuthorizationValidity` is not zero, the valid authorizations reused by the server
+// and expiring within this duration are deactivated, and solved again with a new order.
+//
+// If `Renewal` is true, the certificate replaces an existing certificate:
+// the event `certificate.renewed` is published after the issuance.
 type ObtainRequest struct {
 	Domains        []string
 	MustStaple     bool
@@ -96,6 +116,14 @@ type ObtainRequest struct {
 
 	AlwaysDeactivateAuthorizations bool
 
+	ChallengeFallback bool
+
+	CheckCAA bool
+
+	MinAuthorizationValidity time.Duration
+
+	Renewal bool
+
 	// A string uniquely identifying a previously-issued certificate which this
 	// order is intended to replace.
 	// - https://www.rfc-editor.org/rfc/rfc9773.html#section-5
@@ -116,6 +144,18 @@ func (r ObtainRequest) EffectiveKeyType() (certcrypto.KeyType, error) {
 //
 // If `AlwaysDeactivateAuthorizations` is true, the authorizations are also relinquished if the obtain request was successful.
 // See https://datatracker.ietf.org/doc/html/rfc8555#section-7.5.2.
+//
+// If `ChallengeFallback` is true, when the challenge of an authorization fails,
+// a new order is created and the challenge is solved with the next available solver.
+//
+// If `CheckCAA` is true, the CAA records of the domains are checked before to create the order.
+// See https://www.rfc-editor.org/rfc/rfc8659.html and https://www.rfc-editor.org/rfc/rfc8657.html.
+//
+// If `MinAuthorizationValidity` is not zero, the valid authorizations reused by the server
+// and expiring within this duration are deactivated, and solved again with a new order.
+//
+// If `Renewal` is true, the certificate replaces an existing certificate:
+// the event `certificate.renewed` is published after the issuance.
 type ObtainForCSRRequest struct {
 	CSR *x509.CertificateRequest
 
@@ -134,6 +174,14 @@ type ObtainForCSRRequest struct {
 
 	AlwaysDeactivateAuthorizations bool
 
+	ChallengeFallback bool
+
+	CheckCAA bool
+
+	MinAuthorizationValidity time.Duration
+
+	Renewal bool
+
 	// A string uniquely identifying a previously-issued certificate which this
 	// order is intended to replace.
 	// - https://www.rfc-editor.org/rfc/rfc9773.html#section-5
@@ -155,14 +203,35 @@ type resolver interface {
 type CertifierOptions struct {
 	Timeout             time.Duration
 	OverallRequestLimit int
+
+	// RequestLimiter limits the requests related to the authorizations (optional).
+	// It can be shared between several certifiers to apply the limit to all of them.
+	// If nil, the limiter is based on OverallRequestLimit.
+	RequestLimiter *rate.Limiter
+
+	// AuthorizationCache shares the authorizations between the orders of several certificates (optional).
+	AuthorizationCache *AuthorizationCache
+
+	// Events is the bus used to publish the issuance events (optional).
+	Events *event.Bus
+}
+
+// NewRequestLimiter creates a limiter allowing limit requests per second.
+// If limit is zero or negative, DefaultOverallRequestLimit is used.
+func NewRequestLimiter(limit int) *rate.Limiter {
+	if limit <= 0 {
+		limit = DefaultOverallRequestLimit
+	}
+
+	return rate.NewLimiter(rate.Limit(limit), 1)
 }
 
 // Certifier A service to obtain/renew/revoke certificates.
 type Certifier struct {
-	core                *api.Core
-	resolver            resolver
-	options             CertifierOptions
-	overallRequestLimit int
+	core     *api.Core
+	resolver resolver
+	options  CertifierOptions
+	limiter  *rate.Limiter
 }
 
 // NewCertifier creates a Certifier.
@@ -173,9 +242,9 @@ func NewCertifier(core *api.Core, resolver resolver, options CertifierOptions) *
 		options:  options,
 	}
 
-	c.overallRequestLimit = options.OverallRequestLimit
-	if c.overallRequestLimit <= 0 {
-		c.overallRequestLimit = DefaultOverallRequestLimit
+	c.limiter = options.RequestLimiter
+	if c.limiter == nil {
+		c.limiter = NewRequestLimiter(options.OverallRequestLimit)
 	}
 
 	return c
@@ -185,7 +254,10 @@ func NewCertifier(core *api.Core, resolver resolver, options CertifierOptions) *
 //
 // This function will never return a partial certificate.
 // If one domain in the list fails, the whole certificate will fail.
-func (c *Certifier) Obtain(ctx context.Context, request ObtainRequest) (*Resource, error) {
+func (c *Certifier) Obtain(ctx context.Context, request ObtainRequest) (_ *Resource, err error) {
+	ctx, span := tracing.Start(ctx, "lego.certificate.obtain", tracing.AttrDomains.StringSlice(request.Domains))
+	defer func() { tracing.End(span, err) }()
+
 	if len(request.Domains) == 0 {
 		return nil, errors.New("no domains to obtain a certificate for")
 	}
@@ -205,22 +277,15 @@ func (c *Certifier) Obtain(ctx context.Context, request ObtainRequest) (*Resourc
 		ReplacesCertID: request.ReplacesCertID,
 	}
 
-	order, err := c.core.Orders.New(ctx, domains, orderOpts)
-	if err != nil {
-		return nil, err
-	}
-
-	authz, err := c.getAuthorizations(ctx, order)
-	if err != nil {
-		// If any challenge fails, return. Do not generate partial SAN certificates.
-		c.deactivateAuthorizations(ctx, order, request.AlwaysDeactivateAuthorizations)
-		return nil, err
+	if request.CheckCAA {
+		err = c.checkCAA(ctx, domains)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	err = c.resolver.Solve(ctx, authz)
+	order, authz, err := c.authorize(ctx, domains, orderOpts, request.AlwaysDeactivateAuthorizations, request.ChallengeFallback, request.MinAuthorizationValidity)
 	if err != nil {
-		// If any challenge fails, return. Do not generate partial SAN certificates.
-		c.deactivateAuthorizations(ctx, order, request.AlwaysDeactivateAuthorizations)
 		return nil, err
 	}
 
@@ -249,7 +314,12 @@ func (c *Certifier) Obtain(ctx context.Context, request ObtainRequest) (*Resourc
 		cert.Profile = request.Profile
 	}
 
-	return cert, failures.Join()
+	err = failures.Join()
+	if err == nil && request.Renewal {
+		c.publishRenewed(ctx, cert)
+	}
+
+	return cert, err
 }
 
 // ObtainForCSR tries to obtain a certificate matching the CSR passed into it.
@@ -261,7 +331,10 @@ func (c *Certifier) Obtain(ctx context.Context, request ObtainRequest) (*Resourc
 //
 // This function will never return a partial certificate.
 // If one domain in the list fails, the whole certificate will fail.
-func (c *Certifier) ObtainForCSR(ctx context.Context, request ObtainForCSRRequest) (*Resource, error) {
+func (c *Certifier) ObtainForCSR(ctx context.Context, request ObtainForCSRRequest) (_ *Resource, err error) {
+	ctx, span := tracing.Start(ctx, "lego.certificate.obtain")
+	defer func() { tracing.End(span, err) }()
+
 	if request.CSR == nil {
 		return nil, errors.New("cannot obtain resource for CSR: CSR is missing")
 	}
@@ -283,22 +356,15 @@ func (c *Certifier) ObtainForCSR(ctx context.Context, request ObtainForCSRReques
 		ReplacesCertID: request.ReplacesCertID,
 	}
 
-	order, err := c.core.Orders.New(ctx, domains, orderOpts)
-	if err != nil {
-		return nil, err
-	}
-
-	authz, err := c.getAuthorizations(ctx, order)
-	if err != nil {
-		// If any challenge fails, return. Do not generate partial SAN certificates.
-		c.deactivateAuthorizations(ctx, order, request.AlwaysDeactivateAuthorizations)
-		return nil, err
+	if request.CheckCAA {
+		err = c.checkCAA(ctx, domains)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	err = c.resolver.Solve(ctx, authz)
+	order, authz, err := c.authorize(ctx, domains, orderOpts, request.AlwaysDeactivateAuthorizations, request.ChallengeFallback, request.MinAuthorizationValidity)
 	if err != nil {
-		// If any challenge fails, return. Do not generate partial SAN certificates.
-		c.deactivateAuthorizations(ctx, order, request.AlwaysDeactivateAuthorizations)
 		return nil, err
 	}
 
@@ -339,7 +405,121 @@ func (c *Certifier) ObtainForCSR(ctx context.Context, request ObtainForCSRReques
 		cert.Profile = request.Profile
 	}
 
-	return cert, failures.Join()
+	err = failures.Join()
+	if err == nil && request.Renewal {
+		c.publishRenewed(ctx, cert)
+	}
+
+	return cert, err
+}
+
+// authorize creates an order and solves the challenges of its authorizations.
+//
+// With the fallback, when the challenge of an authorization fails,
+// a new order is created and the failed challenge type is skipped for the related domain.
+// The new order is attempted as long as a new challenge type has failed,
+// and another challenge type is available for the failed authorizations.
+func (c *Certifier) authorize(ctx context.Context, domains []string, orderOpts *api.OrderOptions, alwaysDeactivate, fallback bool, minValidity time.Duration) (acme.ExtendedOrder, []acme.Authorization, error) {
+	// Targeted domain -> failed challenge types.
+	skipped := make(map[string][]challenge.Type)
+
+	for attempt := 1; ; attempt++ {
+		order, authz, err := c.authorizeOrder(ctx, domains, orderOpts, skipped, alwaysDeactivate, minValidity)
+		if err == nil {
+			return order, authz, nil
+		}
+
+		if !fallback || !addFailedChallenges(err, skipped) || !c.hasRemainingChallenges(authz, skipped) {
+			return acme.ExtendedOrder{}, nil, err
+		}
+
+		log.Warn("Challenge validation failed; retrying with a new order and the next solvers.",
+			log.DomainsAttr(domains),
+			slog.Int("attempt", attempt),
+			log.ErrorAttr(err),
+		)
+	}
+}
+
+// authorizeOrder creates an order and solves the challenges of its authorizations, skipping the failed challenge types.
+// The domains are reserved in the authorization cache during the resolution.
+// When the resolution fails, the authorizations of the order are returned with the error.
+func (c *Certifier) authorizeOrder(ctx context.Context, domains []string, orderOpts *api.OrderOptions, skipped map[string][]challenge.Type, alwaysDeactivate bool, minValidity time.Duration) (acme.ExtendedOrder, []acme.Authorization, error) {
+	reservation, err := c.options.AuthorizationCache.reserve(ctx, domains)
+	if err != nil {
+		return acme.ExtendedOrder{}, nil, err
+	}
+
+	ctx = c.options.AuthorizationCache.withPropagation(ctx)
+
+	var validated []acme.Authorization
+
+	defer func() { c.options.AuthorizationCache.release(reservation, validated) }()
+
+	order, authz, err := c.createOrder(ctx, domains, orderOpts, alwaysDeactivate)
+	if err != nil {
+		return acme.ExtendedOrder{}, nil, err
+	}
+
+	if hasExpiringAuthorizations(authz, minValidity) {
+		// The order becomes invalid when one of its authorizations is deactivated.
+		c.deactivateExpiringAuthorizations(ctx, order, minValidity)
+
+		order, authz, err = c.createOrder(ctx, domains, orderOpts, alwaysDeactivate)
+		if err != nil {
+			return acme.ExtendedOrder{}, nil, err
+		}
+	}
+
+	reportReusedAuthorizations(authz)
+
+	err = c.resolver.Solve(ctx, skipChallenges(authz, skipped))
+	if err != nil {
+		// If any challenge fails, return. Do not generate partial SAN certificates.
+		c.deactivateAuthorizations(ctx, order, alwaysDeactivate)
+		return acme.ExtendedOrder{}, authz, err
+	}
+
+	// The authorizations deactivated after the issuance cannot be reused.
+	if !alwaysDeactivate {
+		validated = validAuthorizations(authz)
+	}
+
+	return order, authz, nil
+}
+
+// createOrder creates an order and gets its authorizations.
+func (c *Certifier) createOrder(ctx context.Context, domains []string, orderOpts *api.OrderOptions, alwaysDeactivate bool) (acme.ExtendedOrder, []acme.Authorization, error) {
+	order, err := c.newOrder(ctx, domains, orderOpts)
+	if err != nil {
+		return acme.ExtendedOrder{}, nil, err
+	}
+
+	c.options.Events.Publish(ctx, event.Event{
+		Type:     event.OrderCreated,
+		Domains:  domains,
+		OrderURL: order.Location,
+	})
+
+	authz, err := c.getAuthorizations(ctx, order)
+	if err != nil {
+		// If any challenge fails, return. Do not generate partial SAN certificates.
+		c.deactivateAuthorizations(ctx, order, alwaysDeactivate)
+		return acme.ExtendedOrder{}, nil, err
+	}
+
+	return order, authz, nil
+}
+
+func (c *Certifier) newOrder(ctx context.Context, domains []string, opts *api.OrderOptions) (acme.ExtendedOrder, error) {
+	ctx, span := tracing.Start(ctx, "lego.order.create", tracing.AttrDomains.StringSlice(domains))
+
+	order, err := c.core.Orders.New(ctx, domains, opts)
+
+	span.SetAttributes(tracing.AttrOrderURL.String(order.Location))
+	tracing.End(span, err)
+
+	return order, err
 }
 
 func (c *Certifier) getForOrder(ctx context.Context, domains []string, order acme.ExtendedOrder, request ObtainRequest) (*Resource, error) {
@@ -392,12 +572,25 @@ func (c *Certifier) getForOrder(ctx context.Context, domains []string, order acm
 	return c.getForCSR(ctx, certRes, order, csr, request.Bundle, request.PreferredChain)
 }
 
-func (c *Certifier) getForCSR(ctx context.Context, certRes *Resource, order acme.ExtendedOrder, csr []byte, bundle bool, preferredChain string) (*Resource, error) {
+func (c *Certifier) getForCSR(ctx context.Context, certRes *Resource, order acme.ExtendedOrder, csr []byte, bundle bool, preferredChain string) (_ *Resource, err error) {
+	ctx, span := tracing.Start(ctx, "lego.order.finalize",
+		tracing.AttrDomains.StringSlice(certRes.Domains),
+		tracing.AttrOrderURL.String(order.Location),
+	)
+	defer func() { tracing.End(span, err) }()
+
 	respOrder, err := c.core.Orders.UpdateForCSR(ctx, order.Finalize, csr)
 	if err != nil {
 		return nil, err
 	}
 
+	c.options.Events.Publish(ctx, event.Event{
+		Type:     event.OrderFinalized,
+		Domains:  certRes.Domains,
+		OrderURL: order.Location,
+	})
+
+	certRes.OrderURL = order.Location
 	certRes.CertURL = respOrder.Certificate
 
 	if respOrder.Status == acme.StatusValid {
@@ -464,6 +657,13 @@ func (c *Certifier) checkResponse(ctx context.Context, certRes *Resource, order
 		return false, err
 	}
 
+	c.options.Events.Publish(ctx, event.Event{
+		Type:           event.CertificateDownloaded,
+		Domains:        certRes.Domains,
+		OrderURL:       order.Location,
+		CertificateURL: order.Certificate,
+	})
+
 	// Set the default certificate
 	certRes.IssuerCertificate = certs[order.Certificate].Issuer
 	certRes.Certificate = certs[order.Certificate].Cert
@@ -526,7 +726,17 @@ func (c *Certifier) RevokeWithReason(ctx context.Context, cert []byte, reason *u
 		Reason:      reason,
 	}
 
-	return c.core.Certificates.Revoke(ctx, revokeMsg)
+	err = c.core.Certificates.Revoke(ctx, revokeMsg)
+	if err != nil {
+		return err
+	}
+
+	c.options.Events.Publish(ctx, event.Event{
+		Type:    event.CertificateRevoked,
+		Domains: certcrypto.ExtractDomains(x509Cert),
+	})
+
+	return nil
 }
 
 // RenewOptions options used by [Certifier.Renew].
@@ -540,6 +750,9 @@ type RenewOptions struct {
 	Profile string
 
 	AlwaysDeactivateAuthorizations bool
+	ChallengeFallback              bool
+	CheckCAA                       bool
+	MinAuthorizationValidity       time.Duration
 	// Not supported for CSR request.
 	MustStaple     bool
 	EmailAddresses []string
@@ -585,7 +798,7 @@ func (c *Certifier) Renew(ctx context.Context, certRes Resource, options *RenewO
 			return nil, errP
 		}
 
-		request := ObtainForCSRRequest{CSR: csr}
+		request := ObtainForCSRRequest{CSR: csr, Renewal: true}
 
 		if options != nil {
 			request.NotBefore = options.NotBefore
@@ -594,6 +807,9 @@ func (c *Certifier) Renew(ctx context.Context, certRes Resource, options *RenewO
 			request.PreferredChain = options.PreferredChain
 			request.Profile = options.Profile
 			request.AlwaysDeactivateAuthorizations = options.AlwaysDeactivateAuthorizations
+			request.ChallengeFallback = options.ChallengeFallback
+			request.CheckCAA = options.CheckCAA
+			request.MinAuthorizationValidity = options.MinAuthorizationValidity
 		}
 
 		return c.ObtainForCSR(ctx, request)
@@ -610,6 +826,7 @@ func (c *Certifier) Renew(ctx context.Context, certRes Resource, options *RenewO
 	request := ObtainRequest{
 		Domains:    certcrypto.ExtractDomains(x509Cert),
 		PrivateKey: privateKey,
+		Renewal:    true,
 	}
 
 	if options != nil {
@@ -621,11 +838,22 @@ func (c *Certifier) Renew(ctx context.Context, certRes Resource, options *RenewO
 		request.EmailAddresses = options.EmailAddresses
 		request.Profile = options.Profile
 		request.AlwaysDeactivateAuthorizations = options.AlwaysDeactivateAuthorizations
+		request.ChallengeFallback = options.ChallengeFallback
+		request.CheckCAA = options.CheckCAA
+		request.MinAuthorizationValidity = options.MinAuthorizationValidity
 	}
 
 	return c.Obtain(ctx, request)
 }
 
+func (c *Certifier) publishRenewed(ctx context.Context, certRes *Resource) {
+	c.options.Events.Publish(ctx, event.Event{
+		Type:           event.CertificateRenewed,
+		Domains:        certRes.Domains,
+		CertificateURL: certRes.CertURL,
+	})
+}
+
 // GetOCSP takes a PEM encoded cert or cert bundle returning the raw OCSP response,
 // the parsed response, and an error, if any.
 //
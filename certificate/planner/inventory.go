@@ -0,0 +1,60 @@
+package planner
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ReadInventory reads an inventory of domains.
+//
+// The inventory contains one domain per line, optionally followed by the ID of the challenge:
+//
+//	# comment
+//	example.com
+//	www.example.com http
+//	*.example.org dns
+//
+// The domains without challenge use the default challenge.
+func ReadInventory(reader io.Reader, defaultChallenge string) ([]Domain, error) {
+	var domains []Domain
+
+	scanner := bufio.NewScanner(reader)
+
+	var lineNumber int
+
+	for scanner.Scan() {
+		lineNumber++
+
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+
+		fields := strings.Fields(line)
+
+		switch len(fields) {
+		case 0:
+			continue
+
+		case 1:
+			domains = append(domains, Domain{Name: fields[0], Challenge: defaultChallenge})
+
+		case 2:
+			domains = append(domains, Domain{Name: fields[0], Challenge: fields[1]})
+
+		default:
+			return nil, fmt.Errorf("line %d: invalid entry: %q", lineNumber, strings.TrimSpace(line))
+		}
+	}
+
+	err := scanner.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(domains) == 0 {
+		return nil, errors.New("the inventory is empty")
+	}
+
+	return domains, nil
+}
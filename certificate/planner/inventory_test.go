@@ -0,0 +1,58 @@
+package planner
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadInventory(t *testing.T) {
+	inventory := `
+# comment
+example.com
+www.example.com   http # inline comment
+
+*.example.org dns
+`
+
+	domains, err := ReadInventory(strings.NewReader(inventory), "default")
+	require.NoError(t, err)
+
+	expected := []Domain{
+		{Name: "example.com", Challenge: "default"},
+		{Name: "www.example.com", Challenge: "http"},
+		{Name: "*.example.org", Challenge: "dns"},
+	}
+
+	assert.Equal(t, expected, domains)
+}
+
+func TestReadInventory_error(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		inventory string
+		expected  string
+	}{
+		{
+			desc:      "empty",
+			inventory: "# comment\n\n",
+			expected:  "the inventory is empty",
+		},
+		{
+			desc:      "invalid entry",
+			inventory: "example.com\nexample.org http foo\n",
+			expected:  `line 2: invalid entry: "example.org http foo"`,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := ReadInventory(strings.NewReader(test.inventory), "")
+			require.EqualError(t, err, test.expected)
+		})
+	}
+}
@@ -0,0 +1,295 @@
+// Package planner packs domains into certificates.
+package planner
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// DefaultMaxSANs is the default maximum number of domains (SANs) per certificate.
+// It's the limit of Let's Encrypt.
+const DefaultMaxSANs = 100
+
+// Domain is a domain of the inventory.
+type Domain struct {
+	Name string
+
+	// Challenge is the ID of the challenge used to validate the domain.
+	Challenge string
+}
+
+// Certificate is a group of domains sharing a certificate.
+type Certificate struct {
+	ID string
+
+	// Challenge is the ID of the challenge used to validate all the domains of the certificate.
+	Challenge string
+
+	Domains []string
+}
+
+// Options are the constraints of the planning.
+type Options struct {
+	// MaxSANs is the maximum number of domains per certificate.
+	// Default: DefaultMaxSANs.
+	MaxSANs int
+
+	// GroupByRegistrableDomain puts only the domains with the same registrable domain (eTLD+1) in a certificate.
+	GroupByRegistrableDomain bool
+
+	// WildcardThreshold replaces the subdomains of a domain by a wildcard
+	// when the number of subdomains (with the same challenge) reaches the threshold.
+	// The challenge must support wildcards (DNS-based challenges), see SupportsWildcard.
+	// Zero disables the creation of wildcards.
+	WildcardThreshold int
+
+	// SupportsWildcard reports whether the challenge can validate a wildcard domain (DNS-based challenges).
+	// The wildcards are only created for the challenges supporting them:
+	// if nil, no wildcards are created.
+	SupportsWildcard func(challenge string) bool
+}
+
+// Planner packs domains into the fewest certificates.
+type Planner struct {
+	options Options
+}
+
+// New creates a new Planner.
+func New(options Options) *Planner {
+	if options.MaxSANs <= 0 {
+		options.MaxSANs = DefaultMaxSANs
+	}
+
+	return &Planner{options: options}
+}
+
+// Plan assigns the domains to certificates.
+//
+// The domains are kept in the certificates of the previous plan when the constraints are still satisfied,
+// so adding or removing a domain only changes one certificate.
+// The certificates of the previous plan without remaining domains are removed.
+// The IDs of the new certificates never reuse the IDs of the previous certificates.
+func (p *Planner) Plan(domains []Domain, previous []Certificate) ([]Certificate, error) {
+	inventory, err := normalize(domains)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.options.WildcardThreshold > 0 && p.options.SupportsWildcard != nil {
+		addWildcards(inventory, p.options.WildcardThreshold, p.options.SupportsWildcard)
+	}
+
+	removeCoveredDomains(inventory)
+
+	usedIDs := make(map[string]struct{})
+
+	var certificates []*Certificate
+
+	for _, prev := range slices.SortedFunc(slices.Values(previous), func(a, b Certificate) int { return cmp.Compare(a.ID, b.ID) }) {
+		usedIDs[prev.ID] = struct{}{}
+
+		cert := &Certificate{ID: prev.ID, Challenge: prev.Challenge}
+
+		for _, name := range prev.Domains {
+			name = normalizeName(name)
+
+			challenge, ok := inventory[name]
+			if !ok || challenge != prev.Challenge || !p.accept(cert, name) {
+				continue
+			}
+
+			cert.Domains = append(cert.Domains, name)
+
+			delete(inventory, name)
+		}
+
+		if len(cert.Domains) > 0 {
+			certificates = append(certificates, cert)
+		}
+	}
+
+	// The remaining domains are sorted by reversed labels to put the sibling domains together.
+	remaining := slices.SortedFunc(maps.Keys(inventory), func(a, b string) int {
+		return cmp.Or(
+			cmp.Compare(inventory[a], inventory[b]),
+			cmp.Compare(reverseLabels(a), reverseLabels(b)),
+		)
+	})
+
+	for _, name := range remaining {
+		challenge := inventory[name]
+
+		index := slices.IndexFunc(certificates, func(cert *Certificate) bool {
+			return cert.Challenge == challenge && p.accept(cert, name)
+		})
+
+		if index < 0 {
+			certificates = append(certificates, &Certificate{
+				ID:        newID(usedIDs, p.baseID(name, challenge)),
+				Challenge: challenge,
+			})
+
+			index = len(certificates) - 1
+		}
+
+		certificates[index].Domains = append(certificates[index].Domains, name)
+	}
+
+	var result []Certificate
+	for _, cert := range certificates {
+		result = append(result, *cert)
+	}
+
+	slices.SortFunc(result, func(a, b Certificate) int { return cmp.Compare(a.ID, b.ID) })
+
+	return result, nil
+}
+
+// accept checks if the domain can be added to the certificate.
+func (p *Planner) accept(cert *Certificate, name string) bool {
+	if len(cert.Domains) >= p.options.MaxSANs {
+		return false
+	}
+
+	if !p.options.GroupByRegistrableDomain || len(cert.Domains) == 0 {
+		return true
+	}
+
+	return registrableDomain(cert.Domains[0]) == registrableDomain(name)
+}
+
+func (p *Planner) baseID(name, challenge string) string {
+	if p.options.GroupByRegistrableDomain {
+		return registrableDomain(name)
+	}
+
+	if challenge != "" {
+		return challenge
+	}
+
+	return "certificate"
+}
+
+func normalize(domains []Domain) (map[string]string, error) {
+	inventory := make(map[string]string)
+
+	for _, domain := range domains {
+		name := normalizeName(domain.Name)
+		if name == "" || name == "*" {
+			return nil, fmt.Errorf("invalid domain: %q", domain.Name)
+		}
+
+		if challenge, ok := inventory[name]; ok && challenge != domain.Challenge {
+			return nil, fmt.Errorf("%s: several challenges: %q, %q", name, challenge, domain.Challenge)
+		}
+
+		inventory[name] = domain.Challenge
+	}
+
+	return inventory, nil
+}
+
+// addWildcards adds a wildcard for the parent domains with at least threshold subdomains (with the same challenge),
+// when the challenge supports wildcards.
+func addWildcards(inventory map[string]string, threshold int, supportsWildcard func(challenge string) bool) {
+	// parent -> challenge -> number of subdomains.
+	counts := make(map[string]map[string]int)
+
+	for name, challenge := range inventory {
+		if strings.HasPrefix(name, "*.") {
+			continue
+		}
+
+		_, parent, ok := strings.Cut(name, ".")
+		if !ok || !hasRegistrableDomain(parent) {
+			continue
+		}
+
+		if counts[parent] == nil {
+			counts[parent] = make(map[string]int)
+		}
+
+		counts[parent][challenge]++
+	}
+
+	for parent, challenges := range counts {
+		wildcard := "*." + parent
+
+		for challenge, count := range challenges {
+			if _, exists := inventory[wildcard]; exists || count < threshold || !supportsWildcard(challenge) {
+				continue
+			}
+
+			inventory[wildcard] = challenge
+		}
+	}
+}
+
+// removeCoveredDomains removes the domains covered by a wildcard (with the same challenge).
+func removeCoveredDomains(inventory map[string]string) {
+	for name, challenge := range inventory {
+		if strings.HasPrefix(name, "*.") {
+			continue
+		}
+
+		_, parent, ok := strings.Cut(name, ".")
+		if !ok {
+			continue
+		}
+
+		if wildcardChallenge, exists := inventory["*."+parent]; exists && wildcardChallenge == challenge {
+			delete(inventory, name)
+		}
+	}
+}
+
+// registrableDomain returns the registrable domain (eTLD+1) of a domain, or the domain itself.
+func registrableDomain(name string) string {
+	name = strings.TrimPrefix(name, "*.")
+
+	domain, err := publicsuffix.EffectiveTLDPlusOne(name)
+	if err != nil {
+		return name
+	}
+
+	return domain
+}
+
+// hasRegistrableDomain returns false if the domain is a public suffix (ex: `com`, `co.uk`).
+func hasRegistrableDomain(name string) bool {
+	_, err := publicsuffix.EffectiveTLDPlusOne(name)
+
+	return err == nil
+}
+
+func newID(usedIDs map[string]struct{}, base string) string {
+	id := base
+
+	for i := 2; ; i++ {
+		if _, ok := usedIDs[id]; !ok {
+			break
+		}
+
+		id = fmt.Sprintf("%s-%d", base, i)
+	}
+
+	usedIDs[id] = struct{}{}
+
+	return id
+}
+
+func normalizeName(name string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(name)), ".")
+}
+
+func reverseLabels(name string) string {
+	labels := strings.Split(name, ".")
+	slices.Reverse(labels)
+
+	return strings.Join(labels, ".")
+}
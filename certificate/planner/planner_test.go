@@ -0,0 +1,222 @@
+package planner
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanner_Plan(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		options  Options
+		domains  []Domain
+		previous []Certificate
+		expected []Certificate
+	}{
+		{
+			desc:    "one certificate",
+			domains: []Domain{{Name: "www.example.com"}, {Name: "Example.com."}, {Name: "example.org"}},
+			expected: []Certificate{
+				{ID: "certificate", Domains: []string{"example.com", "www.example.com", "example.org"}},
+			},
+		},
+		{
+			desc:    "max SANs",
+			options: Options{MaxSANs: 2},
+			domains: []Domain{{Name: "a.example.com"}, {Name: "b.example.com"}, {Name: "c.example.com"}},
+			expected: []Certificate{
+				{ID: "certificate", Domains: []string{"a.example.com", "b.example.com"}},
+				{ID: "certificate-2", Domains: []string{"c.example.com"}},
+			},
+		},
+		{
+			desc: "one challenge per certificate",
+			domains: []Domain{
+				{Name: "a.example.com", Challenge: "http"},
+				{Name: "b.example.com", Challenge: "dns"},
+				{Name: "c.example.com", Challenge: "http"},
+			},
+			expected: []Certificate{
+				{ID: "dns", Challenge: "dns", Domains: []string{"b.example.com"}},
+				{ID: "http", Challenge: "http", Domains: []string{"a.example.com", "c.example.com"}},
+			},
+		},
+		{
+			desc:    "group by registrable domain",
+			options: Options{GroupByRegistrableDomain: true},
+			domains: []Domain{
+				{Name: "www.example.com"},
+				{Name: "example.org"},
+				{Name: "foo.example.co.uk"},
+				{Name: "*.example.com"},
+			},
+			expected: []Certificate{
+				{ID: "example.co.uk", Domains: []string{"foo.example.co.uk"}},
+				{ID: "example.com", Domains: []string{"*.example.com"}},
+				{ID: "example.org", Domains: []string{"example.org"}},
+			},
+		},
+		{
+			desc: "wildcard consolidation",
+			domains: []Domain{
+				{Name: "*.example.com", Challenge: "dns"},
+				{Name: "a.example.com", Challenge: "dns"},
+				{Name: "b.example.com", Challenge: "http"},
+				{Name: "a.b.example.com", Challenge: "dns"},
+			},
+			expected: []Certificate{
+				{ID: "dns", Challenge: "dns", Domains: []string{"*.example.com", "a.b.example.com"}},
+				{ID: "http", Challenge: "http", Domains: []string{"b.example.com"}},
+			},
+		},
+		{
+			desc:    "wildcard threshold",
+			options: Options{WildcardThreshold: 2, SupportsWildcard: func(string) bool { return true }},
+			domains: []Domain{
+				{Name: "a.example.com"},
+				{Name: "b.example.com"},
+				{Name: "c.example.org"},
+				{Name: "example.com"},
+				{Name: "example.co.uk"},
+				{Name: "example2.co.uk"},
+			},
+			expected: []Certificate{
+				{ID: "certificate", Domains: []string{"example.com", "*.example.com", "c.example.org", "example.co.uk", "example2.co.uk"}},
+			},
+		},
+		{
+			desc: "wildcard threshold: challenge without wildcard support",
+			options: Options{
+				WildcardThreshold: 2,
+				SupportsWildcard:  func(challenge string) bool { return challenge == "dns" },
+			},
+			domains: []Domain{
+				{Name: "a.example.com", Challenge: "http"},
+				{Name: "b.example.com", Challenge: "http"},
+				{Name: "a.example.org", Challenge: "dns"},
+				{Name: "b.example.org", Challenge: "dns"},
+			},
+			expected: []Certificate{
+				{ID: "dns", Challenge: "dns", Domains: []string{"*.example.org"}},
+				{ID: "http", Challenge: "http", Domains: []string{"a.example.com", "b.example.com"}},
+			},
+		},
+		{
+			desc:    "wildcard threshold: no wildcard support",
+			options: Options{WildcardThreshold: 2},
+			domains: []Domain{{Name: "a.example.com"}, {Name: "b.example.com"}},
+			expected: []Certificate{
+				{ID: "certificate", Domains: []string{"a.example.com", "b.example.com"}},
+			},
+		},
+		{
+			desc: "previous assignments",
+			domains: []Domain{
+				{Name: "a.example.com"},
+				{Name: "b.example.com"},
+				{Name: "c.example.com"},
+				{Name: "new.example.com"},
+			},
+			previous: []Certificate{
+				{ID: "foo", Domains: []string{"b.example.com", "removed.example.com"}},
+				{ID: "bar", Domains: []string{"c.example.com", "a.example.com"}},
+				{ID: "certificate", Domains: []string{"removed.example.org"}},
+			},
+			expected: []Certificate{
+				{ID: "bar", Domains: []string{"c.example.com", "a.example.com", "new.example.com"}},
+				{ID: "foo", Domains: []string{"b.example.com"}},
+			},
+		},
+		{
+			desc:    "previous assignments: constraints",
+			options: Options{MaxSANs: 2, GroupByRegistrableDomain: true},
+			domains: []Domain{
+				{Name: "a.example.com"},
+				{Name: "b.example.com"},
+				{Name: "c.example.com"},
+				{Name: "example.org", Challenge: "dns"},
+			},
+			previous: []Certificate{
+				{ID: "foo", Domains: []string{"a.example.com", "example.org", "b.example.com", "c.example.com"}},
+			},
+			expected: []Certificate{
+				{ID: "example.com", Domains: []string{"c.example.com"}},
+				{ID: "example.org", Challenge: "dns", Domains: []string{"example.org"}},
+				{ID: "foo", Domains: []string{"a.example.com", "b.example.com"}},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			certificates, err := New(test.options).Plan(test.domains, test.previous)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, certificates)
+		})
+	}
+}
+
+func TestPlanner_Plan_stability(t *testing.T) {
+	planner := New(Options{MaxSANs: 10})
+
+	var domains []Domain
+	for i := range 95 {
+		domains = append(domains, Domain{Name: fmt.Sprintf("host%02d.example.com", i)})
+	}
+
+	previous, err := planner.Plan(domains, nil)
+	require.NoError(t, err)
+
+	require.Len(t, previous, 10)
+
+	certificates, err := planner.Plan(append(domains, Domain{Name: "aaa.example.com"}), previous)
+	require.NoError(t, err)
+
+	require.Len(t, certificates, 10)
+
+	// Only the certificate with free space changes.
+	for i, cert := range certificates {
+		if cert.ID != "certificate-10" {
+			assert.Equal(t, previous[i], cert)
+			continue
+		}
+
+		assert.Len(t, previous[i].Domains, 5)
+		assert.Equal(t, slices.Concat(previous[i].Domains, []string{"aaa.example.com"}), cert.Domains)
+	}
+}
+
+func TestPlanner_Plan_error(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		domains  []Domain
+		expected string
+	}{
+		{
+			desc:     "empty domain",
+			domains:  []Domain{{Name: " "}},
+			expected: `invalid domain: " "`,
+		},
+		{
+			desc:     "several challenges",
+			domains:  []Domain{{Name: "example.com", Challenge: "http"}, {Name: "EXAMPLE.com", Challenge: "dns"}},
+			expected: `example.com: several challenges: "http", "dns"`,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(Options{}).Plan(test.domains, nil)
+			require.EqualError(t, err, test.expected)
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"sync/atomic"
 
 	"github.com/go-acme/lego/v5/challenge/internal"
+	"github.com/miekg/dns"
 )
 
 var defaultClient atomic.Pointer[Client]
@@ -53,3 +54,9 @@ func (c *Client) FindZoneByFqdnCustom(ctx context.Context, fqdn string, nameserv
 func (c *Client) ClearFqdnCache() {
 	c.core.ClearFqdnCache()
 }
+
+// LookupCAA returns the relevant CAA RRset of a domain (RFC 8659), and the domain where the RRset has been found.
+// An empty RRset means that no CAA record restricts the issuance.
+func (c *Client) LookupCAA(ctx context.Context, domain string) ([]*dns.CAA, string, error) {
+	return c.core.LookupCAA(ctx, domain)
+}
@@ -3,6 +3,7 @@ package dns01
 import (
 	"context"
 	"crypto/sha256"
+	"encoding/base32"
 	"encoding/base64"
 	"fmt"
 	"log/slog"
@@ -14,8 +15,10 @@ import (
 	"github.com/go-acme/lego/v5/acme"
 	"github.com/go-acme/lego/v5/acme/api"
 	"github.com/go-acme/lego/v5/challenge"
+	"github.com/go-acme/lego/v5/internal/tracing"
 	"github.com/go-acme/lego/v5/internal/wait"
 	"github.com/go-acme/lego/v5/log"
+	"github.com/go-acme/lego/v5/metrics"
 	"github.com/miekg/dns"
 )
 
@@ -34,26 +37,46 @@ const challengeLabel = "_acme-challenge"
 
 type ValidateFunc func(ctx context.Context, core *api.Core, domain string, chlng acme.Challenge) error
 
-// Challenge implements the dns-01 challenge.
+// Challenge implements the dns-01 challenge, and the dns-account-01 challenge (NewAccountChallenge).
 type Challenge struct {
 	core     *api.Core
 	validate ValidateFunc
 	provider challenge.Provider
 	preCheck preCheck
+
+	// chlgType is the type of the challenge: dns-01 or dns-account-01.
+	chlgType challenge.Type
+	// name is the name of the challenge used by the logs and the errors.
+	name string
 }
 
 func NewChallenge(core *api.Core, validate ValidateFunc, provider challenge.Provider, opts ...ChallengeOption) *Challenge {
+	return newChallenge(core, validate, provider, challenge.DNS01, "dns01", opts...)
+}
+
+// NewAccountChallenge creates a dns-account-01 challenge.
+//
+// The challenge is the same as the dns-01 challenge, except the name of the record:
+// the account label is provided to the DNS providers through the context (WithAccountLabel),
+// so GetChallengeInfo returns the account-scoped record (`_[label]._acme-challenge.[domain].`).
+func NewAccountChallenge(core *api.Core, validate ValidateFunc, provider challenge.Provider, opts ...ChallengeOption) *Challenge {
+	return newChallenge(core, validate, provider, challenge.DNSAccount01, "dnsaccount01", opts...)
+}
+
+func newChallenge(core *api.Core, validate ValidateFunc, provider challenge.Provider, chlgType challenge.Type, name string, opts ...ChallengeOption) *Challenge {
 	chlg := &Challenge{
 		core:     core,
 		validate: validate,
 		provider: provider,
 		preCheck: newPreCheck(),
+		chlgType: chlgType,
+		name:     name,
 	}
 
 	for _, opt := range opts {
 		err := opt(chlg)
 		if err != nil {
-			log.Warn("dns01: challenge option skipped.", log.ErrorAttr(err))
+			log.Warn(name+": challenge option skipped.", log.ErrorAttr(err))
 		}
 	}
 
@@ -65,15 +88,20 @@ func NewChallenge(core *api.Core, validate ValidateFunc, provider challenge.Prov
 func (c *Challenge) PreSolve(ctx context.Context, authz acme.Authorization) error {
 	domain := challenge.GetTargetedDomain(authz)
 
-	log.Info("dns01: preparing to solve the challenge.", log.DomainAttr(domain))
+	log.Info(c.name+": preparing to solve the challenge.", log.DomainAttr(domain))
 
-	chlng, err := challenge.FindChallenge(challenge.DNS01, authz)
+	chlng, err := challenge.FindChallenge(c.chlgType, authz)
 	if err != nil {
 		return err
 	}
 
 	if c.provider == nil {
-		return fmt.Errorf("dns01: no DNS Provider configured (%s)", domain)
+		return fmt.Errorf("%s: no DNS Provider configured (%s)", c.name, domain)
+	}
+
+	ctx, err = c.withRecordName(ctx)
+	if err != nil {
+		return err
 	}
 
 	// Generate the Key Authorization for the challenge
@@ -82,9 +110,9 @@ func (c *Challenge) PreSolve(ctx context.Context, authz acme.Authorization) erro
 		return err
 	}
 
-	err = c.provider.Present(ctx, authz.Identifier.Value, chlng.Token, keyAuth)
+	err = tracing.Present(ctx, c.provider, chlng.Type, authz.Identifier.Value, chlng.Token, keyAuth)
 	if err != nil {
-		return fmt.Errorf("dns01: error presenting token (%s): %w", domain, err)
+		return fmt.Errorf("%s: error presenting token (%s): %w", c.name, domain, err)
 	}
 
 	return nil
@@ -93,9 +121,14 @@ func (c *Challenge) PreSolve(ctx context.Context, authz acme.Authorization) erro
 func (c *Challenge) Solve(ctx context.Context, authz acme.Authorization) error {
 	domain := challenge.GetTargetedDomain(authz)
 
-	log.Info("dns01: trying to solve the challenge.", log.DomainAttr(domain))
+	log.Info(c.name+": trying to solve the challenge.", log.DomainAttr(domain))
 
-	chlng, err := challenge.FindChallenge(challenge.DNS01, authz)
+	chlng, err := challenge.FindChallenge(c.chlgType, authz)
+	if err != nil {
+		return err
+	}
+
+	recordCtx, err := c.withRecordName(ctx)
 	if err != nil {
 		return err
 	}
@@ -106,7 +139,7 @@ func (c *Challenge) Solve(ctx context.Context, authz acme.Authorization) error {
 		return err
 	}
 
-	info := GetChallengeInfo(ctx, authz.Identifier.Value, keyAuth)
+	info := GetChallengeInfo(recordCtx, authz.Identifier.Value, keyAuth)
 
 	var timeout, interval time.Duration
 
@@ -117,36 +150,89 @@ func (c *Challenge) Solve(ctx context.Context, authz acme.Authorization) error {
 		timeout, interval = DefaultPropagationTimeout, DefaultPollingInterval
 	}
 
-	log.Info("dns01: waiting for record propagation",
+	// The propagation of the record can be shared with the other challenges of the record (ex: apex and wildcard domains).
+	propagated, unlock, err := getPropagationCache(ctx).lock(ctx, info.EffectiveFQDN)
+	if err != nil {
+		return fmt.Errorf("%s: %w", c.name, err)
+	}
+
+	check := func() (bool, error) {
+		checkCtx, span := tracing.Start(ctx, "lego."+c.name+".propagation_check",
+			tracing.AttrDomain.String(domain),
+			tracing.AttrFQDN.String(info.EffectiveFQDN),
+		)
+
+		stop, callErr := c.preCheck.call(checkCtx, domain, info.EffectiveFQDN, info.Value)
+
+		span.SetAttributes(tracing.AttrPropagated.Bool(stop))
+		tracing.End(span, callErr)
+
+		return stop, callErr
+	}
+
+	if propagated {
+		// The record has already propagated: the value is checked without waiting.
+		var errC error
+
+		propagated, errC = check()
+		if errC != nil {
+			log.Debug(c.name+": could not check the propagated record.", log.DomainAttr(domain), log.ErrorAttr(errC))
+		}
+	}
+
+	if propagated {
+		log.Info(c.name+": the record has already propagated.", log.DomainAttr(domain))
+	} else {
+		err = c.waitPropagation(domain, timeout, interval, check)
+	}
+
+	unlock(err == nil)
+
+	if err != nil {
+		return fmt.Errorf("%s: %w", c.name, err)
+	}
+
+	chlng.KeyAuthorization = keyAuth
+
+	return c.validate(ctx, c.core, domain, chlng)
+}
+
+// waitPropagation waits for the propagation of the record.
+func (c *Challenge) waitPropagation(domain string, timeout, interval time.Duration, check func() (bool, error)) error {
+	log.Info(c.name+": waiting for record propagation",
 		slog.Duration("timeout", timeout),
 		slog.Duration("interval", interval),
 		log.DomainAttr(domain),
 	)
 
+	start := time.Now()
+
 	time.Sleep(interval)
 
-	err = wait.For(timeout, interval, func() (bool, error) {
-		stop, callErr := c.preCheck.call(ctx, domain, info.EffectiveFQDN, info.Value)
+	err := wait.For(timeout, interval, func() (bool, error) {
+		stop, callErr := check()
 		if !stop || callErr != nil {
-			log.Info("dns01: waiting for record propagation.", log.DomainAttr(domain))
+			log.Info(c.name+": waiting for record propagation.", log.DomainAttr(domain))
 		}
 
 		return stop, callErr
 	})
-	if err != nil {
-		return fmt.Errorf("dns01: %w", err)
-	}
 
-	chlng.KeyAuthorization = keyAuth
+	metrics.Default().ObservePropagation(time.Since(start), err)
 
-	return c.validate(ctx, c.core, domain, chlng)
+	return err
 }
 
 // CleanUp cleans the challenge.
 func (c *Challenge) CleanUp(ctx context.Context, authz acme.Authorization) error {
-	log.Info("dns01: cleaning DNS-01 challenge.", log.DomainAttr(challenge.GetTargetedDomain(authz)))
+	log.Info(c.name+": cleaning the challenge.", log.DomainAttr(challenge.GetTargetedDomain(authz)))
 
-	chlng, err := challenge.FindChallenge(challenge.DNS01, authz)
+	chlng, err := challenge.FindChallenge(c.chlgType, authz)
+	if err != nil {
+		return err
+	}
+
+	ctx, err = c.withRecordName(ctx)
 	if err != nil {
 		return err
 	}
@@ -156,7 +242,7 @@ func (c *Challenge) CleanUp(ctx context.Context, authz acme.Authorization) error
 		return err
 	}
 
-	return c.provider.CleanUp(ctx, authz.Identifier.Value, chlng.Token, keyAuth)
+	return tracing.CleanUp(ctx, c.provider, chlng.Type, authz.Identifier.Value, chlng.Token, keyAuth)
 }
 
 func (c *Challenge) Sequential() (bool, time.Duration) {
@@ -167,6 +253,21 @@ func (c *Challenge) Sequential() (bool, time.Duration) {
 	return false, 0
 }
 
+// withRecordName adds the information used to build the name of the record to the context:
+// the account label of the ACME account for the dns-account-01 challenge.
+func (c *Challenge) withRecordName(ctx context.Context) (context.Context, error) {
+	if c.chlgType != challenge.DNSAccount01 {
+		return ctx, nil
+	}
+
+	accountURL := c.core.GetKid()
+	if accountURL == "" {
+		return nil, fmt.Errorf("%s: missing account URL", c.name)
+	}
+
+	return WithAccountLabel(ctx, AccountLabel(accountURL)), nil
+}
+
 type sequential interface {
 	Sequential() time.Duration
 }
@@ -174,6 +275,7 @@ type sequential interface {
 // ChallengeInfo contains the information use to create the TXT record.
 type ChallengeInfo struct {
 	// FQDN is the full-qualified challenge domain (i.e. `_acme-challenge.[domain].`)
+	// With an account label, the FQDN is `_[label]._acme-challenge.[domain].` (dns-account-01).
 	FQDN string
 
 	// EffectiveFQDN contains the resulting FQDN after the CNAMEs resolutions.
@@ -182,7 +284,7 @@ type ChallengeInfo struct {
 	// Value contains the value for the TXT record.
 	Value string
 
-	// Prefix is the challenge prefix (i.e. `_acme-challenge`).
+	// Prefix is the challenge prefix (i.e. `_acme-challenge`, or `_[label]._acme-challenge` with an account label).
 	Prefix string
 }
 
@@ -202,7 +304,29 @@ func (c ChallengeInfo) EffectiveDomain() string {
 	return c.EffectiveFQDN
 }
 
+// accountLabelLength is the number of bytes of the account URL digest used by the account label.
+const accountLabelLength = 10
+
+// AccountLabel returns the account label (i.e. `_[label]`) of an ACME account, used by the dns-account-01 challenge.
+// The label is the lowercase base32 encoding of the first 10 bytes of the SHA-256 digest of the account URL.
+func AccountLabel(accountURL string) string {
+	digest := sha256.Sum256([]byte(accountURL))
+
+	return "_" + strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(digest[:accountLabelLength]))
+}
+
+type accountLabelKey struct{}
+
+// WithAccountLabel returns a copy of the context with an account label.
+// The account label is used by GetChallengeInfo to build the account-scoped challenge domain
+// `_[label]._acme-challenge.[domain].` of the `dns-account-01` challenge.
+func WithAccountLabel(ctx context.Context, label string) context.Context {
+	return context.WithValue(ctx, accountLabelKey{}, label)
+}
+
 // GetChallengeInfo returns information used to create a DNS record which will fulfill the `dns-01` challenge.
+// When the context contains an account label (WithAccountLabel),
+// the information fulfills the `dns-account-01` challenge.
 func GetChallengeInfo(ctx context.Context, domain, keyAuth string) ChallengeInfo {
 	keyAuthShaBytes := sha256.Sum256([]byte(keyAuth))
 	// base64URL encoding without padding
@@ -210,14 +334,27 @@ func GetChallengeInfo(ctx context.Context, domain, keyAuth string) ChallengeInfo
 
 	ok, _ := strconv.ParseBool(os.Getenv("LEGO_DISABLE_CNAME_SUPPORT"))
 
-	fqdn := getAuthorizationDomainName(domain)
+	prefix := getChallengePrefix(ctx)
+
+	fqdn := dns.Fqdn(prefix + "." + domain)
 
 	return ChallengeInfo{
 		Value:         value,
 		FQDN:          getChallengeFQDN(ctx, fqdn, false),
 		EffectiveFQDN: getChallengeFQDN(ctx, fqdn, !ok),
-		Prefix:        challengeLabel,
+		Prefix:        prefix,
+	}
+}
+
+// getChallengePrefix returns the challenge prefix,
+// prefixed by the account label of the context if any.
+func getChallengePrefix(ctx context.Context) string {
+	label, _ := ctx.Value(accountLabelKey{}).(string)
+	if label == "" {
+		return challengeLabel
 	}
+
+	return label + "." + challengeLabel
 }
 
 func getChallengeFQDN(ctx context.Context, fqdn string, followCNAME bool) string {
@@ -227,9 +364,3 @@ func getChallengeFQDN(ctx context.Context, fqdn string, followCNAME bool) string
 
 	return DefaultClient().lookupCNAME(ctx, fqdn)
 }
-
-// getAuthorizationDomainName returns the fully qualified DNS label
-// used by the dns-01 challenge for the given domain.
-func getAuthorizationDomainName(domain string) string {
-	return dns.Fqdn(challengeLabel + "." + domain)
-}
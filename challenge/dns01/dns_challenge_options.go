@@ -41,6 +41,15 @@ func DisableRecursiveNSsPropagationRequirement() ChallengeOption {
 	}
 }
 
+// RequireDNSSEC requires valid DNSSEC signatures (up to the root trust anchors) for the TXT record and the CNAME chain.
+// The records are queried on the recursive nameservers with the DO bit, and the signatures are validated locally.
+func RequireDNSSEC() ChallengeOption {
+	return func(chlg *Challenge) error {
+		chlg.preCheck.requireDNSSEC = true
+		return nil
+	}
+}
+
 func PropagationWait(wait time.Duration, skipCheck bool) ChallengeOption {
 	return WrapPreCheck(func(ctx context.Context, domain, fqdn, value string, check PreCheckFunc) (bool, error) {
 		if skipCheck {
@@ -3,6 +3,8 @@ package dns01
 import (
 	"context"
 	"fmt"
+
+	"github.com/miekg/dns"
 )
 
 // PreCheckFunc checks DNS propagation before notifying ACME that the DNS challenge is ready.
@@ -29,6 +31,9 @@ type preCheck struct {
 
 	// require the TXT record to be propagated to all recursive name servers
 	requireRecursiveNssPropagation bool
+
+	// require valid DNSSEC signatures for the TXT record and the CNAME chain
+	requireDNSSEC bool
 }
 
 func newPreCheck() preCheck {
@@ -51,23 +56,30 @@ func (p preCheck) checkDNSPropagation(ctx context.Context, fqdn, value string) (
 	client := DefaultClient()
 
 	// Initial attempt to resolve at the recursive NS (require getting CNAME)
-	fqdn, err := client.resolveCNAME(ctx, fqdn)
+	effectiveFQDN, err := client.resolveCNAME(ctx, fqdn)
 	if err != nil {
 		return false, fmt.Errorf("initial recursive nameserver: %w", err)
 	}
 
 	if p.requireRecursiveNssPropagation {
-		_, err = client.checkRecursiveNameserversPropagation(ctx, fqdn, value)
+		_, err = client.checkRecursiveNameserversPropagation(ctx, effectiveFQDN, value)
 		if err != nil {
 			return false, fmt.Errorf("recursive nameservers: %w", err)
 		}
 	}
 
+	if p.requireDNSSEC {
+		err = client.core.ValidateDNSSEC(ctx, fqdn, dns.TypeTXT)
+		if err != nil {
+			return false, fmt.Errorf("DNSSEC: %w", err)
+		}
+	}
+
 	if !p.requireAuthoritativeNssPropagation {
 		return true, nil
 	}
 
-	found, err := client.checkAuthoritativeNameserversPropagation(ctx, fqdn, value)
+	found, err := client.checkAuthoritativeNameserversPropagation(ctx, effectiveFQDN, value)
 	if err != nil {
 		return found, fmt.Errorf("authoritative nameservers: %w", err)
 	}
@@ -6,6 +6,7 @@ import (
 	"github.com/go-acme/lego/v5/internal/tester/dnsmock"
 	"github.com/miekg/dns"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func Test_preCheck_checkDNSPropagation(t *testing.T) {
@@ -142,3 +143,23 @@ func Test_preCheck_checkDNSPropagation_requireRecursiveNssPropagation(t *testing
 		})
 	}
 }
+
+func Test_preCheck_checkDNSPropagation_requireDNSSEC(t *testing.T) {
+	mockDefault(t,
+		dnsmock.NewServer().
+			Query("example.com. TXT",
+				dnsmock.Answer(fakeTXT("example.com.", "four")),
+			).
+			Query("example.com. SOA", dnsmock.SOA("")).
+			Build(t),
+	)
+
+	check := newPreCheck()
+	check.requireRecursiveNssPropagation = false
+	check.requireAuthoritativeNssPropagation = false
+	check.requireDNSSEC = true
+
+	ok, err := check.checkDNSPropagation(t.Context(), "example.com.", "four")
+	require.EqualError(t, err, "DNSSEC: example.com. TXT: no RRSIG record")
+	assert.False(t, ok)
+}
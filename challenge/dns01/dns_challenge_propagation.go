@@ -0,0 +1,105 @@
+package dns01
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// PropagationCache shares the propagation of the challenge records between several challenges.
+//
+// The apex domain and the wildcard domain (ex: `example.com` and `*.example.com`) share the same record (`_acme-challenge.example.com.`),
+// even when they are in the orders of separate certificates.
+// The propagation waits of a record are not run at the same time,
+// and once the record has propagated, the next values of the record are checked immediately instead of waiting again.
+//
+// A PropagationCache is added to the context with WithPropagationCache.
+type PropagationCache struct {
+	mu sync.Mutex
+
+	// records are the FQDNs of the records, with the state of their propagation.
+	records map[string]*recordPropagation
+}
+
+type recordPropagation struct {
+	// done is closed at the end of the propagation wait.
+	done chan struct{}
+
+	// propagated is true when a value of the record has propagated.
+	propagated bool
+}
+
+// NewPropagationCache creates a new PropagationCache.
+func NewPropagationCache() *PropagationCache {
+	return &PropagationCache{records: make(map[string]*recordPropagation)}
+}
+
+type propagationCacheKey struct{}
+
+// WithPropagationCache returns a copy of the context with a propagation cache.
+func WithPropagationCache(ctx context.Context, cache *PropagationCache) context.Context {
+	if cache == nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, propagationCacheKey{}, cache)
+}
+
+func getPropagationCache(ctx context.Context) *PropagationCache {
+	cache, _ := ctx.Value(propagationCacheKey{}).(*PropagationCache)
+
+	return cache
+}
+
+// lock waits until the propagation of the record is not being waited by another challenge,
+// then locks the record.
+// It returns true if a value of the record has already propagated,
+// and the function to call at the end of the propagation wait.
+// A nil cache locks nothing.
+func (p *PropagationCache) lock(ctx context.Context, fqdn string) (bool, func(propagated bool), error) {
+	if p == nil {
+		return false, func(bool) {}, nil
+	}
+
+	fqdn = strings.ToLower(fqdn)
+
+	for {
+		p.mu.Lock()
+
+		record, ok := p.records[fqdn]
+		if !ok {
+			record = &recordPropagation{}
+			p.records[fqdn] = record
+		}
+
+		wait := record.done
+		if wait == nil {
+			done := make(chan struct{})
+			record.done = done
+
+			propagated := record.propagated
+
+			p.mu.Unlock()
+
+			return propagated, func(propagated bool) { p.unlock(record, done, propagated) }, nil
+		}
+
+		p.mu.Unlock()
+
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			return false, nil, ctx.Err()
+		}
+	}
+}
+
+func (p *PropagationCache) unlock(record *recordPropagation, done chan struct{}, propagated bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	record.propagated = record.propagated || propagated
+	record.done = nil
+
+	close(done)
+}
@@ -0,0 +1,59 @@
+package dns01
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPropagationCache_lock(t *testing.T) {
+	cache := NewPropagationCache()
+
+	propagated, unlock, err := cache.lock(t.Context(), "_acme-challenge.example.com.")
+	require.NoError(t, err)
+
+	assert.False(t, propagated)
+
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+
+	// The propagation of the record is being waited.
+	_, _, err = cache.lock(ctx, "_ACME-challenge.example.com.")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	unlock(true)
+
+	propagated, unlock, err = cache.lock(t.Context(), "_acme-challenge.example.com.")
+	require.NoError(t, err)
+
+	assert.True(t, propagated)
+
+	// A failed wait doesn't reset the propagation of the record.
+	unlock(false)
+
+	propagated, unlock, err = cache.lock(t.Context(), "_acme-challenge.example.com.")
+	require.NoError(t, err)
+
+	assert.True(t, propagated)
+
+	unlock(false)
+
+	propagated, _, err = cache.lock(t.Context(), "_acme-challenge.example.org.")
+	require.NoError(t, err)
+
+	assert.False(t, propagated)
+}
+
+func TestPropagationCache_nil(t *testing.T) {
+	cache := getPropagationCache(t.Context())
+
+	propagated, unlock, err := cache.lock(t.Context(), "_acme-challenge.example.com.")
+	require.NoError(t, err)
+
+	assert.False(t, propagated)
+
+	unlock(true)
+}
@@ -0,0 +1,126 @@
+package dns01
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/go-acme/lego/v5/challenge"
+	"github.com/go-acme/lego/v5/internal/dnsserver"
+)
+
+// defaultDNSPort is the port that the ProviderServer will default to
+// when no other port is provided.
+const defaultDNSPort = "53"
+
+var _ challenge.Provider = (*ProviderServer)(nil)
+
+type ServerOptions struct {
+	NetworkStack challenge.NetworkStack
+	Host         string
+	Port         string
+
+	// Zone is the zone delegated to the server (optional).
+	// Inside the zone, the server answers NXDOMAIN for the unknown names instead of REFUSED.
+	Zone string
+
+	// Nameserver is the name of the server, used by the SOA and NS records of the zone (optional).
+	Nameserver string
+
+	// TTL of the TXT records (default: DefaultTTL).
+	TTL uint32
+}
+
+// ProviderServer implements ChallengeProvider for `dns-01` challenge.
+// It runs an authoritative DNS server (UDP and TCP) answering the TXT queries of the challenges.
+// The `_acme-challenge` names (or their zone) must be delegated to the server, with NS or CNAME records.
+//
+// The server is started by the first call to Present and stopped when all the challenges are cleaned up.
+type ProviderServer struct {
+	server *dnsserver.Server
+
+	mu      sync.Mutex
+	running bool
+}
+
+// NewProviderServerWithOptions creates a new ProviderServer.
+func NewProviderServerWithOptions(opts ServerOptions) *ProviderServer {
+	if opts.Port == "" {
+		// Fallback to port 53 if the port was not provided.
+		opts.Port = defaultDNSPort
+	}
+
+	if opts.TTL == 0 {
+		opts.TTL = DefaultTTL
+	}
+
+	return &ProviderServer{
+		server: dnsserver.New(dnsserver.Options{
+			Address:      net.JoinHostPort(opts.Host, opts.Port),
+			NetworkStack: opts.NetworkStack,
+			Zone:         opts.Zone,
+			Nameserver:   opts.Nameserver,
+			TTL:          opts.TTL,
+		}),
+	}
+}
+
+// NewProviderServer creates a new ProviderServer on the selected interface and port.
+// Setting host and / or port to an empty string will make the server fall back to
+// the "any" interface and port 53 respectively.
+func NewProviderServer(host, port string) *ProviderServer {
+	return NewProviderServerWithOptions(ServerOptions{Host: host, Port: port})
+}
+
+// Present starts the DNS server (if needed) and serves the TXT record of the challenge.
+func (s *ProviderServer) Present(ctx context.Context, domain, _, keyAuth string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.running {
+		err := s.server.Start(ctx)
+		if err != nil {
+			return fmt.Errorf("could not start DNS server for challenge: %w", err)
+		}
+
+		s.running = true
+	}
+
+	info := GetChallengeInfo(ctx, domain, keyAuth)
+
+	s.server.AddTXT(info.EffectiveFQDN, info.Value)
+
+	return nil
+}
+
+// CleanUp removes the TXT record of the challenge, and stops the DNS server when there are no more records.
+func (s *ProviderServer) CleanUp(ctx context.Context, domain, _, keyAuth string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	info := GetChallengeInfo(ctx, domain, keyAuth)
+
+	s.server.DeleteTXT(info.EffectiveFQDN, info.Value)
+
+	if !s.running || s.server.Len() > 0 {
+		return nil
+	}
+
+	s.running = false
+
+	return s.server.Shutdown(ctx)
+}
+
+// GetAddress returns the address of the DNS server.
+// When the server is running, the address is the one of the listener.
+func (s *ProviderServer) GetAddress() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if addr := s.server.Addr(); addr != nil {
+		return addr.String()
+	}
+
+	return ""
+}
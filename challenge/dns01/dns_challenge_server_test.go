@@ -0,0 +1,59 @@
+package dns01
+
+import (
+	"testing"
+
+	"github.com/go-acme/lego/v5/challenge"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProviderServer(t *testing.T) {
+	server := NewProviderServerWithOptions(ServerOptions{
+		NetworkStack: challenge.IPv4Only,
+		Host:         "127.0.0.1",
+		Port:         "0",
+	})
+
+	assert.Empty(t, server.GetAddress())
+
+	err := server.Present(t.Context(), "example.com", "", "123d==")
+	require.NoError(t, err)
+
+	ctx := WithAccountLabel(t.Context(), "_ujmmovf2vn55tgye")
+
+	err = server.Present(ctx, "example.com", "", "456d==")
+	require.NoError(t, err)
+
+	address := server.GetAddress()
+	require.NotEmpty(t, address)
+
+	client := NewClient(&Options{RecursiveNameservers: []string{address}, NetworkStack: challenge.IPv4Only})
+
+	info := GetChallengeInfo(t.Context(), "example.com", "123d==")
+
+	ok, err := client.checkRecursiveNameserversPropagation(t.Context(), info.EffectiveFQDN, info.Value)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	infoAccount := GetChallengeInfo(ctx, "example.com", "456d==")
+
+	ok, err = client.checkRecursiveNameserversPropagation(t.Context(), infoAccount.EffectiveFQDN, infoAccount.Value)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	err = server.CleanUp(t.Context(), "example.com", "", "123d==")
+	require.NoError(t, err)
+
+	// The server is still running for the remaining challenge.
+	assert.Equal(t, address, server.GetAddress())
+
+	ok, err = client.checkRecursiveNameserversPropagation(t.Context(), infoAccount.EffectiveFQDN, infoAccount.Value)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	err = server.CleanUp(ctx, "example.com", "", "456d==")
+	require.NoError(t, err)
+
+	assert.Empty(t, server.GetAddress())
+}
@@ -350,6 +350,26 @@ func TestGetChallengeInfo_cname_disabled(t *testing.T) {
 	assert.Equal(t, expected, info)
 }
 
+func TestGetChallengeInfo_accountLabel(t *testing.T) {
+	mockDefault(t, dnsmock.NewServer().
+		Query("_ujmmovf2vn55tgye._acme-challenge.example.com. CNAME", dnsmock.Noop).
+		Build(t))
+
+	ctx := WithAccountLabel(t.Context(), "_ujmmovf2vn55tgye")
+
+	info := GetChallengeInfo(ctx, "example.com", "123")
+
+	expected := ChallengeInfo{
+		FQDN:          "_ujmmovf2vn55tgye._acme-challenge.example.com.",
+		EffectiveFQDN: "_ujmmovf2vn55tgye._acme-challenge.example.com.",
+		Value:         "pmWkWSBCL51Bfkhn79xPuKBKHz__H6B-mY6G9_eieuM",
+		Prefix:        "_ujmmovf2vn55tgye._acme-challenge",
+	}
+
+	assert.Equal(t, expected, info)
+	assert.Equal(t, "example.com.", info.Domain())
+}
+
 func TestGetChallengeInfo_Domain(t *testing.T) {
 	mockDefault(t, dnsmock.NewServer().
 		Query("_acme-challenge.example.com. CNAME", dnsmock.Noop).
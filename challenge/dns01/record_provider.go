@@ -0,0 +1,15 @@
+package dns01
+
+import (
+	"context"
+
+	"github.com/miekg/dns"
+)
+
+// RecordProvider is implemented by the DNS providers able to manage the records of any type (ex: CAA).
+// IMPORTANT: this interface is experimental and may change without notice.
+type RecordProvider interface {
+	// SetRecords replaces the records of the type rtype of the FQDN (the RRset) with the records.
+	// An empty list of records removes the RRset.
+	SetRecords(ctx context.Context, fqdn string, rtype uint16, records []dns.RR) error
+}
@@ -0,0 +1,32 @@
+package dnsaccount01
+
+import (
+	"context"
+
+	"github.com/go-acme/lego/v5/acme/api"
+	"github.com/go-acme/lego/v5/challenge"
+	"github.com/go-acme/lego/v5/challenge/dns01"
+)
+
+// ChallengeOption configures the dns-account-01 challenge.
+// The options of the dns-01 challenge are used (ex: dns01.PropagationWait, dns01.RequireDNSSEC).
+type ChallengeOption = dns01.ChallengeOption
+
+// NewChallenge creates a dns-account-01 challenge.
+//
+// The challenge uses the same DNS providers, DNS client, and propagation checks as the dns-01 challenge:
+// only the name of the record is scoped to the ACME account (`_[label]._acme-challenge.[domain].`).
+func NewChallenge(core *api.Core, validate dns01.ValidateFunc, provider challenge.Provider, opts ...ChallengeOption) *dns01.Challenge {
+	return dns01.NewAccountChallenge(core, validate, provider, opts...)
+}
+
+// GetChallengeInfo returns information used to create a DNS record which will fulfill the `dns-account-01` challenge.
+func GetChallengeInfo(ctx context.Context, accountURL, domain, keyAuth string) dns01.ChallengeInfo {
+	return dns01.GetChallengeInfo(dns01.WithAccountLabel(ctx, AccountLabel(accountURL)), domain, keyAuth)
+}
+
+// AccountLabel returns the account label (i.e. `_[label]`) of an ACME account.
+// The label is the lowercase base32 encoding of the first 10 bytes of the SHA-256 digest of the account URL.
+func AccountLabel(accountURL string) string {
+	return dns01.AccountLabel(accountURL)
+}
@@ -0,0 +1,201 @@
+package dnsaccount01
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v5/acme"
+	"github.com/go-acme/lego/v5/acme/api"
+	"github.com/go-acme/lego/v5/challenge"
+	"github.com/go-acme/lego/v5/challenge/dns01"
+	"github.com/go-acme/lego/v5/internal/tester"
+	"github.com/go-acme/lego/v5/internal/tester/dnsmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testAccountURL = "https://example.com/acme/acct/ExampleAccount"
+
+// providerMock records the FQDNs of the records, as computed by the DNS providers.
+type providerMock struct {
+	present, cleanUp error
+
+	presented []string
+	cleaned   []string
+}
+
+func (p *providerMock) Present(ctx context.Context, domain, _, keyAuth string) error {
+	p.presented = append(p.presented, dns01.GetChallengeInfo(ctx, domain, keyAuth).FQDN)
+	return p.present
+}
+
+func (p *providerMock) CleanUp(ctx context.Context, domain, _, keyAuth string) error {
+	p.cleaned = append(p.cleaned, dns01.GetChallengeInfo(ctx, domain, keyAuth).FQDN)
+	return p.cleanUp
+}
+
+func TestAccountLabel(t *testing.T) {
+	// https://datatracker.ietf.org/doc/draft-ietf-acme-dns-account-label/
+	assert.Equal(t, "_ujmmovf2vn55tgye", AccountLabel(testAccountURL))
+}
+
+func TestGetChallengeInfo(t *testing.T) {
+	mockDefault(t, dnsmock.NewServer().
+		Query("_ujmmovf2vn55tgye._acme-challenge.example.com. CNAME", dnsmock.Noop).
+		Build(t))
+
+	info := GetChallengeInfo(t.Context(), testAccountURL, "example.com", "123")
+
+	expected := dns01.ChallengeInfo{
+		FQDN:          "_ujmmovf2vn55tgye._acme-challenge.example.com.",
+		EffectiveFQDN: "_ujmmovf2vn55tgye._acme-challenge.example.com.",
+		Value:         "pmWkWSBCL51Bfkhn79xPuKBKHz__H6B-mY6G9_eieuM",
+		Prefix:        "_ujmmovf2vn55tgye._acme-challenge",
+	}
+
+	assert.Equal(t, expected, info)
+}
+
+func TestChallenge_Solve(t *testing.T) {
+	mockDefault(t, dnsmock.NewServer().
+		Query("_ujmmovf2vn55tgye._acme-challenge.example.com. CNAME", dnsmock.Noop).
+		Build(t))
+
+	server := tester.MockACMEServer().BuildHTTPS(t)
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+
+	core, err := api.New(server.Client(), "lego-test", server.URL+"/dir", testAccountURL, privateKey)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		desc          string
+		validate      dns01.ValidateFunc
+		preCheck      dns01.WrapPreCheckFunc
+		expectedError string
+	}{
+		{
+			desc:     "success",
+			validate: func(_ context.Context, _ *api.Core, _ string, _ acme.Challenge) error { return nil },
+			preCheck: func(_ context.Context, _, _, _ string, _ dns01.PreCheckFunc) (bool, error) { return true, nil },
+		},
+		{
+			desc:          "validate fail",
+			validate:      func(_ context.Context, _ *api.Core, _ string, _ acme.Challenge) error { return errors.New("OOPS") },
+			preCheck:      func(_ context.Context, _, _, _ string, _ dns01.PreCheckFunc) (bool, error) { return true, nil },
+			expectedError: "OOPS",
+		},
+		{
+			desc:     "preCheck fail",
+			validate: func(_ context.Context, _ *api.Core, _ string, _ acme.Challenge) error { return nil },
+			preCheck: func(_ context.Context, _, _, _ string, _ dns01.PreCheckFunc) (bool, error) {
+				return false, errors.New("OOPS")
+			},
+			expectedError: "dnsaccount01: time limit exceeded: last error: OOPS",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			var fqdn string
+
+			preCheck := func(ctx context.Context, domain, f, value string, check dns01.PreCheckFunc) (bool, error) {
+				fqdn = f
+				return test.preCheck(ctx, domain, f, value, check)
+			}
+
+			provider := &providerTimeoutMock{timeout: 2 * time.Second, interval: 500 * time.Millisecond}
+
+			chlg := NewChallenge(core, test.validate, provider, dns01.WrapPreCheck(preCheck))
+
+			authz := acme.Authorization{
+				Identifier: acme.Identifier{
+					Value: "example.com",
+				},
+				Challenges: []acme.Challenge{
+					{Type: challenge.DNSAccount01.String()},
+				},
+			}
+
+			err = chlg.Solve(t.Context(), authz)
+			if test.expectedError != "" {
+				require.EqualError(t, err, test.expectedError)
+			} else {
+				require.NoError(t, err)
+			}
+
+			assert.Equal(t, "_ujmmovf2vn55tgye._acme-challenge.example.com.", fqdn)
+		})
+	}
+}
+
+func TestChallenge_PreSolve_CleanUp(t *testing.T) {
+	mockDefault(t, dnsmock.NewServer().
+		Query("_ujmmovf2vn55tgye._acme-challenge.example.com. CNAME", dnsmock.Noop).
+		Build(t))
+
+	server := tester.MockACMEServer().BuildHTTPS(t)
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+
+	core, err := api.New(server.Client(), "lego-test", server.URL+"/dir", testAccountURL, privateKey)
+	require.NoError(t, err)
+
+	provider := &providerMock{}
+
+	chlg := NewChallenge(core, nil, provider)
+
+	authz := acme.Authorization{
+		Identifier: acme.Identifier{
+			Value: "example.com",
+		},
+		Wildcard: true,
+		Challenges: []acme.Challenge{
+			{Type: challenge.DNSAccount01.String()},
+		},
+	}
+
+	require.NoError(t, chlg.PreSolve(t.Context(), authz))
+	require.NoError(t, chlg.CleanUp(t.Context(), authz))
+
+	assert.Equal(t, []string{"_ujmmovf2vn55tgye._acme-challenge.example.com."}, provider.presented)
+	assert.Equal(t, []string{"_ujmmovf2vn55tgye._acme-challenge.example.com."}, provider.cleaned)
+}
+
+func TestChallenge_PreSolve_missingAccount(t *testing.T) {
+	server := tester.MockACMEServer().BuildHTTPS(t)
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+
+	core, err := api.New(server.Client(), "lego-test", server.URL+"/dir", "", privateKey)
+	require.NoError(t, err)
+
+	chlg := NewChallenge(core, nil, &providerMock{})
+
+	authz := acme.Authorization{
+		Identifier: acme.Identifier{
+			Value: "example.com",
+		},
+		Challenges: []acme.Challenge{
+			{Type: challenge.DNSAccount01.String()},
+		},
+	}
+
+	err = chlg.PreSolve(t.Context(), authz)
+	require.EqualError(t, err, "dnsaccount01: missing account URL")
+}
+
+type providerTimeoutMock struct {
+	providerMock
+
+	timeout, interval time.Duration
+}
+
+func (p *providerTimeoutMock) Timeout() (time.Duration, time.Duration) { return p.timeout, p.interval }
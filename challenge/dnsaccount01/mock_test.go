@@ -0,0 +1,23 @@
+package dnsaccount01
+
+import (
+	"net"
+	"testing"
+
+	"github.com/go-acme/lego/v5/challenge"
+	"github.com/go-acme/lego/v5/challenge/dns01"
+)
+
+// mockDefault sets the default client of the dns-01 challenge,
+// used by dns01.GetChallengeInfo to follow the CNAMEs.
+func mockDefault(t *testing.T, recursiveNS net.Addr) {
+	t.Helper()
+
+	backup := dns01.DefaultClient()
+
+	t.Cleanup(func() {
+		dns01.SetDefaultClient(backup)
+	})
+
+	dns01.SetDefaultClient(dns01.NewClient(&dns01.Options{RecursiveNameservers: []string{recursiveNS.String()}, NetworkStack: challenge.IPv4Only}))
+}
@@ -34,6 +34,9 @@ type preCheck struct {
 
 	// require the TXT record to be propagated to all recursive name servers
 	requireRecursiveNssPropagation bool
+
+	// require valid DNSSEC signatures for the TXT record and the CNAME chain
+	requireDNSSEC bool
 }
 
 func newPreCheck() preCheck {
@@ -72,6 +75,13 @@ func (p preCheck) checkDNSPropagation(ctx context.Context, fqdn string, matcher
 		}
 	}
 
+	if p.requireDNSSEC {
+		err = client.core.ValidateDNSSEC(ctx, dns.Fqdn(fqdn), dns.TypeTXT)
+		if err != nil {
+			return false, fmt.Errorf("DNSSEC: %w", err)
+		}
+	}
+
 	if !p.requireAuthoritativeNssPropagation {
 		return true, nil
 	}
@@ -0,0 +1,19 @@
+package challenge
+
+// SolveError is the error of the resolution of the challenge of an authorization.
+type SolveError struct {
+	// Domain is the targeted domain of the authorization (with the `*.` prefix for a wildcard).
+	Domain string
+	// Type is the type of the challenge.
+	Type Type
+
+	Err error
+}
+
+func (e *SolveError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *SolveError) Unwrap() error {
+	return e.Err
+}
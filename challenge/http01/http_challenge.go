@@ -9,6 +9,7 @@ import (
 	"github.com/go-acme/lego/v5/acme"
 	"github.com/go-acme/lego/v5/acme/api"
 	"github.com/go-acme/lego/v5/challenge"
+	"github.com/go-acme/lego/v5/internal/tracing"
 	"github.com/go-acme/lego/v5/log"
 )
 
@@ -74,13 +75,13 @@ func (c *Challenge) Solve(ctx context.Context, authz acme.Authorization) error {
 		return err
 	}
 
-	err = c.provider.Present(ctx, authz.Identifier.Value, chlng.Token, keyAuth)
+	err = tracing.Present(ctx, c.provider, chlng.Type, authz.Identifier.Value, chlng.Token, keyAuth)
 	if err != nil {
 		return fmt.Errorf("http01: error presenting token (%s): %w", domain, err)
 	}
 
 	defer func() {
-		err := c.provider.CleanUp(ctx, authz.Identifier.Value, chlng.Token, keyAuth)
+		err := tracing.CleanUp(ctx, c.provider, chlng.Type, authz.Identifier.Value, chlng.Token, keyAuth)
 		if err != nil {
 			log.Warn("http01: cleaning up failed.", log.DomainAttr(domain), log.ErrorAttr(err))
 		}
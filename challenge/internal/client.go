@@ -3,12 +3,15 @@ package internal
 import (
 	"context"
 	"errors"
+	"net/http"
 	"os"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/go-acme/lego/v5/challenge"
+	"github.com/go-acme/lego/v5/internal/tracing"
 	"github.com/miekg/dns"
 )
 
@@ -30,6 +33,13 @@ type Client struct {
 	udpClient *dns.Client
 	tcpOnly   bool
 
+	// dotClient and httpClient are used by the DNS-over-TLS and DNS-over-HTTPS resolvers.
+	dotClient  *dns.Client
+	httpClient *http.Client
+
+	// trustAnchors used by the DNSSEC validation.
+	trustAnchors []*dns.DS
+
 	fqdnSoaCache   map[string]*soaCacheEntry
 	muFqdnSoaCache sync.Mutex
 }
@@ -59,7 +69,13 @@ func NewClient(opts *Options) *Client {
 			Net:     opts.NetworkStack.Network("udp"),
 			Timeout: opts.Timeout,
 		},
-		tcpOnly:        opts.TCPOnly,
+		tcpOnly: opts.TCPOnly,
+		dotClient: &dns.Client{
+			Net:     opts.NetworkStack.Network("tcp") + "-tls",
+			Timeout: opts.Timeout,
+		},
+		httpClient:     newDoHClient(opts.Timeout, opts.NetworkStack),
+		trustAnchors:   rootTrustAnchors,
 		fqdnSoaCache:   map[string]*soaCacheEntry{},
 		muFqdnSoaCache: sync.Mutex{},
 	}
@@ -70,8 +86,10 @@ func (c *Client) SendQuery(ctx context.Context, fqdn string, rtype uint16, recur
 }
 
 func (c *Client) SendQueryCustom(ctx context.Context, fqdn string, rtype uint16, nameservers []string, recursive bool) (*dns.Msg, error) {
-	m := createDNSMsg(fqdn, rtype, recursive)
+	return c.sendMsg(ctx, createDNSMsg(fqdn, rtype, recursive), nameservers)
+}
 
+func (c *Client) sendMsg(ctx context.Context, m *dns.Msg, nameservers []string) (*dns.Msg, error) {
 	if len(nameservers) == 0 {
 		return nil, &DNSError{Message: "empty list of nameservers"}
 	}
@@ -98,7 +116,32 @@ func (c *Client) SendQueryCustom(ctx context.Context, fqdn string, rtype uint16,
 	return r, nil
 }
 
-func (c *Client) exchange(ctx context.Context, m *dns.Msg, ns string) (*dns.Msg, error) {
+func (c *Client) exchange(ctx context.Context, m *dns.Msg, ns string) (_ *dns.Msg, err error) {
+	ctx, span := tracing.Start(ctx, "lego.dns.query",
+		tracing.AttrFQDN.String(m.Question[0].Name),
+		tracing.AttrRecordType.String(dns.TypeToString[m.Question[0].Qtype]),
+		tracing.AttrNameserver.String(ns),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	switch {
+	case strings.HasPrefix(ns, schemeDoH):
+		r, err := c.exchangeDoH(ctx, m, ns)
+		if err != nil {
+			return r, &DNSError{Message: "DNS-over-HTTPS call error", MsgIn: m, NS: ns, Err: err}
+		}
+
+		return r, nil
+
+	case strings.HasPrefix(ns, schemeDoT):
+		r, err := c.exchangeDoT(ctx, m, ns)
+		if err != nil {
+			return r, &DNSError{Message: "DNS-over-TLS call error", MsgIn: m, NS: ns, Err: err}
+		}
+
+		return r, nil
+	}
+
 	if c.tcpOnly {
 		r, _, err := c.tcpClient.ExchangeContext(ctx, m, ns)
 		if err != nil {
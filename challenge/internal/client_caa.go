@@ -0,0 +1,43 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/miekg/dns"
+)
+
+// LookupCAA returns the relevant CAA RRset of a domain, and the domain where the RRset has been found.
+// The tree is climbed from the domain to the TLD until a non-empty CAA RRset is found.
+// The CNAMEs are followed by the recursive nameservers.
+// An empty RRset means that no CAA record restricts the issuance.
+// - https://www.rfc-editor.org/rfc/rfc8659.html#section-3
+func (c *Client) LookupCAA(ctx context.Context, domain string) ([]*dns.CAA, string, error) {
+	for fqdn := range DomainsSeq(dns.Fqdn(domain)) {
+		r, err := c.SendQuery(ctx, fqdn, dns.TypeCAA, true)
+		if err != nil {
+			return nil, "", err
+		}
+
+		switch r.Rcode {
+		case dns.RcodeSuccess, dns.RcodeNameError:
+			// NOERROR or NXDOMAIN
+		default:
+			return nil, "", &DNSError{Message: fmt.Sprintf("unexpected response for '%s'", fqdn), MsgOut: r}
+		}
+
+		var records []*dns.CAA
+
+		for _, rr := range r.Answer {
+			if caa, ok := rr.(*dns.CAA); ok {
+				records = append(records, caa)
+			}
+		}
+
+		if len(records) > 0 {
+			return records, fqdn, nil
+		}
+	}
+
+	return nil, "", nil
+}
@@ -0,0 +1,105 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/go-acme/lego/v5/internal/tester/dnsmock"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fakeCAA(name, tag, value string) *dns.CAA {
+	return &dns.CAA{
+		Hdr:   dns.RR_Header{Name: name, Rrtype: dns.TypeCAA, Class: dns.ClassINET, Ttl: 3600},
+		Tag:   tag,
+		Value: value,
+	}
+}
+
+func TestClient_LookupCAA(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		domain           string
+		builder          *dnsmock.Builder
+		expectedDomain   string
+		expectedRecords  []string
+		expectedErrorMsg string
+	}{
+		{
+			desc:   "records on the domain",
+			domain: "example.com",
+			builder: dnsmock.NewServer().
+				Query("example.com. CAA", dnsmock.Answer(
+					fakeCAA("example.com.", "issue", "letsencrypt.org"),
+					fakeCAA("example.com.", "iodef", "mailto:security@example.com"),
+				)),
+			expectedDomain:  "example.com.",
+			expectedRecords: []string{"issue letsencrypt.org", "iodef mailto:security@example.com"},
+		},
+		{
+			desc:   "records on a parent domain",
+			domain: "a.b.example.com",
+			builder: dnsmock.NewServer().
+				Query("a.b.example.com. CAA", dnsmock.Error(dns.RcodeNameError)).
+				Query("b.example.com. CAA", dnsmock.Answer()).
+				Query("example.com. CAA", dnsmock.Answer(fakeCAA("example.com.", "issue", "letsencrypt.org"))),
+			expectedDomain:  "example.com.",
+			expectedRecords: []string{"issue letsencrypt.org"},
+		},
+		{
+			desc:   "CNAME",
+			domain: "www.example.com",
+			builder: dnsmock.NewServer().
+				Query("www.example.com. CAA", dnsmock.Answer(
+					&dns.CNAME{
+						Hdr:    dns.RR_Header{Name: "www.example.com.", Rrtype: dns.TypeCNAME, Class: dns.ClassINET, Ttl: 3600},
+						Target: "example.org.",
+					},
+					fakeCAA("example.org.", "issue", "letsencrypt.org"),
+				)),
+			expectedDomain:  "www.example.com.",
+			expectedRecords: []string{"issue letsencrypt.org"},
+		},
+		{
+			desc:   "no records",
+			domain: "example.com",
+			builder: dnsmock.NewServer().
+				Query("example.com. CAA", dnsmock.Answer()).
+				Query("com. CAA", dnsmock.Answer()),
+		},
+		{
+			desc:   "server failure",
+			domain: "example.com",
+			builder: dnsmock.NewServer().
+				Query("example.com. CAA", dnsmock.Error(dns.RcodeServerFailure)),
+			expectedErrorMsg: "unexpected response for 'example.com.' [question='example.com. IN  CAA', code=SERVFAIL]",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			client := NewClient(&Options{RecursiveNameservers: []string{test.builder.Build(t).String()}})
+
+			records, domain, err := client.LookupCAA(t.Context(), test.domain)
+			if test.expectedErrorMsg != "" {
+				require.EqualError(t, err, test.expectedErrorMsg)
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expectedDomain, domain)
+
+			var values []string
+			for _, record := range records {
+				values = append(values, record.Tag+" "+record.Value)
+			}
+
+			assert.Equal(t, test.expectedRecords, values)
+		})
+	}
+}
@@ -0,0 +1,338 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// rootTrustAnchors are the DS records of the root zone KSKs (https://data.iana.org/root-anchors/root-anchors.xml).
+var rootTrustAnchors = mustParseDS(
+	". 86400 IN DS 20326 8 2 E06D44B80B8F1D39A95C0B0D7C65D08458E880409BBC683457104237C7F8EC8D",
+	". 86400 IN DS 38696 8 2 683D2D0ACB8C9B712A1948B27F741219298D0A450D612C483AF444A4C0FB2B16",
+)
+
+// maxDNSSECChainLength limits the number of zones in a chain of trust.
+const maxDNSSECChainLength = 20
+
+// ValidateDNSSEC queries the records of the fqdn with DNSSEC (DO bit),
+// and validates the signatures of the RRsets of the answer (CNAME chain included) up to the trust anchors.
+func (c *Client) ValidateDNSSEC(ctx context.Context, fqdn string, rtype uint16) error {
+	r, err := c.sendDNSSECQuery(ctx, fqdn, rtype)
+	if err != nil {
+		return err
+	}
+
+	rrsets, sigs := splitRRSets(r.Answer)
+
+	found := slices.ContainsFunc(r.Answer, func(rr dns.RR) bool { return rr.Header().Rrtype == rtype })
+	if !found {
+		return fmt.Errorf("no %s record for %s", dns.TypeToString[rtype], fqdn)
+	}
+
+	for key, rrset := range rrsets {
+		err = c.verifyRRSet(ctx, rrset, sigs[key], 0)
+		if err != nil {
+			return fmt.Errorf("%s %s: %w", key.name, dns.TypeToString[key.rtype], err)
+		}
+	}
+
+	return nil
+}
+
+// verifyRRSet verifies the signatures of an RRset with the DNSKEYs of the signer zone,
+// and the chain of trust of the signer zone.
+func (c *Client) verifyRRSet(ctx context.Context, rrset []dns.RR, sigs []*dns.RRSIG, depth int) error {
+	if len(sigs) == 0 {
+		return errors.New("no RRSIG record")
+	}
+
+	sigs, err := filterSignatures(rrset[0].Header().Name, sigs)
+	if err != nil {
+		return err
+	}
+
+	signer := sigs[0].SignerName
+
+	keys, err := c.lookupTrustedDNSKEYs(ctx, signer, depth)
+	if err != nil {
+		return fmt.Errorf("zone %s: %w", signer, err)
+	}
+
+	return verifySignatures(rrset, sigs, keys)
+}
+
+// lookupTrustedDNSKEYs returns the DNSKEYs of the zone,
+// after the validation of the chain of trust (DNSKEY -> DS -> parent zone) up to a trust anchor.
+func (c *Client) lookupTrustedDNSKEYs(ctx context.Context, zone string, depth int) ([]*dns.DNSKEY, error) {
+	if depth > maxDNSSECChainLength {
+		return nil, errors.New("chain of trust too long")
+	}
+
+	r, err := c.sendDNSSECQuery(ctx, zone, dns.TypeDNSKEY)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		keyRRs  []dns.RR
+		keys    []*dns.DNSKEY
+		keySigs []*dns.RRSIG
+	)
+
+	for _, rr := range r.Answer {
+		switch v := rr.(type) {
+		case *dns.DNSKEY:
+			keyRRs = append(keyRRs, v)
+			keys = append(keys, v)
+
+		case *dns.RRSIG:
+			if v.TypeCovered == dns.TypeDNSKEY {
+				keySigs = append(keySigs, v)
+			}
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("no DNSKEY record")
+	}
+
+	dsSet, err := c.lookupTrustedDS(ctx, zone, depth)
+	if err != nil {
+		return nil, err
+	}
+
+	trusted := matchDS(keys, dsSet)
+	if len(trusted) == 0 {
+		return nil, errors.New("no DNSKEY matching the DS records")
+	}
+
+	// The DNSKEY RRset must be signed by a key referenced by a DS record.
+	err = verifySignatures(keyRRs, keySigs, trusted)
+	if err != nil {
+		return nil, fmt.Errorf("DNSKEY: %w", err)
+	}
+
+	return keys, nil
+}
+
+// lookupTrustedDS returns the DS records of the zone:
+// the trust anchors, or the DS records validated with the parent zone.
+func (c *Client) lookupTrustedDS(ctx context.Context, zone string, depth int) ([]*dns.DS, error) {
+	var anchors []*dns.DS
+
+	for _, anchor := range c.trustAnchors {
+		if strings.EqualFold(anchor.Hdr.Name, zone) {
+			anchors = append(anchors, anchor)
+		}
+	}
+
+	if len(anchors) > 0 {
+		return anchors, nil
+	}
+
+	if zone == "." {
+		return nil, errors.New("no trust anchor")
+	}
+
+	r, err := c.sendDNSSECQuery(ctx, zone, dns.TypeDS)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		dsRRs  []dns.RR
+		dsSet  []*dns.DS
+		dsSigs []*dns.RRSIG
+	)
+
+	for _, rr := range r.Answer {
+		switch v := rr.(type) {
+		case *dns.DS:
+			dsRRs = append(dsRRs, v)
+			dsSet = append(dsSet, v)
+
+		case *dns.RRSIG:
+			if v.TypeCovered == dns.TypeDS {
+				dsSigs = append(dsSigs, v)
+			}
+		}
+	}
+
+	if len(dsSet) == 0 {
+		return nil, errors.New("no DS record (insecure delegation)")
+	}
+
+	// The DS records are signed by the parent zone.
+	for _, sig := range dsSigs {
+		if strings.EqualFold(sig.SignerName, zone) || !dns.IsSubDomain(sig.SignerName, zone) {
+			return nil, fmt.Errorf("DS: invalid signer %s", sig.SignerName)
+		}
+	}
+
+	err = c.verifyRRSet(ctx, dsRRs, dsSigs, depth+1)
+	if err != nil {
+		return nil, fmt.Errorf("DS: %w", err)
+	}
+
+	return dsSet, nil
+}
+
+func (c *Client) sendDNSSECQuery(ctx context.Context, fqdn string, rtype uint16) (*dns.Msg, error) {
+	m := createDNSMsg(fqdn, rtype, true)
+	m.IsEdns0().SetDo()
+
+	// The signatures are validated by the client.
+	m.CheckingDisabled = true
+
+	r, err := c.sendMsg(ctx, m, c.recursiveNameservers)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Rcode != dns.RcodeSuccess {
+		return nil, &DNSError{Message: "unexpected response", MsgIn: m, MsgOut: r}
+	}
+
+	return r, nil
+}
+
+// filterSignatures returns the signatures that can be used to validate the RRset of the owner name:
+// the signer zone must be the owner name or one of its ancestors,
+// and the signatures of records synthesized from a wildcard are rejected
+// because the non-existence of the owner name (NSEC/NSEC3) is not verified.
+func filterSignatures(owner string, sigs []*dns.RRSIG) ([]*dns.RRSIG, error) {
+	var (
+		valid []*dns.RRSIG
+		errs  []error
+	)
+
+	labels := dns.CountLabel(owner)
+
+	for _, sig := range sigs {
+		switch {
+		case !dns.IsSubDomain(sig.SignerName, owner):
+			errs = append(errs, fmt.Errorf("RRSIG (key tag %d): signer %s out of bailiwick", sig.KeyTag, sig.SignerName))
+
+		case int(sig.Labels) < labels:
+			errs = append(errs, fmt.Errorf("RRSIG (key tag %d): wildcard expansion not supported", sig.KeyTag))
+
+		case int(sig.Labels) > labels:
+			errs = append(errs, fmt.Errorf("RRSIG (key tag %d): invalid label count", sig.KeyTag))
+
+		default:
+			valid = append(valid, sig)
+		}
+	}
+
+	if len(valid) == 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return valid, nil
+}
+
+// verifySignatures checks that at least one valid signature of the RRset is made by one of the keys.
+func verifySignatures(rrset []dns.RR, sigs []*dns.RRSIG, keys []*dns.DNSKEY) error {
+	if len(sigs) == 0 {
+		return errors.New("no RRSIG record")
+	}
+
+	var errs []error
+
+	now := time.Now()
+
+	for _, sig := range sigs {
+		if !sig.ValidityPeriod(now) {
+			errs = append(errs, fmt.Errorf("RRSIG (key tag %d): expired or not yet valid signature", sig.KeyTag))
+			continue
+		}
+
+		for _, key := range keys {
+			if key.KeyTag() != sig.KeyTag || key.Algorithm != sig.Algorithm || !strings.EqualFold(key.Hdr.Name, sig.SignerName) {
+				continue
+			}
+
+			err := sig.Verify(key, rrset)
+			if err == nil {
+				return nil
+			}
+
+			errs = append(errs, fmt.Errorf("RRSIG (key tag %d): %w", sig.KeyTag, err))
+		}
+	}
+
+	if len(errs) == 0 {
+		return errors.New("no DNSKEY matching the RRSIG records")
+	}
+
+	return errors.Join(errs...)
+}
+
+// matchDS returns the keys referenced by the DS records.
+func matchDS(keys []*dns.DNSKEY, dsSet []*dns.DS) []*dns.DNSKEY {
+	var matches []*dns.DNSKEY
+
+	for _, key := range keys {
+		for _, ds := range dsSet {
+			if key.KeyTag() != ds.KeyTag || key.Algorithm != ds.Algorithm {
+				continue
+			}
+
+			expected := key.ToDS(ds.DigestType)
+			if expected != nil && strings.EqualFold(expected.Digest, ds.Digest) {
+				matches = append(matches, key)
+				break
+			}
+		}
+	}
+
+	return matches
+}
+
+type rrsetKey struct {
+	name  string
+	rtype uint16
+}
+
+// splitRRSets groups the records by name and type, and associates the signatures.
+func splitRRSets(rrs []dns.RR) (map[rrsetKey][]dns.RR, map[rrsetKey][]*dns.RRSIG) {
+	rrsets := make(map[rrsetKey][]dns.RR)
+	sigs := make(map[rrsetKey][]*dns.RRSIG)
+
+	for _, rr := range rrs {
+		name := strings.ToLower(rr.Header().Name)
+
+		if sig, ok := rr.(*dns.RRSIG); ok {
+			key := rrsetKey{name: name, rtype: sig.TypeCovered}
+			sigs[key] = append(sigs[key], sig)
+
+			continue
+		}
+
+		key := rrsetKey{name: name, rtype: rr.Header().Rrtype}
+		rrsets[key] = append(rrsets[key], rr)
+	}
+
+	return rrsets, sigs
+}
+
+func mustParseDS(records ...string) []*dns.DS {
+	var anchors []*dns.DS
+
+	for _, record := range records {
+		rr, err := dns.NewRR(record)
+		if err != nil {
+			panic(fmt.Sprintf("invalid DS record %q: %v", record, err))
+		}
+
+		anchors = append(anchors, rr.(*dns.DS))
+	}
+
+	return anchors
+}
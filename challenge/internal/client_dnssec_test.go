@@ -0,0 +1,233 @@
+package internal
+
+import (
+	"crypto"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v5/internal/tester/dnsmock"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_ValidateDNSSEC(t *testing.T) {
+	com := newSignedZone(t, "com.")
+	example := newSignedZone(t, "example.com.")
+	other := newSignedZone(t, "example.com.")
+	foreign := newSignedZone(t, "example.org.")
+
+	txt := fakeTXT("_acme-challenge.example.com.", "value")
+	cname := fakeCNAME("_acme-challenge.www.example.com.", "_acme-challenge.example.com.")
+
+	// The signature of "*.example.com." expanded to the queried name.
+	wildcardSig := example.sign(t, fakeTXT("*.example.com.", "value"))
+	wildcardSig.Hdr.Name = txt.Hdr.Name
+
+	testCases := []struct {
+		desc     string
+		fqdn     string
+		answer   []dns.RR
+		ds       *signedZone
+		expected string
+	}{
+		{
+			desc:   "valid",
+			fqdn:   "_acme-challenge.example.com.",
+			answer: []dns.RR{txt, example.sign(t, txt)},
+			ds:     example,
+		},
+		{
+			desc:   "valid CNAME chain",
+			fqdn:   "_acme-challenge.www.example.com.",
+			answer: []dns.RR{cname, example.sign(t, cname), txt, example.sign(t, txt)},
+			ds:     example,
+		},
+		{
+			desc:     "unsigned record",
+			fqdn:     "_acme-challenge.example.com.",
+			answer:   []dns.RR{txt},
+			ds:       example,
+			expected: "_acme-challenge.example.com. TXT: no RRSIG record",
+		},
+		{
+			desc:     "unsigned CNAME",
+			fqdn:     "_acme-challenge.www.example.com.",
+			answer:   []dns.RR{cname, txt, example.sign(t, txt)},
+			ds:       example,
+			expected: "_acme-challenge.www.example.com. CNAME: no RRSIG record",
+		},
+		{
+			desc:     "signed with an unknown key",
+			fqdn:     "_acme-challenge.example.com.",
+			answer:   []dns.RR{txt, other.sign(t, txt)},
+			ds:       example,
+			expected: "_acme-challenge.example.com. TXT: no DNSKEY matching the RRSIG records",
+		},
+		{
+			desc:     "signer out of bailiwick",
+			fqdn:     "_acme-challenge.example.com.",
+			answer:   []dns.RR{txt, foreign.sign(t, txt)},
+			ds:       example,
+			expected: "_acme-challenge.example.com. TXT: RRSIG (key tag " + strconv.Itoa(int(foreign.key.KeyTag())) + "): signer example.org. out of bailiwick",
+		},
+		{
+			desc:     "wildcard expansion",
+			fqdn:     "_acme-challenge.example.com.",
+			answer:   []dns.RR{txt, wildcardSig},
+			ds:       example,
+			expected: "_acme-challenge.example.com. TXT: RRSIG (key tag " + strconv.Itoa(int(example.key.KeyTag())) + "): wildcard expansion not supported",
+		},
+		{
+			desc:     "expired signature",
+			fqdn:     "_acme-challenge.example.com.",
+			answer:   []dns.RR{txt, example.signWithValidity(t, time.Now().Add(-2*time.Hour), time.Now().Add(-time.Hour), txt)},
+			ds:       example,
+			expected: "expired or not yet valid signature",
+		},
+		{
+			desc:     "DS mismatch",
+			fqdn:     "_acme-challenge.example.com.",
+			answer:   []dns.RR{txt, example.sign(t, txt)},
+			ds:       other,
+			expected: "_acme-challenge.example.com. TXT: zone example.com.: no DNSKEY matching the DS records",
+		},
+		{
+			desc:     "no record",
+			fqdn:     "_acme-challenge.example.com.",
+			ds:       example,
+			expected: "no TXT record for _acme-challenge.example.com.",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			ds := test.ds.ds(t, "example.com.")
+
+			// The DS queries are routed to the parent zone by the DNS mux.
+			addr := dnsmock.NewServer().
+				Query(test.fqdn+" TXT", dnsmock.Answer(test.answer...)).
+				Query("example.com. DNSKEY", dnsmock.Answer(example.dnskeys(t)...)).
+				Query("com. DS", dnsmock.Answer(ds, com.sign(t, ds))).
+				Query("com. DNSKEY", dnsmock.Answer(com.dnskeys(t)...)).
+				Build(t)
+
+			client := NewClient(&Options{RecursiveNameservers: []string{addr.String()}})
+			client.trustAnchors = []*dns.DS{com.ds(t, "com.")}
+
+			err := client.ValidateDNSSEC(t.Context(), test.fqdn, dns.TypeTXT)
+			if test.expected == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, test.expected)
+			}
+		})
+	}
+}
+
+func TestClient_ValidateDNSSEC_noTrustAnchor(t *testing.T) {
+	example := newSignedZone(t, "example.com.")
+
+	txt := fakeTXT("_acme-challenge.example.com.", "value")
+	ds := example.ds(t, "example.com.")
+
+	root := newSignedZone(t, ".")
+
+	// The DS queries are routed to the parent zone by the DNS mux.
+	addr := dnsmock.NewServer().
+		Query("_acme-challenge.example.com. TXT", dnsmock.Answer(txt, example.sign(t, txt))).
+		Query("example.com. DNSKEY", dnsmock.Answer(example.dnskeys(t)...)).
+		Query(". DS", dnsmock.Answer(ds, root.sign(t, ds))).
+		Query(". DNSKEY", dnsmock.Answer(root.dnskeys(t)...)).
+		Build(t)
+
+	client := NewClient(&Options{RecursiveNameservers: []string{addr.String()}})
+
+	err := client.ValidateDNSSEC(t.Context(), "_acme-challenge.example.com.", dns.TypeTXT)
+	require.EqualError(t, err, "_acme-challenge.example.com. TXT: zone example.com.: DS: zone .: no DNSKEY matching the DS records")
+}
+
+type signedZone struct {
+	key     *dns.DNSKEY
+	private crypto.Signer
+}
+
+func newSignedZone(t *testing.T, zone string) *signedZone {
+	t.Helper()
+
+	key := &dns.DNSKEY{
+		Hdr:       dns.RR_Header{Name: zone, Rrtype: dns.TypeDNSKEY, Class: dns.ClassINET, Ttl: 3600},
+		Flags:     257,
+		Protocol:  3,
+		Algorithm: dns.ECDSAP256SHA256,
+	}
+
+	private, err := key.Generate(256)
+	require.NoError(t, err)
+
+	return &signedZone{key: key, private: private.(crypto.Signer)}
+}
+
+func (z *signedZone) sign(t *testing.T, rrs ...dns.RR) *dns.RRSIG {
+	t.Helper()
+
+	return z.signWithValidity(t, time.Now().Add(-time.Hour), time.Now().Add(time.Hour), rrs...)
+}
+
+func (z *signedZone) signWithValidity(t *testing.T, inception, expiration time.Time, rrs ...dns.RR) *dns.RRSIG {
+	t.Helper()
+
+	header := rrs[0].Header()
+
+	sig := &dns.RRSIG{
+		Hdr:         dns.RR_Header{Name: header.Name, Rrtype: dns.TypeRRSIG, Class: dns.ClassINET, Ttl: header.Ttl},
+		TypeCovered: header.Rrtype,
+		Algorithm:   z.key.Algorithm,
+		Labels:      uint8(dns.CountLabel(header.Name)),
+		OrigTtl:     header.Ttl,
+		Expiration:  uint32(expiration.Unix()),
+		Inception:   uint32(inception.Unix()),
+		KeyTag:      z.key.KeyTag(),
+		SignerName:  z.key.Hdr.Name,
+	}
+
+	err := sig.Sign(z.private, rrs)
+	require.NoError(t, err)
+
+	return sig
+}
+
+// dnskeys returns the DNSKEY RRset of the zone and its signature.
+func (z *signedZone) dnskeys(t *testing.T) []dns.RR {
+	t.Helper()
+
+	return []dns.RR{z.key, z.sign(t, z.key)}
+}
+
+// ds returns the DS record of the key of the zone, with the owner name.
+func (z *signedZone) ds(t *testing.T, name string) *dns.DS {
+	t.Helper()
+
+	ds := z.key.ToDS(dns.SHA256)
+	require.NotNil(t, ds)
+
+	ds.Hdr.Name = name
+
+	return ds
+}
+
+func fakeTXT(name, value string) *dns.TXT {
+	return &dns.TXT{
+		Hdr: dns.RR_Header{Name: name, Rrtype: dns.TypeTXT, Class: dns.ClassINET, Ttl: 10},
+		Txt: []string{value},
+	}
+}
+
+func fakeCNAME(name, target string) *dns.CNAME {
+	return &dns.CNAME{
+		Hdr:    dns.RR_Header{Name: name, Rrtype: dns.TypeCNAME, Class: dns.ClassINET, Ttl: 10},
+		Target: target,
+	}
+}
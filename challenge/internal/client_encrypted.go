@@ -0,0 +1,123 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-acme/lego/v5/challenge"
+	"github.com/go-acme/lego/v5/internal/tracing"
+	"github.com/miekg/dns"
+)
+
+const (
+	// schemeDoH is the prefix of the DNS-over-HTTPS resolvers (RFC 8484).
+	// ex: `https://cloudflare-dns.com/dns-query`.
+	schemeDoH = "https://"
+
+	// schemeDoT is the prefix of the DNS-over-TLS resolvers (RFC 7858).
+	// ex: `tls://1.1.1.1:853`.
+	schemeDoT = "tls://"
+)
+
+const (
+	defaultDoTPort = "853"
+
+	dnsMessageContentType = "application/dns-message"
+)
+
+// exchangeDoH sends a DNS query with DNS-over-HTTPS (RFC 8484).
+func (c *Client) exchangeDoH(ctx context.Context, m *dns.Msg, endpoint string) (*dns.Msg, error) {
+	// https://www.rfc-editor.org/rfc/rfc8484.html#section-4.1
+	// The DNS ID should be 0 to be cache-friendly.
+	query := m.Copy()
+	query.Id = 0
+
+	data, err := query.Pack()
+	if err != nil {
+		return nil, fmt.Errorf("pack query: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", dnsMessageContentType)
+	req.Header.Set("Accept", dnsMessageContentType)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, dns.MaxMsgSize))
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+
+	r := new(dns.Msg)
+
+	err = r.Unpack(raw)
+	if err != nil {
+		return nil, fmt.Errorf("unpack response: %w", err)
+	}
+
+	r.Id = m.Id
+
+	return r, nil
+}
+
+// exchangeDoT sends a DNS query with DNS-over-TLS (RFC 7858).
+func (c *Client) exchangeDoT(ctx context.Context, m *dns.Msg, ns string) (*dns.Msg, error) {
+	r, _, err := c.dotClient.ExchangeContext(ctx, m, strings.TrimPrefix(ns, schemeDoT))
+
+	return r, err
+}
+
+func newDoHClient(timeout time.Duration, stack challenge.NetworkStack) *http.Client {
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+
+	dialer := &net.Dialer{}
+
+	tr.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return dialer.DialContext(ctx, stack.Network(network), addr)
+	}
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: tracing.WrapTransport(tr),
+	}
+}
+
+// parseEncryptedNameserver normalizes a DNS-over-HTTPS or a DNS-over-TLS resolver.
+// The second value is false if the resolver is not an encrypted resolver.
+func parseEncryptedNameserver(resolver string) (string, bool) {
+	switch {
+	case strings.HasPrefix(resolver, schemeDoH):
+		return resolver, true
+
+	case strings.HasPrefix(resolver, schemeDoT):
+		address := strings.TrimPrefix(resolver, schemeDoT)
+
+		if _, _, err := net.SplitHostPort(address); err != nil {
+			address = net.JoinHostPort(strings.Trim(address, "[]"), defaultDoTPort)
+		}
+
+		return schemeDoT + address, true
+
+	default:
+		return "", false
+	}
+}
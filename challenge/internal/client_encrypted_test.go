@@ -0,0 +1,145 @@
+package internal
+
+import (
+	"crypto/tls"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-acme/lego/v5/internal/tester/dnsmock"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_FindZoneByFqdn_doh(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost || req.Header.Get("Content-Type") != dnsMessageContentType {
+			http.Error(rw, "invalid request", http.StatusBadRequest)
+			return
+		}
+
+		raw, err := io.ReadAll(req.Body)
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		query := new(dns.Msg)
+
+		err = query.Unpack(raw)
+		if err != nil || query.Id != 0 {
+			http.Error(rw, "invalid query", http.StatusBadRequest)
+			return
+		}
+
+		resp := new(dns.Msg).SetReply(query)
+
+		if query.Question[0].Name == "example.com." {
+			resp.Answer = append(resp.Answer, &dns.SOA{
+				Hdr:    dns.RR_Header{Name: "example.com.", Rrtype: dns.TypeSOA, Class: dns.ClassINET, Ttl: 120},
+				Ns:     "ns1.example.com.",
+				Mbox:   "admin.example.com.",
+				Serial: 1,
+			})
+		}
+
+		data, err := resp.Pack()
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		rw.Header().Set("Content-Type", dnsMessageContentType)
+		_, _ = rw.Write(data)
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient(&Options{RecursiveNameservers: []string{server.URL + "/dns-query"}})
+	client.httpClient = server.Client()
+
+	zone, err := client.FindZoneByFqdn(t.Context(), "www.example.com.")
+	require.NoError(t, err)
+
+	assert.Equal(t, "example.com.", zone)
+}
+
+func TestClient_FindZoneByFqdn_doh_error(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		http.Error(rw, "oops", http.StatusInternalServerError)
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient(&Options{RecursiveNameservers: []string{server.URL + "/dns-query"}})
+	client.httpClient = server.Client()
+
+	_, err := client.FindZoneByFqdn(t.Context(), "example.com.")
+	require.ErrorContains(t, err, "DNS-over-HTTPS call error: unexpected status code: 500")
+}
+
+func TestClient_FindZoneByFqdn_dot(t *testing.T) {
+	// Only used to get a certificate and the associated client configuration.
+	certServer := httptest.NewTLSServer(http.NotFoundHandler())
+	t.Cleanup(certServer.Close)
+
+	addr := dnsmock.NewServer().
+		Query("example.com. SOA", dnsmock.SOA("")).
+		Query("www.example.com. SOA", dnsmock.Noop).
+		Build(t, func(server *dns.Server) error {
+			server.Net = "tcp-tls"
+			server.TLSConfig = &tls.Config{Certificates: certServer.TLS.Certificates}
+
+			return nil
+		})
+
+	client := NewClient(&Options{RecursiveNameservers: []string{"tls://" + addr.String()}})
+	client.dotClient.TLSConfig = certServer.Client().Transport.(*http.Transport).TLSClientConfig
+
+	zone, err := client.FindZoneByFqdn(t.Context(), "www.example.com.")
+	require.NoError(t, err)
+
+	assert.Equal(t, "example.com.", zone)
+}
+
+func Test_parseNameservers_encrypted(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		servers  []string
+		expected []string
+	}{
+		{
+			desc:     "DNS-over-HTTPS",
+			servers:  []string{"https://cloudflare-dns.com/dns-query"},
+			expected: []string{"https://cloudflare-dns.com/dns-query"},
+		},
+		{
+			desc:     "DNS-over-TLS without port",
+			servers:  []string{"tls://1.1.1.1"},
+			expected: []string{"tls://1.1.1.1:853"},
+		},
+		{
+			desc:     "DNS-over-TLS with port",
+			servers:  []string{"tls://dns.example.com:8853"},
+			expected: []string{"tls://dns.example.com:8853"},
+		},
+		{
+			desc:     "DNS-over-TLS IPv6",
+			servers:  []string{"tls://2606:4700:4700::1111"},
+			expected: []string{"tls://[2606:4700:4700::1111]:853"},
+		},
+		{
+			desc:     "mixed",
+			servers:  []string{"8.8.8.8", "tls://1.1.1.1", "https://dns.google/dns-query"},
+			expected: []string{"8.8.8.8:53", "tls://1.1.1.1:853", "https://dns.google/dns-query"},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, parseNameservers(test.servers))
+		})
+	}
+}
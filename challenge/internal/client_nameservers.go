@@ -81,6 +81,11 @@ func parseNameservers(servers []string) []string {
 	var resolvers []string
 
 	for _, resolver := range servers {
+		if encrypted, ok := parseEncryptedNameserver(resolver); ok {
+			resolvers = append(resolvers, encrypted)
+			continue
+		}
+
 		// ensure all servers have a port number
 		if _, _, err := net.SplitHostPort(resolver); err != nil {
 			log.Debug("The nameserver does not contain a port, assuming port 53", slog.String("nameserver", resolver), log.ErrorAttr(err))
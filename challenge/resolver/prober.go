@@ -9,6 +9,7 @@ import (
 	"github.com/go-acme/lego/v5/acme"
 	"github.com/go-acme/lego/v5/challenge"
 	"github.com/go-acme/lego/v5/internal/errutils"
+	"github.com/go-acme/lego/v5/internal/tracing"
 	"github.com/go-acme/lego/v5/log"
 )
 
@@ -32,10 +33,20 @@ type sequential interface {
 	Sequential() (bool, time.Duration)
 }
 
-// an authz with the solver we have chosen and the index of the challenge associated with it.
+// an authz with the solver we have chosen and the type of the challenge associated with it.
 type selectedAuthSolver struct {
-	authz  acme.Authorization
-	solver solver
+	authz    acme.Authorization
+	solver   solver
+	chlgType challenge.Type
+}
+
+// solveError wraps the error with the information about the challenge.
+func (s *selectedAuthSolver) solveError(err error) error {
+	return &challenge.SolveError{
+		Domain: challenge.GetTargetedDomain(s.authz),
+		Type:   s.chlgType,
+		Err:    err,
+	}
 }
 
 type Prober struct {
@@ -48,6 +59,16 @@ func NewProber(solverManager *SolverManager) *Prober {
 	}
 }
 
+// ChallengeTypes returns the types of the solvers available for a domain.
+func (p *Prober) ChallengeTypes(domain string) []challenge.Type {
+	return p.solverManager.ChallengeTypes(domain)
+}
+
+// ChallengeType returns the type of the challenge chosen for a domain.
+func (p *Prober) ChallengeType(domain string) challenge.Type {
+	return p.solverManager.ChallengeType(domain)
+}
+
 // Solve Looks through the challenge combinations to find a solvable match.
 // Then solves the challenges in series and returns.
 func (p *Prober) Solve(ctx context.Context, authorizations []acme.Authorization) error {
@@ -69,8 +90,8 @@ func (p *Prober) Solve(ctx context.Context, authorizations []acme.Authorization)
 			continue
 		}
 
-		if solvr := p.solverManager.chooseSolver(authz); solvr != nil {
-			authSolver := &selectedAuthSolver{authz: authz, solver: solvr}
+		if solvr, chlgType := p.solverManager.chooseSolver(authz); solvr != nil {
+			authSolver := &selectedAuthSolver{authz: authz, solver: solvr, chlgType: chlgType}
 
 			switch s := solvr.(type) {
 			case sequential:
@@ -119,7 +140,7 @@ func sequentialSolve(ctx context.Context, authSolvers []*selectedAuthSolver, fai
 
 			err := solvr.PreSolve(ctx, authSolver.authz)
 			if err != nil {
-				failures.Add(domain, err)
+				failures.Add(domain, authSolver.solveError(err))
 
 				cleanUp(ctx, authSolver.solver, authSolver.authz)
 
@@ -130,9 +151,9 @@ func sequentialSolve(ctx context.Context, authSolvers []*selectedAuthSolver, fai
 		}
 
 		// Solve the challenge
-		err := authSolver.solver.Solve(ctx, authSolver.authz)
+		err := solve(ctx, authSolver)
 		if err != nil {
-			failures.Add(domain, err)
+			failures.Add(domain, authSolver.solveError(err))
 
 			cleanUp(ctx, authSolver.solver, authSolver.authz)
 
@@ -186,7 +207,7 @@ func parallelSolve(ctx context.Context, authSolvers []*selectedAuthSolver, failu
 		if solvr, ok := authSolver.solver.(preSolver); ok {
 			err := solvr.PreSolve(ctx, authz)
 			if err != nil {
-				failures.Add(challenge.GetTargetedDomain(authz), err)
+				failures.Add(challenge.GetTargetedDomain(authz), authSolver.solveError(err))
 			}
 		}
 	}
@@ -222,13 +243,26 @@ func parallelSolve(ctx context.Context, authSolvers []*selectedAuthSolver, failu
 			continue
 		}
 
-		err := authSolver.solver.Solve(ctx, authz)
+		err := solve(ctx, authSolver)
 		if err != nil {
-			failures.Add(domain, err)
+			failures.Add(domain, authSolver.solveError(err))
 		}
 	}
 }
 
+// solve solves the challenge of an authorization.
+func solve(ctx context.Context, authSolver *selectedAuthSolver) error {
+	ctx, span := tracing.Start(ctx, "lego.authorization",
+		tracing.AttrDomain.String(challenge.GetTargetedDomain(authSolver.authz)),
+	)
+
+	err := authSolver.solver.Solve(ctx, authSolver.authz)
+
+	tracing.End(span, err)
+
+	return err
+}
+
 func cleanUp(ctx context.Context, solvr solver, authz acme.Authorization) {
 	s, ok := solvr.(cleanup)
 	if !ok {
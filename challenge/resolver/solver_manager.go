@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"log/slog"
 	"maps"
+	"path"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -15,9 +17,11 @@ import (
 	"github.com/go-acme/lego/v5/acme/api"
 	"github.com/go-acme/lego/v5/challenge"
 	"github.com/go-acme/lego/v5/challenge/dns01"
+	"github.com/go-acme/lego/v5/challenge/dnsaccount01"
 	"github.com/go-acme/lego/v5/challenge/dnspersist01"
 	"github.com/go-acme/lego/v5/challenge/http01"
 	"github.com/go-acme/lego/v5/challenge/tlsalpn01"
+	"github.com/go-acme/lego/v5/event"
 	"github.com/go-acme/lego/v5/internal/dnspersist"
 	"github.com/go-acme/lego/v5/internal/wait"
 	"github.com/go-acme/lego/v5/log"
@@ -28,22 +32,37 @@ type byType []acme.Challenge
 func (a byType) Len() int      { return len(a) }
 func (a byType) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
 func (a byType) Less(i, j int) bool {
-	// When users configure both DNS and DNS-PERSIST-01, prefer DNS-01 to avoid
+	// When users configure both DNS and DNS-PERSIST-01, prefer DNS-01 (or DNS-ACCOUNT-01) to avoid
 	// unexpectedly selecting the manual-only DNS-PERSIST-01 workflow.
-	if a[i].Type == string(challenge.DNS01) && a[j].Type == string(challenge.DNSPersist01) {
+	if isDNSProviderType(a[i].Type) && a[j].Type == string(challenge.DNSPersist01) {
 		return true
 	}
 
-	if a[i].Type == string(challenge.DNSPersist01) && a[j].Type == string(challenge.DNS01) {
+	if a[i].Type == string(challenge.DNSPersist01) && isDNSProviderType(a[j].Type) {
 		return false
 	}
 
 	return a[i].Type > a[j].Type
 }
 
+// isDNSProviderType returns true for the DNS challenges solved with a DNS provider.
+func isDNSProviderType(chlgType string) bool {
+	return chlgType == string(challenge.DNS01) || chlgType == string(challenge.DNSAccount01)
+}
+
 type SolverManager struct {
 	core    *api.Core
 	solvers map[challenge.Type]solver
+	events  *event.Bus
+
+	// rules are the solvers dedicated to some domains.
+	rules []*domainRule
+}
+
+// domainRule associates a domain pattern with dedicated solvers.
+type domainRule struct {
+	pattern string
+	manager *SolverManager
 }
 
 func NewSolversManager(core *api.Core) *SolverManager {
@@ -53,28 +72,45 @@ func NewSolversManager(core *api.Core) *SolverManager {
 	}
 }
 
+// SetEvents sets the bus used to publish the challenge events.
+func (c *SolverManager) SetEvents(bus *event.Bus) {
+	c.events = bus
+
+	for _, rule := range c.rules {
+		rule.manager.SetEvents(bus)
+	}
+}
+
 // SetHTTP01Provider specifies a custom provider p that can solve the given HTTP-01 challenge.
 func (c *SolverManager) SetHTTP01Provider(p challenge.Provider, opts ...http01.ChallengeOption) error {
-	c.solvers[challenge.HTTP01] = http01.NewChallenge(c.core, validate, p, opts...)
+	c.solvers[challenge.HTTP01] = http01.NewChallenge(c.core, c.validate, p, opts...)
 	return nil
 }
 
 // SetTLSALPN01Provider specifies a custom provider p that can solve the given TLS-ALPN-01 challenge.
 func (c *SolverManager) SetTLSALPN01Provider(p challenge.Provider, opts ...tlsalpn01.ChallengeOption) error {
-	c.solvers[challenge.TLSALPN01] = tlsalpn01.NewChallenge(c.core, validate, p, opts...)
+	c.solvers[challenge.TLSALPN01] = tlsalpn01.NewChallenge(c.core, c.validate, p, opts...)
 	return nil
 }
 
 // SetDNS01Provider specifies a custom provider p that can solve the given DNS-01 challenge.
 func (c *SolverManager) SetDNS01Provider(p challenge.Provider, opts ...dns01.ChallengeOption) error {
-	c.solvers[challenge.DNS01] = dns01.NewChallenge(c.core, validate, p, opts...)
+	c.solvers[challenge.DNS01] = dns01.NewChallenge(c.core, c.validate, p, opts...)
+	return nil
+}
+
+// SetDNSAccount01Provider specifies a custom provider p that can solve the given DNS-ACCOUNT-01 challenge.
+// The DNS providers of the DNS-01 challenge are compatible with this challenge.
+// IMPORTANT: this method is experimental and may change without notice.
+func (c *SolverManager) SetDNSAccount01Provider(p challenge.Provider, opts ...dnsaccount01.ChallengeOption) error {
+	c.solvers[challenge.DNSAccount01] = dnsaccount01.NewChallenge(c.core, c.validate, p, opts...)
 	return nil
 }
 
 // SetDNSPersist01 configures the dns-persist-01 challenge solver.
 // IMPORTANT: this method is experimental and may change without notice.
 func (c *SolverManager) SetDNSPersist01(opts ...dnspersist01.ChallengeOption) error {
-	chlg, err := dnspersist01.NewChallenge(c.core, validate, dnspersist.NewProvider(), opts...)
+	chlg, err := dnspersist01.NewChallenge(c.core, c.validate, dnspersist.NewProvider(), opts...)
 	if err != nil {
 		return err
 	}
@@ -89,22 +125,92 @@ func (c *SolverManager) Remove(chlgType challenge.Type) {
 	delete(c.solvers, chlgType)
 }
 
-// ResetSolvers removes all solvers.
+// ResetSolvers removes all solvers, including the solvers dedicated to some domains.
 func (c *SolverManager) ResetSolvers() {
 	clear(c.solvers)
+
+	c.rules = nil
 }
 
-// Checks all challenges from the server in order and returns the first matching solver.
-func (c *SolverManager) chooseSolver(authz acme.Authorization) solver {
-	// Allow having a deterministic challenge order
-	sort.Sort(byType(authz.Challenges))
+// ForDomains returns a SolverManager dedicated to the domains matching the pattern.
+//
+// The pattern syntax is the one of path.Match (ex: `*.example.com`),
+// the wildcard domains are matched with their `*.` prefix.
+//
+// The authorizations of the matching domains only use the solvers of the returned SolverManager.
+// The rules are evaluated in the order of their creation, the first matching rule is used.
+// The other domains use the default solvers.
+func (c *SolverManager) ForDomains(pattern string) (*SolverManager, error) {
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+
+	_, err := path.Match(pattern, "")
+	if err != nil {
+		return nil, fmt.Errorf("invalid domain pattern %q: %w", pattern, err)
+	}
+
+	manager := NewSolversManager(c.core)
+	manager.events = c.events
+
+	c.rules = append(c.rules, &domainRule{pattern: pattern, manager: manager})
+
+	return manager, nil
+}
+
+// ChallengeTypes returns the types of the solvers available for a domain.
+// The solvers dedicated to the domain are used if any.
+func (c *SolverManager) ChallengeTypes(domain string) []challenge.Type {
+	for _, rule := range c.rules {
+		if ok, _ := path.Match(rule.pattern, strings.ToLower(domain)); ok {
+			return rule.manager.ChallengeTypes(domain)
+		}
+	}
+
+	return slices.Sorted(maps.Keys(c.solvers))
+}
 
+// ChallengeType returns the type of the challenge chosen for a domain, with the same priority as chooseSolver,
+// assuming the server offers the challenges of all the solvers available for the domain
+// (only the DNS challenges for a wildcard domain).
+// Returns an empty type if no solver is available.
+func (c *SolverManager) ChallengeType(domain string) challenge.Type {
+	var challenges []acme.Challenge
+
+	for _, chlgType := range c.ChallengeTypes(domain) {
+		if strings.HasPrefix(domain, "*.") && !isDNSProviderType(string(chlgType)) && chlgType != challenge.DNSPersist01 {
+			continue
+		}
+
+		challenges = append(challenges, acme.Challenge{Type: string(chlgType)})
+	}
+
+	if len(challenges) == 0 {
+		return ""
+	}
+
+	sort.Sort(byType(challenges))
+
+	return challenge.Type(challenges[0].Type)
+}
+
+// Checks all challenges from the server in order and returns the first matching solver, and the type of the related challenge.
+func (c *SolverManager) chooseSolver(authz acme.Authorization) (solver, challenge.Type) {
 	domain := challenge.GetTargetedDomain(authz)
 
+	for _, rule := range c.rules {
+		if ok, _ := path.Match(rule.pattern, strings.ToLower(domain)); ok {
+			log.Debug("Use the solvers dedicated to the domain.", log.DomainAttr(domain), slog.String("pattern", rule.pattern))
+
+			return rule.manager.chooseSolver(authz)
+		}
+	}
+
+	// Allow having a deterministic challenge order
+	sort.Sort(byType(authz.Challenges))
+
 	for _, chlg := range authz.Challenges {
 		if solvr, ok := c.solvers[challenge.Type(chlg.Type)]; ok {
 			log.Info("Use solver.", log.DomainAttr(domain), slog.String("type", chlg.Type))
-			return solvr
+			return solvr, challenge.Type(chlg.Type)
 		}
 
 		log.Debug("The challenge type is not in the list of available solvers. Skipping.",
@@ -119,6 +225,38 @@ func (c *SolverManager) chooseSolver(authz acme.Authorization) solver {
 		slog.String("solvers", solversToString(c.solvers)),
 	)
 
+	return nil, ""
+}
+
+// validate requests the validation of a challenge, and publishes the related events.
+func (c *SolverManager) validate(ctx context.Context, core *api.Core, domain string, chlg acme.Challenge) error {
+	c.events.Publish(ctx, event.Event{
+		Type:          event.ChallengePresented,
+		Domain:        domain,
+		ChallengeType: chlg.Type,
+		ChallengeURL:  chlg.URL,
+	})
+
+	err := validate(ctx, core, domain, chlg)
+	if err != nil {
+		c.events.Publish(ctx, event.Event{
+			Type:          event.ValidationFailed,
+			Domain:        domain,
+			ChallengeType: chlg.Type,
+			ChallengeURL:  chlg.URL,
+			Error:         err.Error(),
+		})
+
+		return err
+	}
+
+	c.events.Publish(ctx, event.Event{
+		Type:          event.ValidationSucceeded,
+		Domain:        domain,
+		ChallengeType: chlg.Type,
+		ChallengeURL:  chlg.URL,
+	})
+
 	return nil
 }
 
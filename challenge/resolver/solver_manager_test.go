@@ -65,22 +65,156 @@ func TestSolverManager_chooseSolver(t *testing.T) {
 				Challenges: test.challenges,
 			}
 
-			solvr := manager.chooseSolver(authz)
+			solvr, _ := manager.chooseSolver(authz)
 
 			test.expected(t, solvr)
 		})
 	}
 }
 
+func TestSolverManager_chooseSolver_rules(t *testing.T) {
+	defaultSolver := &namedSolverMock{name: "default"}
+	internalSolver := &namedSolverMock{name: "internal"}
+
+	manager := NewSolversManager(nil)
+	manager.solvers[challenge.HTTP01] = defaultSolver
+	manager.solvers[challenge.DNS01] = defaultSolver
+
+	internal, err := manager.ForDomains("*.internal.example.com")
+	require.NoError(t, err)
+
+	internal.solvers[challenge.DNS01] = internalSolver
+
+	_, err = manager.ForDomains("*.example.org")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		desc     string
+		authz    acme.Authorization
+		expected solver
+	}{
+		{
+			desc:     "default",
+			authz:    acme.Authorization{Identifier: acme.Identifier{Value: "www.example.com"}},
+			expected: defaultSolver,
+		},
+		{
+			desc:     "rule",
+			authz:    acme.Authorization{Identifier: acme.Identifier{Value: "a.b.Internal.example.com"}},
+			expected: internalSolver,
+		},
+		{
+			desc:     "rule wildcard",
+			authz:    acme.Authorization{Identifier: acme.Identifier{Value: "internal.example.com"}, Wildcard: true},
+			expected: internalSolver,
+		},
+		{
+			desc:  "rule without solver for the challenges",
+			authz: acme.Authorization{Identifier: acme.Identifier{Value: "www.example.org"}},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			test.authz.Challenges = []acme.Challenge{
+				{Type: challenge.HTTP01.String()},
+				{Type: challenge.DNS01.String()},
+			}
+
+			solvr, _ := manager.chooseSolver(test.authz)
+
+			if test.expected == nil {
+				assert.Nil(t, solvr)
+
+				return
+			}
+
+			assert.Same(t, test.expected, solvr)
+		})
+	}
+}
+
+func TestSolverManager_ChallengeTypes(t *testing.T) {
+	manager := NewSolversManager(nil)
+	manager.solvers[challenge.HTTP01] = &namedSolverMock{name: "default"}
+	manager.solvers[challenge.DNS01] = &namedSolverMock{name: "default"}
+
+	internal, err := manager.ForDomains("*.internal.example.com")
+	require.NoError(t, err)
+
+	internal.solvers[challenge.DNS01] = &namedSolverMock{name: "internal"}
+
+	assert.Equal(t, []challenge.Type{challenge.DNS01, challenge.HTTP01}, manager.ChallengeTypes("www.example.com"))
+	assert.Equal(t, []challenge.Type{challenge.DNS01}, manager.ChallengeTypes("*.internal.example.com"))
+}
+
+func TestSolverManager_ChallengeType(t *testing.T) {
+	manager := NewSolversManager(nil)
+	manager.solvers[challenge.HTTP01] = &namedSolverMock{name: "default"}
+	manager.solvers[challenge.DNS01] = &namedSolverMock{name: "default"}
+	manager.solvers[challenge.DNSPersist01] = &namedSolverMock{name: "default"}
+
+	internal, err := manager.ForDomains("*.internal.example.com")
+	require.NoError(t, err)
+
+	internal.solvers[challenge.DNSPersist01] = &namedSolverMock{name: "internal"}
+
+	web, err := manager.ForDomains("*.web.example.com")
+	require.NoError(t, err)
+
+	web.solvers[challenge.HTTP01] = &namedSolverMock{name: "web"}
+
+	testCases := []struct {
+		desc     string
+		domain   string
+		expected challenge.Type
+	}{
+		{
+			desc:     "default",
+			domain:   "www.example.com",
+			expected: challenge.HTTP01,
+		},
+		{
+			desc:     "wildcard",
+			domain:   "*.example.com",
+			expected: challenge.DNS01,
+		},
+		{
+			desc:     "rule",
+			domain:   "a.internal.example.com",
+			expected: challenge.DNSPersist01,
+		},
+		{
+			desc:   "wildcard without DNS solver",
+			domain: "*.web.example.com",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, manager.ChallengeType(test.domain))
+		})
+	}
+}
+
+func TestSolverManager_ForDomains_invalid(t *testing.T) {
+	manager := NewSolversManager(nil)
+
+	_, err := manager.ForDomains("[example.com")
+	require.EqualError(t, err, `invalid domain pattern "[example.com": syntax error in pattern`)
+}
+
 func Test_byType(t *testing.T) {
 	challenges := []acme.Challenge{
-		{Type: "dns-01"}, {Type: "dns-persist-01"}, {Type: "tlsalpn-01"}, {Type: "http-01"},
+		{Type: "dns-01"}, {Type: "dns-persist-01"}, {Type: "tlsalpn-01"}, {Type: "http-01"}, {Type: "dns-account-01"},
 	}
 
 	sort.Sort(byType(challenges))
 
 	expected := []acme.Challenge{
-		{Type: "tlsalpn-01"}, {Type: "http-01"}, {Type: "dns-01"}, {Type: "dns-persist-01"},
+		{Type: "tlsalpn-01"}, {Type: "http-01"}, {Type: "dns-account-01"}, {Type: "dns-01"}, {Type: "dns-persist-01"},
 	}
 
 	assert.Equal(t, expected, challenges)
@@ -337,6 +471,12 @@ func validateNoBody(privateKey *rsa.PrivateKey, r *http.Request) error {
 
 type solverMock struct{}
 
+type namedSolverMock struct {
+	solverMock
+
+	name string
+}
+
 func (s *solverMock) Solve(ctx context.Context, authz acme.Authorization) error {
 	return nil
 }
@@ -14,6 +14,7 @@ import (
 	"github.com/go-acme/lego/v5/acme/api"
 	"github.com/go-acme/lego/v5/certcrypto"
 	"github.com/go-acme/lego/v5/challenge"
+	"github.com/go-acme/lego/v5/internal/tracing"
 	"github.com/go-acme/lego/v5/log"
 )
 
@@ -73,13 +74,13 @@ func (c *Challenge) Solve(ctx context.Context, authz acme.Authorization) error {
 		return err
 	}
 
-	err = c.provider.Present(ctx, domain, chlng.Token, keyAuth)
+	err = tracing.Present(ctx, c.provider, chlng.Type, domain, chlng.Token, keyAuth)
 	if err != nil {
 		return fmt.Errorf("tlsalpn01: error presenting token (%s): %w", challenge.GetTargetedDomain(authz), err)
 	}
 
 	defer func() {
-		err := c.provider.CleanUp(ctx, domain, chlng.Token, keyAuth)
+		err := tracing.CleanUp(ctx, c.provider, chlng.Type, domain, chlng.Token, keyAuth)
 		if err != nil {
 			log.Warn("tlsalpn01: cleaning up failed.", log.DomainAttr(challenge.GetTargetedDomain(authz)), log.ErrorAttr(err))
 		}
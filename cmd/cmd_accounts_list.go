@@ -4,14 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log/slog"
+	"maps"
 	"os"
-	"path/filepath"
+	"slices"
 
 	"github.com/go-acme/lego/v5/cmd/internal/flags"
 	"github.com/go-acme/lego/v5/cmd/internal/storage"
-	"github.com/go-acme/lego/v5/log"
-	"github.com/mattn/go-zglob"
 	"github.com/urfave/cli/v3"
 )
 
@@ -31,24 +29,22 @@ func createAccountsList() *cli.Command {
 }
 
 func listAccounts(_ context.Context, cmd *cli.Command) error {
-	basePath := cmd.String(flags.FlgPath)
-
-	cfg, err := loadConfiguration(cmd)
-	if err == nil {
-		log.Debug("Configuration loaded from a file.", slog.String("cmd", "accounts list"))
-
-		basePath = cfg.Storage
+	store, err := newCommandStorage(cmd, "accounts list")
+	if err != nil {
+		return err
 	}
 
+	defer func() { _ = store.Close() }()
+
 	if cmd.Bool(flags.FlgFormatJSON) {
-		return listAccountsJSON(basePath)
+		return listAccountsJSON(store.Account)
 	}
 
-	return listAccountsText(basePath)
+	return listAccountsText(store.Account)
 }
 
-func listAccountsText(basePath string) error {
-	accounts, err := readAccounts(basePath)
+func listAccountsText(accountsStorage *storage.AccountsStorage) error {
+	accounts, err := readAccounts(accountsStorage)
 	if err != nil {
 		return err
 	}
@@ -72,8 +68,8 @@ func listAccountsText(basePath string) error {
 	return nil
 }
 
-func listAccountsJSON(basePath string) error {
-	accounts, err := readAccounts(basePath)
+func listAccountsJSON(accountsStorage *storage.AccountsStorage) error {
+	accounts, err := readAccounts(accountsStorage)
 	if err != nil {
 		return err
 	}
@@ -81,24 +77,17 @@ func listAccountsJSON(basePath string) error {
 	return json.NewEncoder(os.Stdout).Encode(accounts)
 }
 
-func readAccounts(basePath string) ([]ListAccount, error) {
-	accountsStorage := storage.NewAccountsStorage(basePath)
-
-	matches, err := zglob.Glob(filepath.Join(accountsStorage.GetRootPath(), "**", "account.json"))
+func readAccounts(accountsStorage *storage.AccountsStorage) ([]ListAccount, error) {
+	all, err := accountsStorage.ReadAll()
 	if err != nil {
 		return nil, err
 	}
 
 	var accounts []ListAccount
 
-	for _, filename := range matches {
-		account, err := storage.ReadJSONFile[storage.Account](filename)
-		if err != nil {
-			return nil, err
-		}
-
+	for _, filename := range slices.Sorted(maps.Keys(all)) {
 		accounts = append(accounts, ListAccount{
-			Account: account,
+			Account: all[filename],
 			Path:    filename,
 		})
 	}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/go-acme/lego/v5/cmd/internal/acmedns"
+	"github.com/go-acme/lego/v5/cmd/internal/flags"
+	"github.com/urfave/cli/v3"
+)
+
+func createAcmeDNSServer() *cli.Command {
+	return &cli.Command{
+		Name: "acmedns-server",
+		Usage: "Run a server compatible with the acme-dns API, with an embedded authoritative DNS server." +
+			" The accounts are stored in the storage (the storage of the configuration file if any, otherwise the storage directory).",
+		Action: acmeDNSServer,
+		Flags:  flags.CreateAcmeDNSServerFlags(),
+	}
+}
+
+func acmeDNSServer(ctx context.Context, cmd *cli.Command) error {
+	_, _, err := parseAddress(cmd, flags.FlgAcmeDNSDNSAddress)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = parseAddress(cmd, flags.FlgAcmeDNSAPIAddress)
+	if err != nil {
+		return err
+	}
+
+	store, err := newCommandStorage(cmd, "acmedns-server")
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = store.Close() }()
+
+	server, err := acmedns.New(store.AcmeDNS, acmedns.Options{
+		Zone:                cmd.String(flags.FlgAcmeDNSZone),
+		Nameserver:          cmd.String(flags.FlgAcmeDNSNameserver),
+		DNSAddress:          cmd.String(flags.FlgAcmeDNSDNSAddress),
+		APIAddress:          cmd.String(flags.FlgAcmeDNSAPIAddress),
+		NetworkStack:        getNetworkStack(cmd),
+		DisableRegistration: cmd.Bool(flags.FlgAcmeDNSDisableRegistration),
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return server.Run(ctx)
+}
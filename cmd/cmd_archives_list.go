@@ -23,17 +23,15 @@ func createArchivesList() *cli.Command {
 }
 
 func listArchives(_ context.Context, cmd *cli.Command) error {
-	basePath := cmd.String(flags.FlgPath)
+	archiver := storage.NewArchiver(cmd.String(flags.FlgPath))
 
 	cfg, err := loadConfiguration(cmd)
 	if err == nil {
 		log.Debug("Configuration loaded from a file.", slog.String("cmd", "archives list"))
 
-		basePath = cfg.Storage
+		archiver = storage.NewArchiverFromConfiguration(cfg.Storage)
 	}
 
-	archiver := storage.NewArchiver(basePath)
-
 	accountPaths, err := archiver.ListArchivedAccounts()
 	if err != nil {
 		return err
@@ -25,17 +25,15 @@ func createArchivesRestore() *cli.Command {
 }
 
 func restoreArchive(_ context.Context, cmd *cli.Command) error {
-	basePath := cmd.String(flags.FlgPath)
+	archiver := storage.NewArchiver(cmd.String(flags.FlgPath))
 
 	cfg, err := loadConfiguration(cmd)
 	if err == nil {
 		log.Debug("Configuration loaded from a file.", slog.String("cmd", "accounts list"))
 
-		basePath = cfg.Storage
+		archiver = storage.NewArchiverFromConfiguration(cfg.Storage)
 	}
 
-	archiver := storage.NewArchiver(basePath)
-
 	accountPaths, err := archiver.ListArchivedAccounts()
 	if err != nil {
 		return err
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-acme/lego/v5/certificate"
+	"github.com/go-acme/lego/v5/cmd/internal/flags"
+	"github.com/go-acme/lego/v5/lego"
+	"github.com/urfave/cli/v3"
+)
+
+func createAuthorizations() *cli.Command {
+	return &cli.Command{
+		Name:  "authorizations",
+		Usage: "Authorizations management.",
+		Commands: []*cli.Command{
+			createAuthorizationsList(),
+			createAuthorizationsDeactivate(),
+		},
+	}
+}
+
+// findAuthorizations gets the authorizations of the orders of the stored certificates, and the authorizations defined by their URLs.
+func findAuthorizations(ctx context.Context, cmd *cli.Command, client *lego.Client) ([]certificate.Authorization, error) {
+	var authorizations []certificate.Authorization
+
+	if certIDs := cmd.StringSlice(flags.FlgCertName); len(certIDs) > 0 {
+		store, err := newCommandStorage(cmd, "authorizations")
+		if err != nil {
+			return nil, err
+		}
+
+		defer func() { _ = store.Close() }()
+
+		for _, certID := range certIDs {
+			resource, err := store.Certificate.ReadResource(certID)
+			if err != nil {
+				return nil, err
+			}
+
+			if resource.OrderURL == "" {
+				return nil, fmt.Errorf("certificate %s: the order URL is not recorded in the certificate resource", certID)
+			}
+
+			authzs, err := client.Certificate.ListAuthorizations(ctx, resource.OrderURL)
+			if err != nil {
+				return nil, fmt.Errorf("certificate %s: list authorizations: %w", certID, err)
+			}
+
+			authorizations = append(authorizations, authzs...)
+		}
+	}
+
+	for _, authzURL := range cmd.StringSlice(flags.FlgAuthzURL) {
+		authz, err := client.Certificate.GetAuthorization(ctx, authzURL)
+		if err != nil {
+			return nil, fmt.Errorf("get authorization %s: %w", authzURL, err)
+		}
+
+		authorizations = append(authorizations, authz)
+	}
+
+	return authorizations, nil
+}
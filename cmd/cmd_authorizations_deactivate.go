@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/go-acme/lego/v5/acme"
+	"github.com/go-acme/lego/v5/challenge"
+	"github.com/go-acme/lego/v5/cmd/internal/flags"
+	"github.com/go-acme/lego/v5/log"
+	"github.com/urfave/cli/v3"
+)
+
+func createAuthorizationsDeactivate() *cli.Command {
+	return &cli.Command{
+		Name: "deactivate",
+		Usage: "Deactivate the authorizations of the account." +
+			" A deactivated authorization cannot be reused by the server: the next orders require to solve the challenges again.",
+		Before: flags.AuthorizationsFlagsValidation,
+		Action: deactivateAuthorizations,
+		Flags:  flags.CreateAuthorizationsDeactivateFlags(),
+	}
+}
+
+func deactivateAuthorizations(ctx context.Context, cmd *cli.Command) error {
+	client, closeClient, err := newRegisteredClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	defer closeClient()
+
+	authorizations, err := findAuthorizations(ctx, cmd, client)
+	if err != nil {
+		return err
+	}
+
+	for _, authz := range authorizations {
+		domain := challenge.GetTargetedDomain(authz.Authorization)
+
+		// Only the pending and valid authorizations can be deactivated.
+		if authz.Status != acme.StatusPending && authz.Status != acme.StatusValid {
+			log.Info("Skipping the authorization.",
+				log.DomainAttr(domain),
+				slog.String("status", authz.Status),
+				slog.String("url", authz.URL),
+			)
+
+			continue
+		}
+
+		err = client.Certificate.DeactivateAuthorization(ctx, authz.URL)
+		if err != nil {
+			return fmt.Errorf("%s: deactivate authorization %s: %w", domain, authz.URL, err)
+		}
+
+		log.Info("The authorization has been deactivated.",
+			log.DomainAttr(domain),
+			slog.String("url", authz.URL),
+		)
+	}
+
+	return nil
+}
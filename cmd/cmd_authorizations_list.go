@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/go-acme/lego/v5/certificate"
+	"github.com/go-acme/lego/v5/challenge"
+	"github.com/go-acme/lego/v5/cmd/internal/flags"
+	"github.com/urfave/cli/v3"
+)
+
+func createAuthorizationsList() *cli.Command {
+	return &cli.Command{
+		Name: "list",
+		Usage: "Display information about the authorizations of the account." +
+			" The valid authorizations are reused by the server." +
+			" The authorizations are found from the orders of the stored certificates, or from their URLs: the listing doesn't create an order.",
+		Before: flags.AuthorizationsFlagsValidation,
+		Action: listAuthorizations,
+		Flags:  flags.CreateAuthorizationsListFlags(),
+	}
+}
+
+func listAuthorizations(ctx context.Context, cmd *cli.Command) error {
+	client, closeClient, err := newRegisteredClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	defer closeClient()
+
+	authorizations, err := findAuthorizations(ctx, cmd, client)
+	if err != nil {
+		return err
+	}
+
+	if cmd.Bool(flags.FlgFormatJSON) {
+		return json.NewEncoder(os.Stdout).Encode(authorizations)
+	}
+
+	listAuthorizationsText(authorizations)
+
+	return nil
+}
+
+func listAuthorizationsText(authorizations []certificate.Authorization) {
+	if len(authorizations) == 0 {
+		fmt.Println("No authorizations were found.")
+		return
+	}
+
+	fmt.Println("Found the following authorizations:")
+
+	for _, authz := range authorizations {
+		fmt.Println(challenge.GetTargetedDomain(authz.Authorization))
+		fmt.Println("├── Status:", authz.Status)
+
+		if !authz.Expires.IsZero() {
+			fmt.Println("├── Expires:", authz.Expires.Format(time.RFC3339))
+		}
+
+		fmt.Println("└── URL:", authz.URL)
+		fmt.Println()
+	}
+}
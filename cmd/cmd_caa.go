@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-acme/lego/v5/challenge/dns01"
+	"github.com/go-acme/lego/v5/cmd/internal/flags"
+	"github.com/go-acme/lego/v5/cmd/internal/prompt"
+	"github.com/go-acme/lego/v5/log"
+	"github.com/go-acme/lego/v5/providers/dns"
+	"github.com/urfave/cli/v3"
+)
+
+func createCAA() *cli.Command {
+	return &cli.Command{
+		Name: "caa",
+		Usage: "Compute the CAA records allowing the issuance of a certificate for the domains," +
+			" restricted to the account (accounturi) and to the challenges (validationmethods)." +
+			" The changes are displayed, and can be applied with a DNS provider.",
+		Before: flags.CAAFlagsValidation,
+		Action: caa,
+		Flags:  flags.CreateCAAFlags(),
+	}
+}
+
+func caa(ctx context.Context, cmd *cli.Command) error {
+	// The challenges are used to compute the validation methods.
+	client, closeClient, err := newChallengeClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	defer closeClient()
+
+	changes, err := client.Certificate.CAAChanges(ctx, cmd.StringSlice(flags.FlgDomains))
+	if err != nil {
+		return fmt.Errorf("CAA: %w", err)
+	}
+
+	var pending int
+
+	for _, change := range changes {
+		if !change.HasChanges() {
+			log.Info("The CAA records are up to date.", log.DomainAttr(change.FQDN))
+
+			continue
+		}
+
+		pending++
+
+		fmt.Println(change.Diff())
+	}
+
+	if pending == 0 || !cmd.IsSet(flags.FlgCAAProvider) {
+		return nil
+	}
+
+	provider, err := newCAARecordProvider(cmd.String(flags.FlgCAAProvider))
+	if err != nil {
+		return err
+	}
+
+	if !cmd.Bool(flags.FlgCAAApply) && !prompt.Confirm("Do you want to apply the changes?") {
+		log.Info("Aborting.")
+		return nil
+	}
+
+	for _, change := range changes {
+		if !change.HasChanges() {
+			continue
+		}
+
+		err = change.Apply(ctx, provider)
+		if err != nil {
+			return fmt.Errorf("%s: apply CAA records: %w", change.FQDN, err)
+		}
+
+		log.Info("The CAA records have been applied.", log.DomainAttr(change.FQDN))
+	}
+
+	return nil
+}
+
+func newCAARecordProvider(name string) (dns01.RecordProvider, error) {
+	provider, err := dns.NewDNSChallengeProviderByName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	recordProvider, ok := provider.(dns01.RecordProvider)
+	if !ok {
+		return nil, fmt.Errorf("the DNS provider '%s' doesn't support the management of the CAA records", name)
+	}
+
+	return recordProvider, nil
+}
@@ -4,9 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log/slog"
 	"os"
-	"path/filepath"
 	"strings"
 	"time"
 
@@ -14,8 +12,6 @@ import (
 	"github.com/go-acme/lego/v5/cmd/internal/flags"
 	"github.com/go-acme/lego/v5/cmd/internal/storage"
 	"github.com/go-acme/lego/v5/internal"
-	"github.com/go-acme/lego/v5/log"
-	"github.com/mattn/go-zglob"
 	"github.com/urfave/cli/v3"
 )
 
@@ -39,24 +35,22 @@ func createListCertificates() *cli.Command {
 }
 
 func listCertificates(_ context.Context, cmd *cli.Command) error {
-	basePath := cmd.String(flags.FlgPath)
-
-	cfg, err := loadConfiguration(cmd)
-	if err == nil {
-		log.Debug("Configuration loaded from a file.", slog.String("cmd", "certificates list"))
-
-		basePath = cfg.Storage
+	store, err := newCommandStorage(cmd, "certificates list")
+	if err != nil {
+		return err
 	}
 
+	defer func() { _ = store.Close() }()
+
 	if cmd.Bool(flags.FlgFormatJSON) {
-		return listCertificatesJSON(basePath)
+		return listCertificatesJSON(store.Certificate)
 	}
 
-	return listCertificatesText(basePath)
+	return listCertificatesText(store.Certificate)
 }
 
-func listCertificatesText(basePath string) error {
-	certs, err := readCertificates(basePath)
+func listCertificatesText(certsStorage *storage.CertificatesStorage) error {
+	certs, err := readCertificates(certsStorage)
 	if err != nil {
 		return err
 	}
@@ -93,8 +87,8 @@ func listCertificatesText(basePath string) error {
 	return nil
 }
 
-func listCertificatesJSON(basePath string) error {
-	certs, err := readCertificates(basePath)
+func listCertificatesJSON(certsStorage *storage.CertificatesStorage) error {
+	certs, err := readCertificates(certsStorage)
 	if err != nil {
 		return err
 	}
@@ -102,20 +96,16 @@ func listCertificatesJSON(basePath string) error {
 	return json.NewEncoder(os.Stdout).Encode(certs)
 }
 
-func readCertificates(basePath string) ([]ListCertificate, error) {
-	certsStorage := storage.NewCertificatesStorage(basePath)
-
-	matches, err := zglob.Glob(filepath.Join(certsStorage.GetRootPath(), "**", "*.json"))
+func readCertificates(certsStorage *storage.CertificatesStorage) ([]ListCertificate, error) {
+	names, err := certsStorage.List()
 	if err != nil {
 		return nil, err
 	}
 
 	var certificates []ListCertificate
 
-	for _, filename := range matches {
-		certFilename := strings.TrimSuffix(filename, storage.ExtResource) + storage.ExtCert
-
-		data, err := os.ReadFile(certFilename)
+	for _, name := range names {
+		data, err := certsStorage.ReadFile(name, storage.ExtCert)
 		if err != nil {
 			return nil, err
 		}
@@ -125,8 +115,6 @@ func readCertificates(basePath string) ([]ListCertificate, error) {
 			return nil, err
 		}
 
-		name := strings.TrimSuffix(filepath.Base(certFilename), storage.ExtCert)
-
 		certificates = append(certificates, ListCertificate{
 			Name:           name,
 			Domains:        pCert.DNSNames,
@@ -134,7 +122,7 @@ func readCertificates(basePath string) ([]ListCertificate, error) {
 			ExpirationDate: pCert.NotAfter.String(),
 			Expired:        pCert.NotAfter.Before(time.Now()),
 			Issuer:         pCert.Issuer.String(),
-			Path:           certFilename,
+			Path:           certsStorage.GetFileName(name, storage.ExtCert),
 		})
 	}
 
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/go-acme/lego/v5/cmd/internal/configuration"
+	"github.com/go-acme/lego/v5/cmd/internal/flags"
+	"github.com/go-acme/lego/v5/cmd/internal/root"
+	"github.com/urfave/cli/v3"
+)
+
+func createDaemon() *cli.Command {
+	return &cli.Command{
+		Name: "daemon",
+		Usage: "Obtain and renew the certificates of the configuration file in a long-running process." +
+			" The configuration is reloaded on SIGHUP.",
+		Action: daemon,
+		Flags:  flags.CreateDaemonFlags(),
+	}
+}
+
+func daemon(ctx context.Context, cmd *cli.Command) error {
+	stopMetrics, err := setUpMetrics(cmd)
+	if err != nil {
+		return err
+	}
+
+	defer stopMetrics()
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+
+	defer signal.Stop(reload)
+
+	return root.Daemon(ctx, func() (*configuration.Configuration, error) {
+		return loadConfiguration(cmd)
+	}, reload)
+}
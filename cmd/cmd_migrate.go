@@ -78,7 +78,7 @@ func createConfigurationFile(root string, cfg *configuration.Configuration) erro
 	wd, err := os.Getwd()
 	if err == nil {
 		if filepath.Join(wd, ".lego") != root {
-			cfg.Storage = "FIXME: " + root
+			cfg.Storage = &configuration.Storage{Path: "FIXME: " + root}
 		}
 	}
 
@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/go-acme/lego/v5/certificate/planner"
+	"github.com/go-acme/lego/v5/cmd/internal/configuration"
+	"github.com/go-acme/lego/v5/cmd/internal/flags"
+	"github.com/go-acme/lego/v5/log"
+	"github.com/urfave/cli/v3"
+	"gopkg.in/yaml.v3"
+)
+
+func createPlan() *cli.Command {
+	return &cli.Command{
+		Name: "plan",
+		Usage: "Pack the domains of an inventory into the fewest certificates, and display the certificates section of the configuration file." +
+			" The certificates of the configuration file are used as the previous plan, to keep the assignments stable.",
+		Action: plan,
+		Flags:  flags.CreatePlanFlags(),
+	}
+}
+
+func plan(_ context.Context, cmd *cli.Command) error {
+	cfg, effective, err := readPlanConfiguration(cmd)
+	if err != nil {
+		return err
+	}
+
+	var current map[string]*configuration.Certificate
+	if cfg != nil {
+		current = cfg.Certificates
+	}
+
+	file, err := os.Open(cmd.String(flags.FlgPlanInventory))
+	if err != nil {
+		return fmt.Errorf("could not open the inventory: %w", err)
+	}
+
+	defer func() { _ = file.Close() }()
+
+	domains, err := planner.ReadInventory(file, getPlanDefaultChallenge(cmd, cfg))
+	if err != nil {
+		return fmt.Errorf("could not read the inventory: %w", err)
+	}
+
+	var previous []planner.Certificate
+
+	for id, cert := range current {
+		// The certificates based on a CSR are not managed by the planner.
+		if len(cert.Domains) == 0 {
+			continue
+		}
+
+		// The effective challenge of the certificate (the challenge can be implicit).
+		previous = append(previous, planner.Certificate{ID: id, Challenge: effective.Certificates[id].Challenge, Domains: cert.Domains})
+	}
+
+	p := planner.New(planner.Options{
+		MaxSANs:                  cmd.Int(flags.FlgPlanMaxSANs),
+		GroupByRegistrableDomain: cmd.Bool(flags.FlgPlanGroupByRegistrableDomain),
+		WildcardThreshold:        cmd.Int(flags.FlgPlanWildcardThreshold),
+		SupportsWildcard:         supportsWildcard(effective),
+	})
+
+	certificates, err := p.Plan(domains, previous)
+	if err != nil {
+		return fmt.Errorf("plan: %w", err)
+	}
+
+	result := make(map[string]*configuration.Certificate)
+
+	for id, cert := range current {
+		if len(cert.Domains) == 0 {
+			result[id] = cert
+		}
+	}
+
+	for _, cert := range certificates {
+		// The other options of the existing certificates are kept.
+		if existing, ok := current[cert.ID]; ok {
+			c := *existing
+			c.Domains = cert.Domains
+
+			result[cert.ID] = &c
+
+			continue
+		}
+
+		result[cert.ID] = &configuration.Certificate{
+			Domains:   cert.Domains,
+			Challenge: cert.Challenge,
+			Account:   cmd.String(flags.FlgPlanAccount),
+		}
+	}
+
+	for id := range current {
+		if _, ok := result[id]; !ok {
+			log.Warn("The certificate is removed from the plan: all its domains are removed or moved.", log.CertNameAttr(id))
+		}
+	}
+
+	encoder := yaml.NewEncoder(os.Stdout)
+	encoder.SetIndent(2)
+
+	err = encoder.Encode(&configuration.Configuration{Certificates: result})
+	if err != nil {
+		return fmt.Errorf("could not encode the certificates: %w", err)
+	}
+
+	return encoder.Close()
+}
+
+// readPlanConfiguration reads the configuration file, if any.
+// The first configuration is read as is (the defaults are not applied): its certificates are displayed.
+// The second configuration is the effective configuration (the defaults are applied): it provides the challenges.
+func readPlanConfiguration(cmd *cli.Command) (*configuration.Configuration, *configuration.Configuration, error) {
+	filename, err := getConfigurationPath(cmd)
+	if err != nil {
+		nfErr := &configuration.FileNotFoundError{}
+		if errors.As(err, &nfErr) {
+			return nil, nil, nil
+		}
+
+		return nil, nil, err
+	}
+
+	cfg, err := configuration.ReadConfiguration(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	effective, err := configuration.ReadConfiguration(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	configuration.ApplyDefaults(effective)
+
+	return cfg, effective, nil
+}
+
+// getPlanDefaultChallenge returns the challenge of the domains of the inventory without challenge:
+// the challenge defined by the flag, or the only challenge of the configuration file (as for the certificates).
+func getPlanDefaultChallenge(cmd *cli.Command, cfg *configuration.Configuration) string {
+	if cmd.IsSet(flags.FlgPlanChallenge) || cfg == nil || len(cfg.Challenges) != 1 {
+		return cmd.String(flags.FlgPlanChallenge)
+	}
+
+	for id := range cfg.Challenges {
+		return id
+	}
+
+	return ""
+}
+
+// supportsWildcard returns a function reporting whether a challenge of the configuration is DNS-based.
+// Without configuration, the type of the challenges is unknown: no challenges support the wildcards.
+func supportsWildcard(cfg *configuration.Configuration) func(challenge string) bool {
+	return func(challenge string) bool {
+		if cfg == nil {
+			return false
+		}
+
+		chlg, ok := cfg.Challenges[challenge]
+
+		return ok && (chlg.DNS != nil || chlg.DNSPersist != nil || chlg.DNSAccount != nil)
+	}
+}
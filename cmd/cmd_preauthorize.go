@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/go-acme/lego/v5/cmd/internal/flags"
+	"github.com/urfave/cli/v3"
+)
+
+func createPreAuthorize() *cli.Command {
+	return &cli.Command{
+		Name: "preauthorize",
+		Usage: "Validate the domains ahead of the issuance (pre-authorization)." +
+			" The valid authorizations are reused by the server for the next certificates of the account, until they expire.",
+		Before: flags.PreAuthorizeFlagsValidation,
+		Action: preAuthorize,
+		Flags:  flags.CreatePreAuthorizeFlags(),
+	}
+}
+
+func preAuthorize(ctx context.Context, cmd *cli.Command) error {
+	client, closeClient, err := newChallengeClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	defer closeClient()
+
+	authorizations, err := client.Certificate.PreAuthorize(ctx, cmd.StringSlice(flags.FlgDomains))
+	if err != nil {
+		return fmt.Errorf("pre-authorize: %w", err)
+	}
+
+	if cmd.Bool(flags.FlgFormatJSON) {
+		return json.NewEncoder(os.Stdout).Encode(authorizations)
+	}
+
+	listAuthorizationsText(authorizations)
+
+	return nil
+}
@@ -4,9 +4,9 @@ import (
 	"context"
 	"errors"
 
+	"github.com/go-acme/lego/v5/cmd/internal"
 	"github.com/go-acme/lego/v5/cmd/internal/flags"
 	"github.com/go-acme/lego/v5/cmd/internal/root"
-	"github.com/go-acme/lego/v5/cmd/internal/storage"
 	"github.com/urfave/cli/v3"
 )
 
@@ -19,12 +19,20 @@ func CreateRootCommand() *cli.Command {
 		Before: func(ctx context.Context, cmd *cli.Command) (context.Context, error) {
 			setUpLogger(cmd, nil)
 
+			err := setUpEvents(cmd, nil)
+			if err != nil {
+				return ctx, err
+			}
+
 			if cmd.NArg() > 0 && cmd.Command(cmd.Args().First()) == nil {
 				return ctx, errors.New("unknown command")
 			}
 
 			return ctx, nil
 		},
+		After: func(_ context.Context, _ *cli.Command) error {
+			return internal.CloseEventSink()
+		},
 		Action:   rootRun,
 		Flags:    flags.CreateRootFlags(),
 		Commands: createCommands(),
@@ -35,11 +43,17 @@ func CreateRootCommand() *cli.Command {
 func createCommands() []*cli.Command {
 	return []*cli.Command{
 		createRun(),
+		createDaemon(),
 		createCertificates(),
 		createAccounts(),
+		createAuthorizations(),
+		createPreAuthorize(),
 		createArchives(),
 		createDNSHelp(),
+		createCAA(),
+		createPlan(),
 		createMigrate(),
+		createAcmeDNSServer(),
 	}
 }
 
@@ -54,7 +68,5 @@ func rootRun(ctx context.Context, cmd *cli.Command) error {
 		return err
 	}
 
-	store := storage.NewConfigurationStorage(cfg.Storage)
-
-	return store.Backup(cfg)
+	return root.BackupConfiguration(cfg)
 }
@@ -32,6 +32,13 @@ func run(ctx context.Context, cmd *cli.Command) error {
 		return err
 	}
 
+	stopMetrics, err := setUpMetrics(cmd)
+	if err != nil {
+		return err
+	}
+
+	defer stopMetrics()
+
 	store := storage.New(cmd.String(flags.FlgPath))
 
 	account, err := store.Account.Get(cmd.String(flags.FlgServer), keyType, cmd.String(flags.FlgEmail), cmd.String(flags.FlgAccountID))
@@ -65,6 +72,25 @@ func run(ctx context.Context, cmd *cli.Command) error {
 		return err
 	}
 
+	// The certificate is locked to prevent several processes (sharing the same storage) from obtaining/renewing it.
+	unlock, err := store.Certificate.Lock(certID)
+	if errors.Is(err, storage.ErrLocked) {
+		log.Warn("The certificate is locked by another process, skipping.", log.CertNameAttr(certID))
+
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("lock the certificate %q: %w", certID, err)
+	}
+
+	defer func() {
+		errU := unlock()
+		if errU != nil {
+			log.Warn("Could not release the certificate lock.", log.CertNameAttr(certID), log.ErrorAttr(errU))
+		}
+	}()
+
 	resource, err := store.Certificate.ReadResource(certID)
 	if err != nil {
 		pe := new(fs.PathError)
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/go-acme/lego/v5/cmd/internal"
 	"github.com/go-acme/lego/v5/cmd/internal/flags"
 	"github.com/go-acme/lego/v5/cmd/internal/hook"
 	"github.com/go-acme/lego/v5/cmd/internal/storage"
@@ -56,20 +57,21 @@ func obtainForDomains(ctx context.Context, cmd *cli.Command, client *lego.Client
 		certRes.ID = certID
 	}
 
+	internal.RecordCertificateExpiry(certRes)
+
 	options := newSaveOptions(cmd)
 
-	err = certsStorage.Save(
-		&storage.Certificate{
-			Resource: certRes,
-			Origin:   storage.OriginCommand,
-		},
-		options,
-	)
+	storedCert := &storage.Certificate{
+		Resource: certRes,
+		Origin:   storage.OriginCommand,
+	}
+
+	err = certsStorage.Save(storedCert, options)
 	if err != nil {
 		return fmt.Errorf("could not save the resource: %w", err)
 	}
 
-	return hookManager.Deploy(ctx, certRes, options)
+	return hookManager.Deploy(ctx, storedCert, options)
 }
 
 func obtainForCSR(ctx context.Context, cmd *cli.Command, client *lego.Client, certID string, certsStorage *storage.CertificatesStorage, hookManager *hook.Manager) error {
@@ -105,18 +107,19 @@ func obtainForCSR(ctx context.Context, cmd *cli.Command, client *lego.Client, ce
 		certRes.ID = certID
 	}
 
+	internal.RecordCertificateExpiry(certRes)
+
 	options := newSaveOptions(cmd)
 
-	err = certsStorage.Save(
-		&storage.Certificate{
-			Resource: certRes,
-			Origin:   storage.OriginCommand,
-		},
-		options,
-	)
+	storedCert := &storage.Certificate{
+		Resource: certRes,
+		Origin:   storage.OriginCommand,
+	}
+
+	err = certsStorage.Save(storedCert, options)
 	if err != nil {
 		return fmt.Errorf("could not save the resource: %w", err)
 	}
 
-	return hookManager.Deploy(ctx, certRes, options)
+	return hookManager.Deploy(ctx, storedCert, options)
 }
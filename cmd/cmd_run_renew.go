@@ -16,11 +16,13 @@ import (
 
 	"github.com/go-acme/lego/v5/acme/api"
 	"github.com/go-acme/lego/v5/certcrypto"
+	"github.com/go-acme/lego/v5/cmd/internal"
 	"github.com/go-acme/lego/v5/cmd/internal/flags"
 	"github.com/go-acme/lego/v5/cmd/internal/hook"
 	"github.com/go-acme/lego/v5/cmd/internal/storage"
 	"github.com/go-acme/lego/v5/lego"
 	"github.com/go-acme/lego/v5/log"
+	"github.com/go-acme/lego/v5/metrics"
 	"github.com/mattn/go-isatty"
 	"github.com/urfave/cli/v3"
 )
@@ -59,6 +61,8 @@ func (p *renewProcessor) renewForDomains(ctx context.Context, certID string, dom
 
 	cert := certificates[0]
 
+	metrics.Default().SetCertificateExpiry(certID, cert.NotAfter)
+
 	if cert.IsCA {
 		return fmt.Errorf("certificate bundle for %q starts with a CA certificate", certID)
 	}
@@ -99,6 +103,8 @@ func (p *renewProcessor) renewForDomains(ctx context.Context, certID string, dom
 		return err
 	}
 
+	request.Renewal = true
+
 	if p.cmd.Bool(flags.FlgReuseKey) {
 		request.PrivateKey, err = p.certsStorage.ReadPrivateKey(certID)
 		if err != nil {
@@ -129,25 +135,30 @@ func (p *renewProcessor) renewForDomains(ctx context.Context, certID string, dom
 
 	certRes, err := client.Certificate.Obtain(ctx, request)
 	if err != nil {
+		metrics.Default().IncRenewal(certID, err)
+
 		return fmt.Errorf("could not obtain the certificate for %q: %w", certID, err)
 	}
 
+	metrics.Default().IncRenewal(certID, nil)
+
 	certRes.ID = certID
 
+	internal.RecordCertificateExpiry(certRes)
+
 	options := newSaveOptions(p.cmd)
 
-	err = p.certsStorage.Save(
-		&storage.Certificate{
-			Resource: certRes,
-			Origin:   storage.OriginCommand,
-		},
-		options,
-	)
+	storedCert := &storage.Certificate{
+		Resource: certRes,
+		Origin:   storage.OriginCommand,
+	}
+
+	err = p.certsStorage.Save(storedCert, options)
 	if err != nil {
 		return fmt.Errorf("could not save the resource: %w", err)
 	}
 
-	return p.hookManager.Deploy(ctx, certRes, options)
+	return p.hookManager.Deploy(ctx, storedCert, options)
 }
 
 func (p *renewProcessor) renewForCSR(ctx context.Context, certID string, changed bool) error {
@@ -164,6 +175,8 @@ func (p *renewProcessor) renewForCSR(ctx context.Context, certID string, changed
 
 	cert := certificates[0]
 
+	metrics.Default().SetCertificateExpiry(certID, cert.NotAfter)
+
 	if cert.IsCA {
 		return fmt.Errorf("CSR: certificate bundle for %q starts with a CA certificate", certID)
 	}
@@ -193,6 +206,7 @@ func (p *renewProcessor) renewForCSR(ctx context.Context, certID string, changed
 	)
 
 	request := newObtainForCSRRequest(p.cmd, csr)
+	request.Renewal = true
 
 	if replacesCertID != "" {
 		request.ReplacesCertID = replacesCertID
@@ -212,25 +226,30 @@ func (p *renewProcessor) renewForCSR(ctx context.Context, certID string, changed
 
 	certRes, err := client.Certificate.ObtainForCSR(ctx, request)
 	if err != nil {
+		metrics.Default().IncRenewal(certID, err)
+
 		return fmt.Errorf("CSR: could not obtain the certificate: %w", err)
 	}
 
+	metrics.Default().IncRenewal(certID, nil)
+
 	certRes.ID = certID
 
+	internal.RecordCertificateExpiry(certRes)
+
 	options := newSaveOptions(p.cmd)
 
-	err = p.certsStorage.Save(
-		&storage.Certificate{
-			Resource: certRes,
-			Origin:   storage.OriginCommand,
-		},
-		options,
-	)
+	storedCert := &storage.Certificate{
+		Resource: certRes,
+		Origin:   storage.OriginCommand,
+	}
+
+	err = p.certsStorage.Save(storedCert, options)
 	if err != nil {
 		return fmt.Errorf("CSR: could not save the resource: %w", err)
 	}
 
-	return p.hookManager.Deploy(ctx, certRes, options)
+	return p.hookManager.Deploy(ctx, storedCert, options)
 }
 
 func (p *renewProcessor) getARIInfo(ctx context.Context, certID string, cert *x509.Certificate) (*time.Time, string, error) {
@@ -336,6 +355,8 @@ func getARIRenewalTime(ctx context.Context, willingToSleep time.Duration, cert *
 		return nil
 	}
 
+	metrics.Default().SetRenewalWindow(certID, renewalInfo.SuggestedWindow.Start, renewalInfo.SuggestedWindow.End)
+
 	now := time.Now().UTC()
 
 	renewalTime := renewalInfo.ShouldRenewAt(now, willingToSleep)
@@ -0,0 +1,349 @@
+// Package acmedns implements a server compatible with the acme-dns HTTP API (https://github.com/joohoi/acme-dns),
+// with an embedded authoritative DNS server.
+package acmedns
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-acme/lego/v5/challenge"
+	"github.com/go-acme/lego/v5/challenge/dns01"
+	"github.com/go-acme/lego/v5/cmd/internal/storage"
+	"github.com/go-acme/lego/v5/internal/dnsserver"
+	"github.com/go-acme/lego/v5/log"
+	"github.com/google/uuid"
+)
+
+// txtValueLength is the length of a TXT record value of a DNS-01 challenge (base64 of a SHA-256 digest).
+const txtValueLength = 43
+
+// maxTXTRecords is the number of TXT records per subdomain,
+// to be able to validate the domain and the wildcard domain at the same time.
+const maxTXTRecords = 2
+
+// Store persists the accounts.
+type Store interface {
+	Save(account *storage.AcmeDNSAccount) error
+	ReadAll() ([]*storage.AcmeDNSAccount, error)
+}
+
+// Options configures a Server.
+type Options struct {
+	// Zone is the zone delegated to the server (ex: `auth.example.com`).
+	// The subdomains of the accounts are allocated in this zone.
+	Zone string
+
+	// Nameserver is the name of the DNS server, used by the SOA and NS records of the zone.
+	Nameserver string
+
+	// DNSAddress is the address of the DNS server (UDP and TCP).
+	DNSAddress string
+
+	// APIAddress is the address of the HTTP API.
+	APIAddress string
+
+	NetworkStack challenge.NetworkStack
+
+	// DisableRegistration disables the registration of new accounts.
+	DisableRegistration bool
+}
+
+// Server is an acme-dns server.
+type Server struct {
+	zone                string
+	apiAddress          string
+	networkStack        challenge.NetworkStack
+	disableRegistration bool
+
+	store Store
+	dns   *dnsserver.Server
+
+	mu       sync.Mutex
+	accounts map[string]*storage.AcmeDNSAccount
+}
+
+// New creates a new Server, and loads the accounts of the store.
+func New(store Store, opts Options) (*Server, error) {
+	if opts.Zone == "" {
+		return nil, errors.New("acmedns: the zone is required")
+	}
+
+	s := &Server{
+		zone:                strings.ToLower(dns01.UnFqdn(opts.Zone)),
+		apiAddress:          opts.APIAddress,
+		networkStack:        opts.NetworkStack,
+		disableRegistration: opts.DisableRegistration,
+		store:               store,
+		dns: dnsserver.New(dnsserver.Options{
+			Address:      opts.DNSAddress,
+			NetworkStack: opts.NetworkStack,
+			Zone:         opts.Zone,
+			Nameserver:   opts.Nameserver,
+		}),
+		accounts: make(map[string]*storage.AcmeDNSAccount),
+	}
+
+	accounts, err := store.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("acmedns: read accounts: %w", err)
+	}
+
+	for _, account := range accounts {
+		s.accounts[account.Username] = account
+
+		s.dns.SetTXT(s.fullDomain(account.Subdomain), account.TXT...)
+	}
+
+	return s, nil
+}
+
+// Run serves the DNS server and the HTTP API until the context is canceled.
+func (s *Server) Run(ctx context.Context) error {
+	err := s.dns.Start(ctx)
+	if err != nil {
+		return fmt.Errorf("acmedns: %w", err)
+	}
+
+	defer func() { _ = s.dns.Shutdown(context.Background()) }()
+
+	var lc net.ListenConfig
+
+	listener, err := lc.Listen(ctx, s.networkStack.Network("tcp"), s.apiAddress)
+	if err != nil {
+		return fmt.Errorf("acmedns: could not start the HTTP API: %w", err)
+	}
+
+	server := &http.Server{
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		WriteTimeout:      30 * time.Second,
+		IdleTimeout:       60 * time.Second,
+	}
+
+	log.Info("acme-dns server started.",
+		slog.String("zone", s.zone),
+		slog.String("dns", s.dns.Addr().String()),
+		slog.String("api", listener.Addr().String()),
+	)
+
+	errCh := make(chan error, 1)
+
+	go func() {
+		errCh <- server.Serve(listener)
+	}()
+
+	select {
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		return server.Shutdown(shutdownCtx)
+
+	case err = <-errCh:
+		return fmt.Errorf("acmedns: HTTP API: %w", err)
+	}
+}
+
+// Handler returns the handler of the HTTP API.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	if !s.disableRegistration {
+		mux.HandleFunc("POST /register", s.register)
+	}
+
+	mux.HandleFunc("POST /update", s.update)
+	mux.HandleFunc("GET /health", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	return mux
+}
+
+// DNSAddr returns the address of the DNS server.
+func (s *Server) DNSAddr() net.Addr {
+	return s.dns.Addr()
+}
+
+func (s *Server) register(w http.ResponseWriter, req *http.Request) {
+	var reg registerRequest
+
+	if req.ContentLength != 0 {
+		err := json.NewDecoder(req.Body).Decode(&reg)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "malformed_json_payload")
+			return
+		}
+	}
+
+	for _, cidr := range reg.AllowFrom {
+		_, _, err := net.ParseCIDR(cidr)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_allowfrom_cidr")
+			return
+		}
+	}
+
+	if reg.AllowFrom == nil {
+		reg.AllowFrom = []string{}
+	}
+
+	password := rand.Text()
+
+	account := &storage.AcmeDNSAccount{
+		Username:     uuid.NewString(),
+		PasswordHash: hashPassword(password),
+		Subdomain:    uuid.NewString(),
+		AllowFrom:    reg.AllowFrom,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := s.store.Save(account)
+	if err != nil {
+		log.Error("acme-dns: could not save the account.", log.ErrorAttr(err))
+
+		writeError(w, http.StatusInternalServerError, "db_error")
+
+		return
+	}
+
+	s.accounts[account.Username] = account
+
+	log.Info("acme-dns: new account.", slog.String("subdomain", account.Subdomain))
+
+	writeJSON(w, http.StatusCreated, registerResponse{
+		Username:   account.Username,
+		Password:   password,
+		FullDomain: s.fullDomain(account.Subdomain),
+		Subdomain:  account.Subdomain,
+		AllowFrom:  account.AllowFrom,
+	})
+}
+
+func (s *Server) update(w http.ResponseWriter, req *http.Request) {
+	var upd updateRequest
+
+	err := json.NewDecoder(req.Body).Decode(&upd)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "malformed_json_payload")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	account, ok := s.accounts[req.Header.Get("X-Api-User")]
+	if !ok || !checkPassword(account.PasswordHash, req.Header.Get("X-Api-Key")) {
+		writeError(w, http.StatusUnauthorized, "forbidden")
+		return
+	}
+
+	if !isAllowed(account.AllowFrom, req.RemoteAddr) {
+		writeError(w, http.StatusUnauthorized, "forbidden")
+		return
+	}
+
+	if upd.Subdomain != account.Subdomain {
+		writeError(w, http.StatusUnauthorized, "forbidden")
+		return
+	}
+
+	if len(upd.TXT) != txtValueLength {
+		writeError(w, http.StatusBadRequest, "bad_txt")
+		return
+	}
+
+	values := append(slices.Clone(account.TXT), upd.TXT)
+	if len(values) > maxTXTRecords {
+		values = values[len(values)-maxTXTRecords:]
+	}
+
+	updated := *account
+	updated.TXT = values
+
+	err = s.store.Save(&updated)
+	if err != nil {
+		log.Error("acme-dns: could not save the account.", log.ErrorAttr(err))
+
+		writeError(w, http.StatusInternalServerError, "db_error")
+
+		return
+	}
+
+	s.accounts[account.Username] = &updated
+
+	s.dns.SetTXT(s.fullDomain(account.Subdomain), values...)
+
+	writeJSON(w, http.StatusOK, updateResponse{TXT: upd.TXT})
+}
+
+func (s *Server) fullDomain(subdomain string) string {
+	return subdomain + "." + s.zone
+}
+
+func hashPassword(password string) string {
+	sum := sha256.Sum256([]byte(password))
+
+	return hex.EncodeToString(sum[:])
+}
+
+func checkPassword(hash, password string) bool {
+	return subtle.ConstantTimeCompare([]byte(hash), []byte(hashPassword(password))) == 1
+}
+
+// isAllowed checks if the remote address matches one of the CIDRs.
+// An empty list allows all the addresses.
+func isAllowed(allowFrom []string, remoteAddr string) bool {
+	if len(allowFrom) == 0 {
+		return true
+	}
+
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+
+	for _, cidr := range allowFrom {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err == nil && ipNet.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	err := json.NewEncoder(w).Encode(data)
+	if err != nil {
+		log.Debug("acme-dns: could not write the response.", log.ErrorAttr(err))
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, errorResponse{Error: message})
+}
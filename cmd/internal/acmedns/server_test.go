@@ -0,0 +1,256 @@
+package acmedns
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-acme/lego/v5/challenge"
+	"github.com/go-acme/lego/v5/cmd/internal/storage"
+	"github.com/miekg/dns"
+	"github.com/nrdcg/goacmedns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer(t *testing.T) {
+	store := storage.NewAcmeDNSStorage(t.TempDir())
+
+	server, apiURL := setupServer(t, store, Options{})
+
+	client, err := goacmedns.NewClient(apiURL)
+	require.NoError(t, err)
+
+	account, err := client.RegisterAccount(t.Context(), nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, account.SubDomain+".auth.example.com", account.FullDomain)
+
+	for _, value := range []string{strings.Repeat("a", 43), strings.Repeat("b", 43), strings.Repeat("c", 43)} {
+		err = client.UpdateTXTRecord(t.Context(), account, value)
+		require.NoError(t, err)
+	}
+
+	expected := []string{strings.Repeat("b", 43), strings.Repeat("c", 43)}
+
+	assert.Equal(t, expected, queryTXT(t, server, account.FullDomain))
+
+	// The accounts and the records are restored from the store.
+	restored, _ := setupServer(t, store, Options{})
+
+	assert.Equal(t, expected, queryTXT(t, restored, account.FullDomain))
+}
+
+func TestServer_update_errors(t *testing.T) {
+	_, apiURL := setupServer(t, storage.NewAcmeDNSStorage(t.TempDir()), Options{})
+
+	client, err := goacmedns.NewClient(apiURL)
+	require.NoError(t, err)
+
+	account, err := client.RegisterAccount(t.Context(), nil)
+	require.NoError(t, err)
+
+	restricted, err := client.RegisterAccount(t.Context(), []string{"192.0.2.0/24"})
+	require.NoError(t, err)
+
+	invalidPassword := account
+	invalidPassword.Password = "invalid"
+
+	unknownUser := account
+	unknownUser.Username = "unknown"
+
+	otherSubdomain := account
+	otherSubdomain.SubDomain = restricted.SubDomain
+
+	value := strings.Repeat("a", 43)
+
+	testCases := []struct {
+		desc     string
+		account  goacmedns.Account
+		value    string
+		expected int
+	}{
+		{
+			desc:     "invalid password",
+			account:  invalidPassword,
+			value:    value,
+			expected: http.StatusUnauthorized,
+		},
+		{
+			desc:     "unknown user",
+			account:  unknownUser,
+			value:    value,
+			expected: http.StatusUnauthorized,
+		},
+		{
+			desc:     "subdomain of another account",
+			account:  otherSubdomain,
+			value:    value,
+			expected: http.StatusUnauthorized,
+		},
+		{
+			desc:     "not allowed address",
+			account:  restricted,
+			value:    value,
+			expected: http.StatusUnauthorized,
+		},
+		{
+			desc:     "invalid TXT value",
+			account:  account,
+			value:    "invalid",
+			expected: http.StatusBadRequest,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			err := client.UpdateTXTRecord(t.Context(), test.account, test.value)
+
+			var clientErr *goacmedns.ClientError
+			require.ErrorAs(t, err, &clientErr)
+
+			assert.Equal(t, test.expected, clientErr.HTTPStatus)
+		})
+	}
+}
+
+func TestServer_register_errors(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		opts     Options
+		body     string
+		expected int
+	}{
+		{
+			desc:     "invalid CIDR",
+			body:     `{"allowfrom": ["invalid"]}`,
+			expected: http.StatusBadRequest,
+		},
+		{
+			desc:     "malformed JSON",
+			body:     `{`,
+			expected: http.StatusBadRequest,
+		},
+		{
+			desc:     "registration disabled",
+			opts:     Options{DisableRegistration: true},
+			expected: http.StatusNotFound,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, apiURL := setupServer(t, storage.NewAcmeDNSStorage(t.TempDir()), test.opts)
+
+			req, err := http.NewRequestWithContext(t.Context(), http.MethodPost, apiURL+"/register", bytes.NewBufferString(test.body))
+			require.NoError(t, err)
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+
+			_ = resp.Body.Close()
+
+			assert.Equal(t, test.expected, resp.StatusCode)
+		})
+	}
+}
+
+func Test_isAllowed(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		allowFrom  []string
+		remoteAddr string
+		assert     assert.BoolAssertionFunc
+	}{
+		{
+			desc:       "no restriction",
+			remoteAddr: "192.0.2.1:1234",
+			assert:     assert.True,
+		},
+		{
+			desc:       "allowed",
+			allowFrom:  []string{"198.51.100.0/24", "192.0.2.0/24"},
+			remoteAddr: "192.0.2.1:1234",
+			assert:     assert.True,
+		},
+		{
+			desc:       "allowed IPv6",
+			allowFrom:  []string{"2001:db8::/32"},
+			remoteAddr: "[2001:db8::1]:1234",
+			assert:     assert.True,
+		},
+		{
+			desc:       "not allowed",
+			allowFrom:  []string{"198.51.100.0/24"},
+			remoteAddr: "192.0.2.1:1234",
+			assert:     assert.False,
+		},
+		{
+			desc:       "invalid remote address",
+			allowFrom:  []string{"198.51.100.0/24"},
+			remoteAddr: "invalid",
+			assert:     assert.False,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			test.assert(t, isAllowed(test.allowFrom, test.remoteAddr))
+		})
+	}
+}
+
+func setupServer(t *testing.T, store Store, opts Options) (*Server, string) {
+	t.Helper()
+
+	opts.Zone = "auth.example.com"
+	opts.Nameserver = "ns.auth.example.com"
+	opts.DNSAddress = "127.0.0.1:0"
+	opts.NetworkStack = challenge.IPv4Only
+
+	server, err := New(store, opts)
+	require.NoError(t, err)
+
+	err = server.dns.Start(t.Context())
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_ = server.dns.Shutdown(context.Background())
+	})
+
+	api := httptest.NewServer(server.Handler())
+	t.Cleanup(api.Close)
+
+	return server, api.URL
+}
+
+func queryTXT(t *testing.T, server *Server, name string) []string {
+	t.Helper()
+
+	m := new(dns.Msg)
+	m.SetQuestion(dns.Fqdn(name), dns.TypeTXT)
+
+	resp, _, err := new(dns.Client).Exchange(m, server.DNSAddr().String())
+	require.NoError(t, err)
+
+	require.Equal(t, dns.RcodeSuccess, resp.Rcode)
+
+	var values []string
+
+	for _, rr := range resp.Answer {
+		if txt, ok := rr.(*dns.TXT); ok {
+			values = append(values, txt.Txt...)
+		}
+	}
+
+	return values
+}
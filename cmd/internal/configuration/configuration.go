@@ -7,9 +7,10 @@ import (
 )
 
 type Configuration struct {
-	Storage      string                  `yaml:"storage,omitempty"`
+	Storage      *Storage                `yaml:"storage,omitempty"`
 	NetworkStack string                  `yaml:"networkStack,omitempty"`
 	UserAgent    string                  `yaml:"userAgent,omitempty"`
+	Concurrency  int                     `yaml:"concurrency,omitempty"`
 	Servers      map[string]*Server      `yaml:"servers,omitempty"`
 	Accounts     map[string]*Account     `yaml:"accounts,omitempty"`
 	Challenges   map[string]*Challenge   `yaml:"challenges,omitempty"`
@@ -18,6 +19,45 @@ type Configuration struct {
 	Log          *Log                    `yaml:"log,omitempty"`
 }
 
+// Storage is the configuration of the storage of the accounts and certificates.
+// It can be defined as a path (string) or as a block.
+type Storage struct {
+	// Path is the local directory used by the filesystem backend (the default backend), its archives, and its configuration backup.
+	Path string `yaml:"path,omitempty"`
+
+	Encryption *KeyEncryption `yaml:"encryption,omitempty"`
+
+	S3  *S3Storage  `yaml:"s3,omitempty"`
+	SQL *SQLStorage `yaml:"sql,omitempty"`
+}
+
+// KeyEncryption is the configuration of the encryption of the private keys at rest.
+// The keys are encrypted with a passphrase (encrypted PKCS#8) or with age recipients.
+type KeyEncryption struct {
+	PassphraseEnv  string `yaml:"passphraseEnv,omitempty"`
+	PassphraseFile string `yaml:"passphraseFile,omitempty"`
+
+	AgeRecipients   []string `yaml:"ageRecipients,omitempty"`
+	AgeIdentityFile string   `yaml:"ageIdentityFile,omitempty"`
+
+	// DecryptForHooks provides a decrypted copy of the private key to the deploy hook.
+	DecryptForHooks bool `yaml:"decryptForHooks,omitempty"`
+}
+
+type S3Storage struct {
+	Bucket       string `yaml:"bucket,omitempty"`
+	Prefix       string `yaml:"prefix,omitempty"`
+	Region       string `yaml:"region,omitempty"`
+	Endpoint     string `yaml:"endpoint,omitempty"`
+	UsePathStyle bool   `yaml:"usePathStyle,omitempty"`
+}
+
+type SQLStorage struct {
+	Driver string `yaml:"driver,omitempty"`
+	DSN    string `yaml:"dsn,omitempty"`
+	Table  string `yaml:"table,omitempty"`
+}
+
 type Server struct {
 	URL                 string `yaml:"url,omitempty"`
 	TLSSkipVerify       bool   `yaml:"tlsSkipVerify,omitempty"`
@@ -32,8 +72,10 @@ type Account struct {
 	Server                 string                  `yaml:"server,omitempty"`
 	Email                  string                  `yaml:"email,omitempty"`
 	KeyType                certcrypto.KeyType      `yaml:"keyType,omitempty"`
+	KeySource              string                  `yaml:"keySource,omitempty"`
 	AcceptsTermsOfService  bool                    `yaml:"acceptsTermsOfService,omitempty"`
 	ExternalAccountBinding *ExternalAccountBinding `yaml:"eab,omitempty"`
+	Concurrency            int                     `yaml:"concurrency,omitempty"`
 }
 
 type ExternalAccountBinding struct {
@@ -48,6 +90,7 @@ type Challenge struct {
 	TLS        *TLSChallenge        `yaml:"tls,omitempty"`
 	DNS        *DNSChallenge        `yaml:"dns,omitempty"`
 	DNSPersist *DNSPersistChallenge `yaml:"dnsPersist,omitempty"`
+	DNSAccount *DNSChallenge        `yaml:"dnsAccount,omitempty"`
 }
 
 type HTTPChallenge struct {
@@ -70,6 +113,15 @@ type DNSChallenge struct {
 	DNSTimeout  int          `yaml:"dnsTimeout,omitempty"`
 	Resolvers   []string     `yaml:"resolvers,omitempty"`
 	EnvFile     string       `yaml:"envFile,omitempty"`
+
+	// Routes are used instead of Provider to route the challenges to a provider based on the zone of the domain.
+	Routes []*DNSRoute `yaml:"routes,omitempty"`
+}
+
+type DNSRoute struct {
+	Zone     string `yaml:"zone,omitempty"`
+	Provider string `yaml:"provider,omitempty"`
+	EnvFile  string `yaml:"envFile,omitempty"`
 }
 
 type DNSPersistChallenge struct {
@@ -83,6 +135,7 @@ type DNSPersistChallenge struct {
 type Propagation struct {
 	DisableAuthoritativeNameservers bool          `yaml:"disableAuthoritativeNameservers,omitempty"`
 	DisableRecursiveNameservers     bool          `yaml:"disableRecursiveNameservers,omitempty"`
+	DNSSEC                          bool          `yaml:"dnssec,omitempty"`
 	Wait                            time.Duration `yaml:"wait,omitempty"`
 }
 
@@ -92,11 +145,15 @@ type Certificate struct {
 	Domains []string `yaml:"domains,omitempty"`
 	CSR     string   `yaml:"csr,omitempty"`
 
-	KeyType certcrypto.KeyType `yaml:"keyType,omitempty"`
+	KeyType   certcrypto.KeyType `yaml:"keyType,omitempty"`
+	KeySource string             `yaml:"keySource,omitempty"`
 
 	Challenge string `yaml:"challenge,omitempty"`
 	Account   string `yaml:"account,omitempty"`
 
+	// ChallengeRules override the challenge for the domains matching a pattern.
+	ChallengeRules []*ChallengeRule `yaml:"challengeRules,omitempty"`
+
 	EnableCommonName bool `yaml:"enableCommonName,omitempty"`
 
 	PreferredChain string `yaml:"preferredChain,omitempty"`
@@ -109,11 +166,26 @@ type Certificate struct {
 
 	AlwaysDeactivateAuthorizations bool `yaml:"alwaysDeactivateAuthorizations,omitempty"`
 
+	// ChallengeFallback retries with a new order and the next available challenge when a challenge fails.
+	ChallengeFallback bool `yaml:"challengeFallback,omitempty"`
+
+	// CheckCAA checks the CAA records of the domains before creating the order.
+	CheckCAA bool `yaml:"checkCAA,omitempty"`
+
+	// MinAuthorizationValidity deactivates the valid authorizations expiring within this duration, to solve them again.
+	MinAuthorizationValidity time.Duration `yaml:"minAuthorizationValidity,omitempty"`
+
 	Renew *RenewConfiguration `yaml:"renew,omitempty"`
 
 	PFX *PFX `yaml:"pfx,omitempty"`
 }
 
+type ChallengeRule struct {
+	// Domain is a pattern (path.Match syntax) matching the domains (ex: `*.internal.example.com`).
+	Domain    string `yaml:"domain,omitempty"`
+	Challenge string `yaml:"challenge,omitempty"`
+}
+
 type RenewConfiguration struct {
 	ARI *ARIConfiguration `yaml:"ari,omitempty"`
 
@@ -150,4 +222,5 @@ type Hook struct {
 type Log struct {
 	Level  string `yaml:"level,omitempty"`
 	Format string `yaml:"format,omitempty"`
+	Events string `yaml:"events,omitempty"`
 }
@@ -12,6 +12,8 @@ const DefaultAccountID = "noemail@example.com"
 
 const defaultLegoDirectory = ".lego"
 
+const defaultSQLTable = "lego_storage"
+
 const (
 	defaultHTTP01       = "http-01"
 	defaultTLSALPN01    = "tls-alpn-01"
@@ -24,8 +26,14 @@ const (
 )
 
 func ApplyDefaults(cfg *Configuration) {
-	if cfg.Storage == "" {
-		cfg.Storage = defaultLegoDirectory
+	if cfg.Storage == nil {
+		cfg.Storage = &Storage{}
+	}
+
+	applyStorageDefaults(cfg.Storage)
+
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 1
 	}
 
 	if len(cfg.Servers) == 0 {
@@ -51,6 +59,16 @@ func ApplyDefaults(cfg *Configuration) {
 	applyDefaultHooks(cfg)
 }
 
+func applyStorageDefaults(storage *Storage) {
+	if storage.Path == "" {
+		storage.Path = defaultLegoDirectory
+	}
+
+	if storage.SQL != nil && storage.SQL.Table == "" {
+		storage.SQL.Table = defaultSQLTable
+	}
+}
+
 func applyServersDefaults(cfg *Configuration) {
 	for _, server := range cfg.Servers {
 		if server.OverallRequestLimit <= 0 {
@@ -70,6 +88,10 @@ func applyAccountsDefaults(cfg *Configuration) {
 		if account.Server == "" {
 			account.Server = lego.DirectoryURLLetsEncrypt
 		}
+
+		if account.Concurrency <= 0 {
+			account.Concurrency = cfg.Concurrency
+		}
 	}
 }
 
@@ -107,30 +129,41 @@ func applyCertificatesDefaults(cfg *Configuration) {
 
 		applyRenewDefaults(cert)
 
-		switch cert.Challenge {
-		case defaultHTTP01:
-			setDefaultHTTP01(cfg)
+		if !setDefaultChallenge(cfg, cert.Challenge) && cert.Challenge == "" && len(cfg.Challenges) == 1 {
+			// If there is only one challenge, use it by default.
+			for c := range cfg.Challenges {
+				cert.Challenge = c
+			}
+		}
+
+		for _, rule := range cert.ChallengeRules {
+			if rule != nil {
+				setDefaultChallenge(cfg, rule.Challenge)
+			}
+		}
+	}
+}
 
-			continue
+// setDefaultChallenge defines the default challenge related to the name, if the name is a default challenge name.
+func setDefaultChallenge(cfg *Configuration, name string) bool {
+	switch name {
+	case defaultHTTP01:
+		setDefaultHTTP01(cfg)
 
-		case defaultTLSALPN01:
-			setDefaultTLSALPN01(cfg)
+		return true
 
-			continue
+	case defaultTLSALPN01:
+		setDefaultTLSALPN01(cfg)
 
-		case defaultDNSPersist01:
-			setDefaultDNSPersist01(cfg)
+		return true
 
-			continue
+	case defaultDNSPersist01:
+		setDefaultDNSPersist01(cfg)
 
-		default:
-			if cert.Challenge == "" && len(cfg.Challenges) == 1 {
-				// If there is only one challenge, use it by default.
-				for c := range cfg.Challenges {
-					cert.Challenge = c
-				}
-			}
-		}
+		return true
+
+	default:
+		return false
 	}
 }
 
@@ -16,7 +16,8 @@ func TestApplyDefaults(t *testing.T) {
 	ApplyDefaults(cfg)
 
 	expected := &Configuration{
-		Storage:      defaultLegoDirectory,
+		Storage:      &Storage{Path: defaultLegoDirectory},
+		Concurrency:  1,
 		Servers:      map[string]*Server{},
 		Accounts:     map[string]*Account{},
 		Challenges:   map[string]*Challenge{},
@@ -126,6 +127,23 @@ func Test_applyAccountsDefaults(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "concurrency",
+			cfg: &Configuration{
+				Concurrency: 4,
+				Accounts: map[string]*Account{
+					"a": {},
+					"b": {Concurrency: 2},
+				},
+			},
+			expected: &Configuration{
+				Concurrency: 4,
+				Accounts: map[string]*Account{
+					"a": {ID: "a", Server: lego.DirectoryURLLetsEncrypt, KeyType: certcrypto.EC256, Concurrency: 4},
+					"b": {ID: "b", Server: lego.DirectoryURLLetsEncrypt, KeyType: certcrypto.EC256, Concurrency: 2},
+				},
+			},
+		},
 	}
 
 	for _, test := range testCases {
@@ -449,6 +467,45 @@ func Test_applyCertificatesDefaults(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "default challenge in a challenge rule",
+			cfg: &Configuration{
+				Accounts: map[string]*Account{},
+				Challenges: map[string]*Challenge{
+					"chlgA": {DNS: &DNSChallenge{Provider: "foo"}},
+				},
+				Certificates: map[string]*Certificate{
+					"a": {
+						Challenge:      "chlgA",
+						ChallengeRules: []*ChallengeRule{{Domain: "www.example.com", Challenge: defaultHTTP01}},
+					},
+				},
+			},
+			expected: &Configuration{
+				Accounts: map[string]*Account{
+					DefaultAccountID: {
+						Server:  lego.DirectoryURLLetsEncrypt,
+						KeyType: certcrypto.EC256,
+					},
+				},
+				Challenges: map[string]*Challenge{
+					"chlgA":       {DNS: &DNSChallenge{Provider: "foo"}},
+					defaultHTTP01: {HTTP: &HTTPChallenge{Address: defaultHTTPAddress}},
+				},
+				Certificates: map[string]*Certificate{
+					"a": {
+						ID:             "a",
+						Account:        DefaultAccountID,
+						KeyType:        certcrypto.EC256,
+						Challenge:      "chlgA",
+						ChallengeRules: []*ChallengeRule{{Domain: "www.example.com", Challenge: defaultHTTP01}},
+						Renew: &RenewConfiguration{
+							ARI: &ARIConfiguration{},
+						},
+					},
+				},
+			},
+		},
 		{
 			desc: "default if only one challenge",
 			cfg: &Configuration{
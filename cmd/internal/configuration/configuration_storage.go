@@ -0,0 +1,37 @@
+package configuration
+
+import (
+	"bytes"
+
+	"gopkg.in/yaml.v3"
+)
+
+// plainStorage is used to avoid the recursion inside the YAML (un)marshaling of Storage.
+type plainStorage Storage
+
+// UnmarshalYAML allows defining the storage as a path (string) or as a block.
+func (s *Storage) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		return value.Decode(&s.Path)
+	}
+
+	// The options of the decoder (KnownFields) are not propagated to the custom unmarshalers.
+	raw, err := yaml.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(raw))
+	decoder.KnownFields(true)
+
+	return decoder.Decode((*plainStorage)(s))
+}
+
+// MarshalYAML uses the short form (path only) when there are no other options.
+func (s *Storage) MarshalYAML() (any, error) {
+	if s.Encryption == nil && s.S3 == nil && s.SQL == nil {
+		return s.Path, nil
+	}
+
+	return (*plainStorage)(s), nil
+}
@@ -0,0 +1,111 @@
+package configuration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestStorage_UnmarshalYAML(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		data     string
+		expected *Storage
+	}{
+		{
+			desc:     "path",
+			data:     `storage: /tmp/lego`,
+			expected: &Storage{Path: "/tmp/lego"},
+		},
+		{
+			desc: "block with path",
+			data: `
+storage:
+  path: /tmp/lego
+`,
+			expected: &Storage{Path: "/tmp/lego"},
+		},
+		{
+			desc: "s3",
+			data: `
+storage:
+  s3:
+    bucket: foo
+    prefix: lego/
+    usePathStyle: true
+`,
+			expected: &Storage{S3: &S3Storage{Bucket: "foo", Prefix: "lego/", UsePathStyle: true}},
+		},
+		{
+			desc: "sql",
+			data: `
+storage:
+  path: /tmp/lego
+  sql:
+    driver: postgres
+    dsn: postgres://lego@localhost/lego
+`,
+			expected: &Storage{
+				Path: "/tmp/lego",
+				SQL:  &SQLStorage{Driver: "postgres", DSN: "postgres://lego@localhost/lego"},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := new(Configuration)
+
+			err := yaml.Unmarshal([]byte(test.data), cfg)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, cfg.Storage)
+		})
+	}
+}
+
+func TestStorage_UnmarshalYAML_unknownField(t *testing.T) {
+	cfg := new(Configuration)
+
+	err := yaml.Unmarshal([]byte("storage:\n  foo: bar\n"), cfg)
+	require.Error(t, err)
+}
+
+func TestStorage_MarshalYAML(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		storage  *Storage
+		expected string
+	}{
+		{
+			desc:     "path only",
+			storage:  &Storage{Path: "/tmp/lego"},
+			expected: "storage: /tmp/lego\n",
+		},
+		{
+			desc:     "s3",
+			storage:  &Storage{Path: "/tmp/lego", S3: &S3Storage{Bucket: "foo"}},
+			expected: "storage:\n    path: /tmp/lego\n    s3:\n        bucket: foo\n",
+		},
+		{
+			desc:     "encryption",
+			storage:  &Storage{Path: "/tmp/lego", Encryption: &KeyEncryption{PassphraseEnv: "FOO"}},
+			expected: "storage:\n    path: /tmp/lego\n    encryption:\n        passphraseEnv: FOO\n",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			data, err := yaml.Marshal(&Configuration{Storage: test.storage})
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, string(data))
+		})
+	}
+}
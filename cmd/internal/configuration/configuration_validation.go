@@ -4,10 +4,13 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"path"
+	"regexp"
 	"slices"
 	"strings"
 
 	"github.com/go-acme/lego/v5/certcrypto"
+	"github.com/go-acme/lego/v5/cmd/internal/pkcs11"
 	"github.com/go-acme/lego/v5/log"
 )
 
@@ -17,9 +20,17 @@ const (
 	LogFormatColored = "colored"
 )
 
+const (
+	SQLDriverSQLite   = "sqlite"
+	SQLDriverPostgres = "postgres"
+)
+
+var sqlTableNameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
 func Validate(cfg *Configuration) error {
 	validators := []func(*Configuration) error{
 		validateLog,
+		validateStorage,
 		validateChallenges,
 		validateCertificates,
 		validateServers,
@@ -61,6 +72,11 @@ func validateCertificates(cfg *Configuration) error {
 			return fmt.Errorf("certificate '%s': challenge: %w", name, err)
 		}
 
+		err = validateChallengeRules(cfg, cert.ChallengeRules)
+		if err != nil {
+			return fmt.Errorf("certificate '%s': challenge rules: %w", name, err)
+		}
+
 		if cert.PFX != nil && !certcrypto.IsPKCS12Supported(cert.PFX.Format) {
 			return fmt.Errorf("certificate '%s': invalid PFX format: %s", name, cert.PFX.Format)
 		}
@@ -90,6 +106,62 @@ func validateCertificate(cert *Certificate) error {
 		return fmt.Errorf("unsupported key type: %s", cert.KeyType)
 	}
 
+	if cert.KeySource != "" {
+		err := validateCertificateKeySource(cert)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validateChallengeRules(cfg *Configuration, rules []*ChallengeRule) error {
+	for i, rule := range rules {
+		if rule == nil || strings.TrimSpace(rule.Domain) == "" {
+			return fmt.Errorf("rule %d: a domain pattern is required", i)
+		}
+
+		_, err := path.Match(rule.Domain, "")
+		if err != nil {
+			return fmt.Errorf("rule %d: invalid domain pattern %q: %w", i, rule.Domain, err)
+		}
+
+		if rule.Challenge == "" {
+			return fmt.Errorf("rule %d: a challenge is required", i)
+		}
+
+		err = existInMap(cfg.Challenges, rule.Challenge)
+		if err != nil {
+			return fmt.Errorf("rule %d: challenge: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+func validateCertificateKeySource(cert *Certificate) error {
+	if !strings.HasPrefix(cert.KeySource, "pkcs11:") {
+		return fmt.Errorf("unsupported key source: %s (only PKCS#11 URIs are supported)", cert.KeySource)
+	}
+
+	if cert.CSR != "" {
+		return errors.New("key source and CSR are mutually exclusive")
+	}
+
+	if cert.PFX != nil {
+		return errors.New("PFX is not supported with a key source: the private key cannot leave the token")
+	}
+
+	return validatePKCS11Support()
+}
+
+// validatePKCS11Support rejects the key sources when lego is built without cgo (ex: the release binaries).
+func validatePKCS11Support() error {
+	if !pkcs11.Supported {
+		return errors.New("the key source is not supported: PKCS#11 requires a build of lego with cgo")
+	}
+
 	return nil
 }
 
@@ -117,17 +189,19 @@ func validateChallenge(chlg *Challenge) error {
 	hasHTTPChallenge := chlg.HTTP != nil
 	hasDNSChallenge := chlg.DNS != nil
 	hasDNSPersistChallenge := chlg.DNSPersist != nil
+	hasDNSAccountChallenge := chlg.DNSAccount != nil
 
-	if !hasTLSChallenge && !hasHTTPChallenge && !hasDNSChallenge && !hasDNSPersistChallenge {
+	if !hasTLSChallenge && !hasHTTPChallenge && !hasDNSChallenge && !hasDNSPersistChallenge && !hasDNSAccountChallenge {
 		return errors.New("at least one challenge type must be defined")
 	}
 
 	if hasDNSChallenge {
-		if chlg.DNS.Provider == "" {
-			return errors.New("a provider is required")
+		err := validateDNSProvider(chlg.DNS)
+		if err != nil {
+			return err
 		}
 
-		err := validatePropagationExclusiveOptions(chlg.DNS.Propagation)
+		err = validatePropagationExclusiveOptions(chlg.DNS.Propagation)
 		if err != nil {
 			return err
 		}
@@ -140,6 +214,53 @@ func validateChallenge(chlg *Challenge) error {
 		}
 	}
 
+	if hasDNSAccountChallenge {
+		err := validateDNSProvider(chlg.DNSAccount)
+		if err != nil {
+			return fmt.Errorf("dnsAccount: %w", err)
+		}
+
+		err = validatePropagationExclusiveOptions(chlg.DNSAccount.Propagation)
+		if err != nil {
+			return fmt.Errorf("dnsAccount: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func validateDNSProvider(chlg *DNSChallenge) error {
+	if len(chlg.Routes) == 0 {
+		if chlg.Provider == "" {
+			return errors.New("a provider is required")
+		}
+
+		return nil
+	}
+
+	if chlg.Provider != "" {
+		return errors.New("'provider' and 'routes' are mutually exclusive")
+	}
+
+	zones := make(map[string]struct{})
+
+	for i, route := range chlg.Routes {
+		if route == nil || strings.TrimSpace(route.Zone) == "" {
+			return fmt.Errorf("route %d: a zone is required", i)
+		}
+
+		if route.Provider == "" {
+			return fmt.Errorf("route %d (%s): a provider is required", i, route.Zone)
+		}
+
+		zone := strings.ToLower(strings.TrimSuffix(strings.TrimSpace(route.Zone), "."))
+		if _, ok := zones[zone]; ok {
+			return fmt.Errorf("route %d (%s): duplicate zone", i, route.Zone)
+		}
+
+		zones[zone] = struct{}{}
+	}
+
 	return nil
 }
 
@@ -167,6 +288,17 @@ func validateAccount(name string, account *Account) error {
 		return fmt.Errorf("unsupported key type: %s", account.KeyType)
 	}
 
+	if account.KeySource != "" {
+		if !strings.HasPrefix(account.KeySource, "pkcs11:") {
+			return fmt.Errorf("unsupported key source: %s (only PKCS#11 URIs are supported)", account.KeySource)
+		}
+
+		err := validatePKCS11Support()
+		if err != nil {
+			return err
+		}
+	}
+
 	if account.ExternalAccountBinding != nil {
 		if account.ExternalAccountBinding.KID == "" || account.ExternalAccountBinding.HmacKey == "" {
 			return errors.New("KID and HMAC key must be provided for External Account Binding")
@@ -202,6 +334,72 @@ func validateServers(cfg *Configuration) error {
 	return nil
 }
 
+func validateStorage(cfg *Configuration) error {
+	if cfg.Storage == nil {
+		return nil
+	}
+
+	if cfg.Storage.S3 != nil && cfg.Storage.SQL != nil {
+		return errors.New("storage: 's3' and 'sql' are mutually exclusive")
+	}
+
+	if cfg.Storage.S3 != nil && cfg.Storage.S3.Bucket == "" {
+		return errors.New("storage: s3: a bucket is required")
+	}
+
+	if cfg.Storage.SQL != nil {
+		err := validateSQLStorage(cfg.Storage.SQL)
+		if err != nil {
+			return fmt.Errorf("storage: sql: %w", err)
+		}
+	}
+
+	if cfg.Storage.Encryption != nil {
+		err := validateKeyEncryption(cfg.Storage.Encryption)
+		if err != nil {
+			return fmt.Errorf("storage: encryption: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func validateKeyEncryption(cfg *KeyEncryption) error {
+	var count int
+
+	for _, defined := range []bool{cfg.PassphraseEnv != "", cfg.PassphraseFile != "", len(cfg.AgeRecipients) > 0} {
+		if defined {
+			count++
+		}
+	}
+
+	if count != 1 {
+		return errors.New("one (and only one) of 'passphraseEnv', 'passphraseFile', or 'ageRecipients' must be defined")
+	}
+
+	if len(cfg.AgeRecipients) > 0 && cfg.AgeIdentityFile == "" {
+		return errors.New("'ageIdentityFile' is required to decrypt the keys encrypted with 'ageRecipients'")
+	}
+
+	return nil
+}
+
+func validateSQLStorage(cfg *SQLStorage) error {
+	if !slices.Contains([]string{SQLDriverSQLite, SQLDriverPostgres}, cfg.Driver) {
+		return fmt.Errorf("unsupported driver '%s'", cfg.Driver)
+	}
+
+	if cfg.DSN == "" {
+		return errors.New("a DSN is required")
+	}
+
+	if cfg.Table != "" && !sqlTableNameRegexp.MatchString(cfg.Table) {
+		return fmt.Errorf("invalid table name '%s'", cfg.Table)
+	}
+
+	return nil
+}
+
 func validateLog(cfg *Configuration) error {
 	if cfg.Log == nil {
 		return nil
@@ -233,6 +431,10 @@ func validatePropagationExclusiveOptions(cfg *Propagation) error {
 		return errors.New("'wait' and 'disableRecursiveNameservers' are mutually exclusive")
 	}
 
+	if cfg.DNSSEC {
+		return errors.New("'wait' and 'dnssec' are mutually exclusive")
+	}
+
 	return nil
 }
 
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/go-acme/lego/v5/certcrypto"
+	"github.com/go-acme/lego/v5/cmd/internal/pkcs11"
 	"github.com/stretchr/testify/require"
 )
 
@@ -68,6 +69,62 @@ func Test_validateChallenges(t *testing.T) {
 			},
 			expected: "challenge 'a': a provider is required",
 		},
+		{
+			desc: "DNS challenge with a provider and routes",
+			cfg: &Configuration{
+				Challenges: map[string]*Challenge{
+					"a": {
+						DNS: &DNSChallenge{
+							Provider: "foo",
+							Routes:   []*DNSRoute{{Zone: "example.com", Provider: "bar"}},
+						},
+					},
+				},
+			},
+			expected: "challenge 'a': 'provider' and 'routes' are mutually exclusive",
+		},
+		{
+			desc: "DNS challenge route without a zone",
+			cfg: &Configuration{
+				Challenges: map[string]*Challenge{
+					"a": {
+						DNS: &DNSChallenge{
+							Routes: []*DNSRoute{{Provider: "bar"}},
+						},
+					},
+				},
+			},
+			expected: "challenge 'a': route 0: a zone is required",
+		},
+		{
+			desc: "DNS challenge route without a provider",
+			cfg: &Configuration{
+				Challenges: map[string]*Challenge{
+					"a": {
+						DNS: &DNSChallenge{
+							Routes: []*DNSRoute{{Zone: "example.com"}},
+						},
+					},
+				},
+			},
+			expected: "challenge 'a': route 0 (example.com): a provider is required",
+		},
+		{
+			desc: "DNS challenge routes with duplicate zones",
+			cfg: &Configuration{
+				Challenges: map[string]*Challenge{
+					"a": {
+						DNS: &DNSChallenge{
+							Routes: []*DNSRoute{
+								{Zone: "example.com", Provider: "foo"},
+								{Zone: "Example.com.", Provider: "bar"},
+							},
+						},
+					},
+				},
+			},
+			expected: "challenge 'a': route 1 (Example.com.): duplicate zone",
+		},
 		{
 			desc: "DNS challenge propagation: wait and DisableAuthoritativeNameservers",
 			cfg: &Configuration{
@@ -102,6 +159,23 @@ func Test_validateChallenges(t *testing.T) {
 			},
 			expected: "challenge 'a': 'wait' and 'disableRecursiveNameservers' are mutually exclusive",
 		},
+		{
+			desc: "DNS challenge propagation: wait and DNSSEC",
+			cfg: &Configuration{
+				Challenges: map[string]*Challenge{
+					"a": {
+						DNS: &DNSChallenge{
+							Provider: "foo",
+							Propagation: &Propagation{
+								DNSSEC: true,
+								Wait:   1,
+							},
+						},
+					},
+				},
+			},
+			expected: "challenge 'a': 'wait' and 'dnssec' are mutually exclusive",
+		},
 		{
 			desc: "DNS persist challenge propagation: wait and DisableAuthoritativeNameservers",
 			cfg: &Configuration{
@@ -134,6 +208,34 @@ func Test_validateChallenges(t *testing.T) {
 			},
 			expected: "challenge 'a': 'wait' and 'disableRecursiveNameservers' are mutually exclusive",
 		},
+		{
+			desc: "DNS account challenge without a provider",
+			cfg: &Configuration{
+				Challenges: map[string]*Challenge{
+					"a": {
+						DNSAccount: &DNSChallenge{},
+					},
+				},
+			},
+			expected: "challenge 'a': dnsAccount: a provider is required",
+		},
+		{
+			desc: "DNS account challenge propagation: wait and DisableAuthoritativeNameservers",
+			cfg: &Configuration{
+				Challenges: map[string]*Challenge{
+					"a": {
+						DNSAccount: &DNSChallenge{
+							Provider: "foo",
+							Propagation: &Propagation{
+								DisableAuthoritativeNameservers: true,
+								Wait:                            1,
+							},
+						},
+					},
+				},
+			},
+			expected: "challenge 'a': dnsAccount: 'wait' and 'disableAuthoritativeNameservers' are mutually exclusive",
+		},
 	}
 
 	for _, test := range testCases {
@@ -240,6 +342,90 @@ func Test_validateCertificates(t *testing.T) {
 			},
 			expected: "certificate 'a': challenge: 'chlg' not found",
 		},
+		{
+			desc: "challenge rule without domain",
+			cfg: &Configuration{
+				Accounts: map[string]*Account{
+					"acc": {},
+				},
+				Challenges: map[string]*Challenge{
+					"yo": {},
+				},
+				Certificates: map[string]*Certificate{
+					"a": {
+						Account:        "acc",
+						Challenge:      "yo",
+						KeyType:        certcrypto.RSA2048,
+						Domains:        []string{"example.com"},
+						ChallengeRules: []*ChallengeRule{{Challenge: "yo"}},
+					},
+				},
+			},
+			expected: "certificate 'a': challenge rules: rule 0: a domain pattern is required",
+		},
+		{
+			desc: "challenge rule with invalid domain pattern",
+			cfg: &Configuration{
+				Accounts: map[string]*Account{
+					"acc": {},
+				},
+				Challenges: map[string]*Challenge{
+					"yo": {},
+				},
+				Certificates: map[string]*Certificate{
+					"a": {
+						Account:        "acc",
+						Challenge:      "yo",
+						KeyType:        certcrypto.RSA2048,
+						Domains:        []string{"example.com"},
+						ChallengeRules: []*ChallengeRule{{Domain: "[a", Challenge: "yo"}},
+					},
+				},
+			},
+			expected: "certificate 'a': challenge rules: rule 0: invalid domain pattern \"[a\": syntax error in pattern",
+		},
+		{
+			desc: "challenge rule without challenge",
+			cfg: &Configuration{
+				Accounts: map[string]*Account{
+					"acc": {},
+				},
+				Challenges: map[string]*Challenge{
+					"yo": {},
+				},
+				Certificates: map[string]*Certificate{
+					"a": {
+						Account:        "acc",
+						Challenge:      "yo",
+						KeyType:        certcrypto.RSA2048,
+						Domains:        []string{"example.com"},
+						ChallengeRules: []*ChallengeRule{{Domain: "*.example.com"}},
+					},
+				},
+			},
+			expected: "certificate 'a': challenge rules: rule 0: a challenge is required",
+		},
+		{
+			desc: "challenge rule with not existing challenge",
+			cfg: &Configuration{
+				Accounts: map[string]*Account{
+					"acc": {},
+				},
+				Challenges: map[string]*Challenge{
+					"yo": {},
+				},
+				Certificates: map[string]*Certificate{
+					"a": {
+						Account:        "acc",
+						Challenge:      "yo",
+						KeyType:        certcrypto.RSA2048,
+						Domains:        []string{"example.com"},
+						ChallengeRules: []*ChallengeRule{{Domain: "*.example.com", Challenge: "chlg"}},
+					},
+				},
+			},
+			expected: "certificate 'a': challenge rules: rule 0: challenge: 'chlg' not found",
+		},
 		{
 			desc: "unsupported key type",
 			cfg: &Configuration{
@@ -260,6 +446,70 @@ func Test_validateCertificates(t *testing.T) {
 			},
 			expected: "certificate 'a': unsupported key type: foo",
 		},
+		{
+			desc: "unsupported key source",
+			cfg: &Configuration{
+				Accounts: map[string]*Account{
+					"acc": {},
+				},
+				Challenges: map[string]*Challenge{
+					"yo": {},
+				},
+				Certificates: map[string]*Certificate{
+					"a": {
+						Account:   "acc",
+						Challenge: "yo",
+						KeyType:   certcrypto.EC256,
+						Domains:   []string{"example.com"},
+						KeySource: "file:/tmp/cert.key",
+					},
+				},
+			},
+			expected: "certificate 'a': unsupported key source: file:/tmp/cert.key (only PKCS#11 URIs are supported)",
+		},
+		{
+			desc: "key source with CSR",
+			cfg: &Configuration{
+				Accounts: map[string]*Account{
+					"acc": {},
+				},
+				Challenges: map[string]*Challenge{
+					"yo": {},
+				},
+				Certificates: map[string]*Certificate{
+					"a": {
+						Account:   "acc",
+						Challenge: "yo",
+						KeyType:   certcrypto.EC256,
+						CSR:       "/tmp/cert.csr",
+						KeySource: "pkcs11:token=lego;object=cert",
+					},
+				},
+			},
+			expected: "certificate 'a': key source and CSR are mutually exclusive",
+		},
+		{
+			desc: "key source with PFX",
+			cfg: &Configuration{
+				Accounts: map[string]*Account{
+					"acc": {},
+				},
+				Challenges: map[string]*Challenge{
+					"yo": {},
+				},
+				Certificates: map[string]*Certificate{
+					"a": {
+						Account:   "acc",
+						Challenge: "yo",
+						KeyType:   certcrypto.EC256,
+						Domains:   []string{"example.com"},
+						KeySource: "pkcs11:token=lego;object=cert",
+						PFX:       &PFX{},
+					},
+				},
+			},
+			expected: "certificate 'a': PFX is not supported with a key source: the private key cannot leave the token",
+		},
 	}
 
 	for _, test := range testCases {
@@ -273,6 +523,16 @@ func Test_validateCertificates(t *testing.T) {
 	}
 }
 
+func Test_validateCertificateKeySource_pkcs11Support(t *testing.T) {
+	err := validateCertificateKeySource(&Certificate{KeySource: "pkcs11:token=lego;object=cert"})
+
+	if pkcs11.Supported {
+		require.NoError(t, err)
+	} else {
+		require.EqualError(t, err, "the key source is not supported: PKCS#11 requires a build of lego with cgo")
+	}
+}
+
 func Test_validateServers(t *testing.T) {
 	cfg := &Configuration{Servers: map[string]*Server{"": {}}}
 
@@ -312,6 +572,18 @@ func Test_validateAccounts(t *testing.T) {
 			},
 			expected: "account 'a': unsupported key type: foo",
 		},
+		{
+			desc: "unsupported key source",
+			cfg: &Configuration{
+				Accounts: map[string]*Account{
+					"a": {
+						KeyType:   certcrypto.EC256,
+						KeySource: "file:/tmp/account.key",
+					},
+				},
+			},
+			expected: "account 'a': unsupported key source: file:/tmp/account.key (only PKCS#11 URIs are supported)",
+		},
 		{
 			desc: "missing KID and HMAC key",
 			cfg: &Configuration{
@@ -336,3 +608,80 @@ func Test_validateAccounts(t *testing.T) {
 		})
 	}
 }
+
+func Test_validateStorage(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		cfg      *Configuration
+		expected string
+	}{
+		{
+			desc: "s3 and sql",
+			cfg: &Configuration{
+				Storage: &Storage{
+					S3:  &S3Storage{Bucket: "foo"},
+					SQL: &SQLStorage{Driver: SQLDriverSQLite, DSN: "lego.db"},
+				},
+			},
+			expected: "storage: 's3' and 'sql' are mutually exclusive",
+		},
+		{
+			desc: "missing bucket",
+			cfg: &Configuration{
+				Storage: &Storage{S3: &S3Storage{}},
+			},
+			expected: "storage: s3: a bucket is required",
+		},
+		{
+			desc: "unsupported driver",
+			cfg: &Configuration{
+				Storage: &Storage{SQL: &SQLStorage{Driver: "foo", DSN: "lego.db"}},
+			},
+			expected: "storage: sql: unsupported driver 'foo'",
+		},
+		{
+			desc: "missing DSN",
+			cfg: &Configuration{
+				Storage: &Storage{SQL: &SQLStorage{Driver: SQLDriverPostgres}},
+			},
+			expected: "storage: sql: a DSN is required",
+		},
+		{
+			desc: "invalid table name",
+			cfg: &Configuration{
+				Storage: &Storage{SQL: &SQLStorage{Driver: SQLDriverSQLite, DSN: "lego.db", Table: "foo;bar"}},
+			},
+			expected: "storage: sql: invalid table name 'foo;bar'",
+		},
+		{
+			desc: "encryption without method",
+			cfg: &Configuration{
+				Storage: &Storage{Encryption: &KeyEncryption{}},
+			},
+			expected: "storage: encryption: one (and only one) of 'passphraseEnv', 'passphraseFile', or 'ageRecipients' must be defined",
+		},
+		{
+			desc: "encryption with passphrase and age",
+			cfg: &Configuration{
+				Storage: &Storage{Encryption: &KeyEncryption{PassphraseEnv: "FOO", AgeRecipients: []string{"age1foo"}}},
+			},
+			expected: "storage: encryption: one (and only one) of 'passphraseEnv', 'passphraseFile', or 'ageRecipients' must be defined",
+		},
+		{
+			desc: "encryption with age without identity",
+			cfg: &Configuration{
+				Storage: &Storage{Encryption: &KeyEncryption{AgeRecipients: []string{"age1foo"}}},
+			},
+			expected: "storage: encryption: 'ageIdentityFile' is required to decrypt the keys encrypted with 'ageRecipients'",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateStorage(test.cfg)
+			require.EqualError(t, err, test.expected)
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/go-acme/lego/v5/event"
+	"github.com/go-acme/lego/v5/log"
+)
+
+// events is the bus of the issuance events of all the clients created by the CLI.
+var events = event.NewBus()
+
+// eventSink is the current sink of the issuance events.
+var eventSink struct {
+	mu          sync.Mutex
+	target      string
+	sink        *EventSink
+	unsubscribe func()
+}
+
+// Events returns the bus of the issuance events.
+func Events() *event.Bus {
+	return events
+}
+
+// SetUpEventSink writes the issuance events to the target.
+// The previous sink, if any, is replaced.
+// An empty target disables the sink.
+func SetUpEventSink(target string) error {
+	eventSink.mu.Lock()
+	defer eventSink.mu.Unlock()
+
+	if eventSink.sink != nil && eventSink.target == target {
+		return nil
+	}
+
+	err := closeEventSink()
+	if err != nil {
+		return err
+	}
+
+	if target == "" {
+		return nil
+	}
+
+	sink, err := NewEventSink(target)
+	if err != nil {
+		return err
+	}
+
+	eventSink.target = target
+	eventSink.sink = sink
+	eventSink.unsubscribe = events.Subscribe(sink)
+
+	return nil
+}
+
+// CloseEventSink closes the current sink of the issuance events.
+func CloseEventSink() error {
+	eventSink.mu.Lock()
+	defer eventSink.mu.Unlock()
+
+	return closeEventSink()
+}
+
+func closeEventSink() error {
+	if eventSink.sink == nil {
+		return nil
+	}
+
+	eventSink.unsubscribe()
+
+	err := eventSink.sink.Close()
+
+	eventSink.target = ""
+	eventSink.sink = nil
+	eventSink.unsubscribe = nil
+
+	return err
+}
+
+var _ event.Subscriber = (*EventSink)(nil)
+
+// EventSink writes the issuance events as JSON lines.
+type EventSink struct {
+	mu sync.Mutex
+	w  io.WriteCloser
+}
+
+// NewEventSink creates an EventSink.
+// The target is a file path (the events are appended), or a socket address (`unix:///path/to/socket`, `tcp://host:port`).
+func NewEventSink(target string) (*EventSink, error) {
+	w, err := openEventTarget(target)
+	if err != nil {
+		return nil, fmt.Errorf("events: %w", err)
+	}
+
+	return &EventSink{w: w}, nil
+}
+
+// HandleEvent implements event.Subscriber.
+func (s *EventSink) HandleEvent(_ context.Context, evt event.Event) {
+	data, err := json.Marshal(evt)
+	if err != nil {
+		log.Warn("Unable to encode the event.", slog.String("type", string(evt.Type)), log.ErrorAttr(err))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err = s.w.Write(append(data, '\n'))
+	if err != nil {
+		log.Warn("Unable to write the event.", slog.String("type", string(evt.Type)), log.ErrorAttr(err))
+	}
+}
+
+// Close closes the underlying file or connection.
+func (s *EventSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.w.Close()
+}
+
+func openEventTarget(target string) (io.WriteCloser, error) {
+	scheme, address, ok := strings.Cut(target, "://")
+	if !ok {
+		return os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	}
+
+	switch scheme {
+	case "unix", "tcp":
+		conn, err := net.Dial(scheme, address)
+		if err != nil {
+			return nil, fmt.Errorf("connect to %s: %w", target, err)
+		}
+
+		return conn, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported scheme %q", scheme)
+	}
+}
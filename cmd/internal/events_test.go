@@ -0,0 +1,88 @@
+package internal
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-acme/lego/v5/event"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventSink_file(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "events.jsonl")
+
+	sink, err := NewEventSink(filename)
+	require.NoError(t, err)
+
+	sink.HandleEvent(t.Context(), event.Event{Type: event.OrderCreated, Domains: []string{"example.com"}})
+	sink.HandleEvent(t.Context(), event.Event{Type: event.ValidationFailed, Domain: "example.com", Error: "oops"})
+
+	require.NoError(t, sink.Close())
+
+	file, err := os.Open(filename)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = file.Close() })
+
+	var events []event.Event
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var evt event.Event
+
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &evt))
+
+		events = append(events, evt)
+	}
+
+	require.NoError(t, scanner.Err())
+
+	expected := []event.Event{
+		{Type: event.OrderCreated, Domains: []string{"example.com"}},
+		{Type: event.ValidationFailed, Domain: "example.com", Error: "oops"},
+	}
+
+	assert.Equal(t, expected, events)
+}
+
+func TestEventSink_socket(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = listener.Close() })
+
+	lines := make(chan string, 1)
+
+	go func() {
+		conn, errA := listener.Accept()
+		if errA != nil {
+			return
+		}
+
+		defer func() { _ = conn.Close() }()
+
+		scanner := bufio.NewScanner(conn)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+
+	sink, err := NewEventSink("tcp://" + listener.Addr().String())
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = sink.Close() })
+
+	sink.HandleEvent(t.Context(), event.Event{Type: event.CertificateRevoked, Domains: []string{"example.com"}})
+
+	assert.JSONEq(t, `{"type":"certificate.revoked","time":"0001-01-01T00:00:00Z","domains":["example.com"]}`, <-lines)
+}
+
+func TestNewEventSink_unsupportedScheme(t *testing.T) {
+	_, err := NewEventSink("udp://127.0.0.1:1234")
+	require.EqualError(t, err, `events: unsupported scheme "udp"`)
+}
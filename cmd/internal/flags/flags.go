@@ -13,6 +13,7 @@ import (
 	"github.com/go-acme/lego/v5/acme"
 	"github.com/go-acme/lego/v5/certcrypto"
 	"github.com/go-acme/lego/v5/certificate"
+	"github.com/go-acme/lego/v5/certificate/planner"
 	"github.com/go-acme/lego/v5/cmd/internal/storage"
 	"github.com/go-acme/lego/v5/internal"
 	"github.com/go-acme/lego/v5/lego"
@@ -48,6 +49,7 @@ func CreateRunFlags() []cli.Flag {
 	flags = append(flags, createDeployHookFlags()...)
 	flags = append(flags, createPostHookFlags()...)
 	flags = append(flags, CreateRenewFlags()...)
+	flags = append(flags, createMetricsFlags()...)
 
 	flags = append(flags,
 		&cli.StringFlag{
@@ -110,6 +112,16 @@ func CreateRenewFlags() []cli.Flag {
 	}
 }
 
+func CreateDaemonFlags() []cli.Flag {
+	flags := []cli.Flag{
+		createConfigFlag(),
+	}
+
+	flags = append(flags, createMetricsFlags()...)
+
+	return flags
+}
+
 func CreateRevokeFlags() []cli.Flag {
 	flags := []cli.Flag{
 		createConfigFlag(),
@@ -225,6 +237,188 @@ func CreateMigrateFlags() []cli.Flag {
 	}
 }
 
+func CreateCAAFlags() []cli.Flag {
+	flags := []cli.Flag{
+		createConfigFlag(),
+		CreatePathFlag(false),
+		createDomainFlag(),
+		createKeyTypeFlag("Key type to use for the private key of the account."),
+		&cli.StringFlag{
+			Category: categoryCAA,
+			Name:     FlgCAAProvider,
+			Sources:  cli.EnvVars(toEnvName(FlgCAAProvider)),
+			Usage: "Apply the CAA records with this DNS provider." +
+				" The provider must support the management of the CAA records (e.g. 'rfc2136')." +
+				" By default, the changes are only displayed.",
+		},
+		&cli.BoolFlag{
+			Category: categoryCAA,
+			Name:     FlgCAAApply,
+			Sources:  cli.EnvVars(toEnvName(FlgCAAApply)),
+			Usage:    "Apply the CAA records without confirmation.",
+		},
+	}
+
+	flags = append(flags, createAccountFlags()...)
+	flags = append(flags, createACMEClientFlags()...)
+	flags = append(flags, createChallengesFlags()...)
+
+	return flags
+}
+
+func CreatePreAuthorizeFlags() []cli.Flag {
+	flags := []cli.Flag{
+		createConfigFlag(),
+		CreatePathFlag(false),
+		createDomainFlag(),
+		createKeyTypeFlag("Key type to use for the private key of the account."),
+		&cli.BoolFlag{
+			Name:  FlgFormatJSON,
+			Usage: "Format the output as JSON.",
+		},
+	}
+
+	flags = append(flags, createAccountFlags()...)
+	flags = append(flags, createACMEClientFlags()...)
+	flags = append(flags, createChallengesFlags()...)
+
+	return flags
+}
+
+func CreateAuthorizationsListFlags() []cli.Flag {
+	flags := createAuthorizationsFlags()
+
+	flags = append(flags,
+		&cli.BoolFlag{
+			Name:  FlgFormatJSON,
+			Usage: "Format the output as JSON.",
+		},
+	)
+
+	return flags
+}
+
+func CreateAuthorizationsDeactivateFlags() []cli.Flag {
+	return createAuthorizationsFlags()
+}
+
+func createAuthorizationsFlags() []cli.Flag {
+	flags := []cli.Flag{
+		createConfigFlag(),
+		CreatePathFlag(false),
+		&cli.StringSliceFlag{
+			Name:    FlgCertName,
+			Aliases: []string{flgAliasCertName},
+			Sources: cli.EnvVars(toEnvName(FlgCertName)),
+			Usage: "The authorizations of the stored certificates (the authorizations of the orders recorded in the certificate resources)." +
+				" For multiple values either repeat the flag or provide a comma-separated list.",
+		},
+		&cli.StringSliceFlag{
+			Category: categoryAuthorizations,
+			Name:     FlgAuthzURL,
+			Sources:  cli.EnvVars(toEnvName(FlgAuthzURL)),
+			Usage:    "The URL of an authorization. For multiple values either repeat the flag or provide a comma-separated list.",
+		},
+		createKeyTypeFlag("Key type to use for the private key of the account."),
+	}
+
+	flags = append(flags, createAccountFlags()...)
+	flags = append(flags, createACMEClientFlags()...)
+
+	return flags
+}
+
+func CreatePlanFlags() []cli.Flag {
+	return []cli.Flag{
+		createConfigFlag(),
+		&cli.StringFlag{
+			Category: categoryPlan,
+			Name:     FlgPlanInventory,
+			Sources:  cli.EnvVars(toEnvName(FlgPlanInventory)),
+			Usage: "Path to the inventory of the domains." +
+				" One domain per line, optionally followed by the challenge ID (e.g. 'www.example.com http').",
+			Required: true,
+		},
+		&cli.IntFlag{
+			Category: categoryPlan,
+			Name:     FlgPlanMaxSANs,
+			Sources:  cli.EnvVars(toEnvName(FlgPlanMaxSANs)),
+			Usage:    "The maximum number of domains per certificate (the limit of the CA).",
+			Value:    planner.DefaultMaxSANs,
+		},
+		&cli.BoolFlag{
+			Category: categoryPlan,
+			Name:     FlgPlanGroupByRegistrableDomain,
+			Sources:  cli.EnvVars(toEnvName(FlgPlanGroupByRegistrableDomain)),
+			Usage:    "Only put the domains with the same registrable domain (e.g. 'example.co.uk') in a certificate.",
+		},
+		&cli.IntFlag{
+			Category: categoryPlan,
+			Name:     FlgPlanWildcardThreshold,
+			Sources:  cli.EnvVars(toEnvName(FlgPlanWildcardThreshold)),
+			Usage: "Replace the subdomains of a domain by a wildcard when the number of subdomains reaches the threshold." +
+				" Only for the DNS challenges of the configuration file. By default, no wildcards are created.",
+		},
+		&cli.StringFlag{
+			Category: categoryPlan,
+			Name:     FlgPlanChallenge,
+			Sources:  cli.EnvVars(toEnvName(FlgPlanChallenge)),
+			Usage: "The challenge ID of the domains of the inventory without challenge." +
+				" By default, the only challenge of the configuration file, if any.",
+		},
+		&cli.StringFlag{
+			Category: categoryPlan,
+			Name:     FlgPlanAccount,
+			Sources:  cli.EnvVars(toEnvName(FlgPlanAccount)),
+			Usage:    "The account ID of the new certificates.",
+		},
+	}
+}
+
+func CreateAcmeDNSServerFlags() []cli.Flag {
+	flags := []cli.Flag{
+		createConfigFlag(),
+		CreatePathFlag(true),
+		&cli.StringFlag{
+			Category: categoryAcmeDNSServer,
+			Name:     FlgAcmeDNSZone,
+			Sources:  cli.EnvVars(toEnvName(FlgAcmeDNSZone)),
+			Usage:    "The zone delegated to the server (e.g. 'auth.example.com'). The subdomains of the accounts are allocated in this zone.",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Category: categoryAcmeDNSServer,
+			Name:     FlgAcmeDNSNameserver,
+			Sources:  cli.EnvVars(toEnvName(FlgAcmeDNSNameserver)),
+			Usage:    "The name of the DNS server (e.g. 'ns.auth.example.com'), used by the SOA and NS records of the zone.",
+		},
+		&cli.StringFlag{
+			Category: categoryAcmeDNSServer,
+			Name:     FlgAcmeDNSDNSAddress,
+			Sources:  cli.EnvVars(toEnvName(FlgAcmeDNSDNSAddress)),
+			Usage:    "Set the address of the DNS server (UDP and TCP). Supported: interface:port or :port.",
+			Value:    ":53",
+		},
+		&cli.StringFlag{
+			Category: categoryAcmeDNSServer,
+			Name:     FlgAcmeDNSAPIAddress,
+			Sources:  cli.EnvVars(toEnvName(FlgAcmeDNSAPIAddress)),
+			Usage:    "Set the address of the HTTP API. Supported: interface:port or :port.",
+			Value:    ":8080",
+		},
+		&cli.BoolFlag{
+			Category: categoryAcmeDNSServer,
+			Name:     FlgAcmeDNSDisableRegistration,
+			Sources:  cli.EnvVars(toEnvName(FlgAcmeDNSDisableRegistration)),
+			Usage:    "Disable the registration of new accounts (the '/register' endpoint).",
+		},
+	}
+
+	flags = append(flags, createNetworkStackFlags()...)
+
+	return flags
+}
+
 func createACMEClientFlags() []cli.Flag {
 	return []cli.Flag{
 		&cli.StringFlag{
@@ -296,6 +490,7 @@ func createChallengesFlags() []cli.Flag {
 	flags = append(flags, createTLSChallengeFlags()...)
 	flags = append(flags, createDNSChallengeFlags()...)
 	flags = append(flags, createDNSPersistChallengeFlags()...)
+	flags = append(flags, createDNSAccountChallengeFlags()...)
 	flags = append(flags, createNetworkStackFlags()...)
 
 	return flags
@@ -409,7 +604,7 @@ func createDNSChallengeFlags() []cli.Flag {
 			Name:     FlgDNSResolvers,
 			Sources:  cli.EnvVars(toEnvName(FlgDNSResolvers)),
 			Usage: "Set the nameservers to use for performing (recursive) CNAME resolving, apex domain determination, and propagation checks." +
-				" Syntax: 'host:port'. For multiple values either repeat the flag or provide a comma-separated list." +
+				" Syntax: 'host:port', 'tls://host:port' (DNS-over-TLS), or 'https://host/dns-query' (DNS-over-HTTPS). For multiple values either repeat the flag or provide a comma-separated list." +
 				" The default is to use the system nameservers, or Cloudflare's nameservers if the system's cannot be determined.",
 		},
 		&cli.IntFlag{
@@ -427,6 +622,7 @@ func createDNSChallengeFlags() []cli.Flag {
 			FlgDNSPropagationWait,
 			FlgDNSPropagationDisableANS,
 			FlgDNSPropagationDisableRNS,
+			FlgDNSPropagationDNSSEC,
 		)...,
 	)
 
@@ -461,7 +657,7 @@ func createDNSPersistChallengeFlags() []cli.Flag {
 			Name:     FlgDNSPersistResolvers,
 			Sources:  cli.EnvVars(toEnvName(FlgDNSPersistResolvers)),
 			Usage: "Set the resolvers to use for DNS-PERSIST-01 TXT lookups." +
-				" Syntax: 'host:port'. For multiple values either repeat the flag or provide a comma-separated list." +
+				" Syntax: 'host:port', 'tls://host:port' (DNS-over-TLS), or 'https://host/dns-query' (DNS-over-HTTPS). For multiple values either repeat the flag or provide a comma-separated list." +
 				" The default is to use the system nameservers, or Cloudflare's nameservers if the system's cannot be determined.",
 		},
 		&cli.IntFlag{
@@ -478,13 +674,55 @@ func createDNSPersistChallengeFlags() []cli.Flag {
 			FlgDNSPersistPropagationWait,
 			FlgDNSPersistPropagationDisableANS,
 			FlgDNSPersistPropagationDisableRNS,
+			FlgDNSPersistPropagationDNSSEC,
 		)...,
 	)
 
 	return flags
 }
 
-func createDNSPropagationFlags(category, flgWait, flgANS, flgRNS string) []cli.Flag {
+func createDNSAccountChallengeFlags() []cli.Flag {
+	flags := []cli.Flag{
+		&cli.StringFlag{
+			Category: categoryDNSAccount01Challenge,
+			Name:     FlgDNSAccount,
+			Sources:  cli.EnvVars(toEnvName(FlgDNSAccount)),
+			Usage: "Solve a DNS-ACCOUNT-01 challenge using the specified provider (the DNS-01 providers)." +
+				" The records are scoped to the ACME account. Can be mixed with other types of challenges. Run 'lego dnshelp' for help on usage.",
+		},
+		&cli.StringSliceFlag{
+			Category: categoryDNSAccount01Challenge,
+			Name:     FlgDNSAccountResolvers,
+			Sources:  cli.EnvVars(toEnvName(FlgDNSAccountResolvers)),
+			Usage: "Set the nameservers to use for performing (recursive) CNAME resolving, apex domain determination, and propagation checks." +
+				" Syntax: 'host:port', 'tls://host:port' (DNS-over-TLS), or 'https://host/dns-query' (DNS-over-HTTPS). For multiple values either repeat the flag or provide a comma-separated list." +
+				" The default is to use the system nameservers, or Cloudflare's nameservers if the system's cannot be determined." +
+				" Ignored when the DNS-01 challenge is also used: both challenges use the nameservers of the DNS-01 challenge.",
+		},
+		&cli.IntFlag{
+			Category: categoryDNSAccount01Challenge,
+			Name:     FlgDNSAccountTimeout,
+			Sources:  cli.EnvVars(toEnvName(FlgDNSAccountTimeout)),
+			Usage: "Set the DNS timeout value to a specific value in seconds. Used only when performing authoritative name server queries." +
+				" Ignored when the DNS-01 challenge is also used: both challenges use the DNS timeout of the DNS-01 challenge.",
+			Value: 10,
+		},
+	}
+
+	flags = append(flags,
+		createDNSPropagationFlags(
+			categoryDNSAccount01Challenge,
+			FlgDNSAccountPropagationWait,
+			FlgDNSAccountPropagationDisableANS,
+			FlgDNSAccountPropagationDisableRNS,
+			FlgDNSAccountPropagationDNSSEC,
+		)...,
+	)
+
+	return flags
+}
+
+func createDNSPropagationFlags(category, flgWait, flgANS, flgRNS, flgDNSSEC string) []cli.Flag {
 	return []cli.Flag{
 		&cli.BoolFlag{
 			Category: category,
@@ -498,6 +736,12 @@ func createDNSPropagationFlags(category, flgWait, flgANS, flgRNS string) []cli.F
 			Sources:  cli.EnvVars(toEnvName(flgRNS)),
 			Usage:    "By setting this flag to true, disables the need to await propagation of the TXT record to all recursive name servers (aka resolvers).",
 		},
+		&cli.BoolFlag{
+			Category: category,
+			Name:     flgDNSSEC,
+			Sources:  cli.EnvVars(toEnvName(flgDNSSEC)),
+			Usage:    "By setting this flag to true, requires valid DNSSEC signatures for the TXT record and its CNAME chain (validated up to the root trust anchors).",
+		},
 		&cli.DurationFlag{
 			Category: category,
 			Name:     flgWait,
@@ -648,6 +892,25 @@ func createObtainFlags() []cli.Flag {
 			Sources:  cli.EnvVars(toEnvName(FlgAlwaysDeactivateAuthorizations)),
 			Usage:    "Force the authorizations to be relinquished even if the certificate request was successful.",
 		},
+		&cli.BoolFlag{
+			Category: categoryAdvanced,
+			Name:     FlgChallengeFallback,
+			Sources:  cli.EnvVars(toEnvName(FlgChallengeFallback)),
+			Usage:    "When a challenge fails, retry with a new order and the next available challenge type (ex: dns-01 after http-01).",
+		},
+		&cli.BoolFlag{
+			Category: categoryAdvanced,
+			Name:     FlgCheckCAA,
+			Sources:  cli.EnvVars(toEnvName(FlgCheckCAA)),
+			Usage:    "Check the CAA records of the domains (RFC 8659, RFC 8657) before creating the order.",
+		},
+		&cli.DurationFlag{
+			Category: categoryAdvanced,
+			Name:     FlgMinAuthzValidity,
+			Sources:  cli.EnvVars(toEnvName(FlgMinAuthzValidity)),
+			Usage: "The minimum remaining validity of the valid authorizations reused by the server (ex: 72h)." +
+				" The authorizations expiring sooner are deactivated, and the challenges are solved again. By default, all the valid authorizations are reused.",
+		},
 	}
 }
 
@@ -810,6 +1073,23 @@ func createLogFlags() []cli.Flag {
 			Usage:    "Set the logging format. Supported values: 'colored', 'text', 'json'.",
 			Value:    "colored",
 		},
+		&cli.StringFlag{
+			Category: categoryLogs,
+			Name:     FlgLogEvents,
+			Sources:  cli.EnvVars(toEnvName(FlgLogEvents)),
+			Usage:    "Write the issuance events as JSON lines to a file, or to a socket ('unix:///path/to/socket', 'tcp://host:port').",
+		},
+	}
+}
+
+func createMetricsFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Category: categoryMetrics,
+			Name:     FlgMetricsAddress,
+			Sources:  cli.EnvVars(toEnvName(FlgMetricsAddress)),
+			Usage:    "Serve the Prometheus metrics on this address (e.g. ':9090'), on the '/metrics' path. By default, the metrics are disabled.",
+		},
 	}
 }
 
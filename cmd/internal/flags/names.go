@@ -10,6 +10,7 @@ const (
 	categoryTLSALPN01Challenge    = "Flags related to the TLS-ALPN-01 challenge:"
 	categoryDNS01Challenge        = "Flags related to the DNS-01 challenge:"
 	categoryDNSPersist01Challenge = "Flags related to the DNS-PERSIST-01 challenge:"
+	categoryDNSAccount01Challenge = "Flags related to the DNS-ACCOUNT-01 challenge:"
 	categoryStorage               = "Flags related to the storage:"
 	categoryHooks                 = "Flags related to hooks:"
 	categoryEAB                   = "Flags related to External Account Binding:"
@@ -17,7 +18,12 @@ const (
 	categoryAdvanced              = "Flags related to advanced options:"
 	categoryRenew                 = "Flags related to certificate renewal:"
 	categoryLogs                  = "Flags related to logs:"
+	categoryMetrics               = "Flags related to metrics:"
 	categoryConfiguration         = "Flags related to the configuration file:"
+	categoryAcmeDNSServer         = "Flags related to the acme-dns server:"
+	categoryCAA                   = "Flags related to the CAA records:"
+	categoryPlan                  = "Flags related to the planning:"
+	categoryAuthorizations        = "Flags related to the authorizations:"
 )
 
 // Flag aliases (short-codes).
@@ -54,6 +60,9 @@ const (
 	FlgPreferredChain                 = "preferred-chain"
 	FlgProfile                        = "profile"
 	FlgAlwaysDeactivateAuthorizations = "always-deactivate-authorizations"
+	FlgChallengeFallback              = "challenge-fallback"
+	FlgCheckCAA                       = "check-caa"
+	FlgMinAuthzValidity               = "min-authz-validity"
 )
 
 // Flag names related to the storage.
@@ -112,6 +121,7 @@ const (
 	FlgDNSPropagationWait       = "dns.propagation.wait"
 	FlgDNSPropagationDisableANS = "dns.propagation.disable-ans"
 	FlgDNSPropagationDisableRNS = "dns.propagation.disable-rns"
+	FlgDNSPropagationDNSSEC     = "dns.propagation.dnssec"
 	FlgDNSResolvers             = "dns.resolvers"
 	FlgDNSTimeout               = "dns.timeout"
 )
@@ -124,10 +134,22 @@ const (
 	FlgDNSPersistPropagationWait       = "dns-persist.propagation.wait"
 	FlgDNSPersistPropagationDisableANS = "dns-persist.propagation.disable-ans"
 	FlgDNSPersistPropagationDisableRNS = "dns-persist.propagation.disable-rns"
+	FlgDNSPersistPropagationDNSSEC     = "dns-persist.propagation.dnssec"
 	FlgDNSPersistResolvers             = "dns-persist.resolvers"
 	FlgDNSPersistTimeout               = "dns-persist.timeout"
 )
 
+// Flag names related to the DNS-ACCOUNT-01 challenge.
+const (
+	FlgDNSAccount                      = "dns-account"
+	FlgDNSAccountPropagationWait       = "dns-account.propagation.wait"
+	FlgDNSAccountPropagationDisableANS = "dns-account.propagation.disable-ans"
+	FlgDNSAccountPropagationDisableRNS = "dns-account.propagation.disable-rns"
+	FlgDNSAccountPropagationDNSSEC     = "dns-account.propagation.dnssec"
+	FlgDNSAccountResolvers             = "dns-account.resolvers"
+	FlgDNSAccountTimeout               = "dns-account.timeout"
+)
+
 // Flags names related to hooks.
 const (
 	FlgPreHook           = "pre-hook"
@@ -142,6 +164,12 @@ const (
 const (
 	FlgLogLevel  = "log.level"
 	FlgLogFormat = "log.format"
+	FlgLogEvents = "log.events"
+)
+
+// Flag names related to metrics.
+const (
+	FlgMetricsAddress = "metrics.address"
 )
 
 // Flag names related to the configuration file.
@@ -181,6 +209,31 @@ const (
 	FlgAccountOnly = "account-only"
 )
 
+// Flag names related to the caa command.
+const (
+	FlgCAAProvider = "caa.provider"
+	FlgCAAApply    = "caa.apply"
+)
+
+// Flag names related to the plan command.
+const (
+	FlgPlanInventory                = "plan.inventory"
+	FlgPlanMaxSANs                  = "plan.max-sans"
+	FlgPlanGroupByRegistrableDomain = "plan.group-by-registrable-domain"
+	FlgPlanWildcardThreshold        = "plan.wildcard-threshold"
+	FlgPlanChallenge                = "plan.challenge"
+	FlgPlanAccount                  = "plan.account"
+)
+
+// Flag names related to the acmedns-server command.
+const (
+	FlgAcmeDNSZone                = "acmedns.zone"
+	FlgAcmeDNSNameserver          = "acmedns.nameserver"
+	FlgAcmeDNSDNSAddress          = "acmedns.dns-address"
+	FlgAcmeDNSAPIAddress          = "acmedns.api-address"
+	FlgAcmeDNSDisableRegistration = "acmedns.disable-registration"
+)
+
 func toEnvName(flg string) string {
 	fields := strings.FieldsFunc(flg, func(r rune) bool {
 		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
@@ -188,3 +241,8 @@ func toEnvName(flg string) string {
 
 	return "LEGO_" + strings.ToUpper(strings.Join(fields, "_"))
 }
+
+// Flag names related to the authorizations.
+const (
+	FlgAuthzURL = "authz.url"
+)
@@ -39,6 +39,44 @@ func RunFlagsValidation(ctx context.Context, cmd *cli.Command) (context.Context,
 	return ctx, validateNetworkStack(cmd)
 }
 
+func CAAFlagsValidation(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+	if len(cmd.StringSlice(FlgDomains)) == 0 {
+		return ctx, fmt.Errorf("please specify '--%s'/'-%s'", FlgDomains, flgAliasDomains)
+	}
+
+	if cmd.Bool(FlgCAAApply) && !cmd.IsSet(FlgCAAProvider) {
+		return ctx, fmt.Errorf("'--%s' requires '--%s'", FlgCAAApply, FlgCAAProvider)
+	}
+
+	err := validateChallengeRequirements(cmd)
+	if err != nil {
+		return ctx, err
+	}
+
+	return ctx, validateNetworkStack(cmd)
+}
+
+func PreAuthorizeFlagsValidation(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+	if len(cmd.StringSlice(FlgDomains)) == 0 {
+		return ctx, fmt.Errorf("please specify '--%s'/'-%s'", FlgDomains, flgAliasDomains)
+	}
+
+	err := validateChallengeRequirements(cmd)
+	if err != nil {
+		return ctx, err
+	}
+
+	return ctx, validateNetworkStack(cmd)
+}
+
+func AuthorizationsFlagsValidation(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+	if len(cmd.StringSlice(FlgCertName)) == 0 && len(cmd.StringSlice(FlgAuthzURL)) == 0 {
+		return ctx, fmt.Errorf("please specify '--%s'/'-%s' or '--%s'", FlgCertName, flgAliasCertName, FlgAuthzURL)
+	}
+
+	return ctx, nil
+}
+
 func validateNetworkStack(cmd *cli.Command) error {
 	if cmd.Bool(FlgIPv4Only) && cmd.Bool(FlgIPv6Only) {
 		return fmt.Errorf("cannot specify both '--%s' and '--%s'", FlgIPv4Only, FlgIPv6Only)
@@ -48,20 +86,27 @@ func validateNetworkStack(cmd *cli.Command) error {
 }
 
 func validateChallengeRequirements(cmd *cli.Command) error {
-	if !cmd.Bool(FlgHTTP) && !cmd.Bool(FlgTLS) && !cmd.IsSet(FlgDNS) && !cmd.Bool(FlgDNSPersist) {
-		return fmt.Errorf("no challenge selected: you must specify at least one challenge: '--%s', '--%s', '--%s', '--%s'",
-			FlgHTTP, FlgTLS, FlgDNS, FlgDNSPersist)
+	if !cmd.Bool(FlgHTTP) && !cmd.Bool(FlgTLS) && !cmd.IsSet(FlgDNS) && !cmd.Bool(FlgDNSPersist) && !cmd.IsSet(FlgDNSAccount) {
+		return fmt.Errorf("no challenge selected: you must specify at least one challenge: '--%s', '--%s', '--%s', '--%s', '--%s'",
+			FlgHTTP, FlgTLS, FlgDNS, FlgDNSPersist, FlgDNSAccount)
 	}
 
 	if isSetBool(cmd, FlgDNS) {
-		err := validatePropagationExclusiveOptions(cmd, FlgDNSPropagationWait, FlgDNSPropagationDisableANS, FlgDNSPropagationDisableRNS)
+		err := validatePropagationExclusiveOptions(cmd, FlgDNSPropagationWait, FlgDNSPropagationDisableANS, FlgDNSPropagationDisableRNS, FlgDNSPropagationDNSSEC)
 		if err != nil {
 			return err
 		}
 	}
 
 	if isSetBool(cmd, FlgDNSPersist) {
-		err := validatePropagationExclusiveOptions(cmd, FlgDNSPersistPropagationWait, FlgDNSPersistPropagationDisableANS, FlgDNSPersistPropagationDisableRNS)
+		err := validatePropagationExclusiveOptions(cmd, FlgDNSPersistPropagationWait, FlgDNSPersistPropagationDisableANS, FlgDNSPersistPropagationDisableRNS, FlgDNSPersistPropagationDNSSEC)
+		if err != nil {
+			return err
+		}
+	}
+
+	if cmd.IsSet(FlgDNSAccount) {
+		err := validatePropagationExclusiveOptions(cmd, FlgDNSAccountPropagationWait, FlgDNSAccountPropagationDisableANS, FlgDNSAccountPropagationDisableRNS, FlgDNSAccountPropagationDNSSEC)
 		if err != nil {
 			return err
 		}
@@ -70,7 +115,7 @@ func validateChallengeRequirements(cmd *cli.Command) error {
 	return nil
 }
 
-func validatePropagationExclusiveOptions(cmd *cli.Command, flgWait, flgANS, flgRNS string) error {
+func validatePropagationExclusiveOptions(cmd *cli.Command, flgWait, flgANS, flgRNS, flgDNSSEC string) error {
 	if !cmd.IsSet(flgWait) {
 		return nil
 	}
@@ -83,6 +128,10 @@ func validatePropagationExclusiveOptions(cmd *cli.Command, flgWait, flgANS, flgR
 		return fmt.Errorf("'--%s' and '--%s' are mutually exclusive", flgWait, flgRNS)
 	}
 
+	if isSetBool(cmd, flgDNSSEC) {
+		return fmt.Errorf("'--%s' and '--%s' are mutually exclusive", flgWait, flgDNSSEC)
+	}
+
 	return nil
 }
 
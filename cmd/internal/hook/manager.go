@@ -84,15 +84,25 @@ func (h *Manager) PreForCSR(ctx context.Context, certID string, request certific
 }
 
 // Deploy runs the deploy-hook if defined.
-func (h *Manager) Deploy(ctx context.Context, certRes *certificate.Resource, options *storage.SaveOptions) error {
+func (h *Manager) Deploy(ctx context.Context, cert *storage.Certificate, options *storage.SaveOptions) error {
 	if h.deploy == nil || h.deploy.Cmd == "" {
 		return nil
 	}
 
+	certRes := cert.Resource
+
+	// The hook needs the files on the local filesystem.
+	certsStorage, cleanUp, err := h.certsStorage.Local(certRes.ID)
+	if err != nil {
+		return fmt.Errorf("deploy hook: %w", err)
+	}
+
+	defer cleanUp()
+
 	addCertificateMetadata(h.metadata, certRes.ID, certRes.Domains, certRes.KeyType)
-	addCertificatePathsMetadata(h.metadata, certRes, h.certsStorage, options)
+	addCertificatePathsMetadata(h.metadata, cert, certsStorage, options)
 
-	err := Launch(ctx, h.deploy.Cmd, h.deploy.Timeout, h.metadata)
+	err = Launch(ctx, h.deploy.Cmd, h.deploy.Timeout, h.metadata)
 	if err != nil {
 		log.Error("Deploy hook.", log.ErrorAttr(err))
 
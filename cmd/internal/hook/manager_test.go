@@ -19,6 +19,7 @@ func Test_Manager(t *testing.T) {
 	testCases := []struct {
 		desc           string
 		options        []Option
+		keySource      string
 		metadataPre    map[string]*regexp.Regexp
 		metadataDeploy map[string]*regexp.Regexp
 	}{
@@ -70,6 +71,21 @@ func Test_Manager(t *testing.T) {
 				"LEGO_HOOK_CERT_KEY_PATH":       regexp.MustCompile(`.+[/\\]certificates[/\\]b\.key`),
 			},
 		},
+		{
+			desc: "deploy-hook only (key source)",
+			options: []Option{
+				WithDeploy("echo Deploy Hook", 1*time.Second),
+			},
+			keySource: "pkcs11:token=lego;object=example.net",
+			metadataDeploy: map[string]*regexp.Regexp{
+				"LEGO_HOOK_CERT_DOMAINS":        regexp.MustCompile(`example\.net`),
+				"LEGO_HOOK_CERT_KEY_TYPE":       regexp.MustCompile("EC384"),
+				"LEGO_HOOK_CERT_NAME":           regexp.MustCompile("b"),
+				"LEGO_HOOK_CERT_NAME_SANITIZED": regexp.MustCompile("b"),
+				"LEGO_HOOK_CERT_PATH":           regexp.MustCompile(`.+[/\\]certificates[/\\]b\.crt`),
+				"LEGO_HOOK_CERT_KEY_SOURCE":     regexp.MustCompile(`^pkcs11:token=lego;object=example\.net$`),
+			},
+		},
 		{
 			desc: "post-hook only",
 			options: []Option{
@@ -135,7 +151,7 @@ func Test_Manager(t *testing.T) {
 				KeyType: certcrypto.EC384,
 			}
 
-			err = manager.Deploy(t.Context(), resource, &storage.SaveOptions{})
+			err = manager.Deploy(t.Context(), &storage.Certificate{Resource: resource, KeySource: test.keySource}, &storage.SaveOptions{})
 			require.NoError(t, err)
 
 			t.Log("deploy", manager.metadata)
@@ -227,7 +243,7 @@ func Test_Manager_errors(t *testing.T) {
 			err := manager.PreForDomains(t.Context(), "a", request)
 			test.requirePre(t, err)
 
-			err = manager.Deploy(t.Context(), &certificate.Resource{ID: "example.org"}, &storage.SaveOptions{})
+			err = manager.Deploy(t.Context(), &storage.Certificate{Resource: &certificate.Resource{ID: "example.org"}}, &storage.SaveOptions{})
 			test.requireDeploy(t, err)
 
 			err = manager.Post(t.Context())
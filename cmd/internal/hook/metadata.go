@@ -4,7 +4,6 @@ import (
 	"strings"
 
 	"github.com/go-acme/lego/v5/certcrypto"
-	"github.com/go-acme/lego/v5/certificate"
 	"github.com/go-acme/lego/v5/cmd/internal/storage"
 )
 
@@ -25,6 +24,7 @@ const (
 	EnvCertDomains       = envPrefix + "CERT_DOMAINS"
 	EnvCertPath          = envPrefix + "CERT_PATH"
 	EnvCertKeyPath       = envPrefix + "CERT_KEY_PATH"
+	EnvCertKeySource     = envPrefix + "CERT_KEY_SOURCE"
 	EnvIssuerCertKeyPath = envPrefix + "ISSUER_CERT_PATH"
 	EnvCertPEMPath       = envPrefix + "CERT_PEM_PATH"
 	EnvCertPFXPath       = envPrefix + "CERT_PFX_PATH"
@@ -36,9 +36,19 @@ func addAccountMetadata(meta map[string]string, account *storage.Account) {
 	meta[EnvAccountServer] = account.Server
 }
 
-func addCertificatePathsMetadata(meta map[string]string, certRes *certificate.Resource, certsStorage *storage.CertificatesStorage, options *storage.SaveOptions) {
+func addCertificatePathsMetadata(meta map[string]string, cert *storage.Certificate, certsStorage *storage.CertificatesStorage, options *storage.SaveOptions) {
+	certRes := cert.Resource
+
 	meta[EnvCertPath] = certsStorage.GetFileName(certRes.ID, storage.ExtCert)
-	meta[EnvCertKeyPath] = certsStorage.GetFileName(certRes.ID, storage.ExtKey)
+
+	// The private key held by a token is not saved: only the reference to the key is available.
+	if cert.KeySource != "" {
+		meta[EnvCertKeySource] = cert.KeySource
+		delete(meta, EnvCertKeyPath)
+	} else {
+		meta[EnvCertKeyPath] = certsStorage.GetFileName(certRes.ID, storage.ExtKey)
+		delete(meta, EnvCertKeySource)
+	}
 
 	if certRes.IssuerCertificate != nil {
 		meta[EnvIssuerCertKeyPath] = certsStorage.GetFileName(certRes.ID, storage.ExtIssuer)
@@ -0,0 +1,81 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/go-acme/lego/v5/certcrypto"
+	"github.com/go-acme/lego/v5/certificate"
+	"github.com/go-acme/lego/v5/log"
+	"github.com/go-acme/lego/v5/metrics"
+	legoprometheus "github.com/go-acme/lego/v5/metrics/prometheus"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// RecordCertificateExpiry records the expiry of the certificate of a resource.
+func RecordCertificateExpiry(certRes *certificate.Resource) {
+	cert, err := certcrypto.ParsePEMCertificate(certRes.Certificate)
+	if err != nil {
+		log.Debug("Unable to parse the certificate for the metrics.", log.CertNameAttr(certRes.ID), log.ErrorAttr(err))
+		return
+	}
+
+	metrics.Default().SetCertificateExpiry(certRes.ID, cert.NotAfter)
+}
+
+// ServeMetrics serves the Prometheus metrics on the address (`/metrics`),
+// and sets the default metrics recorder.
+// The returned function stops the server.
+func ServeMetrics(address string) (func(), error) {
+	registry := prometheus.NewRegistry()
+
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	recorder, err := legoprometheus.NewRecorder(registry)
+	if err != nil {
+		return nil, fmt.Errorf("metrics: %w", err)
+	}
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("metrics: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+
+	server := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		errS := server.Serve(listener)
+		if errS != nil && !errors.Is(errS, http.ErrServerClosed) {
+			log.Error("The metrics server has stopped.", log.ErrorAttr(errS))
+		}
+	}()
+
+	metrics.SetDefault(recorder)
+
+	log.Info("Serving the metrics.", slog.String("address", listener.Addr().String()))
+
+	return func() {
+		metrics.SetDefault(nil)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		_ = server.Shutdown(ctx)
+	}, nil
+}
@@ -0,0 +1,200 @@
+// Package multiplexer implements a DNS provider which routes the challenges to other DNS providers based on the zone of the domain.
+// It is used by the DNS routes of the configuration file (the "routes" option of the DNS challenges).
+package multiplexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-acme/lego/v5/challenge"
+	"github.com/go-acme/lego/v5/challenge/dns01"
+)
+
+var _ challenge.ProviderTimeout = (*DNSProvider)(nil)
+
+// Route associates a zone suffix with a DNS provider.
+type Route struct {
+	// Zone is the suffix of the zones handled by the provider (ex: `example.com`).
+	Zone string
+	// Provider is the DNS provider of the zones.
+	Provider challenge.Provider
+}
+
+// Config Provider configuration.
+type Config struct {
+	Routes []Route
+}
+
+// DNSProvider implements the challenge.Provider interface.
+type DNSProvider struct {
+	routes []Route
+}
+
+// NewDNSProviderConfig returns a new DNS provider which routes the challenges to the providers of the configuration.
+//
+// The provider of a challenge is the one with the longest zone suffix matching the zone of the domain.
+// The zone of the domain is found with dns01.Client.FindZoneByFqdn.
+//
+// If at least one of the providers requires a sequential resolution,
+// the returned provider also implements the `Sequential() time.Duration` method.
+func NewDNSProviderConfig(config *Config) (challenge.ProviderTimeout, error) {
+	if config == nil {
+		return nil, errors.New("multiplexer: the configuration is nil")
+	}
+
+	if len(config.Routes) == 0 {
+		return nil, errors.New("multiplexer: no routes")
+	}
+
+	d := &DNSProvider{}
+
+	zones := make(map[string]struct{})
+
+	for i, route := range config.Routes {
+		zone := normalizeZone(route.Zone)
+		if zone == "" {
+			return nil, fmt.Errorf("multiplexer: route %d: the zone is required", i)
+		}
+
+		if route.Provider == nil {
+			return nil, fmt.Errorf("multiplexer: route %d (%s): the provider is required", i, route.Zone)
+		}
+
+		if _, ok := zones[zone]; ok {
+			return nil, fmt.Errorf("multiplexer: duplicate zone: %s", route.Zone)
+		}
+
+		zones[zone] = struct{}{}
+
+		d.routes = append(d.routes, Route{Zone: zone, Provider: route.Provider})
+	}
+
+	if interval, ok := d.sequenceInterval(); ok {
+		return &sequentialDNSProvider{DNSProvider: d, interval: interval}, nil
+	}
+
+	return d, nil
+}
+
+// Present creates a TXT record to fulfill the dns-01 challenge.
+func (d *DNSProvider) Present(ctx context.Context, domain, token, keyAuth string) error {
+	provider, err := d.findProvider(ctx, domain, keyAuth)
+	if err != nil {
+		return fmt.Errorf("multiplexer: %w", err)
+	}
+
+	return provider.Present(ctx, domain, token, keyAuth)
+}
+
+// CleanUp removes the TXT record matching the specified parameters.
+func (d *DNSProvider) CleanUp(ctx context.Context, domain, token, keyAuth string) error {
+	provider, err := d.findProvider(ctx, domain, keyAuth)
+	if err != nil {
+		return fmt.Errorf("multiplexer: %w", err)
+	}
+
+	return provider.CleanUp(ctx, domain, token, keyAuth)
+}
+
+// Timeout returns the timeout and interval to use when checking for DNS propagation.
+// The timeout is the longest timeout of the providers, and the interval is the shortest interval of the providers.
+func (d *DNSProvider) Timeout() (timeout, interval time.Duration) {
+	for _, route := range d.routes {
+		t, i := dns01.DefaultPropagationTimeout, dns01.DefaultPollingInterval
+
+		if p, ok := route.Provider.(challenge.ProviderTimeout); ok {
+			t, i = p.Timeout()
+		}
+
+		timeout = max(timeout, t)
+
+		if interval == 0 || i < interval {
+			interval = i
+		}
+	}
+
+	return timeout, interval
+}
+
+func (d *DNSProvider) findProvider(ctx context.Context, domain, keyAuth string) (challenge.Provider, error) {
+	info := dns01.GetChallengeInfo(ctx, domain, keyAuth)
+
+	authZone, err := dns01.DefaultClient().FindZoneByFqdn(ctx, info.EffectiveFQDN)
+	if err != nil {
+		return nil, fmt.Errorf("could not find zone for domain %q: %w", domain, err)
+	}
+
+	route, ok := matchRoute(d.routes, authZone)
+	if !ok {
+		return nil, fmt.Errorf("no provider for the zone %q (domain %q)", dns01.UnFqdn(authZone), domain)
+	}
+
+	return route.Provider, nil
+}
+
+// sequenceInterval returns the longest sequence interval of the providers requiring a sequential resolution.
+func (d *DNSProvider) sequenceInterval() (time.Duration, bool) {
+	var (
+		interval time.Duration
+		found    bool
+	)
+
+	for _, route := range d.routes {
+		p, ok := route.Provider.(sequential)
+		if !ok {
+			continue
+		}
+
+		found = true
+		interval = max(interval, p.Sequential())
+	}
+
+	return interval, found
+}
+
+// sequentialDNSProvider is a DNSProvider with at least one provider requiring a sequential resolution.
+type sequentialDNSProvider struct {
+	*DNSProvider
+
+	interval time.Duration
+}
+
+// Sequential All DNS challenges for this provider will be resolved sequentially.
+// Returns the interval between each iteration.
+func (d *sequentialDNSProvider) Sequential() time.Duration {
+	return d.interval
+}
+
+type sequential interface {
+	Sequential() time.Duration
+}
+
+// matchRoute returns the route with the longest zone suffix matching the zone.
+func matchRoute(routes []Route, zone string) (Route, bool) {
+	zone = normalizeZone(zone)
+
+	var (
+		match Route
+		found bool
+	)
+
+	for _, route := range routes {
+		if zone != route.Zone && !strings.HasSuffix(zone, "."+route.Zone) {
+			continue
+		}
+
+		if !found || len(route.Zone) > len(match.Zone) {
+			match = route
+			found = true
+		}
+	}
+
+	return match, found
+}
+
+func normalizeZone(zone string) string {
+	return strings.ToLower(dns01.UnFqdn(strings.TrimSpace(zone)))
+}
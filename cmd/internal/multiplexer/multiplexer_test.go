@@ -0,0 +1,212 @@
+package multiplexer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v5/challenge"
+	"github.com/go-acme/lego/v5/challenge/dns01"
+	"github.com/go-acme/lego/v5/internal/tester/dnsmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeProvider struct {
+	presented []string
+	cleaned   []string
+}
+
+func (f *fakeProvider) Present(_ context.Context, domain, _, _ string) error {
+	f.presented = append(f.presented, domain)
+	return nil
+}
+
+func (f *fakeProvider) CleanUp(_ context.Context, domain, _, _ string) error {
+	f.cleaned = append(f.cleaned, domain)
+	return nil
+}
+
+type fakeTimeoutProvider struct {
+	fakeProvider
+
+	timeout, interval time.Duration
+}
+
+func (f *fakeTimeoutProvider) Timeout() (timeout, interval time.Duration) {
+	return f.timeout, f.interval
+}
+
+type fakeSequentialProvider struct {
+	fakeProvider
+
+	sequence time.Duration
+}
+
+func (f *fakeSequentialProvider) Sequential() time.Duration {
+	return f.sequence
+}
+
+func TestNewDNSProviderConfig(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		config   *Config
+		expected string
+	}{
+		{
+			desc:     "nil config",
+			expected: "multiplexer: the configuration is nil",
+		},
+		{
+			desc:     "no routes",
+			config:   &Config{},
+			expected: "multiplexer: no routes",
+		},
+		{
+			desc:     "missing zone",
+			config:   &Config{Routes: []Route{{Provider: &fakeProvider{}}}},
+			expected: "multiplexer: route 0: the zone is required",
+		},
+		{
+			desc:     "missing provider",
+			config:   &Config{Routes: []Route{{Zone: "example.com"}}},
+			expected: "multiplexer: route 0 (example.com): the provider is required",
+		},
+		{
+			desc: "duplicate zone",
+			config: &Config{Routes: []Route{
+				{Zone: "example.com", Provider: &fakeProvider{}},
+				{Zone: "Example.com.", Provider: &fakeProvider{}},
+			}},
+			expected: "multiplexer: duplicate zone: Example.com.",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewDNSProviderConfig(test.config)
+			require.EqualError(t, err, test.expected)
+		})
+	}
+}
+
+func TestDNSProvider_Timeout(t *testing.T) {
+	provider, err := NewDNSProviderConfig(&Config{Routes: []Route{
+		{Zone: "example.com", Provider: &fakeTimeoutProvider{timeout: 10 * time.Minute, interval: 10 * time.Second}},
+		{Zone: "example.org", Provider: &fakeTimeoutProvider{timeout: 2 * time.Minute, interval: 5 * time.Second}},
+		{Zone: "example.net", Provider: &fakeProvider{}},
+	}})
+	require.NoError(t, err)
+
+	timeout, interval := provider.Timeout()
+
+	assert.Equal(t, 10*time.Minute, timeout)
+	assert.Equal(t, dns01.DefaultPollingInterval, interval)
+}
+
+func TestDNSProvider_Sequential(t *testing.T) {
+	provider, err := NewDNSProviderConfig(&Config{Routes: []Route{
+		{Zone: "example.com", Provider: &fakeProvider{}},
+	}})
+	require.NoError(t, err)
+
+	assert.NotImplements(t, (*sequential)(nil), provider)
+
+	provider, err = NewDNSProviderConfig(&Config{Routes: []Route{
+		{Zone: "example.com", Provider: &fakeProvider{}},
+		{Zone: "example.org", Provider: &fakeSequentialProvider{sequence: time.Minute}},
+		{Zone: "example.net", Provider: &fakeSequentialProvider{sequence: 2 * time.Minute}},
+	}})
+	require.NoError(t, err)
+
+	require.Implements(t, (*sequential)(nil), provider)
+
+	assert.Equal(t, 2*time.Minute, provider.(sequential).Sequential())
+}
+
+func TestDNSProvider_Present(t *testing.T) {
+	mockDefault(t)
+
+	providerCom := &fakeProvider{}
+	providerSub := &fakeProvider{}
+	providerOrg := &fakeProvider{}
+
+	provider, err := NewDNSProviderConfig(&Config{Routes: []Route{
+		{Zone: "example.com", Provider: providerCom},
+		{Zone: "sub.example.com", Provider: providerSub},
+		{Zone: "example.org", Provider: providerOrg},
+	}})
+	require.NoError(t, err)
+
+	for _, domain := range []string{"example.com", "www.example.com", "a.sub.example.com", "example.org"} {
+		require.NoError(t, provider.Present(t.Context(), domain, "token", "keyAuth"))
+		require.NoError(t, provider.CleanUp(t.Context(), domain, "token", "keyAuth"))
+	}
+
+	assert.Equal(t, []string{"example.com", "www.example.com"}, providerCom.presented)
+	assert.Equal(t, []string{"a.sub.example.com"}, providerSub.presented)
+	assert.Equal(t, []string{"example.org"}, providerOrg.presented)
+
+	assert.Equal(t, providerCom.presented, providerCom.cleaned)
+	assert.Equal(t, providerSub.presented, providerSub.cleaned)
+	assert.Equal(t, providerOrg.presented, providerOrg.cleaned)
+
+	err = provider.Present(t.Context(), "example.net", "token", "keyAuth")
+	require.EqualError(t, err, `multiplexer: no provider for the zone "example.net" (domain "example.net")`)
+}
+
+func Test_matchRoute(t *testing.T) {
+	routes := []Route{
+		{Zone: "example.com"},
+		{Zone: "sub.example.com"},
+	}
+
+	testCases := []struct {
+		zone     string
+		expected string
+		found    bool
+	}{
+		{zone: "example.com.", expected: "example.com", found: true},
+		{zone: "EXAMPLE.com.", expected: "example.com", found: true},
+		{zone: "foo.example.com.", expected: "example.com", found: true},
+		{zone: "sub.example.com.", expected: "sub.example.com", found: true},
+		{zone: "foo.sub.example.com.", expected: "sub.example.com", found: true},
+		{zone: "notexample.com."},
+		{zone: "example.org."},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.zone, func(t *testing.T) {
+			t.Parallel()
+
+			route, found := matchRoute(routes, test.zone)
+
+			assert.Equal(t, test.found, found)
+			assert.Equal(t, test.expected, route.Zone)
+		})
+	}
+}
+
+func mockDefault(t *testing.T) {
+	t.Helper()
+
+	addr := dnsmock.NewServer().
+		Query("sub.example.com.", dnsmock.SOA("sub.example.com.")).
+		Query("example.com.", dnsmock.SOA("example.com.")).
+		Query("example.org.", dnsmock.SOA("example.org.")).
+		Query("example.net.", dnsmock.SOA("example.net.")).
+		Build(t)
+
+	backup := dns01.DefaultClient()
+
+	t.Cleanup(func() {
+		dns01.SetDefaultClient(backup)
+	})
+
+	dns01.SetDefaultClient(dns01.NewClient(&dns01.Options{
+		RecursiveNameservers: []string{addr.String()},
+		NetworkStack:         challenge.IPv4Only,
+	}))
+}
@@ -0,0 +1,170 @@
+package pkcs11
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	pkcs11uri "github.com/stefanberger/go-pkcs11uri"
+)
+
+// Scheme is the scheme of the PKCS#11 URIs (RFC 7512).
+const Scheme = "pkcs11:"
+
+// Environment variables used when the PKCS#11 URI doesn't define the module or the PIN.
+const (
+	EnvModule = "LEGO_PKCS11_MODULE"
+	EnvPIN    = "LEGO_PKCS11_PIN"
+)
+
+// IsURI returns true if the value is a PKCS#11 URI.
+func IsURI(value string) bool {
+	return strings.HasPrefix(value, Scheme)
+}
+
+// KeyReference returns the PKCS#11 URI without the PIN value.
+// It can be stored to reference the key without leaking the PIN.
+func KeyReference(uri string) string {
+	base, query, found := strings.Cut(uri, "?")
+	if !found {
+		return uri
+	}
+
+	var attrs []string
+
+	for attr := range strings.SplitSeq(query, "&") {
+		if !strings.HasPrefix(attr, "pin-value=") {
+			attrs = append(attrs, attr)
+		}
+	}
+
+	if len(attrs) == 0 {
+		return base
+	}
+
+	return base + "?" + strings.Join(attrs, "&")
+}
+
+// Signer is a private key held by a PKCS#11 token.
+// The private key never leaves the token: the signatures are computed by the token.
+type Signer struct {
+	crypto.Signer
+
+	closer io.Closer
+}
+
+// Close releases the session with the token.
+func (s *Signer) Close() error {
+	if s.closer == nil {
+		return nil
+	}
+
+	return s.closer.Close()
+}
+
+// keyReference is the information extracted from a PKCS#11 URI.
+type keyReference struct {
+	module string
+	pin    string
+
+	tokenLabel  string
+	tokenSerial string
+	slotID      *int
+
+	id    []byte
+	label []byte
+}
+
+// parseURI parses a PKCS#11 URI.
+//
+// Example: pkcs11:token=lego;object=account?module-path=/usr/lib/softhsm/libsofthsm2.so&pin-value=1234
+func parseURI(raw string) (*keyReference, error) {
+	uri := pkcs11uri.New()
+	uri.SetAllowAnyModule(true)
+
+	err := uri.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("pkcs11: invalid URI: %w", err)
+	}
+
+	ref := &keyReference{}
+
+	ref.module, err = getModule(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	ref.pin, err = getPIN(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	if v, ok := uri.GetPathAttribute("token", false); ok {
+		ref.tokenLabel = v
+	}
+
+	if v, ok := uri.GetPathAttribute("serial", false); ok {
+		ref.tokenSerial = v
+	}
+
+	if v, ok := uri.GetPathAttribute("slot-id", false); ok {
+		slotID, errA := strconv.Atoi(v)
+		if errA != nil {
+			return nil, fmt.Errorf("pkcs11: invalid slot-id %q: %w", v, errA)
+		}
+
+		ref.slotID = &slotID
+	}
+
+	if ref.tokenLabel == "" && ref.tokenSerial == "" && ref.slotID == nil {
+		return nil, errors.New("pkcs11: the URI must define the token (token, serial, or slot-id)")
+	}
+
+	if v, ok := uri.GetPathAttribute("id", false); ok {
+		ref.id = []byte(v)
+	}
+
+	if v, ok := uri.GetPathAttribute("object", false); ok {
+		ref.label = []byte(v)
+	}
+
+	if len(ref.id) == 0 && len(ref.label) == 0 {
+		return nil, errors.New("pkcs11: the URI must define the key (id or object)")
+	}
+
+	return ref, nil
+}
+
+func getModule(uri *pkcs11uri.Pkcs11URI) (string, error) {
+	if _, ok := uri.GetQueryAttribute("module-path", false); ok {
+		module, err := uri.GetModule()
+		if err != nil {
+			return "", fmt.Errorf("pkcs11: %w", err)
+		}
+
+		return module, nil
+	}
+
+	if module := os.Getenv(EnvModule); module != "" {
+		return module, nil
+	}
+
+	return "", fmt.Errorf("pkcs11: the URI must define the module (module-path) or the environment variable %s must be set", EnvModule)
+}
+
+func getPIN(uri *pkcs11uri.Pkcs11URI) (string, error) {
+	if uri.HasPIN() {
+		pin, err := uri.GetPIN()
+		if err != nil {
+			return "", fmt.Errorf("pkcs11: %w", err)
+		}
+
+		return strings.TrimRight(pin, "\r\n"), nil
+	}
+
+	return os.Getenv(EnvPIN), nil
+}
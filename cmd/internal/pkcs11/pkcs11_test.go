@@ -0,0 +1,151 @@
+package pkcs11
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseURI(t *testing.T) {
+	module := filepath.Join(t.TempDir(), "libsofthsm2.so")
+
+	err := os.WriteFile(module, []byte("module"), 0o600)
+	require.NoError(t, err)
+
+	pinFile := filepath.Join(t.TempDir(), "pin.txt")
+
+	err = os.WriteFile(pinFile, []byte("5678\n"), 0o600)
+	require.NoError(t, err)
+
+	slotID := 1
+
+	testCases := []struct {
+		desc     string
+		uri      string
+		expected *keyReference
+	}{
+		{
+			desc: "token and object",
+			uri:  "pkcs11:token=lego;object=account?module-path=" + module + "&pin-value=1234",
+			expected: &keyReference{
+				module:     module,
+				pin:        "1234",
+				tokenLabel: "lego",
+				label:      []byte("account"),
+			},
+		},
+		{
+			desc: "slot and id",
+			uri:  "pkcs11:slot-id=1;id=%01%02?module-path=" + module + "&pin-source=file:" + pinFile,
+			expected: &keyReference{
+				module: module,
+				pin:    "5678",
+				slotID: &slotID,
+				id:     []byte{1, 2},
+			},
+		},
+		{
+			desc: "serial",
+			uri:  "pkcs11:serial=abc;object=account?module-path=" + module,
+			expected: &keyReference{
+				module:      module,
+				tokenSerial: "abc",
+				label:       []byte("account"),
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			ref, err := parseURI(test.uri)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, ref)
+		})
+	}
+}
+
+func Test_parseURI_errors(t *testing.T) {
+	module := filepath.Join(t.TempDir(), "libsofthsm2.so")
+
+	err := os.WriteFile(module, []byte("module"), 0o600)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		desc     string
+		uri      string
+		expected string
+	}{
+		{
+			desc:     "not a PKCS#11 URI",
+			uri:      "file:/tmp/account.key",
+			expected: "pkcs11: invalid URI: Malformed pkcs11 URI: missing pcks11: prefix",
+		},
+		{
+			desc:     "missing module",
+			uri:      "pkcs11:token=lego;object=account",
+			expected: "pkcs11: the URI must define the module (module-path) or the environment variable LEGO_PKCS11_MODULE must be set",
+		},
+		{
+			desc:     "missing token",
+			uri:      "pkcs11:object=account?module-path=" + module,
+			expected: "pkcs11: the URI must define the token (token, serial, or slot-id)",
+		},
+		{
+			desc:     "missing key",
+			uri:      "pkcs11:token=lego?module-path=" + module,
+			expected: "pkcs11: the URI must define the key (id or object)",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Setenv(EnvModule, "")
+
+			_, err := parseURI(test.uri)
+			require.EqualError(t, err, test.expected)
+		})
+	}
+}
+
+func TestKeyReference(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		uri      string
+		expected string
+	}{
+		{
+			desc:     "without query",
+			uri:      "pkcs11:token=lego;object=cert",
+			expected: "pkcs11:token=lego;object=cert",
+		},
+		{
+			desc:     "PIN value only",
+			uri:      "pkcs11:token=lego;object=cert?pin-value=1234",
+			expected: "pkcs11:token=lego;object=cert",
+		},
+		{
+			desc:     "PIN value and module",
+			uri:      "pkcs11:token=lego;object=cert?pin-value=1234&module-path=/usr/lib/softhsm/libsofthsm2.so",
+			expected: "pkcs11:token=lego;object=cert?module-path=/usr/lib/softhsm/libsofthsm2.so",
+		},
+		{
+			desc:     "PIN source",
+			uri:      "pkcs11:token=lego;object=cert?pin-source=/run/secrets/pin",
+			expected: "pkcs11:token=lego;object=cert?pin-source=/run/secrets/pin",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, KeyReference(test.uri))
+		})
+	}
+}
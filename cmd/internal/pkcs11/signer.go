@@ -0,0 +1,130 @@
+//go:build cgo
+
+package pkcs11
+
+import (
+	"crypto/elliptic"
+	"errors"
+	"fmt"
+
+	"github.com/ThalesGroup/crypto11"
+	"github.com/go-acme/lego/v5/certcrypto"
+)
+
+// Supported is true when lego is built with cgo, which is required by PKCS#11.
+const Supported = true
+
+// NewSigner finds the private key identified by the PKCS#11 URI.
+// The Signer must be closed to release the session with the token.
+func NewSigner(uri string) (*Signer, error) {
+	ref, err := parseURI(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, err := configure(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := ctx.FindKeyPair(ref.id, ref.label)
+	if err != nil {
+		_ = ctx.Close()
+
+		return nil, fmt.Errorf("pkcs11: find the key: %w", err)
+	}
+
+	if key == nil {
+		_ = ctx.Close()
+
+		return nil, errors.New("pkcs11: key not found")
+	}
+
+	return &Signer{Signer: key, closer: ctx}, nil
+}
+
+// GetOrGenerateSigner finds the private key identified by the PKCS#11 URI,
+// or generates it inside the token if it doesn't exist.
+// The Signer must be closed to release the session with the token.
+func GetOrGenerateSigner(uri string, keyType certcrypto.KeyType) (*Signer, error) {
+	ref, err := parseURI(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, err := configure(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := ctx.FindKeyPair(ref.id, ref.label)
+	if err != nil {
+		_ = ctx.Close()
+
+		return nil, fmt.Errorf("pkcs11: find the key: %w", err)
+	}
+
+	if key != nil {
+		return &Signer{Signer: key, closer: ctx}, nil
+	}
+
+	key, err = generateKeyPair(ctx, ref, keyType)
+	if err != nil {
+		_ = ctx.Close()
+
+		return nil, err
+	}
+
+	return &Signer{Signer: key, closer: ctx}, nil
+}
+
+func generateKeyPair(ctx *crypto11.Context, ref *keyReference, keyType certcrypto.KeyType) (crypto11.Signer, error) {
+	// The ID is required to find the key pair later.
+	id := ref.id
+	if len(id) == 0 {
+		id = ref.label
+	}
+
+	var (
+		key crypto11.Signer
+		err error
+	)
+
+	switch keyType {
+	case certcrypto.EC256:
+		key, err = ctx.GenerateECDSAKeyPairWithLabel(id, ref.label, elliptic.P256())
+	case certcrypto.EC384:
+		key, err = ctx.GenerateECDSAKeyPairWithLabel(id, ref.label, elliptic.P384())
+	case certcrypto.RSA2048:
+		key, err = ctx.GenerateRSAKeyPairWithLabel(id, ref.label, 2048)
+	case certcrypto.RSA3072:
+		key, err = ctx.GenerateRSAKeyPairWithLabel(id, ref.label, 3072)
+	case certcrypto.RSA4096:
+		key, err = ctx.GenerateRSAKeyPairWithLabel(id, ref.label, 4096)
+	case certcrypto.RSA8192:
+		key, err = ctx.GenerateRSAKeyPairWithLabel(id, ref.label, 8192)
+	default:
+		return nil, fmt.Errorf("pkcs11: unsupported key type: %s", keyType)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("pkcs11: generate the key: %w", err)
+	}
+
+	return key, nil
+}
+
+func configure(ref *keyReference) (*crypto11.Context, error) {
+	ctx, err := crypto11.Configure(&crypto11.Config{
+		Path:        ref.module,
+		TokenLabel:  ref.tokenLabel,
+		TokenSerial: ref.tokenSerial,
+		SlotNumber:  ref.slotID,
+		Pin:         ref.pin,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("pkcs11: open the token: %w", err)
+	}
+
+	return ctx, nil
+}
@@ -0,0 +1,32 @@
+//go:build !cgo
+
+package pkcs11
+
+import (
+	"errors"
+
+	"github.com/go-acme/lego/v5/certcrypto"
+)
+
+// Supported is true when lego is built with cgo, which is required by PKCS#11.
+const Supported = false
+
+var errNoCGO = errors.New("pkcs11: not supported, lego must be built with cgo")
+
+// NewSigner finds the private key identified by the PKCS#11 URI.
+// PKCS#11 requires cgo: without cgo, an error is always returned.
+func NewSigner(uri string) (*Signer, error) {
+	_, err := parseURI(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, errNoCGO
+}
+
+// GetOrGenerateSigner finds the private key identified by the PKCS#11 URI,
+// or generates it inside the token if it doesn't exist.
+// PKCS#11 requires cgo: without cgo, an error is always returned.
+func GetOrGenerateSigner(uri string, _ certcrypto.KeyType) (*Signer, error) {
+	return NewSigner(uri)
+}
@@ -0,0 +1,86 @@
+//go:build cgo
+
+package pkcs11
+
+import (
+	"crypto"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"os"
+	"testing"
+
+	"github.com/ThalesGroup/crypto11"
+	"github.com/go-acme/lego/v5/certcrypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The test requires SoftHSM (or another PKCS#11 module) with an initialized token:
+//
+//	softhsm2-util --init-token --free --label lego --pin 1234 --so-pin 1234
+//	LEGO_TEST_PKCS11_MODULE=/usr/lib/softhsm/libsofthsm2.so go test ./cmd/internal/pkcs11/
+const envTestModule = "LEGO_TEST_PKCS11_MODULE"
+
+func TestNewSigner(t *testing.T) {
+	module := os.Getenv(envTestModule)
+	if module == "" {
+		t.Skipf("%s is not defined", envTestModule)
+	}
+
+	ctx, err := crypto11.Configure(&crypto11.Config{Path: module, TokenLabel: "lego", Pin: "1234"})
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = ctx.Close() })
+
+	key, err := ctx.GenerateECDSAKeyPairWithLabel([]byte("test-signer"), []byte("test-signer"), elliptic.P256())
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = key.Delete() })
+
+	signer, err := NewSigner("pkcs11:token=lego;object=test-signer?module-path=" + module + "&pin-value=1234")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = signer.Close() })
+
+	assert.Equal(t, key.Public(), signer.Public())
+
+	digest := sha256.Sum256([]byte("lego"))
+
+	_, err = signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	require.NoError(t, err)
+}
+
+func TestGetOrGenerateSigner(t *testing.T) {
+	module := os.Getenv(envTestModule)
+	if module == "" {
+		t.Skipf("%s is not defined", envTestModule)
+	}
+
+	uri := "pkcs11:token=lego;object=test-generate?module-path=" + module + "&pin-value=1234"
+
+	signer, err := GetOrGenerateSigner(uri, certcrypto.EC384)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = signer.Close() })
+
+	keyType, err := certcrypto.GetPrivateKeyType(signer)
+	require.NoError(t, err)
+
+	assert.Equal(t, certcrypto.EC384, keyType)
+
+	// The existing key is reused.
+	existing, err := GetOrGenerateSigner(uri, certcrypto.RSA2048)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = existing.Close() })
+
+	assert.Equal(t, signer.Public(), existing.Public())
+
+	t.Cleanup(func() {
+		key, ok := existing.Signer.(crypto11.Signer)
+		if ok {
+			_ = key.Delete()
+		}
+	})
+}
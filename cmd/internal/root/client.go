@@ -0,0 +1,54 @@
+package root
+
+import (
+	"fmt"
+
+	"github.com/go-acme/lego/v5/cmd/internal/configuration"
+	"github.com/go-acme/lego/v5/cmd/internal/storage"
+	"github.com/go-acme/lego/v5/lego"
+)
+
+// NewAccountClient creates a client for a registered account of the configuration.
+// The account is read from the storage of the configuration, with its key source if any.
+// The returned function releases the storage and the resources related to the account key.
+func NewAccountClient(cfg *configuration.Configuration, accountID string) (*lego.Client, func(), error) {
+	accountConfig, ok := cfg.Accounts[accountID]
+	if !ok {
+		return nil, nil, fmt.Errorf("the account %s is not defined in the configuration", accountID)
+	}
+
+	store, err := storage.NewFromConfiguration(cfg.Storage)
+	if err != nil {
+		return nil, nil, fmt.Errorf("set up storage: %w", err)
+	}
+
+	serverConfig := configuration.GetServerConfig(cfg, accountID)
+
+	account, closeKey, err := getAccount(store.Account, accountConfig, serverConfig.URL)
+	if err != nil {
+		_ = store.Close()
+
+		return nil, nil, fmt.Errorf("set up account: %w", err)
+	}
+
+	closeFn := func() {
+		closeKey()
+
+		_ = store.Close()
+	}
+
+	if account.GetRegistration() == nil {
+		closeFn()
+
+		return nil, nil, fmt.Errorf("the account %s is not registered", account.GetID())
+	}
+
+	client, err := lego.NewClient(newClientConfig(serverConfig, account, cfg.UserAgent))
+	if err != nil {
+		closeFn()
+
+		return nil, nil, fmt.Errorf("new client: %w", err)
+	}
+
+	return client, closeFn, nil
+}
@@ -0,0 +1,164 @@
+package root
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/go-acme/lego/v5/cmd/internal/configuration"
+	"github.com/go-acme/lego/v5/cmd/internal/storage"
+	"github.com/go-acme/lego/v5/log"
+)
+
+// daemonRetryDelay is the minimum delay between two attempts to obtain/renew the same certificate.
+// It prevents a certificate in error (or always detected as changed) from being processed in a loop.
+const daemonRetryDelay = time.Hour
+
+// ConfigurationLoader loads (or reloads) the configuration.
+type ConfigurationLoader func() (*configuration.Configuration, error)
+
+// Daemon obtains and renews the certificates defined in the configuration until the context is canceled.
+//
+// The first cycle behaves like the `run` command.
+// Then, the next renewal time of each certificate is computed (ARI or renewal days),
+// and the process sleeps until the earliest one.
+//
+// The configuration is reloaded each time a signal is received on the reload channel.
+func Daemon(ctx context.Context, loader ConfigurationLoader, reload <-chan os.Signal) error {
+	cfg, err := loader()
+	if err != nil {
+		return err
+	}
+
+	d := &daemon{attempts: make(map[string]time.Time)}
+
+	err = d.archive(cfg)
+	if err != nil {
+		return err
+	}
+
+	d.cycle(ctx, cfg, slices.Sorted(maps.Keys(cfg.Certificates)), false)
+
+	for {
+		schedules := d.schedule(ctx, cfg, time.Now())
+
+		wakeUp := nextWakeUp(schedules, time.Now())
+
+		log.Info("Waiting for the next renewal check.",
+			slog.Time("wakeUp", wakeUp),
+			slog.Duration("sleep", time.Until(wakeUp).Round(time.Second)),
+		)
+
+		timer := time.NewTimer(time.Until(wakeUp))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			log.Info("Shutting down the daemon.")
+
+			return nil
+
+		case <-reload:
+			timer.Stop()
+
+			log.Info("Reloading the configuration.")
+
+			newCfg, errL := loader()
+			if errL != nil {
+				log.Error("Could not reload the configuration, the previous configuration is kept.", log.ErrorAttr(errL))
+
+				continue
+			}
+
+			err = d.archive(newCfg)
+			if err != nil {
+				log.Error("Could not archive the removed accounts and certificates.", log.ErrorAttr(err))
+			}
+
+			cfg = newCfg
+
+			// The configuration may have changed (new certificates, new domains, etc.),
+			// so the certificates are processed like the first cycle.
+			d.cycle(ctx, cfg, slices.Sorted(maps.Keys(cfg.Certificates)), false)
+
+		case <-timer.C:
+			d.cycle(ctx, cfg, dueCertificates(schedules, time.Now()), true)
+		}
+	}
+}
+
+type daemon struct {
+	// attempts contains the time of the last attempt to process each certificate.
+	attempts map[string]time.Time
+}
+
+func (d *daemon) archive(cfg *configuration.Configuration) error {
+	archiver := storage.NewArchiverFromConfiguration(cfg.Storage)
+
+	err := archiver.Accounts(cfg)
+	if err != nil {
+		return err
+	}
+
+	return archiver.Certificates(cfg.Certificates)
+}
+
+// cycle processes the certificates with a single run:
+// an error related to one certificate doesn't block the processing of the others.
+func (d *daemon) cycle(ctx context.Context, cfg *configuration.Configuration, certIDs []string, forceRenew bool) {
+	if len(certIDs) == 0 {
+		return
+	}
+
+	now := time.Now()
+
+	for _, certID := range certIDs {
+		d.attempts[certID] = now
+	}
+
+	err := process(ctx, cfg, &configuration.Filter{CertificateIDs: certIDs}, forceRenew, func(certID string, err error) {
+		log.Error("Could not process the certificate.",
+			log.CertNameAttr(certID),
+			slog.Time("retryAt", time.Now().Add(daemonRetryDelay)),
+			log.ErrorAttr(err),
+		)
+	})
+	if err != nil {
+		log.Error("Could not process the certificates.",
+			slog.Time("retryAt", time.Now().Add(daemonRetryDelay)),
+			log.ErrorAttr(err),
+		)
+	}
+
+	err = BackupConfiguration(cfg)
+	if err != nil {
+		log.Warn("Could not back up the configuration.", log.ErrorAttr(err))
+	}
+}
+
+func (d *daemon) schedule(ctx context.Context, cfg *configuration.Configuration, now time.Time) []renewalSchedule {
+	schedules := getRenewalSchedules(ctx, cfg, now)
+
+	for i, s := range schedules {
+		lastAttempt, ok := d.attempts[s.certID]
+		if !ok {
+			continue
+		}
+
+		retryAt := lastAttempt.Add(daemonRetryDelay)
+
+		if !s.renewAt.After(now) && retryAt.After(now) {
+			schedules[i].renewAt = retryAt
+		}
+
+		if !s.checkAt.After(now) && retryAt.After(now) {
+			schedules[i].checkAt = retryAt
+		}
+	}
+
+	return schedules
+}
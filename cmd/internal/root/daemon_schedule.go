@@ -0,0 +1,221 @@
+package root
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"log/slog"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/go-acme/lego/v5/acme/api"
+	"github.com/go-acme/lego/v5/certcrypto"
+	"github.com/go-acme/lego/v5/cmd/internal/configuration"
+	"github.com/go-acme/lego/v5/cmd/internal/storage"
+	"github.com/go-acme/lego/v5/lego"
+	"github.com/go-acme/lego/v5/log"
+)
+
+// maxDaemonSleep is the maximum duration between two renewal checks.
+const maxDaemonSleep = 24 * time.Hour
+
+// minARIRetryAfter is the minimum duration between two calls to the renewalInfo endpoint for a certificate.
+const minARIRetryAfter = time.Minute
+
+// renewalSchedule describes when a certificate must be renewed.
+type renewalSchedule struct {
+	certID string
+
+	// renewAt is the time when the certificate must be renewed.
+	renewAt time.Time
+
+	// checkAt is the time when the renewal time must be computed again (ARI Retry-After).
+	checkAt time.Time
+}
+
+// getRenewalSchedules computes the renewal schedule of all the certificates defined in the configuration.
+func getRenewalSchedules(ctx context.Context, cfg *configuration.Configuration, now time.Time) []renewalSchedule {
+	store, err := storage.NewFromConfiguration(cfg.Storage)
+	if err != nil {
+		log.Error("Could not set up the storage, the certificates will be processed immediately.", log.ErrorAttr(err))
+
+		return immediateSchedules(cfg, now)
+	}
+
+	defer func() { _ = store.Close() }()
+
+	var schedules []renewalSchedule
+
+	var closeKeys []func()
+
+	defer func() {
+		for _, closeKey := range closeKeys {
+			closeKey()
+		}
+	}()
+
+	for _, accountNode := range configuration.LookupCertificates(cfg, slices.Sorted(maps.Keys(cfg.Certificates))) {
+		lazyClient := sync.OnceValues(func() (*lego.Client, error) {
+			account, closeKey, err := getAccount(store.Account, accountNode.Account, accountNode.ServerConfig.URL)
+			if err != nil {
+				return nil, err
+			}
+
+			closeKeys = append(closeKeys, closeKey)
+
+			return lego.NewClient(newClientConfig(accountNode.ServerConfig, account, cfg.UserAgent))
+		})
+
+		for _, certConfig := range accountNode.Children {
+			schedules = append(schedules, getRenewalSchedule(ctx, lazyClient, store.Certificate, certConfig, now))
+		}
+	}
+
+	return schedules
+}
+
+// immediateSchedules returns schedules to process all the certificates immediately.
+func immediateSchedules(cfg *configuration.Configuration, now time.Time) []renewalSchedule {
+	var schedules []renewalSchedule
+
+	for _, certID := range slices.Sorted(maps.Keys(cfg.Certificates)) {
+		schedules = append(schedules, renewalSchedule{certID: certID, renewAt: now, checkAt: now})
+	}
+
+	return schedules
+}
+
+// getRenewalSchedule computes the renewal schedule of a certificate.
+// A certificate that doesn't exist, or that doesn't match the configuration, must be processed immediately.
+func getRenewalSchedule(ctx context.Context, lazyClient lzSetUp, certsStorage *storage.CertificatesStorage, certConfig *configuration.Certificate, now time.Time) renewalSchedule {
+	immediately := renewalSchedule{certID: certConfig.ID, renewAt: now, checkAt: now}
+
+	resource, err := certsStorage.ReadResource(certConfig.ID)
+	if err != nil {
+		return immediately
+	}
+
+	if hasChanged(resource, certConfig) {
+		return immediately
+	}
+
+	certificates, err := certsStorage.ReadCertificate(certConfig.ID)
+	if err != nil || len(certificates) == 0 {
+		return immediately
+	}
+
+	cert := certificates[0]
+
+	if !sameConfiguredDomains(cert, certConfig) {
+		return immediately
+	}
+
+	if certConfig.Renew.ARI != nil && !certConfig.Renew.ARI.Disable {
+		schedule, ok := getARIRenewalSchedule(ctx, lazyClient, cert, certConfig.ID, now)
+		if ok {
+			return schedule
+		}
+	}
+
+	dueDate := getDueDate(cert, certConfig.Renew.Days, now)
+
+	return renewalSchedule{
+		certID:  certConfig.ID,
+		renewAt: dueDate,
+		checkAt: dueDate,
+	}
+}
+
+// getARIRenewalSchedule uses the renewalInfo endpoint to select a renewal time inside the suggested window.
+func getARIRenewalSchedule(ctx context.Context, lazyClient lzSetUp, cert *x509.Certificate, certID string, now time.Time) (renewalSchedule, bool) {
+	client, err := lazyClient()
+	if err != nil {
+		log.Warn("Could not set up the client to call the renewal info endpoint.",
+			log.CertNameAttr(certID),
+			log.ErrorAttr(err),
+		)
+
+		return renewalSchedule{}, false
+	}
+
+	renewalInfo, err := client.Certificate.GetRenewalInfo(ctx, cert)
+	if err != nil {
+		if !errors.Is(err, api.ErrNoARI) {
+			log.Warn("Calling renewal info endpoint",
+				log.CertNameAttr(certID),
+				log.ErrorAttr(err),
+			)
+		}
+
+		return renewalSchedule{}, false
+	}
+
+	// The daemon can sleep until the end of the suggested window,
+	// so ShouldRenewAt always returns a time.
+	renewAt := renewalInfo.ShouldRenewAt(now, renewalInfo.SuggestedWindow.End.Sub(now))
+	if renewAt == nil {
+		return renewalSchedule{}, false
+	}
+
+	retryAfter := min(max(renewalInfo.RetryAfter, minARIRetryAfter), maxDaemonSleep)
+	if renewalInfo.RetryAfter == 0 {
+		retryAfter = maxDaemonSleep
+	}
+
+	log.Debug("RenewalInfo endpoint suggests a renewal time.",
+		log.CertNameAttr(certID),
+		slog.Time("renewalTime", *renewAt),
+		slog.Duration("retryAfter", retryAfter),
+	)
+
+	return renewalSchedule{
+		certID:  certID,
+		renewAt: *renewAt,
+		checkAt: now.Add(retryAfter),
+	}, true
+}
+
+// nextWakeUp returns the earliest time when a certificate must be renewed or checked.
+func nextWakeUp(schedules []renewalSchedule, now time.Time) time.Time {
+	wakeUp := now.Add(maxDaemonSleep)
+
+	for _, s := range schedules {
+		if s.renewAt.Before(wakeUp) {
+			wakeUp = s.renewAt
+		}
+
+		if s.checkAt.Before(wakeUp) {
+			wakeUp = s.checkAt
+		}
+	}
+
+	return wakeUp
+}
+
+// dueCertificates returns the IDs of the certificates that must be renewed.
+func dueCertificates(schedules []renewalSchedule, now time.Time) []string {
+	var certIDs []string
+
+	for _, s := range schedules {
+		if !s.renewAt.After(now) {
+			certIDs = append(certIDs, s.certID)
+		}
+	}
+
+	return certIDs
+}
+
+func sameConfiguredDomains(cert *x509.Certificate, certConfig *configuration.Certificate) bool {
+	if certConfig.CSR == "" {
+		return sameDomains(certcrypto.ExtractDomains(cert), certConfig.Domains)
+	}
+
+	csr, err := storage.ReadCSRFile(certConfig.CSR)
+	if err != nil {
+		return false
+	}
+
+	return sameDomainsCertificate(cert, csr)
+}
@@ -0,0 +1,67 @@
+package root
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_nextWakeUp(t *testing.T) {
+	now := time.Date(2025, 1, 19, 1, 1, 1, 1, time.UTC)
+
+	testCases := []struct {
+		desc      string
+		schedules []renewalSchedule
+		expected  time.Time
+	}{
+		{
+			desc:     "no schedules",
+			expected: now.Add(maxDaemonSleep),
+		},
+		{
+			desc: "earliest renewal",
+			schedules: []renewalSchedule{
+				{certID: "a", renewAt: now.Add(3 * time.Hour), checkAt: now.Add(3 * time.Hour)},
+				{certID: "b", renewAt: now.Add(2 * time.Hour), checkAt: now.Add(5 * time.Hour)},
+			},
+			expected: now.Add(2 * time.Hour),
+		},
+		{
+			desc: "earliest check",
+			schedules: []renewalSchedule{
+				{certID: "a", renewAt: now.Add(30 * 24 * time.Hour), checkAt: now.Add(6 * time.Hour)},
+				{certID: "b", renewAt: now.Add(20 * 24 * time.Hour), checkAt: now.Add(7 * time.Hour)},
+			},
+			expected: now.Add(6 * time.Hour),
+		},
+		{
+			desc: "far away",
+			schedules: []renewalSchedule{
+				{certID: "a", renewAt: now.Add(30 * 24 * time.Hour), checkAt: now.Add(30 * 24 * time.Hour)},
+			},
+			expected: now.Add(maxDaemonSleep),
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, nextWakeUp(test.schedules, now))
+		})
+	}
+}
+
+func Test_dueCertificates(t *testing.T) {
+	now := time.Date(2025, 1, 19, 1, 1, 1, 1, time.UTC)
+
+	schedules := []renewalSchedule{
+		{certID: "past", renewAt: now.Add(-time.Hour), checkAt: now},
+		{certID: "now", renewAt: now, checkAt: now},
+		{certID: "check-only", renewAt: now.Add(time.Hour), checkAt: now.Add(-time.Minute)},
+		{certID: "future", renewAt: now.Add(time.Hour), checkAt: now.Add(time.Hour)},
+	}
+
+	assert.Equal(t, []string{"past", "now"}, dueCertificates(schedules, now))
+}
@@ -0,0 +1,46 @@
+package root
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v5/cmd/internal/configuration"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_daemon_schedule(t *testing.T) {
+	now := time.Now()
+
+	cfg := &configuration.Configuration{
+		Storage: &configuration.Storage{Path: t.TempDir()},
+		Accounts: map[string]*configuration.Account{
+			"acc": {ID: "acc", Server: "https://example.com/dir"},
+		},
+		Certificates: map[string]*configuration.Certificate{
+			"recent": {ID: "recent", Account: "acc", Domains: []string{"example.com"}, Renew: &configuration.RenewConfiguration{}},
+			"old":    {ID: "old", Account: "acc", Domains: []string{"example.org"}, Renew: &configuration.RenewConfiguration{}},
+			"new":    {ID: "new", Account: "acc", Domains: []string{"example.net"}, Renew: &configuration.RenewConfiguration{}},
+		},
+	}
+
+	d := &daemon{attempts: map[string]time.Time{
+		"recent": now.Add(-10 * time.Minute),
+		"old":    now.Add(-2 * daemonRetryDelay),
+	}}
+
+	schedules := d.schedule(context.Background(), cfg, now)
+
+	expected := map[string]time.Time{
+		// The certificate was processed recently: the next attempt is delayed.
+		"recent": now.Add(-10 * time.Minute).Add(daemonRetryDelay),
+		"old":    now,
+		"new":    now,
+	}
+
+	assert.Len(t, schedules, len(expected))
+
+	for _, s := range schedules {
+		assert.Equal(t, expected[s.certID], s.renewAt, s.certID)
+	}
+}
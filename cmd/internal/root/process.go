@@ -11,18 +11,19 @@ import (
 	"sync"
 	"time"
 
+	"github.com/go-acme/lego/v5/certificate"
 	"github.com/go-acme/lego/v5/challenge"
 	"github.com/go-acme/lego/v5/cmd/internal"
 	"github.com/go-acme/lego/v5/cmd/internal/configuration"
 	"github.com/go-acme/lego/v5/cmd/internal/hook"
 	"github.com/go-acme/lego/v5/cmd/internal/storage"
-	"github.com/go-acme/lego/v5/internal/dotenv"
 	"github.com/go-acme/lego/v5/lego"
+	"github.com/go-acme/lego/v5/log"
 	"github.com/go-acme/lego/v5/registration"
 )
 
 func Process(ctx context.Context, cfg *configuration.Configuration) error {
-	archiver := storage.NewArchiver(cfg.Storage)
+	archiver := storage.NewArchiverFromConfiguration(cfg.Storage)
 
 	err := archiver.Accounts(cfg)
 	if err != nil {
@@ -34,111 +35,322 @@ func Process(ctx context.Context, cfg *configuration.Configuration) error {
 		return err
 	}
 
-	return process(ctx, cfg)
+	return process(ctx, cfg, nil, false, nil)
 }
 
-func process(ctx context.Context, cfg *configuration.Configuration) error {
+// BackupConfiguration saves the effective configuration in the storage backend.
+func BackupConfiguration(cfg *configuration.Configuration) error {
+	store, err := storage.NewFromConfiguration(cfg.Storage)
+	if err != nil {
+		return fmt.Errorf("set up storage: %w", err)
+	}
+
+	defer func() { _ = store.Close() }()
+
+	return store.Configuration.Backup(cfg)
+}
+
+// certificateErrorHandler receives the error of a certificate.
+// It's called concurrently by the certificates processed at the same time.
+type certificateErrorHandler func(certID string, err error)
+
+// process obtains or renews the certificates matching the filter.
+// If forceRenew is true, the renewal period checks are skipped.
+//
+// The certificates are processed concurrently, bounded by the global concurrency and the concurrency of the accounts.
+// When a certificate fails, the certificates not yet started are skipped,
+// unless onError is defined: the errors of the certificates (and of their accounts) are sent to onError,
+// and the other certificates are processed.
+func process(ctx context.Context, cfg *configuration.Configuration, filter *configuration.Filter, forceRenew bool, onError certificateErrorHandler) error {
 	networkStack := getNetworkStack(cfg)
 
-	store := storage.New(cfg.Storage)
+	store, err := storage.NewFromConfiguration(cfg.Storage)
+	if err != nil {
+		return fmt.Errorf("set up storage: %w", err)
+	}
+
+	defer func() { _ = store.Close() }()
 
-	for _, accountNode := range configuration.LookupChallenges(cfg, nil) {
-		account, err := store.Account.Get(accountNode.ServerConfig.URL, accountNode.KeyType, accountNode.Email, accountNode.ID)
+	var jobs []*accountJobs
+
+	// The accounts are set up sequentially because the registration may require a confirmation.
+	for _, accountNode := range configuration.LookupChallenges(cfg, filter) {
+		account, closeKey, err := getAccount(store.Account, accountNode.Account, accountNode.ServerConfig.URL)
 		if err != nil {
+			if onError != nil {
+				reportAccountError(accountNode, err, onError)
+
+				continue
+			}
+
 			return err
 		}
 
+		defer closeKey()
+
+		// The limiter is shared by the clients of the account to respect the overall request limit.
+		limiter := certificate.NewRequestLimiter(accountNode.ServerConfig.OverallRequestLimit)
+
+		// The authorizations are shared by the certificates of the account during the run.
+		authorizations := certificate.NewAuthorizationCache()
+
+		clients := newClientPool(func() (*lego.Client, error) {
+			config := newClientConfig(accountNode.ServerConfig, account, cfg.UserAgent)
+			config.Certificate.RequestLimiter = limiter
+			config.Certificate.AuthorizationCache = authorizations
+
+			return lego.NewClient(config)
+		})
+
+		var registrationClient *lego.Client
+
 		lazyClient := sync.OnceValues(func() (*lego.Client, error) {
-			return lego.NewClient(newClientConfig(accountNode.ServerConfig, account, cfg.UserAgent))
+			client, errC := clients.get()
+			registrationClient = client
+
+			return client, errC
 		})
 
 		err = handleRegistration(ctx, lazyClient, accountNode.Account, store.Account, account, true)
 		if err != nil {
+			if onError != nil {
+				reportAccountError(accountNode, fmt.Errorf("registration: %w", err), onError)
+
+				continue
+			}
+
 			return fmt.Errorf("registration: %w", err)
 		}
 
-		hm := hook.NewManager(
-			store.Certificate,
-			withHooks(cfg.Hooks),
-			hook.WithAccountMetadata(account),
-		)
+		if registrationClient != nil {
+			clients.put(registrationClient)
+		}
 
-		for _, chlgNode := range accountNode.Children {
-			// Clone the hook manager for each certificate because:
-			// each certificate is different, so the metadata is different, except for the account information.
-			hookManager := hm.Clone()
+		jobs = append(jobs, &accountJobs{
+			node:    accountNode,
+			clients: clients,
+			hooks: hook.NewManager(
+				store.Certificate,
+				withHooks(cfg.Hooks),
+				hook.WithAccountMetadata(account),
+			),
+		})
+	}
 
-			err := processChallenges(ctx, lazyClient, chlgNode, store, hookManager, networkStack)
-			if err != nil {
-				return err
-			}
+	p := &processor{
+		challenges:   cfg.Challenges,
+		store:        store,
+		networkStack: networkStack,
+		forceRenew:   forceRenew,
+		slots:        make(chan struct{}, max(cfg.Concurrency, 1)),
+		gate:         newChallengeGate(),
+		locks:        newSequentialLocks(),
+		onError:      onError,
+	}
+
+	var wg sync.WaitGroup
+
+	for _, aj := range jobs {
+		wg.Go(func() {
+			p.processAccount(ctx, aj)
+		})
+	}
+
+	wg.Wait()
+
+	return p.err()
+}
+
+// reportAccountError sends the error of an account to onError for each certificate of the account.
+func reportAccountError(accountNode *configuration.AccountNode[*configuration.ChallengeNode], err error, onError certificateErrorHandler) {
+	for _, chlgNode := range accountNode.Children {
+		for _, cert := range chlgNode.Certificates {
+			onError(cert.ID, err)
 		}
 	}
+}
 
-	return nil
+// accountJobs are the certificates of an account.
+type accountJobs struct {
+	node    *configuration.AccountNode[*configuration.ChallengeNode]
+	clients *clientPool
+	hooks   *hook.Manager
 }
 
-func processChallenges(ctx context.Context, lazyClient lzSetUp, chlgNode *configuration.ChallengeNode, store *storage.Storage, hookManager *hook.Manager, networkStack challenge.NetworkStack) error {
-	if chlgNode.DNS != nil {
-		cleanUp, err := dotenv.Load(chlgNode.DNS.EnvFile)
+type processor struct {
+	challenges   map[string]*configuration.Challenge
+	store        *storage.Storage
+	networkStack challenge.NetworkStack
+	forceRenew   bool
 
-		defer cleanUp()
+	// slots bounds the number of certificates processed concurrently (global concurrency).
+	slots chan struct{}
+	gate  *challengeGate
+	locks *sequentialLocks
 
-		if err != nil {
-			return fmt.Errorf("load environment variables: %w", err)
+	// onError receives the errors of the certificates, if defined.
+	onError certificateErrorHandler
+
+	mu   sync.Mutex
+	errs []error
+}
+
+// processAccount processes the certificates of an account, bounded by the concurrency of the account.
+func (p *processor) processAccount(ctx context.Context, aj *accountJobs) {
+	accountSlots := make(chan struct{}, max(aj.node.Concurrency, 1))
+
+	var wg sync.WaitGroup
+
+	defer wg.Wait()
+
+	for _, chlgNode := range aj.node.Children {
+		for _, cert := range chlgNode.Certificates {
+			accountSlots <- struct{}{}
+			p.slots <- struct{}{}
+
+			if p.failed() {
+				<-p.slots
+				<-accountSlots
+
+				return
+			}
+
+			// Clone the hook manager for each certificate because:
+			// each certificate is different, so the metadata is different, except for the account information.
+			hookManager := aj.hooks.Clone()
+
+			wg.Go(func() {
+				defer func() {
+					<-p.slots
+					<-accountSlots
+				}()
+
+				err := p.processChallengeCertificate(ctx, aj.clients, chlgNode, cert, hookManager)
+				if err != nil {
+					p.addError(cert.ID, err)
+				}
+			})
 		}
 	}
+}
+
+// processChallengeCertificate obtains or renews a certificate with a dedicated client.
+func (p *processor) processChallengeCertificate(ctx context.Context, clients *clientPool, chlgNode *configuration.ChallengeNode, cert *configuration.Certificate, hookManager *hook.Manager) error {
+	err := p.gate.enter(chlgNode.Challenge, isExclusive(chlgNode.Challenge, cert))
+	if err != nil {
+		return err
+	}
+
+	defer p.gate.leave()
+
+	var client *lego.Client
 
+	defer func() {
+		if client != nil {
+			clients.put(client)
+		}
+	}()
+
+	// The solvers are configured for each certificate because the challenge rules are specific to each certificate.
 	lazySetup := sync.OnceValues(func() (*lego.Client, error) {
-		client, errC := lazyClient()
+		c, errC := clients.get()
 		if errC != nil {
 			return nil, fmt.Errorf("set up client: %w", errC)
 		}
 
+		client = c
+
 		client.Challenge.ResetSolvers()
 
-		errC = setupChallenges(client, chlgNode.Challenge, networkStack)
+		errC = setupChallenges(client.Challenge, chlgNode.Challenge, p.locks, p.networkStack)
 		if errC != nil {
 			return nil, fmt.Errorf("setup challenges: %w", errC)
 		}
 
+		errC = setupChallengeRules(client.Challenge, p.challenges, cert.ChallengeRules, p.locks, p.networkStack)
+		if errC != nil {
+			return nil, fmt.Errorf("setup challenge rules: %w", errC)
+		}
+
 		return client, nil
 	})
 
-	for _, cert := range chlgNode.Certificates {
-		resource, err := store.Certificate.ReadResource(cert.ID)
-		if err != nil {
-			pe := new(fs.PathError)
-			if !errors.As(err, &pe) {
-				return fmt.Errorf("reading certificate resource file for %q: %w", cert.ID, err)
-			}
-		}
+	return processCertificate(ctx, lazySetup, cert, p.store, hookManager, p.forceRenew)
+}
 
-		if resource == nil {
-			// Run
-			err = obtain(ctx, lazySetup, cert.ID, cert, store.Certificate, hookManager)
-			if err != nil {
-				return err
-			}
+func (p *processor) addError(certID string, err error) {
+	if p.onError != nil {
+		p.onError(certID, err)
 
-			continue
-		}
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.errs = append(p.errs, err)
+}
+
+func (p *processor) failed() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return len(p.errs) > 0
+}
+
+func (p *processor) err() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return errors.Join(p.errs...)
+}
+
+// processCertificate obtains or renews a certificate.
+// The certificate is locked during the process:
+// if the certificate is locked by another process (sharing the same storage), the certificate is skipped.
+func processCertificate(ctx context.Context, lazySetup lzSetUp, cert *configuration.Certificate, store *storage.Storage, hookManager *hook.Manager, forceRenew bool) error {
+	unlock, err := store.Certificate.Lock(cert.ID)
+	if errors.Is(err, storage.ErrLocked) {
+		log.Warn("The certificate is locked by another process, skipping.", log.CertNameAttr(cert.ID))
+
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("lock the certificate %q: %w", cert.ID, err)
+	}
 
-		// Renew
-		rp := &renewProcessor{
-			certConfig:   cert,
-			lazyClient:   lazySetup,
-			certsStorage: store.Certificate,
-			hookManager:  hookManager,
+	defer func() {
+		errU := unlock()
+		if errU != nil {
+			log.Warn("Could not release the certificate lock.", log.CertNameAttr(cert.ID), log.ErrorAttr(errU))
 		}
+	}()
 
-		err = rp.renew(ctx, cert.ID, resource)
-		if err != nil {
-			return err
+	// The resource is read after the lock because it may have been updated by another process.
+	resource, err := store.Certificate.ReadResource(cert.ID)
+	if err != nil {
+		pe := new(fs.PathError)
+		if !errors.As(err, &pe) {
+			return fmt.Errorf("reading certificate resource file for %q: %w", cert.ID, err)
 		}
 	}
 
-	return nil
+	if resource == nil {
+		// Run
+		return obtain(ctx, lazySetup, cert.ID, cert, store.Certificate, hookManager)
+	}
+
+	// Renew
+	rp := &renewProcessor{
+		certConfig:   cert,
+		lazyClient:   lazySetup,
+		certsStorage: store.Certificate,
+		hookManager:  hookManager,
+		force:        forceRenew,
+	}
+
+	return rp.renew(ctx, cert.ID, resource)
 }
 
 func getNetworkStack(cfg *configuration.Configuration) challenge.NetworkStack {
@@ -183,6 +395,8 @@ func newClientConfig(serverConfig *configuration.Server, account registration.Us
 
 	config.HTTPClient = internal.NewRetryableClient(config.HTTPClient)
 
+	config.Events = internal.Events()
+
 	return config
 }
 
@@ -10,54 +10,121 @@ import (
 	"github.com/go-acme/lego/v5/challenge/dns01"
 	"github.com/go-acme/lego/v5/challenge/dnspersist01"
 	"github.com/go-acme/lego/v5/challenge/http01"
+	"github.com/go-acme/lego/v5/challenge/resolver"
 	"github.com/go-acme/lego/v5/challenge/tlsalpn01"
 	"github.com/go-acme/lego/v5/cmd/internal/configuration"
-	"github.com/go-acme/lego/v5/lego"
+	"github.com/go-acme/lego/v5/cmd/internal/multiplexer"
+	"github.com/go-acme/lego/v5/internal/dotenv"
 	"github.com/go-acme/lego/v5/providers/dns"
 	"github.com/go-acme/lego/v5/providers/http/memcached"
 	"github.com/go-acme/lego/v5/providers/http/s3"
 	"github.com/go-acme/lego/v5/providers/http/webroot"
 )
 
-func setupChallenges(client *lego.Client, chlgConfig *configuration.Challenge, networkStack challenge.NetworkStack) error {
+// setupChallenges configures the solvers of the challenge.
+// The locks serialize the DNS providers requiring a sequential resolution between the certificates processed concurrently (optional).
+func setupChallenges(manager *resolver.SolverManager, chlgConfig *configuration.Challenge, locks *sequentialLocks, networkStack challenge.NetworkStack) error {
 	if chlgConfig.HTTP != nil {
-		err := setupHTTPProvider(client, chlgConfig.HTTP, networkStack)
+		err := setupHTTPProvider(manager, chlgConfig.HTTP, networkStack)
 		if err != nil {
 			return fmt.Errorf("HTTP challenge provider: %w", err)
 		}
 	}
 
 	if chlgConfig.TLS != nil {
-		err := setupTLSProvider(client, chlgConfig.TLS, networkStack)
+		err := setupTLSProvider(manager, chlgConfig.TLS, networkStack)
 		if err != nil {
 			return fmt.Errorf("TLS challenge provider: %w", err)
 		}
 	}
 
 	if chlgConfig.DNS != nil {
-		err := setupDNS(client, chlgConfig.DNS, networkStack)
+		err := setupDNS(manager, chlgConfig.DNS, locks, networkStack)
 		if err != nil {
 			return fmt.Errorf("DNS challenge provider: %w", err)
 		}
 	}
 
 	if chlgConfig.DNSPersist != nil {
-		err := setupDNSPersist(client, chlgConfig.DNSPersist, networkStack)
+		err := setupDNSPersist(manager, chlgConfig.DNSPersist, networkStack)
 		if err != nil {
 			return fmt.Errorf("DNS-PERSIST challenge provider: %w", err)
 		}
 	}
 
+	if chlgConfig.DNSAccount != nil {
+		err := setupDNSAccount(manager, chlgConfig.DNSAccount, chlgConfig.DNS == nil, locks, networkStack)
+		if err != nil {
+			return fmt.Errorf("DNS-ACCOUNT challenge provider: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// setupChallengeRules configures the solvers dedicated to the domains matching the rules.
+func setupChallengeRules(manager *resolver.SolverManager, challenges map[string]*configuration.Challenge, rules []*configuration.ChallengeRule, locks *sequentialLocks, networkStack challenge.NetworkStack) error {
+	for _, rule := range rules {
+		ruleManager, err := manager.ForDomains(rule.Domain)
+		if err != nil {
+			return err
+		}
+
+		err = setupChallengeRule(ruleManager, challenges[rule.Challenge], locks, networkStack)
+		if err != nil {
+			return fmt.Errorf("rule %s (%s): %w", rule.Domain, rule.Challenge, err)
+		}
+	}
+
 	return nil
 }
 
-func setupHTTPProvider(client *lego.Client, chlg *configuration.HTTPChallenge, networkStack challenge.NetworkStack) error {
+func setupChallengeRule(manager *resolver.SolverManager, chlgConfig *configuration.Challenge, locks *sequentialLocks, networkStack challenge.NetworkStack) error {
+	cleanUp, err := loadEnvFiles(chlgConfig)
+
+	defer cleanUp()
+
+	if err != nil {
+		return err
+	}
+
+	return setupChallenges(manager, chlgConfig, locks, networkStack)
+}
+
+// loadEnvFiles loads the environment files of the DNS challenges.
+func loadEnvFiles(chlgConfig *configuration.Challenge) (func(), error) {
+	var cleanUps []func()
+
+	cleanUp := func() {
+		for _, fn := range cleanUps {
+			fn()
+		}
+	}
+
+	for _, chlg := range []*configuration.DNSChallenge{chlgConfig.DNS, chlgConfig.DNSAccount} {
+		if chlg == nil {
+			continue
+		}
+
+		fn, err := dotenv.Load(chlg.EnvFile)
+
+		cleanUps = append(cleanUps, fn)
+
+		if err != nil {
+			return cleanUp, fmt.Errorf("load environment variables: %w", err)
+		}
+	}
+
+	return cleanUp, nil
+}
+
+func setupHTTPProvider(manager *resolver.SolverManager, chlg *configuration.HTTPChallenge, networkStack challenge.NetworkStack) error {
 	provider, err := createHTTPProvider(chlg, networkStack)
 	if err != nil {
 		return fmt.Errorf("create: %w", err)
 	}
 
-	return client.Challenge.SetHTTP01Provider(provider, http01.SetDelay(chlg.Delay))
+	return manager.SetHTTP01Provider(provider, http01.SetDelay(chlg.Delay))
 }
 
 func createHTTPProvider(chlg *configuration.HTTPChallenge, networkStack challenge.NetworkStack) (challenge.Provider, error) {
@@ -111,7 +178,7 @@ func createHTTPProvider(chlg *configuration.HTTPChallenge, networkStack challeng
 	}
 }
 
-func setupTLSProvider(client *lego.Client, chlg *configuration.TLSChallenge, networkStack challenge.NetworkStack) error {
+func setupTLSProvider(manager *resolver.SolverManager, chlg *configuration.TLSChallenge, networkStack challenge.NetworkStack) error {
 	options := tlsalpn01.Options{
 		Network: networkStack.Network("tcp"),
 	}
@@ -126,18 +193,44 @@ func setupTLSProvider(client *lego.Client, chlg *configuration.TLSChallenge, net
 		options.Port = port
 	}
 
-	return client.Challenge.SetTLSALPN01Provider(
+	return manager.SetTLSALPN01Provider(
 		tlsalpn01.NewProviderServerWithOptions(options),
 		tlsalpn01.SetDelay(chlg.Delay),
 	)
 }
 
-func setupDNS(client *lego.Client, chlg *configuration.DNSChallenge, networkStack challenge.NetworkStack) error {
-	provider, err := dns.NewDNSChallengeProviderByName(chlg.Provider)
+func setupDNS(manager *resolver.SolverManager, chlg *configuration.DNSChallenge, locks *sequentialLocks, networkStack challenge.NetworkStack) error {
+	provider, err := newDNSProvider(chlg)
 	if err != nil {
 		return err
 	}
 
+	provider = locks.wrap(chlg, provider)
+
+	dns01.SetDefaultClient(newDNSClient(chlg, networkStack))
+
+	return manager.SetDNS01Provider(provider, propagationOptions(chlg))
+}
+
+// setupDNSAccount configures the dns-account-01 challenge.
+// The dns-account-01 challenge uses the DNS client of the dns-01 challenge:
+// the client is configured only when the dns-01 challenge is not configured.
+func setupDNSAccount(manager *resolver.SolverManager, chlg *configuration.DNSChallenge, setupDNS01Client bool, locks *sequentialLocks, networkStack challenge.NetworkStack) error {
+	provider, err := newDNSProvider(chlg)
+	if err != nil {
+		return err
+	}
+
+	provider = locks.wrap(chlg, provider)
+
+	if setupDNS01Client {
+		dns01.SetDefaultClient(newDNSClient(chlg, networkStack))
+	}
+
+	return manager.SetDNSAccount01Provider(provider, propagationOptions(chlg))
+}
+
+func newDNSClient(chlg *configuration.DNSChallenge, networkStack challenge.NetworkStack) *dns01.Client {
 	opts := &dns01.Options{RecursiveNameservers: chlg.Resolvers}
 
 	if chlg.DNSTimeout > 0 {
@@ -146,27 +239,64 @@ func setupDNS(client *lego.Client, chlg *configuration.DNSChallenge, networkStac
 
 	opts.NetworkStack = networkStack
 
-	dns01.SetDefaultClient(dns01.NewClient(opts))
+	return dns01.NewClient(opts)
+}
 
-	return client.Challenge.SetDNS01Provider(provider,
-		dns01.LazyCondOption(chlg.Propagation != nil, func() dns01.ChallengeOption {
-			if chlg.Propagation.Wait > 0 {
-				return dns01.PropagationWait(chlg.Propagation.Wait, true)
-			}
+// propagationOptions returns the options of the propagation checks of the dns-01 and dns-account-01 challenges.
+func propagationOptions(chlg *configuration.DNSChallenge) dns01.ChallengeOption {
+	return dns01.LazyCondOption(chlg.Propagation != nil, func() dns01.ChallengeOption {
+		if chlg.Propagation.Wait > 0 {
+			return dns01.PropagationWait(chlg.Propagation.Wait, true)
+		}
 
-			return dns01.CombineOptions(
-				dns01.CondOptions(chlg.Propagation.DisableAuthoritativeNameservers,
-					dns01.DisableAuthoritativeNssPropagationRequirement(),
-				),
-				dns01.CondOptions(chlg.Propagation.DisableRecursiveNameservers,
-					dns01.DisableRecursiveNSsPropagationRequirement(),
-				),
-			)
-		}),
-	)
+		return dns01.CombineOptions(
+			dns01.CondOptions(chlg.Propagation.DisableAuthoritativeNameservers,
+				dns01.DisableAuthoritativeNssPropagationRequirement(),
+			),
+			dns01.CondOptions(chlg.Propagation.DisableRecursiveNameservers,
+				dns01.DisableRecursiveNSsPropagationRequirement(),
+			),
+			dns01.CondOptions(chlg.Propagation.DNSSEC,
+				dns01.RequireDNSSEC(),
+			),
+		)
+	})
 }
 
-func setupDNSPersist(client *lego.Client, chlg *configuration.DNSPersistChallenge, networkStack challenge.NetworkStack) error {
+func newDNSProvider(chlg *configuration.DNSChallenge) (challenge.Provider, error) {
+	if len(chlg.Routes) == 0 {
+		return dns.NewDNSChallengeProviderByName(chlg.Provider)
+	}
+
+	config := &multiplexer.Config{}
+
+	for _, route := range chlg.Routes {
+		provider, err := newRouteDNSProvider(route)
+		if err != nil {
+			return nil, fmt.Errorf("route %s: %w", route.Zone, err)
+		}
+
+		config.Routes = append(config.Routes, multiplexer.Route{Zone: route.Zone, Provider: provider})
+	}
+
+	return multiplexer.NewDNSProviderConfig(config)
+}
+
+// newRouteDNSProvider creates the DNS provider of a route.
+// The environment variables of the route are only loaded during the creation of the provider.
+func newRouteDNSProvider(route *configuration.DNSRoute) (challenge.Provider, error) {
+	cleanUp, err := dotenv.Load(route.EnvFile)
+
+	defer cleanUp()
+
+	if err != nil {
+		return nil, fmt.Errorf("load environment variables: %w", err)
+	}
+
+	return dns.NewDNSChallengeProviderByName(route.Provider)
+}
+
+func setupDNSPersist(manager *resolver.SolverManager, chlg *configuration.DNSPersistChallenge, networkStack challenge.NetworkStack) error {
 	opts := &dns01.Options{RecursiveNameservers: chlg.Resolvers}
 
 	if chlg.DNSTimeout > 0 {
@@ -177,7 +307,7 @@ func setupDNSPersist(client *lego.Client, chlg *configuration.DNSPersistChalleng
 
 	dnspersist01.SetDefaultClient(dnspersist01.NewClient(opts))
 
-	return client.Challenge.SetDNSPersist01(
+	return manager.SetDNSPersist01(
 		dnspersist01.WithIssuerDomainName(chlg.IssuerDomainName),
 		dnspersist01.CondOptions(!chlg.PersistUntil.IsZero(),
 			dnspersist01.WithPersistUntil(chlg.PersistUntil),
@@ -194,6 +324,9 @@ func setupDNSPersist(client *lego.Client, chlg *configuration.DNSPersistChalleng
 				dnspersist01.CondOptions(chlg.Propagation.DisableRecursiveNameservers,
 					dnspersist01.DisableRecursiveNSsPropagationRequirement(),
 				),
+				dnspersist01.CondOptions(chlg.Propagation.DNSSEC,
+					dnspersist01.RequireDNSSEC(),
+				),
 			)
 		}),
 	)
@@ -0,0 +1,115 @@
+package root
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v5/challenge"
+	"github.com/go-acme/lego/v5/challenge/dns01"
+	"github.com/go-acme/lego/v5/challenge/resolver"
+	"github.com/go-acme/lego/v5/cmd/internal/configuration"
+	"github.com/go-acme/lego/v5/providers/dns/exec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_newDNSProvider_routes(t *testing.T) {
+	dir := t.TempDir()
+
+	envFileA := filepath.Join(dir, "a.env")
+	require.NoError(t, os.WriteFile(envFileA, []byte("EXEC_PATH=/usr/bin/a\n"), 0o600))
+
+	envFileB := filepath.Join(dir, "b.env")
+	require.NoError(t, os.WriteFile(envFileB, []byte("EXEC_PATH=/usr/bin/b\n"), 0o600))
+
+	chlg := &configuration.DNSChallenge{
+		Routes: []*configuration.DNSRoute{
+			{Zone: "example.com", Provider: "exec", EnvFile: envFileA},
+			{Zone: "example.org", Provider: "exec", EnvFile: envFileB},
+		},
+	}
+
+	provider, err := newDNSProvider(chlg)
+	require.NoError(t, err)
+
+	// The exec provider is sequential.
+	assert.Implements(t, (*interface{ Sequential() time.Duration })(nil), provider)
+
+	_, found := os.LookupEnv(exec.EnvPath)
+	assert.False(t, found)
+}
+
+func Test_newDNSProvider_routes_error(t *testing.T) {
+	chlg := &configuration.DNSChallenge{
+		Routes: []*configuration.DNSRoute{
+			{Zone: "example.com", Provider: "exec"},
+		},
+	}
+
+	_, err := newDNSProvider(chlg)
+	require.EqualError(t, err, "route example.com: exec: some credentials information are missing: EXEC_PATH")
+}
+
+func Test_setupChallengeRules(t *testing.T) {
+	envFile := filepath.Join(t.TempDir(), "exec.env")
+	require.NoError(t, os.WriteFile(envFile, []byte("EXEC_PATH=/usr/bin/a\n"), 0o600))
+
+	challenges := map[string]*configuration.Challenge{
+		"http": {HTTP: &configuration.HTTPChallenge{Address: ":8080"}},
+		"dns":  {DNS: &configuration.DNSChallenge{Provider: "exec", EnvFile: envFile}},
+	}
+
+	rules := []*configuration.ChallengeRule{
+		{Domain: "*.example.com", Challenge: "http"},
+		{Domain: "*.example.org", Challenge: "dns"},
+	}
+
+	err := setupChallengeRules(resolver.NewSolversManager(nil), challenges, rules, nil, challenge.DualStack)
+	require.NoError(t, err)
+
+	_, found := os.LookupEnv(exec.EnvPath)
+	assert.False(t, found)
+}
+
+func Test_setupChallengeRules_error(t *testing.T) {
+	challenges := map[string]*configuration.Challenge{
+		"dns": {DNS: &configuration.DNSChallenge{Provider: "exec"}},
+	}
+
+	rules := []*configuration.ChallengeRule{
+		{Domain: "*.example.org", Challenge: "dns"},
+	}
+
+	err := setupChallengeRules(resolver.NewSolversManager(nil), challenges, rules, nil, challenge.DualStack)
+	require.EqualError(t, err, "rule *.example.org (dns): DNS challenge provider: exec: some credentials information are missing: EXEC_PATH")
+}
+
+func Test_setupChallengeRule_dnsAccount(t *testing.T) {
+	backup := dns01.DefaultClient()
+
+	t.Cleanup(func() {
+		dns01.SetDefaultClient(backup)
+	})
+
+	envFile := filepath.Join(t.TempDir(), "exec.env")
+	require.NoError(t, os.WriteFile(envFile, []byte("EXEC_PATH=/usr/bin/a\n"), 0o600))
+
+	chlg := &configuration.Challenge{
+		DNSAccount: &configuration.DNSChallenge{Provider: "exec", EnvFile: envFile},
+	}
+
+	err := setupChallengeRule(resolver.NewSolversManager(nil), chlg, nil, challenge.DualStack)
+	require.NoError(t, err)
+
+	_, found := os.LookupEnv(exec.EnvPath)
+	assert.False(t, found)
+
+	chlg = &configuration.Challenge{
+		DNSAccount: &configuration.DNSChallenge{Provider: "exec"},
+	}
+
+	err = setupChallengeRule(resolver.NewSolversManager(nil), chlg, nil, challenge.DualStack)
+	require.EqualError(t, err, "DNS-ACCOUNT challenge provider: exec: some credentials information are missing: EXEC_PATH")
+}
@@ -0,0 +1,291 @@
+package root
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-acme/lego/v5/challenge"
+	"github.com/go-acme/lego/v5/challenge/dns01"
+	"github.com/go-acme/lego/v5/cmd/internal/configuration"
+	"github.com/go-acme/lego/v5/lego"
+)
+
+// clientPool provides a client for each certificate processed concurrently:
+// the solvers are configured for each certificate, so a client cannot be shared.
+type clientPool struct {
+	mu   sync.Mutex
+	idle []*lego.Client
+
+	newClient lzSetUp
+}
+
+func newClientPool(newClient lzSetUp) *clientPool {
+	return &clientPool{newClient: newClient}
+}
+
+// get returns an idle client, or creates a new client.
+func (p *clientPool) get() (*lego.Client, error) {
+	p.mu.Lock()
+
+	if len(p.idle) > 0 {
+		client := p.idle[len(p.idle)-1]
+		p.idle = p.idle[:len(p.idle)-1]
+
+		p.mu.Unlock()
+
+		return client, nil
+	}
+
+	p.mu.Unlock()
+
+	return p.newClient()
+}
+
+// put makes the client available for another certificate.
+func (p *clientPool) put(client *lego.Client) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.idle = append(p.idle, client)
+}
+
+// challengeGate allows only the certificates of challenges with the same process-wide state at a time:
+// the environment variables (envFile) and the DNS clients (resolvers) are process-wide.
+// The certificates of challenges without process-wide state (ex: DNS providers configured by the environment of the process)
+// are processed concurrently, even if the challenges are different.
+//
+// An exclusive certificate is processed alone:
+// the built-in HTTP and TLS servers bind a port, and the challenge rules change the process-wide DNS clients.
+type challengeGate struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+
+	current   string
+	exclusive bool
+	active    int
+	cleanUp   func()
+}
+
+func newChallengeGate() *challengeGate {
+	g := &challengeGate{}
+	g.cond = sync.NewCond(&g.mu)
+
+	return g
+}
+
+// enter waits until the certificate can be processed.
+// The environment variables of the challenge are loaded by the first certificate sharing the process-wide state.
+func (g *challengeGate) enter(chlg *configuration.Challenge, exclusive bool) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	state := processWideState(chlg)
+
+	for g.active > 0 && (exclusive || g.exclusive || g.current != state) {
+		g.cond.Wait()
+	}
+
+	if g.active == 0 {
+		cleanUp, err := loadEnvFiles(chlg)
+		if err != nil {
+			cleanUp()
+
+			return err
+		}
+
+		g.current = state
+		g.exclusive = exclusive
+		g.cleanUp = cleanUp
+	}
+
+	g.active++
+
+	return nil
+}
+
+// leave releases the gate.
+// The environment variables of the challenge are unloaded by the last certificate of the challenge.
+func (g *challengeGate) leave() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.active--
+
+	if g.active > 0 {
+		return
+	}
+
+	g.cleanUp()
+	g.cleanUp = nil
+
+	g.cond.Broadcast()
+}
+
+// processWideState identifies the process-wide state changed by a challenge:
+// the environment files, and the options of the DNS clients.
+// The challenges with the same state can be used at the same time.
+func processWideState(chlg *configuration.Challenge) string {
+	var parts []string
+
+	for name, dnsChlg := range map[string]*configuration.DNSChallenge{"dns": chlg.DNS, "dnsAccount": chlg.DNSAccount} {
+		if dnsChlg == nil || (dnsChlg.EnvFile == "" && len(dnsChlg.Resolvers) == 0 && dnsChlg.DNSTimeout == 0) {
+			continue
+		}
+
+		parts = append(parts, fmt.Sprintf("%s:%s:%s:%d", name, dnsChlg.EnvFile, strings.Join(dnsChlg.Resolvers, ","), dnsChlg.DNSTimeout))
+	}
+
+	if p := chlg.DNSPersist; p != nil && (len(p.Resolvers) > 0 || p.DNSTimeout > 0) {
+		parts = append(parts, fmt.Sprintf("dnsPersist:%s:%d", strings.Join(p.Resolvers, ","), p.DNSTimeout))
+	}
+
+	slices.Sort(parts)
+
+	return strings.Join(parts, "|")
+}
+
+// isExclusive returns true if the certificate cannot be processed concurrently with other certificates.
+func isExclusive(chlg *configuration.Challenge, cert *configuration.Certificate) bool {
+	if len(cert.ChallengeRules) > 0 || chlg.TLS != nil {
+		return true
+	}
+
+	if chlg.HTTP == nil {
+		return false
+	}
+
+	return chlg.HTTP.Webroot == "" && len(chlg.HTTP.MemcachedHosts) == 0 && chlg.HTTP.S3Bucket == ""
+}
+
+// sequentialLocks are the locks of the DNS providers requiring a sequential resolution,
+// shared by the certificates processed concurrently.
+type sequentialLocks struct {
+	mu    sync.Mutex
+	locks map[*configuration.DNSChallenge]*sequentialLock
+}
+
+func newSequentialLocks() *sequentialLocks {
+	return &sequentialLocks{locks: make(map[*configuration.DNSChallenge]*sequentialLock)}
+}
+
+// wrap serializes the challenges of the provider if the provider requires a sequential resolution.
+func (s *sequentialLocks) wrap(chlg *configuration.DNSChallenge, provider challenge.Provider) challenge.Provider {
+	p, ok := provider.(sequential)
+	if s == nil || !ok {
+		return provider
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lock, ok := s.locks[chlg]
+	if !ok {
+		lock = &sequentialLock{
+			sem:      make(chan struct{}, 1),
+			interval: p.Sequential(),
+			holders:  make(map[string]struct{}),
+		}
+
+		s.locks[chlg] = lock
+	}
+
+	return &sequentialProvider{Provider: provider, lock: lock}
+}
+
+type sequential interface {
+	Sequential() time.Duration
+}
+
+// sequentialLock is held from the creation to the cleanup of a challenge.
+type sequentialLock struct {
+	sem      chan struct{}
+	interval time.Duration
+
+	mu       sync.Mutex
+	holders  map[string]struct{}
+	released time.Time
+}
+
+func (l *sequentialLock) acquire(ctx context.Context, key string) error {
+	select {
+	case l.sem <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	l.mu.Lock()
+	l.holders[key] = struct{}{}
+	wait := time.Until(l.released.Add(l.interval))
+	l.mu.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		l.release(key)
+
+		return ctx.Err()
+	}
+}
+
+func (l *sequentialLock) release(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// The cleanup is also called when the creation of the challenge has failed.
+	if _, ok := l.holders[key]; !ok {
+		return
+	}
+
+	delete(l.holders, key)
+
+	l.released = time.Now()
+
+	<-l.sem
+}
+
+// sequentialProvider is a DNS provider requiring a sequential resolution,
+// used by several certificates processed concurrently.
+type sequentialProvider struct {
+	challenge.Provider
+
+	lock *sequentialLock
+}
+
+func (p *sequentialProvider) Present(ctx context.Context, domain, token, keyAuth string) error {
+	err := p.lock.acquire(ctx, domain+token)
+	if err != nil {
+		return err
+	}
+
+	return p.Provider.Present(ctx, domain, token, keyAuth)
+}
+
+func (p *sequentialProvider) CleanUp(ctx context.Context, domain, token, keyAuth string) error {
+	defer p.lock.release(domain + token)
+
+	return p.Provider.CleanUp(ctx, domain, token, keyAuth)
+}
+
+func (p *sequentialProvider) Timeout() (timeout, interval time.Duration) {
+	if provider, ok := p.Provider.(challenge.ProviderTimeout); ok {
+		return provider.Timeout()
+	}
+
+	return dns01.DefaultPropagationTimeout, dns01.DefaultPollingInterval
+}
+
+func (p *sequentialProvider) Sequential() time.Duration {
+	return p.lock.interval
+}
@@ -0,0 +1,340 @@
+package root
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v5/cmd/internal/configuration"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_isExclusive(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		chlg     *configuration.Challenge
+		cert     *configuration.Certificate
+		expected bool
+	}{
+		{
+			desc: "DNS",
+			chlg: &configuration.Challenge{DNS: &configuration.DNSChallenge{Provider: "exec"}},
+			cert: &configuration.Certificate{},
+		},
+		{
+			desc: "HTTP webroot",
+			chlg: &configuration.Challenge{HTTP: &configuration.HTTPChallenge{Webroot: "/var/www"}},
+			cert: &configuration.Certificate{},
+		},
+		{
+			desc:     "HTTP server",
+			chlg:     &configuration.Challenge{HTTP: &configuration.HTTPChallenge{}},
+			cert:     &configuration.Certificate{},
+			expected: true,
+		},
+		{
+			desc:     "TLS server",
+			chlg:     &configuration.Challenge{TLS: &configuration.TLSChallenge{}},
+			cert:     &configuration.Certificate{},
+			expected: true,
+		},
+		{
+			desc: "challenge rules",
+			chlg: &configuration.Challenge{DNS: &configuration.DNSChallenge{Provider: "exec"}},
+			cert: &configuration.Certificate{
+				ChallengeRules: []*configuration.ChallengeRule{{Domain: "*.example.org", Challenge: "dns"}},
+			},
+			expected: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, isExclusive(test.chlg, test.cert))
+		})
+	}
+}
+
+func Test_challengeGate(t *testing.T) {
+	gate := newChallengeGate()
+
+	chlgA := &configuration.Challenge{ID: "a", DNS: &configuration.DNSChallenge{Provider: "exec", Resolvers: []string{"1.1.1.1"}}}
+	chlgB := &configuration.Challenge{ID: "b", DNS: &configuration.DNSChallenge{Provider: "exec", Resolvers: []string{"8.8.8.8"}}}
+
+	// The certificates of the same challenge are processed concurrently.
+	require.NoError(t, gate.enter(chlgA, false))
+	require.NoError(t, gate.enter(chlgA, false))
+
+	var entered atomic.Bool
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		assert.NoError(t, gate.enter(chlgB, false))
+
+		entered.Store(true)
+
+		gate.leave()
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	assert.False(t, entered.Load())
+
+	gate.leave()
+
+	time.Sleep(50 * time.Millisecond)
+	assert.False(t, entered.Load())
+
+	gate.leave()
+
+	<-done
+
+	assert.True(t, entered.Load())
+}
+
+func Test_challengeGate_differentProviders(t *testing.T) {
+	gate := newChallengeGate()
+
+	chlgA := &configuration.Challenge{ID: "a", DNS: &configuration.DNSChallenge{Provider: "cloudflare"}}
+	chlgB := &configuration.Challenge{ID: "b", DNS: &configuration.DNSChallenge{Provider: "route53"}}
+
+	require.NoError(t, gate.enter(chlgA, false))
+
+	done := make(chan struct{})
+
+	// The challenges without process-wide state are processed concurrently.
+	go func() {
+		defer close(done)
+
+		assert.NoError(t, gate.enter(chlgB, false))
+
+		gate.leave()
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("the certificate of the second challenge is blocked")
+	}
+
+	gate.leave()
+}
+
+func Test_processWideState(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		chlg     *configuration.Challenge
+		expected string
+	}{
+		{
+			desc: "HTTP",
+			chlg: &configuration.Challenge{HTTP: &configuration.HTTPChallenge{Webroot: "/var/www"}},
+		},
+		{
+			desc: "DNS without options",
+			chlg: &configuration.Challenge{DNS: &configuration.DNSChallenge{Provider: "exec"}},
+		},
+		{
+			desc:     "DNS with environment file",
+			chlg:     &configuration.Challenge{DNS: &configuration.DNSChallenge{Provider: "exec", EnvFile: "exec.env"}},
+			expected: "dns:exec.env::0",
+		},
+		{
+			desc: "DNS and DNS persist with resolvers",
+			chlg: &configuration.Challenge{
+				DNS:        &configuration.DNSChallenge{Provider: "exec", Resolvers: []string{"1.1.1.1", "8.8.8.8"}, DNSTimeout: 10},
+				DNSPersist: &configuration.DNSPersistChallenge{Resolvers: []string{"1.1.1.1"}},
+			},
+			expected: "dns::1.1.1.1,8.8.8.8:10|dnsPersist:1.1.1.1:0",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, processWideState(test.chlg))
+		})
+	}
+}
+
+func Test_challengeGate_exclusive(t *testing.T) {
+	gate := newChallengeGate()
+
+	chlg := &configuration.Challenge{ID: "a"}
+
+	require.NoError(t, gate.enter(chlg, true))
+
+	var entered atomic.Bool
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		assert.NoError(t, gate.enter(chlg, false))
+
+		entered.Store(true)
+
+		gate.leave()
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	assert.False(t, entered.Load())
+
+	gate.leave()
+
+	<-done
+
+	assert.True(t, entered.Load())
+}
+
+func Test_sequentialLocks_wrap(t *testing.T) {
+	locks := newSequentialLocks()
+
+	chlg := &configuration.DNSChallenge{Provider: "exec"}
+
+	provider := &fakeProvider{}
+
+	// Not sequential.
+	assert.Same(t, provider, locks.wrap(chlg, provider))
+
+	seqProvider := &fakeSequentialProvider{interval: time.Second}
+
+	a, ok := locks.wrap(chlg, seqProvider).(*sequentialProvider)
+	require.True(t, ok)
+
+	b, ok := locks.wrap(chlg, seqProvider).(*sequentialProvider)
+	require.True(t, ok)
+
+	// The lock is shared by the providers of the same challenge.
+	assert.Same(t, a.lock, b.lock)
+	assert.Equal(t, time.Second, b.Sequential())
+
+	var nilLocks *sequentialLocks
+
+	assert.Same(t, seqProvider, nilLocks.wrap(chlg, seqProvider))
+}
+
+func Test_sequentialProvider(t *testing.T) {
+	interval := 100 * time.Millisecond
+
+	locks := newSequentialLocks()
+
+	chlg := &configuration.DNSChallenge{Provider: "exec"}
+
+	seqProvider := &fakeSequentialProvider{interval: interval}
+
+	var (
+		mu       sync.Mutex
+		active   int
+		maxCount int
+		events   []time.Time
+	)
+
+	seqProvider.onPresent = func() {
+		mu.Lock()
+		defer mu.Unlock()
+
+		active++
+		maxCount = max(maxCount, active)
+
+		events = append(events, time.Now())
+	}
+
+	seqProvider.onCleanUp = func() {
+		mu.Lock()
+		defer mu.Unlock()
+
+		active--
+
+		events = append(events, time.Now())
+	}
+
+	var wg sync.WaitGroup
+
+	for _, domain := range []string{"a.example.com", "b.example.com", "c.example.com"} {
+		provider := locks.wrap(chlg, seqProvider)
+
+		wg.Go(func() {
+			assert.NoError(t, provider.Present(t.Context(), domain, "token", "keyAuth"))
+			assert.NoError(t, provider.CleanUp(t.Context(), domain, "token", "keyAuth"))
+		})
+	}
+
+	wg.Wait()
+
+	assert.Equal(t, 1, maxCount)
+
+	require.Len(t, events, 6)
+
+	// The interval is respected between a cleanup and the next challenge.
+	for i := 2; i < len(events); i += 2 {
+		assert.GreaterOrEqual(t, events[i].Sub(events[i-1]), interval)
+	}
+}
+
+func Test_sequentialProvider_cleanUpWithoutPresent(t *testing.T) {
+	locks := newSequentialLocks()
+
+	provider := locks.wrap(&configuration.DNSChallenge{Provider: "exec"}, &fakeSequentialProvider{})
+
+	require.NoError(t, provider.Present(t.Context(), "a.example.com", "token", "keyAuth"))
+
+	// The cleanup of another challenge doesn't release the lock.
+	require.NoError(t, provider.CleanUp(t.Context(), "b.example.com", "token", "keyAuth"))
+
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+
+	err := provider.Present(ctx, "b.example.com", "token", "keyAuth")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	require.NoError(t, provider.CleanUp(t.Context(), "a.example.com", "token", "keyAuth"))
+
+	require.NoError(t, provider.Present(t.Context(), "b.example.com", "token", "keyAuth"))
+}
+
+type fakeProvider struct{}
+
+func (p *fakeProvider) Present(_ context.Context, _, _, _ string) error {
+	return nil
+}
+
+func (p *fakeProvider) CleanUp(_ context.Context, _, _, _ string) error {
+	return nil
+}
+
+type fakeSequentialProvider struct {
+	interval time.Duration
+
+	onPresent func()
+	onCleanUp func()
+}
+
+func (p *fakeSequentialProvider) Present(_ context.Context, _, _, _ string) error {
+	if p.onPresent != nil {
+		p.onPresent()
+	}
+
+	return nil
+}
+
+func (p *fakeSequentialProvider) CleanUp(_ context.Context, _, _, _ string) error {
+	if p.onCleanUp != nil {
+		p.onCleanUp()
+	}
+
+	return nil
+}
+
+func (p *fakeSequentialProvider) Sequential() time.Duration {
+	return p.interval
+}
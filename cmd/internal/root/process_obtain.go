@@ -6,8 +6,10 @@ import (
 	"fmt"
 
 	"github.com/go-acme/lego/v5/certificate"
+	"github.com/go-acme/lego/v5/cmd/internal"
 	"github.com/go-acme/lego/v5/cmd/internal/configuration"
 	"github.com/go-acme/lego/v5/cmd/internal/hook"
+	"github.com/go-acme/lego/v5/cmd/internal/pkcs11"
 	"github.com/go-acme/lego/v5/cmd/internal/storage"
 	"github.com/go-acme/lego/v5/lego"
 )
@@ -32,6 +34,17 @@ func obtainForDomains(ctx context.Context, client *lego.Client, certID string, c
 	// I didn't find a use case for it when using the file configuration.
 	// Maybe this can be added in the future.
 
+	if certConfig.KeySource != "" {
+		signer, err := pkcs11.GetOrGenerateSigner(certConfig.KeySource, certConfig.KeyType)
+		if err != nil {
+			return fmt.Errorf("certificate key source: %w", err)
+		}
+
+		defer func() { _ = signer.Close() }()
+
+		request.PrivateKey = signer
+	}
+
 	err := hookManager.PreForDomains(ctx, certID, request)
 	if err != nil {
 		return err
@@ -48,20 +61,22 @@ func obtainForDomains(ctx context.Context, client *lego.Client, certID string, c
 		certRes.ID = certID
 	}
 
+	internal.RecordCertificateExpiry(certRes)
+
 	options := newSaveOptions(certConfig)
 
-	err = certsStorage.Save(
-		&storage.Certificate{
-			Resource: certRes,
-			Origin:   storage.OriginConfiguration,
-		},
-		options,
-	)
+	storedCert := &storage.Certificate{
+		Resource:  certRes,
+		Origin:    storage.OriginConfiguration,
+		KeySource: getKeyReference(certConfig),
+	}
+
+	err = certsStorage.Save(storedCert, options)
 	if err != nil {
 		return fmt.Errorf("could not save the resource: %w", err)
 	}
 
-	return hookManager.Deploy(ctx, certRes, options)
+	return hookManager.Deploy(ctx, storedCert, options)
 }
 
 func obtainForCSR(ctx context.Context, client *lego.Client, certID string, certConfig *configuration.Certificate, certsStorage *storage.CertificatesStorage, hookManager *hook.Manager) error {
@@ -93,20 +108,21 @@ func obtainForCSR(ctx context.Context, client *lego.Client, certID string, certC
 		certRes.ID = certID
 	}
 
+	internal.RecordCertificateExpiry(certRes)
+
 	options := newSaveOptions(certConfig)
 
-	err = certsStorage.Save(
-		&storage.Certificate{
-			Resource: certRes,
-			Origin:   storage.OriginConfiguration,
-		},
-		options,
-	)
+	storedCert := &storage.Certificate{
+		Resource: certRes,
+		Origin:   storage.OriginConfiguration,
+	}
+
+	err = certsStorage.Save(storedCert, options)
 	if err != nil {
 		return fmt.Errorf("could not save the resource: %w", err)
 	}
 
-	return hookManager.Deploy(ctx, certRes, options)
+	return hookManager.Deploy(ctx, storedCert, options)
 }
 
 func newObtainRequest(certConfig *configuration.Certificate, domains []string) certificate.ObtainRequest {
@@ -121,6 +137,9 @@ func newObtainRequest(certConfig *configuration.Certificate, domains []string) c
 		EnableCommonName:               certConfig.EnableCommonName,
 		Profile:                        certConfig.Profile,
 		AlwaysDeactivateAuthorizations: certConfig.AlwaysDeactivateAuthorizations,
+		ChallengeFallback:              certConfig.ChallengeFallback,
+		CheckCAA:                       certConfig.CheckCAA,
+		MinAuthorizationValidity:       certConfig.MinAuthorizationValidity,
 	}
 }
 
@@ -134,12 +153,16 @@ func newObtainForCSRRequest(certConfig *configuration.Certificate, csr *x509.Cer
 		EnableCommonName:               certConfig.EnableCommonName,
 		Profile:                        certConfig.Profile,
 		AlwaysDeactivateAuthorizations: certConfig.AlwaysDeactivateAuthorizations,
+		ChallengeFallback:              certConfig.ChallengeFallback,
+		CheckCAA:                       certConfig.CheckCAA,
+		MinAuthorizationValidity:       certConfig.MinAuthorizationValidity,
 	}
 }
 
 func newSaveOptions(certConfig *configuration.Certificate) *storage.SaveOptions {
 	opt := &storage.SaveOptions{
-		PEM: true,
+		// The PEM file contains the private key, so it cannot be created when the key is held by a token.
+		PEM: certConfig.KeySource == "",
 	}
 
 	if certConfig.PFX != nil {
@@ -150,3 +173,12 @@ func newSaveOptions(certConfig *configuration.Certificate) *storage.SaveOptions
 
 	return opt
 }
+
+// getKeyReference returns the reference to the private key held by a PKCS#11 token (without the PIN).
+func getKeyReference(certConfig *configuration.Certificate) string {
+	if certConfig.KeySource == "" {
+		return ""
+	}
+
+	return pkcs11.KeyReference(certConfig.KeySource)
+}
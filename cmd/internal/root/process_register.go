@@ -8,6 +8,7 @@ import (
 
 	"github.com/go-acme/lego/v5/acme"
 	"github.com/go-acme/lego/v5/cmd/internal/configuration"
+	"github.com/go-acme/lego/v5/cmd/internal/pkcs11"
 	"github.com/go-acme/lego/v5/cmd/internal/prompt"
 	"github.com/go-acme/lego/v5/cmd/internal/storage"
 	"github.com/go-acme/lego/v5/lego"
@@ -16,6 +17,36 @@ import (
 	"github.com/go-acme/lego/v5/registration/zerossl"
 )
 
+// getAccount gets the account from the storage.
+// If the account key is held by a PKCS#11 token (keySource), the key is used through the token.
+// The returned function releases the resources related to the key.
+func getAccount(accountsStorage *storage.AccountsStorage, accountConfig *configuration.Account, server string) (*storage.Account, func(), error) {
+	if accountConfig.KeySource == "" {
+		account, err := accountsStorage.Get(server, accountConfig.KeyType, accountConfig.Email, accountConfig.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return account, func() {}, nil
+	}
+
+	signer, err := pkcs11.NewSigner(accountConfig.KeySource)
+	if err != nil {
+		return nil, nil, fmt.Errorf("account key source: %w", err)
+	}
+
+	closeFn := func() { _ = signer.Close() }
+
+	account, err := accountsStorage.GetWithKey(server, signer, accountConfig.Email, accountConfig.ID)
+	if err != nil {
+		closeFn()
+
+		return nil, nil, err
+	}
+
+	return account, closeFn, nil
+}
+
 func handleRegistration(ctx context.Context, lazyClient lzSetUp, accountConfig *configuration.Account, accountsStorage *storage.AccountsStorage, account *storage.Account, allowRegister bool) error {
 	err := updateAccountOrigin(accountsStorage, account)
 	if err != nil {
@@ -15,11 +15,14 @@ import (
 
 	"github.com/go-acme/lego/v5/acme/api"
 	"github.com/go-acme/lego/v5/certcrypto"
+	"github.com/go-acme/lego/v5/cmd/internal"
 	"github.com/go-acme/lego/v5/cmd/internal/configuration"
 	"github.com/go-acme/lego/v5/cmd/internal/hook"
+	"github.com/go-acme/lego/v5/cmd/internal/pkcs11"
 	"github.com/go-acme/lego/v5/cmd/internal/storage"
 	"github.com/go-acme/lego/v5/lego"
 	"github.com/go-acme/lego/v5/log"
+	"github.com/go-acme/lego/v5/metrics"
 	"github.com/mattn/go-isatty"
 )
 
@@ -32,6 +35,9 @@ type renewProcessor struct {
 
 	certsStorage *storage.CertificatesStorage
 	hookManager  *hook.Manager
+
+	// force skips the renewal period checks.
+	force bool
 }
 
 func (p *renewProcessor) renew(ctx context.Context, certID string, resource *storage.Certificate) error {
@@ -52,6 +58,8 @@ func (p *renewProcessor) renewForDomains(ctx context.Context, certID string, cha
 
 	cert := certificates[0]
 
+	metrics.Default().SetCertificateExpiry(certID, cert.NotAfter)
+
 	if cert.IsCA {
 		return fmt.Errorf("certificate bundle for %q starts with a CA certificate", certID)
 	}
@@ -71,7 +79,7 @@ func (p *renewProcessor) renewForDomains(ctx context.Context, certID string, cha
 			return err
 		}
 
-		if ariRenewalTime == nil && !isInRenewalPeriod(cert, certID, p.certConfig.Renew.Days, time.Now()) {
+		if ariRenewalTime == nil && !p.force && !isInRenewalPeriod(cert, certID, p.certConfig.Renew.Days, time.Now()) {
 			return nil
 		}
 	} else {
@@ -85,8 +93,21 @@ func (p *renewProcessor) renewForDomains(ctx context.Context, certID string, cha
 	)
 
 	request := newObtainRequest(p.certConfig, renewalDomains)
+	request.Renewal = true
+
+	switch {
+	case p.certConfig.KeySource != "":
+		// The key held by the token is always reused.
+		signer, errS := pkcs11.GetOrGenerateSigner(p.certConfig.KeySource, p.certConfig.KeyType)
+		if errS != nil {
+			return fmt.Errorf("certificate key source: %w", errS)
+		}
+
+		defer func() { _ = signer.Close() }()
+
+		request.PrivateKey = signer
 
-	if p.certConfig.Renew != nil && p.certConfig.Renew.ReuseKey {
+	case p.certConfig.Renew != nil && p.certConfig.Renew.ReuseKey:
 		request.PrivateKey, err = p.certsStorage.ReadPrivateKey(certID)
 		if err != nil {
 			return err
@@ -116,25 +137,31 @@ func (p *renewProcessor) renewForDomains(ctx context.Context, certID string, cha
 
 	certRes, err := client.Certificate.Obtain(ctx, request)
 	if err != nil {
+		metrics.Default().IncRenewal(certID, err)
+
 		return fmt.Errorf("could not obtain the certificate for %q: %w", certID, err)
 	}
 
+	metrics.Default().IncRenewal(certID, nil)
+
 	certRes.ID = certID
 
+	internal.RecordCertificateExpiry(certRes)
+
 	options := newSaveOptions(p.certConfig)
 
-	err = p.certsStorage.Save(
-		&storage.Certificate{
-			Resource: certRes,
-			Origin:   storage.OriginConfiguration,
-		},
-		options,
-	)
+	storedCert := &storage.Certificate{
+		Resource:  certRes,
+		Origin:    storage.OriginConfiguration,
+		KeySource: getKeyReference(p.certConfig),
+	}
+
+	err = p.certsStorage.Save(storedCert, options)
 	if err != nil {
 		return fmt.Errorf("could not save the resource: %w", err)
 	}
 
-	return p.hookManager.Deploy(ctx, certRes, options)
+	return p.hookManager.Deploy(ctx, storedCert, options)
 }
 
 func (p *renewProcessor) renewForCSR(ctx context.Context, certID string, changed bool) error {
@@ -151,6 +178,8 @@ func (p *renewProcessor) renewForCSR(ctx context.Context, certID string, changed
 
 	cert := certificates[0]
 
+	metrics.Default().SetCertificateExpiry(certID, cert.NotAfter)
+
 	if cert.IsCA {
 		return fmt.Errorf("CSR: certificate bundle for %q starts with a CA certificate", certID)
 	}
@@ -166,7 +195,7 @@ func (p *renewProcessor) renewForCSR(ctx context.Context, certID string, changed
 			return fmt.Errorf("CSR: %w", err)
 		}
 
-		if ariRenewalTime == nil && !isInRenewalPeriod(cert, certID, p.certConfig.Renew.Days, time.Now()) {
+		if ariRenewalTime == nil && !p.force && !isInRenewalPeriod(cert, certID, p.certConfig.Renew.Days, time.Now()) {
 			return nil
 		}
 	} else {
@@ -180,6 +209,7 @@ func (p *renewProcessor) renewForCSR(ctx context.Context, certID string, changed
 	)
 
 	request := newObtainForCSRRequest(p.certConfig, csr)
+	request.Renewal = true
 
 	if replacesCertID != "" {
 		request.ReplacesCertID = replacesCertID
@@ -199,25 +229,31 @@ func (p *renewProcessor) renewForCSR(ctx context.Context, certID string, changed
 
 	certRes, err := client.Certificate.ObtainForCSR(ctx, request)
 	if err != nil {
+		metrics.Default().IncRenewal(certID, err)
+
 		return fmt.Errorf("CSR: could not obtain the certificate: %w", err)
 	}
 
+	metrics.Default().IncRenewal(certID, nil)
+
 	certRes.ID = certID
 
+	internal.RecordCertificateExpiry(certRes)
+
 	options := newSaveOptions(p.certConfig)
 
-	err = p.certsStorage.Save(
-		&storage.Certificate{
-			Resource: certRes,
-			Origin:   storage.OriginConfiguration,
-		},
-		options,
-	)
+	storedCert := &storage.Certificate{
+		Resource:  certRes,
+		Origin:    storage.OriginConfiguration,
+		KeySource: getKeyReference(p.certConfig),
+	}
+
+	err = p.certsStorage.Save(storedCert, options)
 	if err != nil {
 		return fmt.Errorf("CSR: could not save the resource: %w", err)
 	}
 
-	return p.hookManager.Deploy(ctx, certRes, options)
+	return p.hookManager.Deploy(ctx, storedCert, options)
 }
 
 func (p *renewProcessor) getARIInfo(ctx context.Context, certID string, cert *x509.Certificate) (*time.Time, string, error) {
@@ -314,6 +350,8 @@ func getARIRenewalTime(ctx context.Context, willingToSleep time.Duration, cert *
 		return nil
 	}
 
+	metrics.Default().SetRenewalWindow(certID, renewalInfo.SuggestedWindow.Start, renewalInfo.SuggestedWindow.End)
+
 	now := time.Now().UTC()
 
 	renewalTime := renewalInfo.ShouldRenewAt(now, willingToSleep)
@@ -371,5 +409,5 @@ func sameDomains(a, b []string) bool {
 }
 
 func hasChanged(resource *storage.Certificate, cfg *configuration.Certificate) bool {
-	return resource.Profile != cfg.Profile
+	return resource.Profile != cfg.Profile || resource.KeySource != getKeyReference(cfg)
 }
@@ -0,0 +1,64 @@
+package root
+
+import (
+	"testing"
+
+	"github.com/go-acme/lego/v5/certificate"
+	"github.com/go-acme/lego/v5/cmd/internal/configuration"
+	"github.com/go-acme/lego/v5/cmd/internal/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_hasChanged(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		resource *storage.Certificate
+		cfg      *configuration.Certificate
+		assert   assert.BoolAssertionFunc
+	}{
+		{
+			desc:     "no changes",
+			resource: &storage.Certificate{Resource: &certificate.Resource{Profile: "foo"}},
+			cfg:      &configuration.Certificate{Profile: "foo"},
+			assert:   assert.False,
+		},
+		{
+			desc:     "profile",
+			resource: &storage.Certificate{Resource: &certificate.Resource{Profile: "foo"}},
+			cfg:      &configuration.Certificate{Profile: "bar"},
+			assert:   assert.True,
+		},
+		{
+			desc: "same key source",
+			resource: &storage.Certificate{
+				Resource:  &certificate.Resource{},
+				KeySource: "pkcs11:token=lego;object=cert",
+			},
+			cfg:    &configuration.Certificate{KeySource: "pkcs11:token=lego;object=cert?pin-value=1234"},
+			assert: assert.False,
+		},
+		{
+			desc:     "new key source",
+			resource: &storage.Certificate{Resource: &certificate.Resource{}},
+			cfg:      &configuration.Certificate{KeySource: "pkcs11:token=lego;object=cert"},
+			assert:   assert.True,
+		},
+		{
+			desc: "removed key source",
+			resource: &storage.Certificate{
+				Resource:  &certificate.Resource{},
+				KeySource: "pkcs11:token=lego;object=cert",
+			},
+			cfg:    &configuration.Certificate{},
+			assert: assert.True,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			test.assert(t, hasChanged(test.resource, test.cfg))
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package root
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-acme/lego/v5/cmd/internal/configuration"
+	"github.com/go-acme/lego/v5/cmd/internal/hook"
+	"github.com/go-acme/lego/v5/cmd/internal/storage"
+	"github.com/go-acme/lego/v5/lego"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_processCertificate_locked(t *testing.T) {
+	store := storage.New(t.TempDir())
+
+	cert := &configuration.Certificate{ID: "example.com", Domains: []string{"example.com"}}
+
+	unlock, err := store.Certificate.Lock(cert.ID)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = unlock() })
+
+	lazySetup := func() (*lego.Client, error) {
+		return nil, errors.New("the client must not be used")
+	}
+
+	err = processCertificate(context.Background(), lazySetup, cert, store, hook.NewManager(store.Certificate), false)
+	require.NoError(t, err)
+}
+
+func Test_processor_addError(t *testing.T) {
+	errs := map[string]error{}
+
+	p := &processor{
+		onError: func(certID string, err error) {
+			errs[certID] = err
+		},
+	}
+
+	p.addError("a.example.com", errors.New("a"))
+	p.addError("b.example.com", errors.New("b"))
+
+	// The errors are reported for each certificate, and don't skip the other certificates.
+	assert.False(t, p.failed())
+	require.NoError(t, p.err())
+
+	assert.Equal(t, map[string]error{"a.example.com": errors.New("a"), "b.example.com": errors.New("b")}, errs)
+}
+
+func Test_processor_addError_withoutHandler(t *testing.T) {
+	p := &processor{}
+
+	p.addError("a.example.com", errors.New("a"))
+
+	assert.True(t, p.failed())
+	require.EqualError(t, p.err(), "a")
+}
@@ -17,16 +17,23 @@ func Revoke(ctx context.Context, cmd *cli.Command, cfg *configuration.Configurat
 	reason := cmd.Uint(flags.FlgReason)
 	keep := cmd.Bool(flags.FlgKeep)
 
-	store := storage.New(cfg.Storage)
+	store, err := storage.NewFromConfiguration(cfg.Storage)
+	if err != nil {
+		return fmt.Errorf("set up storage: %w", err)
+	}
+
+	defer func() { _ = store.Close() }()
 
 	accountNodes := configuration.LookupCertificates(cfg, cmd.StringSlice(flags.FlgCertName))
 
 	for _, accountNode := range accountNodes {
-		account, err := store.Account.Get(accountNode.ServerConfig.URL, accountNode.KeyType, accountNode.Email, accountNode.ID)
+		account, closeKey, err := getAccount(store.Account, accountNode.Account, accountNode.ServerConfig.URL)
 		if err != nil {
 			return fmt.Errorf("set up account: %w", err)
 		}
 
+		defer closeKey()
+
 		lazyClient := sync.OnceValues(func() (*lego.Client, error) {
 			return lego.NewClient(newClientConfig(accountNode.ServerConfig, account, cfg.UserAgent))
 		})
@@ -104,9 +104,22 @@ func ReadJSONFile[T any](filename string) (*T, error) {
 		return nil, err
 	}
 
+	return unmarshalJSON[T](data)
+}
+
+func getJSON[T any](backend Backend, key string) (*T, error) {
+	data, err := backend.Get(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return unmarshalJSON[T](data)
+}
+
+func unmarshalJSON[T any](data []byte) (*T, error) {
 	result := new(T)
 
-	err = json.Unmarshal(data, result)
+	err := json.Unmarshal(data, result)
 	if err != nil {
 		return nil, err
 	}
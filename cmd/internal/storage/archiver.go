@@ -2,6 +2,7 @@ package storage
 
 import (
 	"archive/zip"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
@@ -10,6 +11,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/go-acme/lego/v5/cmd/internal/configuration"
 	"github.com/go-acme/lego/v5/log"
 	"github.com/mattn/go-zglob"
 )
@@ -20,10 +22,17 @@ const (
 	baseArchivesFolderName = "archives"
 )
 
+// ErrArchivesNotSupported is returned when the archives are used with a remote storage backend.
+var ErrArchivesNotSupported = errors.New("the archives are not supported by the remote storage backends")
+
 // Archiver manages the archiving of accounts and certificates.
 type Archiver struct {
 	basePath string
 
+	// disabled is true when the files are stored by a remote backend:
+	// the archiver only handles the files of the local filesystem.
+	disabled bool
+
 	maxTimeBeforeCleaning time.Duration
 
 	accountsBasePath     string
@@ -48,7 +57,29 @@ func NewArchiver(basePath string) *Archiver {
 	}
 }
 
+// NewArchiverFromConfiguration creates a new Archiver based on the storage configuration.
+// With a remote backend (S3, SQL), the archiving is skipped and the archives cannot be listed or restored.
+func NewArchiverFromConfiguration(cfg *configuration.Storage) *Archiver {
+	archiver := NewArchiver(cfg.Path)
+	archiver.disabled = cfg.S3 != nil || cfg.SQL != nil
+
+	return archiver
+}
+
+// skip returns true, and logs a warning, when the archiving is disabled.
+func (m *Archiver) skip() bool {
+	if m.disabled {
+		log.Warn("The archives are not supported by the remote storage backends: the files are kept in the backend.")
+	}
+
+	return m.disabled
+}
+
 func (m *Archiver) Restore(archivePath string) error {
+	if m.disabled {
+		return ErrArchivesNotSupported
+	}
+
 	err := m.restore(archivePath)
 	if err != nil {
 		return err
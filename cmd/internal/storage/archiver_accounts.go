@@ -16,6 +16,10 @@ import (
 )
 
 func (m *Archiver) Accounts(cfg *configuration.Configuration) error {
+	if m.skip() {
+		return nil
+	}
+
 	err := m.cleanArchivedAccounts()
 	if err != nil {
 		return fmt.Errorf("clean archived accounts: %w", err)
@@ -30,6 +34,10 @@ func (m *Archiver) Accounts(cfg *configuration.Configuration) error {
 }
 
 func (m *Archiver) ListArchivedAccounts() ([]string, error) {
+	if m.disabled {
+		return nil, ErrArchivesNotSupported
+	}
+
 	return listArchives(m.accountsArchivePath)
 }
 
@@ -15,7 +15,7 @@ import (
 
 func TestArchiver_Accounts(t *testing.T) {
 	cfg := &configuration.Configuration{
-		Storage: t.TempDir(),
+		Storage: &configuration.Storage{Path: t.TempDir()},
 		Accounts: map[string]*configuration.Account{
 			"foo": {
 				Server:  "https://ca.example.com/dir",
@@ -24,7 +24,7 @@ func TestArchiver_Accounts(t *testing.T) {
 		},
 	}
 
-	archiver := NewArchiver(cfg.Storage)
+	archiver := NewArchiver(cfg.Storage.Path)
 	archiver.maxTimeBeforeCleaning = 0
 
 	err := os.MkdirAll(archiver.accountsBasePath, 0o700)
@@ -57,7 +57,7 @@ func TestArchiver_Accounts(t *testing.T) {
 
 func TestArchiver_Restore_accounts(t *testing.T) {
 	cfg := &configuration.Configuration{
-		Storage: t.TempDir(),
+		Storage: &configuration.Storage{Path: t.TempDir()},
 		Accounts: map[string]*configuration.Account{
 			"foo": {
 				Server:  "https://ca.example.org/dir",
@@ -66,7 +66,7 @@ func TestArchiver_Restore_accounts(t *testing.T) {
 		},
 	}
 
-	archiver := NewArchiver(cfg.Storage)
+	archiver := NewArchiver(cfg.Storage.Path)
 	archiver.maxTimeBeforeCleaning = 0
 
 	err := os.MkdirAll(archiver.accountsBasePath, 0o700)
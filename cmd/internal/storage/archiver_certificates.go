@@ -15,6 +15,10 @@ import (
 )
 
 func (m *Archiver) Certificates(certificates map[string]*configuration.Certificate) error {
+	if m.skip() {
+		return nil
+	}
+
 	err := m.cleanArchivedCertificates()
 	if err != nil {
 		return fmt.Errorf("clean archived certificates: %w", err)
@@ -29,12 +33,20 @@ func (m *Archiver) Certificates(certificates map[string]*configuration.Certifica
 }
 
 func (m *Archiver) Certificate(certID string) error {
+	if m.skip() {
+		return nil
+	}
+
 	return m.archiveCertificates(func(resourceID string) bool {
 		return certID != resourceID
 	}, false)
 }
 
 func (m *Archiver) ListArchivedCertificates() ([]string, error) {
+	if m.disabled {
+		return nil, ErrArchivesNotSupported
+	}
+
 	return listArchives(m.certificatesArchivePath)
 }
 
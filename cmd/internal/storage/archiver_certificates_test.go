@@ -18,13 +18,13 @@ func TestArchiver_Certificates(t *testing.T) {
 	archiveDomain := "example.org"
 
 	cfg := &configuration.Configuration{
-		Storage: t.TempDir(),
+		Storage: &configuration.Storage{Path: t.TempDir()},
 		Certificates: map[string]*configuration.Certificate{
 			domain: {},
 		},
 	}
 
-	archiver := NewArchiver(cfg.Storage)
+	archiver := NewArchiver(cfg.Storage.Path)
 	archiver.maxTimeBeforeCleaning = 0
 
 	domainFiles := generateFakeCertificateFiles(t, archiver.certificatesBasePath, domain)
@@ -61,13 +61,13 @@ func TestArchiver_Restore_certificates(t *testing.T) {
 	archiveDomain := "example.org"
 
 	cfg := &configuration.Configuration{
-		Storage: t.TempDir(),
+		Storage: &configuration.Storage{Path: t.TempDir()},
 		Certificates: map[string]*configuration.Certificate{
 			domain: {},
 		},
 	}
 
-	archiver := NewArchiver(cfg.Storage)
+	archiver := NewArchiver(cfg.Storage.Path)
 	archiver.maxTimeBeforeCleaning = 0
 
 	domainFiles := generateFakeCertificateFiles(t, archiver.certificatesBasePath, domain)
@@ -227,3 +227,29 @@ func generateFakeCertificateFiles(t *testing.T, dir, domain string) []string {
 
 	return filenames
 }
+
+func TestArchiver_Certificate_remoteBackend(t *testing.T) {
+	domain := "example.com"
+
+	archiver := NewArchiverFromConfiguration(&configuration.Storage{
+		Path: t.TempDir(),
+		S3:   &configuration.S3Storage{Bucket: "lego"},
+	})
+
+	domainFiles := generateFakeCertificateFiles(t, archiver.certificatesBasePath, domain)
+
+	err := archiver.Certificate(domain)
+	require.NoError(t, err)
+
+	for _, file := range domainFiles {
+		assert.FileExists(t, file)
+	}
+
+	assert.NoDirExists(t, archiver.certificatesArchivePath)
+
+	_, err = archiver.ListArchivedCertificates()
+	require.ErrorIs(t, err, ErrArchivesNotSupported)
+
+	err = archiver.Restore(filepath.Join(archiver.certificatesArchivePath, domain+"_1.zip"))
+	require.ErrorIs(t, err, ErrArchivesNotSupported)
+}
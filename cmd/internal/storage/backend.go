@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"errors"
+	"io/fs"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/go-acme/lego/v5/cmd/internal/configuration"
+	"github.com/go-acme/lego/v5/log"
+)
+
+// ErrLocked is returned by Backend.Lock when the lock is already held.
+var ErrLocked = errors.New("already locked")
+
+// staleLockTimeout is the duration after which a lock is considered stale.
+// A lock is held during the issuance of one certificate, so this is a lot longer than the expected duration.
+const staleLockTimeout = time.Hour
+
+// Backend stores the files of the accounts and the certificates.
+//
+// The keys are slash-separated paths relative to the root of the storage,
+// ex: "certificates/example.com.crt" or "accounts/acme-v02.api.letsencrypt.org/foo@example.com/account.json".
+type Backend interface {
+	// Get returns the content associated with the key.
+	// If the key doesn't exist, the error wraps fs.ErrNotExist.
+	Get(key string) ([]byte, error)
+
+	// Put creates or replaces the content associated with the key.
+	Put(key string, data []byte) error
+
+	// List returns the keys starting with the prefix.
+	List(prefix string) ([]string, error)
+
+	// Delete removes the content associated with the key.
+	// Deleting a key that doesn't exist is not an error.
+	Delete(key string) error
+
+	// Lock acquires an exclusive lock named by the key, and returns the function to release it.
+	// If the lock is already held, the error is ErrLocked.
+	// A lock older than staleLockTimeout must be considered as released,
+	// so a held lock must be refreshed until it is released.
+	Lock(key string) (func() error, error)
+}
+
+// NewBackend creates the backend defined by the configuration.
+// The default backend is the local filesystem.
+func NewBackend(cfg *configuration.Storage) (Backend, error) {
+	switch {
+	case cfg.S3 != nil:
+		return NewS3Backend(cfg.S3)
+
+	case cfg.SQL != nil:
+		return NewSQLBackend(cfg.SQL)
+
+	default:
+		return NewFileBackend(cfg.Path), nil
+	}
+}
+
+// startLockRefresh calls refresh at each interval until the lock is released,
+// so a lock held during a long issuance is not considered stale.
+// The returned function stops the refresh.
+func startLockRefresh(name string, interval time.Duration, refresh func() error) func() {
+	stop := make(chan struct{})
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+
+			case <-ticker.C:
+				err := refresh()
+				if err != nil {
+					log.Warn("Could not refresh the lock.", slog.String("lock", name), log.ErrorAttr(err))
+				}
+			}
+		}
+	}()
+
+	return sync.OnceFunc(func() { close(stop) })
+}
+
+func exists(backend Backend, key string) (bool, error) {
+	_, err := backend.Get(key)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func notExistError(op, key string) error {
+	return &fs.PathError{Op: op, Path: key, Err: fs.ErrNotExist}
+}
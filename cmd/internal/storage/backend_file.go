@@ -0,0 +1,228 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-acme/lego/v5/log"
+	"github.com/gofrs/flock"
+)
+
+const baseLocksFolderName = ".locks"
+
+var _ Backend = (*FileBackend)(nil)
+
+// FileBackend is a Backend based on the local filesystem.
+//
+//	./.lego/certificates/example.com.crt
+//	     │    └── key
+//	     └── "path" option
+type FileBackend struct {
+	basePath string
+
+	// lockRefreshInterval is the interval of the updates of the modification time of a held lock file.
+	lockRefreshInterval time.Duration
+}
+
+// NewFileBackend creates a new FileBackend.
+func NewFileBackend(basePath string) *FileBackend {
+	return &FileBackend{
+		basePath:            basePath,
+		lockRefreshInterval: staleLockTimeout / 4,
+	}
+}
+
+// Get returns the content of the file associated with the key.
+func (b *FileBackend) Get(key string) ([]byte, error) {
+	return os.ReadFile(b.Path(key))
+}
+
+// Put writes the file associated with the key.
+// The parent directories are created if needed.
+func (b *FileBackend) Put(key string, data []byte) error {
+	filename := b.Path(key)
+
+	err := CreateNonExistingFolder(filepath.Dir(filename))
+	if err != nil {
+		return fmt.Errorf("could not check/create the directory %q: %w", filepath.Dir(filename), err)
+	}
+
+	return os.WriteFile(filename, data, filePerm)
+}
+
+// List returns the keys of the files starting with the prefix.
+func (b *FileBackend) List(prefix string) ([]string, error) {
+	root := b.Path(path.Dir(prefix))
+	if strings.HasSuffix(prefix, "/") {
+		root = b.Path(prefix)
+	}
+
+	locksPath := filepath.Join(b.basePath, baseLocksFolderName)
+
+	var keys []string
+
+	err := filepath.WalkDir(root, func(filename string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			if filename == locksPath {
+				return fs.SkipDir
+			}
+
+			return nil
+		}
+
+		rel, err := filepath.Rel(b.basePath, filename)
+		if err != nil {
+			return err
+		}
+
+		key := filepath.ToSlash(rel)
+
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// Delete removes the file associated with the key.
+func (b *FileBackend) Delete(key string) error {
+	err := os.Remove(b.Path(key))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// Lock acquires an exclusive lock (flock) on a lock file.
+// The lock file is removed when the lock is released.
+// The modification time of the lock file is updated while the lock is held.
+// A lock file not updated since staleLockTimeout is considered stale (ex: a host lost with a network filesystem),
+// and is removed.
+func (b *FileBackend) Lock(key string) (func() error, error) {
+	filename := b.Path(path.Join(baseLocksFolderName, key+".lock"))
+
+	err := CreateNonExistingFolder(filepath.Dir(filename))
+	if err != nil {
+		return nil, fmt.Errorf("could not check/create the directory %q: %w", filepath.Dir(filename), err)
+	}
+
+	unlock, err := tryFileLock(filename, b.lockRefreshInterval)
+	if !errors.Is(err, ErrLocked) {
+		return unlock, err
+	}
+
+	if !isStaleLockFile(filename) {
+		return nil, fmt.Errorf("%s: %w", key, ErrLocked)
+	}
+
+	log.Warn("Removing a stale lock.", slog.String("filepath", filename))
+
+	err = os.Remove(filename)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		log.Warn("Could not remove the stale lock.", slog.String("filepath", filename), log.ErrorAttr(err))
+
+		return nil, fmt.Errorf("%s: %w", key, ErrLocked)
+	}
+
+	unlock, err = tryFileLock(filename, b.lockRefreshInterval)
+	if errors.Is(err, ErrLocked) {
+		return nil, fmt.Errorf("%s: %w", key, ErrLocked)
+	}
+
+	return unlock, err
+}
+
+func tryFileLock(filename string, refreshInterval time.Duration) (func() error, error) {
+	fileLock := flock.New(filename, flock.SetPermissions(filePerm))
+
+	locked, err := fileLock.TryLock()
+	if err != nil {
+		return nil, fmt.Errorf("lock %q: %w", filename, err)
+	}
+
+	if !locked {
+		return nil, ErrLocked
+	}
+
+	// The lock file may have been removed (stale lock or released lock) between the opening and the locking.
+	if !isSameFile(fileLock) {
+		_ = fileLock.Unlock()
+
+		return nil, ErrLocked
+	}
+
+	// The modification time is used to detect stale locks.
+	now := time.Now()
+
+	err = os.Chtimes(filename, now, now)
+	if err != nil {
+		_ = fileLock.Unlock()
+
+		return nil, fmt.Errorf("lock %q: %w", filename, err)
+	}
+
+	// The modification time is updated until the lock is released.
+	stopRefresh := startLockRefresh(filename, refreshInterval, func() error {
+		now := time.Now()
+
+		return os.Chtimes(filename, now, now)
+	})
+
+	unlock := func() error {
+		stopRefresh()
+
+		// The file is removed before the release of the lock to avoid removing a lock acquired by another process.
+		// The removal can fail (ex: on Windows), but a remaining lock file is not a problem.
+		_ = os.Remove(filename)
+
+		return fileLock.Unlock()
+	}
+
+	return unlock, nil
+}
+
+func isSameFile(fileLock *flock.Flock) bool {
+	locked, err := fileLock.Stat()
+	if err != nil {
+		return false
+	}
+
+	current, err := os.Stat(fileLock.Path())
+	if err != nil {
+		return false
+	}
+
+	return os.SameFile(locked, current)
+}
+
+func isStaleLockFile(filename string) bool {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return false
+	}
+
+	return time.Since(info.ModTime()) > staleLockTimeout
+}
+
+// Path returns the path of the file associated with the key.
+func (b *FileBackend) Path(key string) string {
+	return filepath.Join(b.basePath, filepath.FromSlash(key))
+}
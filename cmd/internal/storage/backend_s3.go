@@ -0,0 +1,272 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+	"github.com/go-acme/lego/v5/cmd/internal/configuration"
+	"github.com/go-acme/lego/v5/log"
+)
+
+// s3Timeout is the timeout of each request to the object store.
+const s3Timeout = 30 * time.Second
+
+var _ Backend = (*S3Backend)(nil)
+
+// S3Backend is a Backend based on an S3-compatible object store.
+// The credentials are read from the environment (same as the AWS CLI).
+//
+//	s3://bucket/lego/certificates/example.com.crt
+//	      │      │     └── key
+//	      │      └── "prefix" option
+//	      └── "bucket" option
+type S3Backend struct {
+	client *s3.Client
+	bucket string
+	prefix string
+
+	// lockRefreshInterval is the interval of the rewrites of a held lock object.
+	lockRefreshInterval time.Duration
+}
+
+// NewS3Backend creates a new S3Backend.
+func NewS3Backend(cfg *configuration.S3Storage) (*S3Backend, error) {
+	if cfg.Bucket == "" {
+		return nil, errors.New("s3: bucket name missing")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s3Timeout)
+	defer cancel()
+
+	var optFns []func(*config.LoadOptions) error
+	if cfg.Region != "" {
+		optFns = append(optFns, config.WithRegion(cfg.Region))
+	}
+
+	awsCfg, err := config.LoadDefaultConfig(ctx, optFns...)
+	if err != nil {
+		return nil, fmt.Errorf("s3: unable to create AWS config: %w", err)
+	}
+
+	client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		if cfg.Endpoint != "" {
+			o.BaseEndpoint = aws.String(cfg.Endpoint)
+		}
+
+		o.UsePathStyle = cfg.UsePathStyle
+	})
+
+	return &S3Backend{
+		client:              client,
+		bucket:              cfg.Bucket,
+		prefix:              strings.Trim(cfg.Prefix, "/"),
+		lockRefreshInterval: staleLockTimeout / 4,
+	}, nil
+}
+
+// Get returns the content of the object associated with the key.
+func (b *S3Backend) Get(key string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s3Timeout)
+	defer cancel()
+
+	output, err := b.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(b.objectKey(key)),
+	})
+	if err != nil {
+		var nsk *types.NoSuchKey
+		if errors.As(err, &nsk) {
+			return nil, notExistError("get", key)
+		}
+
+		return nil, fmt.Errorf("s3: get %q: %w", key, err)
+	}
+
+	defer func() { _ = output.Body.Close() }()
+
+	return io.ReadAll(output.Body)
+}
+
+// Put creates or replaces the object associated with the key.
+func (b *S3Backend) Put(key string, data []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s3Timeout)
+	defer cancel()
+
+	_, err := b.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(b.objectKey(key)),
+		Body:   bytes.NewReader(data),
+	})
+	if err != nil {
+		return fmt.Errorf("s3: put %q: %w", key, err)
+	}
+
+	return nil
+}
+
+// List returns the keys of the objects starting with the prefix.
+func (b *S3Backend) List(prefix string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s3Timeout)
+	defer cancel()
+
+	paginator := s3.NewListObjectsV2Paginator(b.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(b.bucket),
+		Prefix: aws.String(b.objectKey(prefix)),
+	})
+
+	var keys []string
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("s3: list %q: %w", prefix, err)
+		}
+
+		for _, object := range page.Contents {
+			key := aws.ToString(object.Key)
+
+			if b.prefix != "" {
+				key = strings.TrimPrefix(key, b.prefix+"/")
+			}
+
+			if strings.HasPrefix(key, baseLocksFolderName+"/") {
+				continue
+			}
+
+			keys = append(keys, key)
+		}
+	}
+
+	return keys, nil
+}
+
+// Delete removes the object associated with the key.
+func (b *S3Backend) Delete(key string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s3Timeout)
+	defer cancel()
+
+	_, err := b.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(b.objectKey(key)),
+	})
+	if err != nil {
+		return fmt.Errorf("s3: delete %q: %w", key, err)
+	}
+
+	return nil
+}
+
+// Lock creates a lock object with a conditional write (If-None-Match).
+// The lock object is rewritten (If-Match) while the lock is held, and removed when the lock is released.
+// A lock object older than staleLockTimeout is considered stale, and is removed.
+func (b *S3Backend) Lock(key string) (func() error, error) {
+	lockKey := path.Join(baseLocksFolderName, key+".lock")
+
+	unlock, err := b.tryLock(lockKey)
+	if !errors.Is(err, ErrLocked) {
+		return unlock, err
+	}
+
+	if !b.isStaleLock(lockKey) {
+		return nil, fmt.Errorf("%s: %w", key, ErrLocked)
+	}
+
+	log.Warn("Removing a stale lock.", slog.String("key", lockKey))
+
+	err = b.Delete(lockKey)
+	if err != nil {
+		return nil, err
+	}
+
+	unlock, err = b.tryLock(lockKey)
+	if errors.Is(err, ErrLocked) {
+		return nil, fmt.Errorf("%s: %w", key, ErrLocked)
+	}
+
+	return unlock, err
+}
+
+func (b *S3Backend) tryLock(lockKey string) (func() error, error) {
+	etag, err := b.putLock(lockKey, &s3.PutObjectInput{IfNoneMatch: aws.String("*")})
+	if err != nil {
+		return nil, err
+	}
+
+	// The modification time of the lock object is updated until the lock is released.
+	// The conditional write (If-Match) doesn't overwrite a lock acquired by another process.
+	stopRefresh := startLockRefresh(lockKey, b.lockRefreshInterval, func() error {
+		newETag, errP := b.putLock(lockKey, &s3.PutObjectInput{IfMatch: etag})
+		if errP != nil {
+			return errP
+		}
+
+		etag = newETag
+
+		return nil
+	})
+
+	unlock := func() error {
+		stopRefresh()
+
+		return b.Delete(lockKey)
+	}
+
+	return unlock, nil
+}
+
+// putLock writes the lock object with the conditions of the input, and returns the ETag of the lock object.
+func (b *S3Backend) putLock(lockKey string, input *s3.PutObjectInput) (*string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s3Timeout)
+	defer cancel()
+
+	input.Bucket = aws.String(b.bucket)
+	input.Key = aws.String(b.objectKey(lockKey))
+	input.Body = strings.NewReader(time.Now().UTC().Format(time.RFC3339))
+
+	output, err := b.client.PutObject(ctx, input)
+	if err != nil {
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) && (apiErr.ErrorCode() == "PreconditionFailed" || apiErr.ErrorCode() == "ConditionalRequestConflict") {
+			return nil, ErrLocked
+		}
+
+		return nil, fmt.Errorf("s3: lock %q: %w", lockKey, err)
+	}
+
+	return output.ETag, nil
+}
+
+func (b *S3Backend) isStaleLock(lockKey string) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), s3Timeout)
+	defer cancel()
+
+	output, err := b.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(b.objectKey(lockKey)),
+	})
+	if err != nil || output.LastModified == nil {
+		return false
+	}
+
+	return time.Since(*output.LastModified) > staleLockTimeout
+}
+
+func (b *S3Backend) objectKey(key string) string {
+	if b.prefix == "" {
+		return key
+	}
+
+	return b.prefix + "/" + key
+}
@@ -0,0 +1,162 @@
+package storage
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeS3Object is an object of the fakeS3 server.
+type fakeS3Object struct {
+	etag    string
+	modTime time.Time
+}
+
+// fakeS3 is a minimal S3 server, only for the lock objects (conditional writes, HEAD, DELETE).
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string]*fakeS3Object
+	version int
+}
+
+func (f *fakeS3) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	object, ok := f.objects[req.URL.Path]
+
+	switch req.Method {
+	case http.MethodPut:
+		if req.Header.Get("If-None-Match") == "*" && ok {
+			rw.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+
+		if match := req.Header.Get("If-Match"); match != "" && (!ok || object.etag != match) {
+			rw.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+
+		f.version++
+
+		object = &fakeS3Object{etag: strconv.Quote(strconv.Itoa(f.version)), modTime: time.Now()}
+		f.objects[req.URL.Path] = object
+
+		rw.Header().Set("ETag", object.etag)
+
+	case http.MethodHead:
+		if !ok {
+			rw.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		rw.Header().Set("ETag", object.etag)
+		rw.Header().Set("Last-Modified", object.modTime.UTC().Format(http.TimeFormat))
+
+	case http.MethodDelete:
+		delete(f.objects, req.URL.Path)
+
+		rw.WriteHeader(http.StatusNoContent)
+
+	default:
+		rw.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (f *fakeS3) setModTime(path string, modTime time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.objects[path].modTime = modTime
+}
+
+func (f *fakeS3) getModTime(path string) (time.Time, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	object, ok := f.objects[path]
+	if !ok {
+		return time.Time{}, false
+	}
+
+	return object.modTime, true
+}
+
+func setupS3Backend(t *testing.T) (*S3Backend, *fakeS3) {
+	t.Helper()
+
+	fake := &fakeS3{objects: make(map[string]*fakeS3Object)}
+
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	client := s3.New(s3.Options{
+		BaseEndpoint:               aws.String(server.URL),
+		Region:                     "us-east-1",
+		UsePathStyle:               true,
+		Credentials:                aws.AnonymousCredentials{},
+		RequestChecksumCalculation: aws.RequestChecksumCalculationWhenRequired,
+		RetryMaxAttempts:           1,
+	})
+
+	backend := &S3Backend{
+		client:              client,
+		bucket:              "lego",
+		prefix:              "data",
+		lockRefreshInterval: staleLockTimeout / 4,
+	}
+
+	return backend, fake
+}
+
+func TestS3Backend_Lock_stale(t *testing.T) {
+	backend, fake := setupS3Backend(t)
+
+	_, err := backend.Lock("certificates/example.com")
+	require.NoError(t, err)
+
+	_, err = backend.Lock("certificates/example.com")
+	require.ErrorIs(t, err, ErrLocked)
+
+	fake.setModTime("/lego/data/.locks/certificates/example.com.lock", time.Now().Add(-2*staleLockTimeout))
+
+	unlock, err := backend.Lock("certificates/example.com")
+	require.NoError(t, err)
+
+	require.NoError(t, unlock())
+
+	_, ok := fake.getModTime("/lego/data/.locks/certificates/example.com.lock")
+	assert.False(t, ok)
+}
+
+func TestS3Backend_Lock_refresh(t *testing.T) {
+	backend, fake := setupS3Backend(t)
+	backend.lockRefreshInterval = 10 * time.Millisecond
+
+	unlock, err := backend.Lock("certificates/example.com")
+	require.NoError(t, err)
+
+	path := "/lego/data/.locks/certificates/example.com.lock"
+
+	fake.setModTime(path, time.Now().Add(-2*staleLockTimeout))
+
+	// The lock is held: the lock object is rewritten.
+	assert.Eventually(t, func() bool {
+		modTime, ok := fake.getModTime(path)
+
+		return ok && time.Since(modTime) < staleLockTimeout
+	}, time.Second, 10*time.Millisecond)
+
+	_, err = backend.Lock("certificates/example.com")
+	require.ErrorIs(t, err, ErrLocked)
+
+	require.NoError(t, unlock())
+}
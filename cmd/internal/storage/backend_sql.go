@@ -0,0 +1,257 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-acme/lego/v5/cmd/internal/configuration"
+	_ "github.com/jackc/pgx/v5/stdlib" // Postgres driver.
+	_ "modernc.org/sqlite"             // SQLite driver.
+)
+
+// sqlTimeout is the timeout of each query.
+const sqlTimeout = 30 * time.Second
+
+type sqlDialect struct {
+	driverName string
+	blobType   string
+	// placeholder returns the placeholder of the n-th (1-based) argument.
+	placeholder func(n int) string
+}
+
+var sqlDialects = map[string]sqlDialect{
+	configuration.SQLDriverSQLite: {
+		driverName:  "sqlite",
+		blobType:    "BLOB",
+		placeholder: func(int) string { return "?" },
+	},
+	configuration.SQLDriverPostgres: {
+		driverName:  "pgx",
+		blobType:    "BYTEA",
+		placeholder: func(n int) string { return "$" + strconv.Itoa(n) },
+	},
+}
+
+var _ Backend = (*SQLBackend)(nil)
+
+// SQLBackend is a Backend based on a SQL database (SQLite or Postgres).
+//
+// The files are stored inside the table defined by the configuration,
+// and the locks inside a second table with the "_locks" suffix.
+type SQLBackend struct {
+	db      *sql.DB
+	dialect sqlDialect
+
+	table      string
+	locksTable string
+
+	// lockRefreshInterval is the interval of the updates of the creation time of a held lock.
+	lockRefreshInterval time.Duration
+}
+
+// NewSQLBackend creates a new SQLBackend.
+// The tables are created if they don't exist.
+func NewSQLBackend(cfg *configuration.SQLStorage) (*SQLBackend, error) {
+	dialect, ok := sqlDialects[cfg.Driver]
+	if !ok {
+		return nil, fmt.Errorf("sql: unsupported driver %q", cfg.Driver)
+	}
+
+	db, err := sql.Open(dialect.driverName, cfg.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("sql: open database: %w", err)
+	}
+
+	b := &SQLBackend{
+		db:                  db,
+		dialect:             dialect,
+		table:               cfg.Table,
+		locksTable:          cfg.Table + "_locks",
+		lockRefreshInterval: staleLockTimeout / 4,
+	}
+
+	err = b.createTables()
+	if err != nil {
+		_ = db.Close()
+
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// Close closes the database.
+func (b *SQLBackend) Close() error {
+	return b.db.Close()
+}
+
+func (b *SQLBackend) createTables() error {
+	ctx, cancel := context.WithTimeout(context.Background(), sqlTimeout)
+	defer cancel()
+
+	queries := []string{
+		fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (name TEXT PRIMARY KEY, content %s NOT NULL)", b.table, b.dialect.blobType),
+		fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (name TEXT PRIMARY KEY, created_at BIGINT NOT NULL)", b.locksTable),
+	}
+
+	for _, query := range queries {
+		_, err := b.db.ExecContext(ctx, query)
+		if err != nil {
+			return fmt.Errorf("sql: create table: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Get returns the content of the row associated with the key.
+func (b *SQLBackend) Get(key string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), sqlTimeout)
+	defer cancel()
+
+	query := fmt.Sprintf("SELECT content FROM %s WHERE name = %s", b.table, b.dialect.placeholder(1))
+
+	var data []byte
+
+	err := b.db.QueryRowContext(ctx, query, key).Scan(&data)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, notExistError("get", key)
+		}
+
+		return nil, fmt.Errorf("sql: get %q: %w", key, err)
+	}
+
+	return data, nil
+}
+
+// Put creates or replaces the row associated with the key.
+func (b *SQLBackend) Put(key string, data []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), sqlTimeout)
+	defer cancel()
+
+	query := fmt.Sprintf("INSERT INTO %s (name, content) VALUES (%s, %s) ON CONFLICT (name) DO UPDATE SET content = excluded.content",
+		b.table, b.dialect.placeholder(1), b.dialect.placeholder(2))
+
+	_, err := b.db.ExecContext(ctx, query, key, data)
+	if err != nil {
+		return fmt.Errorf("sql: put %q: %w", key, err)
+	}
+
+	return nil
+}
+
+// List returns the keys starting with the prefix.
+func (b *SQLBackend) List(prefix string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), sqlTimeout)
+	defer cancel()
+
+	query := fmt.Sprintf(`SELECT name FROM %s WHERE name LIKE %s ESCAPE '\' ORDER BY name`, b.table, b.dialect.placeholder(1))
+
+	pattern := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(prefix) + "%"
+
+	rows, err := b.db.QueryContext(ctx, query, pattern)
+	if err != nil {
+		return nil, fmt.Errorf("sql: list %q: %w", prefix, err)
+	}
+
+	defer func() { _ = rows.Close() }()
+
+	var keys []string
+
+	for rows.Next() {
+		var key string
+
+		err = rows.Scan(&key)
+		if err != nil {
+			return nil, fmt.Errorf("sql: list %q: %w", prefix, err)
+		}
+
+		// LIKE can be case-insensitive (SQLite).
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys, rows.Err()
+}
+
+// Delete removes the row associated with the key.
+func (b *SQLBackend) Delete(key string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), sqlTimeout)
+	defer cancel()
+
+	query := fmt.Sprintf("DELETE FROM %s WHERE name = %s", b.table, b.dialect.placeholder(1))
+
+	_, err := b.db.ExecContext(ctx, query, key)
+	if err != nil {
+		return fmt.Errorf("sql: delete %q: %w", key, err)
+	}
+
+	return nil
+}
+
+// Lock inserts a row inside the locks table.
+// The creation time of the row is updated while the lock is held, and the row is removed when the lock is released.
+// A row older than staleLockTimeout is considered stale, and is removed.
+func (b *SQLBackend) Lock(key string) (func() error, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), sqlTimeout)
+	defer cancel()
+
+	staleQuery := fmt.Sprintf("DELETE FROM %s WHERE name = %s AND created_at < %s",
+		b.locksTable, b.dialect.placeholder(1), b.dialect.placeholder(2))
+
+	_, err := b.db.ExecContext(ctx, staleQuery, key, time.Now().Add(-staleLockTimeout).Unix())
+	if err != nil {
+		return nil, fmt.Errorf("sql: lock %q: remove stale lock: %w", key, err)
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (name, created_at) VALUES (%s, %s) ON CONFLICT (name) DO NOTHING",
+		b.locksTable, b.dialect.placeholder(1), b.dialect.placeholder(2))
+
+	result, err := b.db.ExecContext(ctx, query, key, time.Now().Unix())
+	if err != nil {
+		return nil, fmt.Errorf("sql: lock %q: %w", key, err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("sql: lock %q: %w", key, err)
+	}
+
+	if affected == 0 {
+		return nil, fmt.Errorf("%s: %w", key, ErrLocked)
+	}
+
+	stopRefresh := startLockRefresh(key, b.lockRefreshInterval, func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), sqlTimeout)
+		defer cancel()
+
+		query := fmt.Sprintf("UPDATE %s SET created_at = %s WHERE name = %s",
+			b.locksTable, b.dialect.placeholder(1), b.dialect.placeholder(2))
+
+		_, err := b.db.ExecContext(ctx, query, time.Now().Unix(), key)
+
+		return err
+	})
+
+	unlock := func() error {
+		stopRefresh()
+
+		ctx, cancel := context.WithTimeout(context.Background(), sqlTimeout)
+		defer cancel()
+
+		query := fmt.Sprintf("DELETE FROM %s WHERE name = %s", b.locksTable, b.dialect.placeholder(1))
+
+		_, err := b.db.ExecContext(ctx, query, key)
+
+		return err
+	}
+
+	return unlock, nil
+}
@@ -0,0 +1,202 @@
+package storage
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v5/cmd/internal/configuration"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileBackend(t *testing.T) {
+	testBackend(t, NewFileBackend(t.TempDir()))
+}
+
+func TestSQLBackend(t *testing.T) {
+	backend, err := NewSQLBackend(&configuration.SQLStorage{
+		Driver: configuration.SQLDriverSQLite,
+		DSN:    filepath.Join(t.TempDir(), "lego.db"),
+		Table:  "lego_storage",
+	})
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = backend.Close() })
+
+	testBackend(t, backend)
+}
+
+func testBackend(t *testing.T, backend Backend) {
+	t.Helper()
+
+	_, err := backend.Get("certificates/example.com.crt")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+
+	err = backend.Put("certificates/example.com.crt", []byte("foo"))
+	require.NoError(t, err)
+
+	err = backend.Put("certificates/example.com.crt", []byte("bar"))
+	require.NoError(t, err)
+
+	err = backend.Put("certificates/example.org.json", []byte("{}"))
+	require.NoError(t, err)
+
+	err = backend.Put("accounts/example.com/foo@example.com/account.json", []byte("{}"))
+	require.NoError(t, err)
+
+	data, err := backend.Get("certificates/example.com.crt")
+	require.NoError(t, err)
+
+	assert.Equal(t, []byte("bar"), data)
+
+	keys, err := backend.List("certificates/")
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, []string{"certificates/example.com.crt", "certificates/example.org.json"}, keys)
+
+	keys, err = backend.List("certificates/example.c")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"certificates/example.com.crt"}, keys)
+
+	keys, err = backend.List("archives/")
+	require.NoError(t, err)
+
+	assert.Empty(t, keys)
+
+	unlock, err := backend.Lock("example.com")
+	require.NoError(t, err)
+
+	_, err = backend.Lock("example.com")
+	require.ErrorIs(t, err, ErrLocked)
+
+	keys, err = backend.List("")
+	require.NoError(t, err)
+
+	assert.Len(t, keys, 3)
+
+	require.NoError(t, unlock())
+
+	unlock, err = backend.Lock("example.com")
+	require.NoError(t, err)
+
+	require.NoError(t, unlock())
+
+	err = backend.Delete("certificates/example.com.crt")
+	require.NoError(t, err)
+
+	err = backend.Delete("certificates/example.com.crt")
+	require.NoError(t, err)
+
+	_, err = backend.Get("certificates/example.com.crt")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+}
+
+func TestFileBackend_Lock_stale(t *testing.T) {
+	backend := NewFileBackend(t.TempDir())
+
+	_, err := backend.Lock("certificates/example.com")
+	require.NoError(t, err)
+
+	_, err = backend.Lock("certificates/example.com")
+	require.ErrorIs(t, err, ErrLocked)
+
+	old := time.Now().Add(-2 * staleLockTimeout)
+
+	err = os.Chtimes(backend.Path(".locks/certificates/example.com.lock"), old, old)
+	require.NoError(t, err)
+
+	unlock, err := backend.Lock("certificates/example.com")
+	require.NoError(t, err)
+
+	require.NoError(t, unlock())
+
+	assert.NoFileExists(t, backend.Path(".locks/certificates/example.com.lock"))
+}
+
+func TestFileBackend_Lock_refresh(t *testing.T) {
+	backend := NewFileBackend(t.TempDir())
+	backend.lockRefreshInterval = 10 * time.Millisecond
+
+	unlock, err := backend.Lock("certificates/example.com")
+	require.NoError(t, err)
+
+	filename := backend.Path(".locks/certificates/example.com.lock")
+
+	old := time.Now().Add(-2 * staleLockTimeout)
+
+	err = os.Chtimes(filename, old, old)
+	require.NoError(t, err)
+
+	// The lock is held: the modification time is updated.
+	assert.Eventually(t, func() bool {
+		info, errS := os.Stat(filename)
+
+		return errS == nil && time.Since(info.ModTime()) < staleLockTimeout
+	}, time.Second, 10*time.Millisecond)
+
+	_, err = backend.Lock("certificates/example.com")
+	require.ErrorIs(t, err, ErrLocked)
+
+	require.NoError(t, unlock())
+}
+
+func TestSQLBackend_Lock_stale(t *testing.T) {
+	backend, err := NewSQLBackend(&configuration.SQLStorage{
+		Driver: configuration.SQLDriverSQLite,
+		DSN:    filepath.Join(t.TempDir(), "lego.db"),
+		Table:  "lego_storage",
+	})
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = backend.Close() })
+
+	_, err = backend.db.Exec("INSERT INTO lego_storage_locks (name, created_at) VALUES (?, ?)",
+		"certificates/example.com", time.Now().Add(-2*staleLockTimeout).Unix())
+	require.NoError(t, err)
+
+	unlock, err := backend.Lock("certificates/example.com")
+	require.NoError(t, err)
+
+	_, err = backend.Lock("certificates/example.com")
+	require.ErrorIs(t, err, ErrLocked)
+
+	require.NoError(t, unlock())
+}
+
+func TestSQLBackend_Lock_refresh(t *testing.T) {
+	backend, err := NewSQLBackend(&configuration.SQLStorage{
+		Driver: configuration.SQLDriverSQLite,
+		DSN:    filepath.Join(t.TempDir(), "lego.db"),
+		Table:  "lego_storage",
+	})
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = backend.Close() })
+
+	backend.lockRefreshInterval = 10 * time.Millisecond
+
+	unlock, err := backend.Lock("certificates/example.com")
+	require.NoError(t, err)
+
+	_, err = backend.db.Exec("UPDATE lego_storage_locks SET created_at = ? WHERE name = ?",
+		time.Now().Add(-2*staleLockTimeout).Unix(), "certificates/example.com")
+	require.NoError(t, err)
+
+	// The lock is held: the creation time is updated.
+	assert.Eventually(t, func() bool {
+		var createdAt int64
+
+		errQ := backend.db.QueryRow("SELECT created_at FROM lego_storage_locks WHERE name = ?", "certificates/example.com").Scan(&createdAt)
+
+		return errQ == nil && time.Since(time.Unix(createdAt, 0)) < staleLockTimeout
+	}, time.Second, 10*time.Millisecond)
+
+	_, err = backend.Lock("certificates/example.com")
+	require.ErrorIs(t, err, ErrLocked)
+
+	require.NoError(t, unlock())
+}
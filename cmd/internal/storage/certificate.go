@@ -6,4 +6,8 @@ type Certificate struct {
 	*certificate.Resource
 
 	Origin string `json:"origin,omitempty"`
+
+	// KeySource is the reference to the private key held by a PKCS#11 token (PKCS#11 URI without the PIN).
+	// The private key file is not saved.
+	KeySource string `json:"keySource,omitempty"`
 }
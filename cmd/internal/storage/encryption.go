@@ -0,0 +1,310 @@
+package storage
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/go-acme/lego/v5/certcrypto"
+	"github.com/go-acme/lego/v5/cmd/internal/configuration"
+	"github.com/youmark/pkcs8"
+)
+
+// Environment variables used to decrypt the private keys when there is no configuration (ex: ReadPrivateKeyFile).
+const (
+	EnvKeyPassphrase     = "LEGO_KEY_PASSPHRASE"
+	EnvKeyPassphraseFile = "LEGO_KEY_PASSPHRASE_FILE"
+	EnvAgeIdentityFile   = "LEGO_AGE_IDENTITY_FILE"
+)
+
+const pemTypeEncryptedPrivateKey = "ENCRYPTED PRIVATE KEY"
+
+// KeyEncryption encrypts and decrypts the private keys at rest.
+//
+// With a passphrase, the private key PEM blocks are converted to encrypted PKCS#8 blocks,
+// the other blocks (ex: certificates inside the PEM file) are kept as-is.
+// With age recipients, the whole content is encrypted and armored.
+//
+// A nil KeyEncryption doesn't encrypt,
+// and decrypts with the passphrase or the age identity defined by the environment variables.
+type KeyEncryption struct {
+	passphrase []byte
+
+	recipients []age.Recipient
+	identities []age.Identity
+
+	decryptForHooks bool
+}
+
+// NewKeyEncryption creates a KeyEncryption from the configuration.
+// It returns nil if the encryption is not configured.
+func NewKeyEncryption(cfg *configuration.KeyEncryption) (*KeyEncryption, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+
+	enc := &KeyEncryption{decryptForHooks: cfg.DecryptForHooks}
+
+	switch {
+	case cfg.PassphraseEnv != "":
+		passphrase := os.Getenv(cfg.PassphraseEnv)
+		if passphrase == "" {
+			return nil, fmt.Errorf("key encryption: the environment variable %s is empty", cfg.PassphraseEnv)
+		}
+
+		enc.passphrase = []byte(passphrase)
+
+	case cfg.PassphraseFile != "":
+		passphrase, err := readPassphraseFile(cfg.PassphraseFile)
+		if err != nil {
+			return nil, fmt.Errorf("key encryption: %w", err)
+		}
+
+		enc.passphrase = passphrase
+
+	case len(cfg.AgeRecipients) > 0:
+		recipients, err := age.ParseRecipients(strings.NewReader(strings.Join(cfg.AgeRecipients, "\n")))
+		if err != nil {
+			return nil, fmt.Errorf("key encryption: parsing the age recipients: %w", err)
+		}
+
+		identities, err := readAgeIdentityFile(cfg.AgeIdentityFile)
+		if err != nil {
+			return nil, fmt.Errorf("key encryption: %w", err)
+		}
+
+		enc.recipients = recipients
+		enc.identities = identities
+	}
+
+	return enc, nil
+}
+
+// encrypt encrypts the private keys contained in the data.
+func (e *KeyEncryption) encrypt(data []byte) ([]byte, error) {
+	switch {
+	case e == nil:
+		return data, nil
+
+	case len(e.recipients) > 0:
+		return encryptAge(data, e.recipients)
+
+	case len(e.passphrase) > 0:
+		return encryptPKCS8(data, e.passphrase)
+
+	default:
+		return data, nil
+	}
+}
+
+// decrypt decrypts the private keys contained in the data.
+// The data that are not encrypted are returned unchanged.
+func (e *KeyEncryption) decrypt(data []byte) ([]byte, error) {
+	var err error
+
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte(armor.Header)) {
+		data, err = decryptAge(data, e.getIdentities)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !bytes.Contains(data, []byte("-----BEGIN "+pemTypeEncryptedPrivateKey+"-----")) {
+		return data, nil
+	}
+
+	return decryptPKCS8(data, e.getPassphrase)
+}
+
+func (e *KeyEncryption) getPassphrase() ([]byte, error) {
+	if e != nil && len(e.passphrase) > 0 {
+		return e.passphrase, nil
+	}
+
+	if passphrase := os.Getenv(EnvKeyPassphrase); passphrase != "" {
+		return []byte(passphrase), nil
+	}
+
+	if filename := os.Getenv(EnvKeyPassphraseFile); filename != "" {
+		return readPassphraseFile(filename)
+	}
+
+	return nil, fmt.Errorf("the private key is encrypted: a passphrase is required (%s or %s)", EnvKeyPassphrase, EnvKeyPassphraseFile)
+}
+
+func (e *KeyEncryption) getIdentities() ([]age.Identity, error) {
+	if e != nil && len(e.identities) > 0 {
+		return e.identities, nil
+	}
+
+	if filename := os.Getenv(EnvAgeIdentityFile); filename != "" {
+		return readAgeIdentityFile(filename)
+	}
+
+	return nil, fmt.Errorf("the private key is encrypted with age: an identity file is required (%s)", EnvAgeIdentityFile)
+}
+
+func encryptAge(data []byte, recipients []age.Recipient) ([]byte, error) {
+	buf := new(bytes.Buffer)
+
+	armorWriter := armor.NewWriter(buf)
+
+	w, err := age.Encrypt(armorWriter, recipients...)
+	if err != nil {
+		return nil, fmt.Errorf("age encryption: %w", err)
+	}
+
+	_, err = w.Write(data)
+	if err != nil {
+		return nil, fmt.Errorf("age encryption: %w", err)
+	}
+
+	err = w.Close()
+	if err != nil {
+		return nil, fmt.Errorf("age encryption: %w", err)
+	}
+
+	err = armorWriter.Close()
+	if err != nil {
+		return nil, fmt.Errorf("age encryption: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func decryptAge(data []byte, getIdentities func() ([]age.Identity, error)) ([]byte, error) {
+	identities, err := getIdentities()
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := age.Decrypt(armor.NewReader(bytes.NewReader(data)), identities...)
+	if err != nil {
+		return nil, fmt.Errorf("age decryption: %w", err)
+	}
+
+	decrypted, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("age decryption: %w", err)
+	}
+
+	return decrypted, nil
+}
+
+func encryptPKCS8(data, passphrase []byte) ([]byte, error) {
+	return transformPEMBlocks(data, func(block *pem.Block) (*pem.Block, error) {
+		if block.Type == pemTypeEncryptedPrivateKey || !isPrivateKeyBlock(block) {
+			return block, nil
+		}
+
+		privateKey, err := certcrypto.ParsePEMPrivateKey(pem.EncodeToMemory(block))
+		if err != nil {
+			return nil, err
+		}
+
+		der, err := pkcs8.MarshalPrivateKey(privateKey, passphrase, nil)
+		if err != nil {
+			return nil, fmt.Errorf("PKCS#8 encryption: %w", err)
+		}
+
+		return &pem.Block{Type: pemTypeEncryptedPrivateKey, Bytes: der}, nil
+	})
+}
+
+func decryptPKCS8(data []byte, getPassphrase func() ([]byte, error)) ([]byte, error) {
+	passphrase, err := getPassphrase()
+	if err != nil {
+		return nil, err
+	}
+
+	return transformPEMBlocks(data, func(block *pem.Block) (*pem.Block, error) {
+		if block.Type != pemTypeEncryptedPrivateKey {
+			return block, nil
+		}
+
+		privateKey, err := pkcs8.ParsePKCS8PrivateKey(block.Bytes, passphrase)
+		if err != nil {
+			return nil, fmt.Errorf("PKCS#8 decryption: %w", err)
+		}
+
+		signer, ok := privateKey.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("PKCS#8 decryption: unsupported private key type %T", privateKey)
+		}
+
+		return certcrypto.PEMBlock(signer), nil
+	})
+}
+
+// transformPEMBlocks applies a function to each PEM block of the data.
+func transformPEMBlocks(data []byte, fn func(block *pem.Block) (*pem.Block, error)) ([]byte, error) {
+	buf := new(bytes.Buffer)
+
+	rest := data
+
+	for {
+		var block *pem.Block
+
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+
+		newBlock, err := fn(block)
+		if err != nil {
+			return nil, err
+		}
+
+		err = pem.Encode(buf, newBlock)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if buf.Len() == 0 {
+		return nil, errors.New("no PEM data found")
+	}
+
+	return buf.Bytes(), nil
+}
+
+func isPrivateKeyBlock(block *pem.Block) bool {
+	return block.Type == "PRIVATE KEY" || strings.HasSuffix(block.Type, " PRIVATE KEY")
+}
+
+func readPassphraseFile(filename string) ([]byte, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("reading the passphrase file: %w", err)
+	}
+
+	passphrase := bytes.TrimRight(data, "\r\n")
+	if len(passphrase) == 0 {
+		return nil, fmt.Errorf("the passphrase file %q is empty", filename)
+	}
+
+	return passphrase, nil
+}
+
+func readAgeIdentityFile(filename string) ([]age.Identity, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("reading the age identity file: %w", err)
+	}
+
+	defer func() { _ = file.Close() }()
+
+	identities, err := age.ParseIdentities(file)
+	if err != nil {
+		return nil, fmt.Errorf("parsing the age identity file %q: %w", filename, err)
+	}
+
+	return identities, nil
+}
@@ -1,17 +1,61 @@
 package storage
 
+import (
+	"io"
+
+	"github.com/go-acme/lego/v5/cmd/internal/configuration"
+)
+
 type Storage struct {
 	Certificate   *CertificatesStorage
 	Account       *AccountsStorage
+	AcmeDNS       *AcmeDNSStorage
 	Archiver      *Archiver
 	Configuration *ConfigurationStorage
+
+	backend Backend
 }
 
+// New creates a storage based on the local filesystem.
 func New(basePath string) *Storage {
+	return newStorage(basePath, NewFileBackend(basePath), nil)
+}
+
+// NewFromConfiguration creates a storage based on the backend defined by the configuration.
+// The configuration backup is stored by the backend, and the archives are only supported by the local filesystem.
+func NewFromConfiguration(cfg *configuration.Storage) (*Storage, error) {
+	encryption, err := NewKeyEncryption(cfg.Encryption)
+	if err != nil {
+		return nil, err
+	}
+
+	backend, err := NewBackend(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	store := newStorage(cfg.Path, backend, encryption)
+	store.Archiver = NewArchiverFromConfiguration(cfg)
+
+	return store, nil
+}
+
+func newStorage(basePath string, backend Backend, encryption *KeyEncryption) *Storage {
 	return &Storage{
-		Certificate:   NewCertificatesStorage(basePath),
-		Account:       NewAccountsStorage(basePath),
+		Certificate:   newCertificatesStorage(basePath, backend, encryption),
+		Account:       newAccountsStorage(basePath, backend, encryption),
+		AcmeDNS:       newAcmeDNSStorage(basePath, backend),
 		Archiver:      NewArchiver(basePath),
-		Configuration: NewConfigurationStorage(basePath),
+		Configuration: newConfigurationStorage(backend),
+		backend:       backend,
 	}
 }
+
+// Close releases the resources of the backend (ex: database connections).
+func (s *Storage) Close() error {
+	if closer, ok := s.backend.(io.Closer); ok {
+		return closer.Close()
+	}
+
+	return nil
+}
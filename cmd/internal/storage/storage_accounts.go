@@ -6,9 +6,10 @@ import (
 	"encoding/pem"
 	"errors"
 	"fmt"
+	"io/fs"
 	"log/slog"
 	"net/url"
-	"os"
+	"path"
 	"path/filepath"
 	"strings"
 
@@ -73,14 +74,25 @@ func (e *PrivateKeyNotFound) Error() string {
 //	     │      │             └── CA server ("server" option)
 //	     │      └── root accounts directory
 //	     └── "path" option
+//
+// The files are read and written through the backend, with the same layout.
 type AccountsStorage struct {
 	rootPath string
+
+	backend    Backend
+	encryption *KeyEncryption
 }
 
 // NewAccountsStorage Creates a new AccountsStorage.
 func NewAccountsStorage(basePath string) *AccountsStorage {
+	return newAccountsStorage(basePath, NewFileBackend(basePath), nil)
+}
+
+func newAccountsStorage(basePath string, backend Backend, encryption *KeyEncryption) *AccountsStorage {
 	return &AccountsStorage{
-		rootPath: filepath.Join(basePath, baseAccountsRootFolderName),
+		rootPath:   filepath.Join(basePath, baseAccountsRootFolderName),
+		backend:    backend,
+		encryption: encryption,
 	}
 }
 
@@ -105,14 +117,12 @@ func (s *AccountsStorage) Save(account *Account) error {
 		return fmt.Errorf("invalid server URL %q: %w", account.Server, err)
 	}
 
-	accountFilePath := s.getAccountFilePath(server, account.GetID())
-
-	err = os.MkdirAll(filepath.Dir(accountFilePath), 0o700)
+	err = s.backend.Put(s.getAccountFileKey(server, account.GetID()), jsonBytes)
 	if err != nil {
-		return fmt.Errorf("create the directory %q for the account: %w", filepath.Dir(accountFilePath), err)
+		return fmt.Errorf("save the account file %q: %w", s.getAccountFilePath(server, account.GetID()), err)
 	}
 
-	return os.WriteFile(accountFilePath, jsonBytes, filePerm)
+	return nil
 }
 
 // SavePrivateKey saves the private key to a file defined by the account.
@@ -160,6 +170,82 @@ func (s *AccountsStorage) Get(server string, keyType certcrypto.KeyType, email,
 	return s.getAccount(serverURL, keyType, effectiveAccountID)
 }
 
+// GetWithKey gets an account from a file or creates a new one (the account file is saved),
+// using a private key not managed by the storage (ex: a key held by a PKCS#11 token).
+// The private key file is neither read nor written.
+func (s *AccountsStorage) GetWithKey(server string, privateKey crypto.Signer, email, accountID string) (*Account, error) {
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, fmt.Errorf("invalid server URL %q: %w", server, err)
+	}
+
+	keyType, err := certcrypto.GetPrivateKeyType(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the private key type: %w", err)
+	}
+
+	effectiveAccountID := GetEffectiveAccountID(email, accountID)
+
+	if !s.existsAccountFile(serverURL, effectiveAccountID) {
+		account := &Account{
+			Server:  serverURL.String(),
+			ID:      effectiveAccountID,
+			Email:   email,
+			KeyType: keyType,
+			key:     privateKey,
+		}
+
+		err = s.Save(account)
+		if err != nil {
+			return nil, err
+		}
+
+		return account, nil
+	}
+
+	account, err := getJSON[Account](s.backend, s.getAccountFileKey(serverURL, effectiveAccountID))
+	if err != nil {
+		return nil, fmt.Errorf("could not read the account file %q: %w", s.getAccountFilePath(serverURL, effectiveAccountID), err)
+	}
+
+	if fixIntegrity(account, serverURL.String(), keyType) {
+		err = s.Save(account)
+		if err != nil {
+			return nil, fmt.Errorf("could not save the account file: %w", err)
+		}
+	}
+
+	account.key = privateKey
+
+	return account, nil
+}
+
+// ReadAll reads all the saved accounts.
+// The accounts are indexed by the path of their account file.
+func (s *AccountsStorage) ReadAll() (map[string]*Account, error) {
+	keys, err := s.backend.List(baseAccountsRootFolderName + "/")
+	if err != nil {
+		return nil, err
+	}
+
+	accounts := make(map[string]*Account)
+
+	for _, key := range keys {
+		if path.Base(key) != accountFileName {
+			continue
+		}
+
+		account, err := getJSON[Account](s.backend, key)
+		if err != nil {
+			return nil, fmt.Errorf("could not read the account file %q: %w", key, err)
+		}
+
+		accounts[filepath.Join(filepath.Dir(s.rootPath), filepath.FromSlash(key))] = account
+	}
+
+	return accounts, nil
+}
+
 // createAccount creates a new account.
 func (s *AccountsStorage) createAccount(server *url.URL, keyType certcrypto.KeyType, email, accountID string) (*Account, error) {
 	effectiveAccountID := GetEffectiveAccountID(email, accountID)
@@ -184,7 +270,7 @@ func (s *AccountsStorage) createAccount(server *url.URL, keyType certcrypto.KeyT
 func (s *AccountsStorage) getAccount(server *url.URL, keyType certcrypto.KeyType, effectiveAccountID string) (*Account, error) {
 	accountFilePath := s.getAccountFilePath(server, effectiveAccountID)
 
-	account, err := ReadJSONFile[Account](accountFilePath)
+	account, err := getJSON[Account](s.backend, s.getAccountFileKey(server, effectiveAccountID))
 	if err != nil {
 		return nil, fmt.Errorf("could not read the account file %q: %w", accountFilePath, err)
 	}
@@ -253,14 +339,13 @@ func (s *AccountsStorage) createPrivateKey(server *url.URL, keyType certcrypto.K
 
 func (s *AccountsStorage) savePrivateKey(server *url.URL, effectiveAccountID string, privateKey crypto.Signer) error {
 	accKeyPath := s.getAccountKeyPath(server, effectiveAccountID)
-	keysPath := filepath.Dir(accKeyPath)
 
-	err := CreateNonExistingFolder(keysPath)
+	keyBytes, err := s.encryption.encrypt(pem.EncodeToMemory(certcrypto.PEMBlock(privateKey)))
 	if err != nil {
-		return fmt.Errorf("could not check/create the directory %q for the account (accountID: %s): %w", keysPath, effectiveAccountID, err)
+		return fmt.Errorf("encrypt the private key (accountID: %s): %w", effectiveAccountID, err)
 	}
 
-	err = savePrivateKey(accKeyPath, privateKey)
+	err = s.backend.Put(s.getAccountKeyKey(server, effectiveAccountID), keyBytes)
 	if err != nil {
 		return fmt.Errorf("save the private key (accountID: %s): %w", effectiveAccountID, err)
 	}
@@ -275,13 +360,14 @@ func (s *AccountsStorage) savePrivateKey(server *url.URL, effectiveAccountID str
 func (s *AccountsStorage) readPrivateKey(server *url.URL, effectiveAccountID string) (crypto.Signer, error) {
 	accKeyPath := s.getAccountKeyPath(server, effectiveAccountID)
 
-	if _, err := os.Stat(accKeyPath); os.IsNotExist(err) {
+	keyBytes, err := s.backend.Get(s.getAccountKeyKey(server, effectiveAccountID))
+	if errors.Is(err, fs.ErrNotExist) {
 		return nil, &PrivateKeyNotFound{AccountID: effectiveAccountID}
 	} else if err != nil {
 		return nil, err
 	}
 
-	privateKey, err := ReadPrivateKeyFile(accKeyPath)
+	privateKey, err := parsePrivateKey(keyBytes, s.encryption)
 	if err != nil {
 		return nil, fmt.Errorf("error loading the private key file %q: %w", accKeyPath, err)
 	}
@@ -293,16 +379,15 @@ func (s *AccountsStorage) readPrivateKey(server *url.URL, effectiveAccountID str
 func (s *AccountsStorage) existsAccountFile(server *url.URL, effectiveAccountID string) bool {
 	accountFilePath := s.getAccountFilePath(server, effectiveAccountID)
 
-	if _, err := os.Stat(accountFilePath); os.IsNotExist(err) {
-		return false
-	} else if err != nil {
+	ok, err := exists(s.backend, s.getAccountFileKey(server, effectiveAccountID))
+	if err != nil {
 		log.Fatal("Could not read the account file.",
 			slog.String("filepath", accountFilePath),
 			log.ErrorAttr(err),
 		)
 	}
 
-	return true
+	return ok
 }
 
 // getAccountKeyPath returns the account private key path.
@@ -320,13 +405,19 @@ func (s *AccountsStorage) getRootUserPath(server *url.URL, effectiveAccountID st
 	return filepath.Join(s.rootPath, sanitizeHost(server), effectiveAccountID)
 }
 
-func savePrivateKey(filename string, privateKey crypto.Signer) error {
-	err := os.WriteFile(filename, pem.EncodeToMemory(certcrypto.PEMBlock(privateKey)), filePerm)
-	if err != nil {
-		return fmt.Errorf("PEM encoding: %w", err)
-	}
+// getAccountKeyKey returns the backend key of the account private key.
+func (s *AccountsStorage) getAccountKeyKey(server *url.URL, effectiveAccountID string) string {
+	return path.Join(getRootUserKey(server, effectiveAccountID), effectiveAccountID+".key")
+}
 
-	return nil
+// getAccountFileKey returns the backend key of the account file.
+func (s *AccountsStorage) getAccountFileKey(server *url.URL, effectiveAccountID string) string {
+	return path.Join(getRootUserKey(server, effectiveAccountID), accountFileName)
+}
+
+// getRootUserKey returns the backend key prefix of an account.
+func getRootUserKey(server *url.URL, effectiveAccountID string) string {
+	return path.Join(baseAccountsRootFolderName, sanitizeHost(server), effectiveAccountID)
 }
 
 func sanitizeHost(uri *url.URL) string {
@@ -247,3 +247,31 @@ func createFakeAccountFile(t *testing.T, storage *AccountsStorage, server *url.U
 	err = json.NewEncoder(file).Encode(existingAccount)
 	require.NoError(t, err)
 }
+
+func TestAccountsStorage_GetWithKey(t *testing.T) {
+	storage := NewAccountsStorage(t.TempDir())
+
+	email := "test@example.com"
+
+	privateKey, err := certcrypto.GeneratePrivateKey(certcrypto.EC384)
+	require.NoError(t, err)
+
+	server, err := url.Parse("https://example.com/dir")
+	require.NoError(t, err)
+
+	account, err := storage.GetWithKey(server.String(), privateKey, email, "")
+	require.NoError(t, err)
+
+	assert.Equal(t, email, account.GetID())
+	assert.Equal(t, certcrypto.EC384, account.GetKeyType())
+	assert.Equal(t, privateKey, account.GetPrivateKey())
+
+	assert.FileExists(t, storage.getAccountFilePath(server, email))
+	assert.NoFileExists(t, storage.getAccountKeyPath(server, email))
+
+	account, err = storage.GetWithKey(server.String(), privateKey, email, "")
+	require.NoError(t, err)
+
+	assert.Equal(t, privateKey, account.GetPrivateKey())
+	assert.NoFileExists(t, storage.getAccountKeyPath(server, email))
+}
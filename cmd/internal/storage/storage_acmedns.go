@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"path/filepath"
+)
+
+const baseAcmeDNSRootFolderName = "acmedns"
+
+// AcmeDNSAccount is an account of the acme-dns server (`lego acmedns-server`).
+type AcmeDNSAccount struct {
+	Username string `json:"username"`
+	// PasswordHash is the SHA-256 hash (hex) of the password.
+	PasswordHash string   `json:"passwordHash"`
+	Subdomain    string   `json:"subdomain"`
+	AllowFrom    []string `json:"allowFrom,omitempty"`
+
+	// TXT is the values of the TXT records of the subdomain.
+	TXT []string `json:"txt,omitempty"`
+}
+
+// AcmeDNSStorage A storage for the accounts of the acme-dns server.
+//
+// rootPath:
+//
+//	./.lego/acmedns/
+//	     │      └── root acme-dns directory
+//	     └── "path" option
+//
+// accountFilePath:
+//
+//	./.lego/acmedns/0a1b2c3d-4e5f-6789-abcd-ef0123456789.json
+//	     │      │             └── username
+//	     │      └── root acme-dns directory
+//	     └── "path" option
+//
+// The files are read and written through the backend, with the same layout.
+type AcmeDNSStorage struct {
+	rootPath string
+
+	backend Backend
+}
+
+// NewAcmeDNSStorage Creates a new AcmeDNSStorage.
+func NewAcmeDNSStorage(basePath string) *AcmeDNSStorage {
+	return newAcmeDNSStorage(basePath, NewFileBackend(basePath))
+}
+
+func newAcmeDNSStorage(basePath string, backend Backend) *AcmeDNSStorage {
+	return &AcmeDNSStorage{
+		rootPath: filepath.Join(basePath, baseAcmeDNSRootFolderName),
+		backend:  backend,
+	}
+}
+
+// GetRootPath returns the root path of the storage of the acme-dns accounts.
+func (s *AcmeDNSStorage) GetRootPath() string {
+	return s.rootPath
+}
+
+// Save saves the account to a file.
+func (s *AcmeDNSStorage) Save(account *AcmeDNSAccount) error {
+	jsonBytes, err := json.MarshalIndent(account, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	err = s.backend.Put(getAcmeDNSAccountKey(account.Username), jsonBytes)
+	if err != nil {
+		return fmt.Errorf("save the acme-dns account %q: %w", account.Username, err)
+	}
+
+	return nil
+}
+
+// ReadAll reads all the accounts.
+func (s *AcmeDNSStorage) ReadAll() ([]*AcmeDNSAccount, error) {
+	keys, err := s.backend.List(baseAcmeDNSRootFolderName + "/")
+	if err != nil {
+		return nil, err
+	}
+
+	var accounts []*AcmeDNSAccount
+
+	for _, key := range keys {
+		if path.Ext(key) != ".json" {
+			continue
+		}
+
+		account, err := getJSON[AcmeDNSAccount](s.backend, key)
+		if err != nil {
+			return nil, fmt.Errorf("could not read the acme-dns account file %q: %w", key, err)
+		}
+
+		accounts = append(accounts, account)
+	}
+
+	return accounts, nil
+}
+
+func getAcmeDNSAccountKey(username string) string {
+	return path.Join(baseAcmeDNSRootFolderName, username+".json")
+}
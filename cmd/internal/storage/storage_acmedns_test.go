@@ -0,0 +1,46 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcmeDNSStorage_Save(t *testing.T) {
+	basePath := t.TempDir()
+
+	storage := NewAcmeDNSStorage(basePath)
+
+	account := &AcmeDNSAccount{
+		Username:     "c36f50e8-4632-44f0-83fe-e070fef28a10",
+		PasswordHash: "hash",
+		Subdomain:    "8e5700ea-a4bf-41c7-8a77-e990661dcc6a",
+		AllowFrom:    []string{"192.168.100.1/24"},
+	}
+
+	err := storage.Save(account)
+	require.NoError(t, err)
+
+	assert.FileExists(t, filepath.Join(storage.GetRootPath(), account.Username+".json"))
+
+	// Not an account file.
+	err = os.WriteFile(filepath.Join(storage.GetRootPath(), "README"), []byte("foo"), 0o600)
+	require.NoError(t, err)
+
+	accounts, err := storage.ReadAll()
+	require.NoError(t, err)
+
+	assert.Equal(t, []*AcmeDNSAccount{account}, accounts)
+}
+
+func TestAcmeDNSStorage_ReadAll_empty(t *testing.T) {
+	storage := NewAcmeDNSStorage(t.TempDir())
+
+	accounts, err := storage.ReadAll()
+	require.NoError(t, err)
+
+	assert.Empty(t, accounts)
+}
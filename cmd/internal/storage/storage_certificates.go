@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 	"unicode"
@@ -42,17 +43,35 @@ const (
 //	./.lego/archives/
 //	     │      └── archived certificates directory
 //	     └── "path" option
+//
+// The files are read and written through the backend, with the same layout.
 type CertificatesStorage struct {
 	rootPath string
+
+	backend    Backend
+	encryption *KeyEncryption
 }
 
 // NewCertificatesStorage create a new certificates storage.
 func NewCertificatesStorage(basePath string) *CertificatesStorage {
+	return newCertificatesStorage(basePath, NewFileBackend(basePath), nil)
+}
+
+func newCertificatesStorage(basePath string, backend Backend, encryption *KeyEncryption) *CertificatesStorage {
 	return &CertificatesStorage{
-		rootPath: filepath.Join(basePath, baseCertificatesFolderName),
+		rootPath:   filepath.Join(basePath, baseCertificatesFolderName),
+		backend:    backend,
+		encryption: encryption,
 	}
 }
 
+// Lock acquires the lock of a certificate, and returns the function to release it.
+// It prevents several processes (sharing the same storage) from obtaining or renewing the same certificate.
+// If the certificate is locked by another process, the error is ErrLocked.
+func (s *CertificatesStorage) Lock(certID string) (func() error, error) {
+	return s.backend.Lock(path.Join(baseCertificatesFolderName, SanitizedName(certID)))
+}
+
 func CreateNonExistingFolder(path string) error {
 	if _, err := os.Stat(path); os.IsNotExist(err) {
 		return os.MkdirAll(path, 0o700)
@@ -82,12 +101,22 @@ func SanitizedName(name string) string {
 }
 
 // ReadPrivateKeyFile reads a private key file.
+// An encrypted private key is decrypted with the passphrase or the age identity defined by the environment variables.
 func ReadPrivateKeyFile(filename string) (crypto.Signer, error) {
 	keyBytes, err := os.ReadFile(filename)
 	if err != nil {
 		return nil, fmt.Errorf("reading the private key: %w", err)
 	}
 
+	return parsePrivateKey(keyBytes, nil)
+}
+
+func parsePrivateKey(keyBytes []byte, encryption *KeyEncryption) (crypto.Signer, error) {
+	keyBytes, err := encryption.decrypt(keyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("decrypting the private key: %w", err)
+	}
+
 	privateKey, err := certcrypto.ParsePEMPrivateKey(keyBytes)
 	if err != nil {
 		return nil, fmt.Errorf("parsing the private key: %w", err)
@@ -98,12 +127,16 @@ func ReadPrivateKeyFile(filename string) (crypto.Signer, error) {
 
 // ReadCertificateFile reads a certificate file.
 func ReadCertificateFile(filename string) ([]*x509.Certificate, error) {
-	keyBytes, err := os.ReadFile(filename)
+	certBytes, err := os.ReadFile(filename)
 	if err != nil {
 		return nil, fmt.Errorf("reading the certificate: %w", err)
 	}
 
-	certs, err := certcrypto.ParsePEMBundle(keyBytes)
+	return parseCertificates(certBytes)
+}
+
+func parseCertificates(certBytes []byte) ([]*x509.Certificate, error) {
+	certs, err := certcrypto.ParsePEMBundle(certBytes)
 	if err != nil {
 		return nil, fmt.Errorf("parsing the certificate: %w", err)
 	}
@@ -4,10 +4,14 @@ import (
 	"crypto"
 	"crypto/x509"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
 	"log/slog"
 	"os"
+	"path"
 	"path/filepath"
+	"strings"
 
 	"github.com/go-acme/lego/v5/log"
 )
@@ -28,12 +32,22 @@ func (s *CertificatesStorage) ReadResource(certID string) (*Certificate, error)
 }
 
 func (s *CertificatesStorage) ReadCertificate(certID string) ([]*x509.Certificate, error) {
+	certBytes, err := s.ReadFile(certID, ExtCert)
+	if err != nil {
+		return nil, fmt.Errorf("reading the certificate: %w", err)
+	}
+
 	// The input may be a bundle or a single certificate.
-	return ReadCertificateFile(s.GetFileName(certID, ExtCert))
+	return parseCertificates(certBytes)
 }
 
 func (s *CertificatesStorage) ReadPrivateKey(certID string) (crypto.Signer, error) {
-	privateKey, err := ReadPrivateKeyFile(s.GetFileName(certID, ExtKey))
+	keyBytes, err := s.ReadFile(certID, ExtKey)
+	if err != nil {
+		return nil, fmt.Errorf("error while parsing the private key for %q: reading the private key: %w", certID, err)
+	}
+
+	privateKey, err := parsePrivateKey(keyBytes, s.encryption)
 	if err != nil {
 		return nil, fmt.Errorf("error while parsing the private key for %q: %w", certID, err)
 	}
@@ -42,19 +56,87 @@ func (s *CertificatesStorage) ReadPrivateKey(certID string) (crypto.Signer, erro
 }
 
 func (s *CertificatesStorage) ReadFile(certID, extension string) ([]byte, error) {
-	return os.ReadFile(s.GetFileName(certID, extension))
+	return s.backend.Get(s.getKey(certID, extension))
 }
 
 func (s *CertificatesStorage) ExistsFile(certID, extension string) bool {
-	filePath := s.GetFileName(certID, extension)
+	ok, err := exists(s.backend, s.getKey(certID, extension))
+	if err != nil {
+		log.Fatal("File stat", slog.String("filepath", s.GetFileName(certID, extension)), log.ErrorAttr(err))
+	}
+
+	return ok
+}
 
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		return false
-	} else if err != nil {
-		log.Fatal("File stat", slog.String("filepath", filePath), log.ErrorAttr(err))
+// List returns the sanitized names of the saved certificates.
+func (s *CertificatesStorage) List() ([]string, error) {
+	keys, err := s.backend.List(baseCertificatesFolderName + "/")
+	if err != nil {
+		return nil, err
 	}
 
-	return true
+	var names []string
+
+	for _, key := range keys {
+		if !strings.HasSuffix(key, ExtResource) {
+			continue
+		}
+
+		names = append(names, strings.TrimSuffix(path.Base(key), ExtResource))
+	}
+
+	return names, nil
+}
+
+// Local returns a storage where the files of the certificate are available on the local filesystem.
+// If the backend is not the local filesystem, or if the private keys must be decrypted for the hooks,
+// the files are copied inside a temporary directory removed by the clean-up function.
+func (s *CertificatesStorage) Local(certID string) (*CertificatesStorage, func(), error) {
+	decrypt := s.encryption != nil && s.encryption.decryptForHooks
+
+	if _, ok := s.backend.(*FileBackend); ok && !decrypt {
+		return s, func() {}, nil
+	}
+
+	dir, err := os.MkdirTemp("", "lego-")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cleanUp := func() { _ = os.RemoveAll(dir) }
+
+	local := NewCertificatesStorage(dir)
+
+	for _, ext := range []string{ExtCert, ExtIssuer, ExtKey, ExtPEM, ExtPFX, ExtResource} {
+		data, err := s.ReadFile(certID, ext)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+
+		if err != nil {
+			cleanUp()
+
+			return nil, nil, err
+		}
+
+		if decrypt && (ext == ExtKey || ext == ExtPEM) {
+			data, err = s.encryption.decrypt(data)
+			if err != nil {
+				cleanUp()
+
+				return nil, nil, fmt.Errorf("decrypting the private key: %w", err)
+			}
+		}
+
+		err = local.backend.Put(local.getKey(certID, ext), data)
+		if err != nil {
+			cleanUp()
+
+			return nil, nil, err
+		}
+	}
+
+	return local, cleanUp, nil
 }
 
 func (s *CertificatesStorage) GetRootPath() string {
@@ -64,3 +146,8 @@ func (s *CertificatesStorage) GetRootPath() string {
 func (s *CertificatesStorage) GetFileName(certID, extension string) string {
 	return filepath.Join(s.rootPath, SanitizedName(certID)+extension)
 }
+
+// getKey returns the backend key of a certificate file.
+func (s *CertificatesStorage) getKey(certID, extension string) string {
+	return path.Join(baseCertificatesFolderName, SanitizedName(certID)+extension)
+}
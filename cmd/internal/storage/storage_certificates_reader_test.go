@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/go-acme/lego/v5/certificate"
+	"github.com/go-acme/lego/v5/cmd/internal/configuration"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -145,3 +146,44 @@ func TestCertificatesStorage_ReadCertificate(t *testing.T) {
 
 	assert.NotEmpty(t, cert)
 }
+
+func TestCertificatesStorage_Local(t *testing.T) {
+	backend, err := NewSQLBackend(&configuration.SQLStorage{
+		Driver: configuration.SQLDriverSQLite,
+		DSN:    filepath.Join(t.TempDir(), "lego.db"),
+		Table:  "lego_storage",
+	})
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = backend.Close() })
+
+	certsStorage := newCertificatesStorage(t.TempDir(), backend, nil)
+
+	err = certsStorage.Save(&Certificate{
+		Resource: &certificate.Resource{
+			ID:          "example.com",
+			Domains:     []string{"example.com"},
+			PrivateKey:  []byte("PrivateKey"),
+			Certificate: []byte("Certificate"),
+		},
+	}, nil)
+	require.NoError(t, err)
+
+	assert.NoFileExists(t, certsStorage.GetFileName("example.com", ExtCert))
+
+	names, err := certsStorage.List()
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"example.com"}, names)
+
+	local, cleanUp, err := certsStorage.Local("example.com")
+	require.NoError(t, err)
+
+	assert.FileExists(t, local.GetFileName("example.com", ExtCert))
+	assert.FileExists(t, local.GetFileName("example.com", ExtKey))
+	assert.NoFileExists(t, local.GetFileName("example.com", ExtIssuer))
+
+	cleanUp()
+
+	assert.NoFileExists(t, local.GetFileName("example.com", ExtCert))
+}
@@ -27,17 +27,12 @@ type SaveOptions struct {
 // Save saves the certificate and related files.
 // - the resource file (JSON)
 // - the certificate file
-// - the private key file (if any)
+// - the private key file (if any, and not held by a token)
 // - the issuer certificate file (if any)
 // - the PFX file (if needed)
 // - the PEM file (if needed).
 func (s *CertificatesStorage) Save(certRes *Certificate, opts *SaveOptions) error {
-	err := CreateNonExistingFolder(s.rootPath)
-	if err != nil {
-		return fmt.Errorf("root folder creation: %w", err)
-	}
-
-	err = s.writeFile(certRes.ID, ExtCert, certRes.Certificate)
+	err := s.writeFile(certRes.ID, ExtCert, certRes.Certificate)
 	if err != nil {
 		return fmt.Errorf("unable to save the certificate for %q: %w", certRes.ID, err)
 	}
@@ -49,14 +44,22 @@ func (s *CertificatesStorage) Save(certRes *Certificate, opts *SaveOptions) erro
 		}
 	}
 
-	// if we were given a CSR, we don't know the private key
-	if certRes.PrivateKey != nil {
+	switch {
+	case certRes.KeySource != "":
+		// the private key is held by a token: only the reference to the key is stored (inside the resource file)
+		err = s.writeKeySourceFiles(certRes, opts)
+		if err != nil {
+			return fmt.Errorf("unable to save the certificate files for %q: %w", certRes.ID, err)
+		}
+
+	case len(certRes.PrivateKey) > 0:
 		err = s.writeCertificateFiles(certRes, opts)
 		if err != nil {
 			return fmt.Errorf("unable to save the private key for %q: %w", certRes.ID, err)
 		}
-	} else if opts != nil && (opts.PEM || opts.PFX) {
-		// we don't have the private key; can't write the .pem or .pfx file
+
+	case opts != nil && (opts.PEM || opts.PFX):
+		// if we were given a CSR, we don't know the private key; can't write the .pem or .pfx file
 		return fmt.Errorf("unable to save PEM or PFX without the private key for %q: probable usage of a CSR", certRes.ID)
 	}
 
@@ -78,7 +81,7 @@ func (s *CertificatesStorage) saveResource(certRes *Certificate) error {
 }
 
 func (s *CertificatesStorage) writeCertificateFiles(certRes *Certificate, opts *SaveOptions) error {
-	err := s.writeFile(certRes.ID, ExtKey, certRes.PrivateKey)
+	err := s.writeEncryptedFile(certRes.ID, ExtKey, certRes.PrivateKey)
 	if err != nil {
 		return fmt.Errorf("unable to save the key file: %w", err)
 	}
@@ -88,7 +91,7 @@ func (s *CertificatesStorage) writeCertificateFiles(certRes *Certificate, opts *
 	}
 
 	if opts.PEM {
-		err = s.writeFile(certRes.ID, ExtPEM, bytes.Join([][]byte{certRes.Certificate, certRes.PrivateKey}, nil))
+		err = s.writeEncryptedFile(certRes.ID, ExtPEM, bytes.Join([][]byte{certRes.Certificate, certRes.PrivateKey}, nil))
 		if err != nil {
 			return fmt.Errorf("unable to save the PEM file: %w", err)
 		}
@@ -104,6 +107,27 @@ func (s *CertificatesStorage) writeCertificateFiles(certRes *Certificate, opts *
 	return nil
 }
 
+// writeKeySourceFiles writes the optional files of a certificate with a private key held by a token.
+// The private key is never written: the PEM file only contains the certificate, and the PFX file cannot be created.
+func (s *CertificatesStorage) writeKeySourceFiles(certRes *Certificate, opts *SaveOptions) error {
+	if opts == nil {
+		return nil
+	}
+
+	if opts.PFX {
+		return errors.New("unable to save the PFX file: the private key is held by a token")
+	}
+
+	if opts.PEM {
+		err := s.writeFile(certRes.ID, ExtPEM, certRes.Certificate)
+		if err != nil {
+			return fmt.Errorf("unable to save the PEM file: %w", err)
+		}
+	}
+
+	return nil
+}
+
 func (s *CertificatesStorage) writePFXFile(certRes *Certificate, password, format string) error {
 	certPemBlock, _ := pem.Decode(certRes.Certificate)
 	if certPemBlock == nil {
@@ -138,13 +162,23 @@ func (s *CertificatesStorage) writePFXFile(certRes *Certificate, password, forma
 	return s.writeFile(certRes.ID, ExtPFX, pfxBytes)
 }
 
+// writeEncryptedFile writes a file containing a private key, encrypted if the encryption is enabled.
+func (s *CertificatesStorage) writeEncryptedFile(domain, extension string, data []byte) error {
+	encrypted, err := s.encryption.encrypt(data)
+	if err != nil {
+		return fmt.Errorf("encrypting the private key: %w", err)
+	}
+
+	return s.writeFile(domain, extension, encrypted)
+}
+
 func (s *CertificatesStorage) writeFile(domain, extension string, data []byte) error {
-	filePath := s.GetFileName(domain, extension)
+	key := s.getKey(domain, extension)
 
 	log.Info("Writing file.",
-		slog.String("filepath", filePath))
+		slog.String("key", key))
 
-	return os.WriteFile(filePath, data, filePerm)
+	return s.backend.Put(key, data)
 }
 
 func getCertificateChain(certRes *Certificate) ([]*x509.Certificate, error) {
@@ -109,3 +109,99 @@ func TestCertificatesStorage_Save_pem(t *testing.T) {
 
 	assert.JSONEq(t, string(expected), string(actual))
 }
+
+func TestCertificatesStorage_Save_keySource(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		encryption *KeyEncryption
+		privateKey []byte
+		options    *SaveOptions
+	}{
+		{
+			desc: "without encryption",
+		},
+		{
+			desc:       "with encryption",
+			encryption: &KeyEncryption{passphrase: []byte("secret")},
+		},
+		{
+			desc:       "with encryption and an empty private key",
+			encryption: &KeyEncryption{passphrase: []byte("secret")},
+			privateKey: []byte{},
+		},
+		{
+			desc:    "PEM without encryption",
+			options: &SaveOptions{PEM: true},
+		},
+		{
+			desc:       "PEM with encryption",
+			encryption: &KeyEncryption{passphrase: []byte("secret")},
+			options:    &SaveOptions{PEM: true},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			basePath := t.TempDir()
+
+			writer := newCertificatesStorage(basePath, NewFileBackend(basePath), test.encryption)
+
+			resource := &Certificate{
+				Resource: &certificate.Resource{
+					ID:                "example.com",
+					Domains:           []string{"example.com"},
+					KeyType:           "EC256",
+					PrivateKey:        test.privateKey,
+					Certificate:       []byte("Certificate"),
+					IssuerCertificate: []byte("IssuerCertificate"),
+				},
+				KeySource: "pkcs11:token=lego;object=example.com",
+			}
+
+			err := writer.Save(resource, test.options)
+			require.NoError(t, err)
+
+			require.FileExists(t, filepath.Join(basePath, baseCertificatesFolderName, "example.com.crt"))
+			require.FileExists(t, filepath.Join(basePath, baseCertificatesFolderName, "example.com.json"))
+
+			// Only the reference to the key is stored.
+			assert.NoFileExists(t, filepath.Join(basePath, baseCertificatesFolderName, "example.com.key"))
+
+			actual, err := os.ReadFile(filepath.Join(basePath, baseCertificatesFolderName, "example.com.json"))
+			require.NoError(t, err)
+
+			assert.Contains(t, string(actual), `"keySource": "pkcs11:token=lego;object=example.com"`)
+
+			if test.options == nil || !test.options.PEM {
+				assert.NoFileExists(t, filepath.Join(basePath, baseCertificatesFolderName, "example.com.pem"))
+				return
+			}
+
+			pemFile, err := os.ReadFile(filepath.Join(basePath, baseCertificatesFolderName, "example.com.pem"))
+			require.NoError(t, err)
+
+			assert.Equal(t, "Certificate", string(pemFile))
+		})
+	}
+}
+
+func TestCertificatesStorage_Save_keySource_pfx(t *testing.T) {
+	basePath := t.TempDir()
+
+	writer := NewCertificatesStorage(basePath)
+
+	resource := &Certificate{
+		Resource: &certificate.Resource{
+			ID:          "example.com",
+			Domains:     []string{"example.com"},
+			KeyType:     "EC256",
+			Certificate: []byte("Certificate"),
+		},
+		KeySource: "pkcs11:token=lego;object=example.com",
+	}
+
+	err := writer.Save(resource, &SaveOptions{PFX: true})
+	require.EqualError(t, err, `unable to save the certificate files for "example.com": unable to save the PFX file: the private key is held by a token`)
+}
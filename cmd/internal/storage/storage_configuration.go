@@ -1,53 +1,59 @@
 package storage
 
 import (
-	"os"
-	"path/filepath"
+	"bytes"
 
 	"github.com/go-acme/lego/v5/cmd/internal/configuration"
 	"gopkg.in/yaml.v3"
 )
 
+const configurationBackupKey = ".lego.bck.yaml"
+
 // ConfigurationStorage is a storage for the configuration.
 // The backup is the effective configuration and cannot be used directly.
 type ConfigurationStorage struct {
-	backupPath string
+	backend Backend
 }
 
-// NewConfigurationStorage creates a new ConfigurationStorage.
+// NewConfigurationStorage creates a new ConfigurationStorage based on the local filesystem.
 func NewConfigurationStorage(basePath string) *ConfigurationStorage {
-	return &ConfigurationStorage{
-		backupPath: filepath.Join(basePath, ".lego.bck.yaml"),
-	}
+	return newConfigurationStorage(NewFileBackend(basePath))
+}
+
+func newConfigurationStorage(backend Backend) *ConfigurationStorage {
+	return &ConfigurationStorage{backend: backend}
 }
 
 // Backup saves the configuration to the storage.
 func (s *ConfigurationStorage) Backup(cfg *configuration.Configuration) error {
-	file, err := os.Create(s.backupPath)
+	buf := new(bytes.Buffer)
+
+	encoder := yaml.NewEncoder(buf)
+	encoder.SetIndent(2)
+
+	err := encoder.Encode(cfg)
 	if err != nil {
 		return err
 	}
 
-	encoder := yaml.NewEncoder(file)
-	encoder.SetIndent(2)
-
-	defer func() { _ = encoder.Close() }()
+	err = encoder.Close()
+	if err != nil {
+		return err
+	}
 
-	return encoder.Encode(cfg)
+	return s.backend.Put(configurationBackupKey, buf.Bytes())
 }
 
 // ReadBackup reads the backup configuration.
 func (s *ConfigurationStorage) ReadBackup() (*configuration.Configuration, error) {
-	file, err := os.Open(s.backupPath)
+	data, err := s.backend.Get(configurationBackupKey)
 	if err != nil {
 		return nil, err
 	}
 
-	defer func() { _ = file.Close() }()
-
 	cfg := &configuration.Configuration{}
 
-	err = yaml.NewDecoder(file).Decode(cfg)
+	err = yaml.Unmarshal(data, cfg)
 	if err != nil {
 		return nil, err
 	}
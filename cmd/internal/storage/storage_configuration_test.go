@@ -0,0 +1,44 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/go-acme/lego/v5/cmd/internal/configuration"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigurationStorage_Backup(t *testing.T) {
+	basePath := t.TempDir()
+
+	store, err := NewFromConfiguration(&configuration.Storage{
+		Path: basePath,
+		SQL: &configuration.SQLStorage{
+			Driver: configuration.SQLDriverSQLite,
+			DSN:    filepath.Join(t.TempDir(), "lego.db"),
+			Table:  "lego_storage",
+		},
+	})
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = store.Close() })
+
+	cfg := &configuration.Configuration{
+		Storage: &configuration.Storage{Path: basePath},
+		Certificates: map[string]*configuration.Certificate{
+			"example.com": {Domains: []string{"example.com"}},
+		},
+	}
+
+	err = store.Configuration.Backup(cfg)
+	require.NoError(t, err)
+
+	// The backup is stored by the backend, not on the local filesystem.
+	assert.NoFileExists(t, filepath.Join(basePath, configurationBackupKey))
+
+	backup, err := store.Configuration.ReadBackup()
+	require.NoError(t, err)
+
+	assert.Equal(t, cfg, backup)
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/go-acme/lego/v5/cmd/internal"
 	"github.com/go-acme/lego/v5/cmd/internal/configuration"
 	"github.com/go-acme/lego/v5/cmd/internal/flags"
 	"github.com/go-acme/lego/v5/log"
@@ -62,6 +63,17 @@ func setUpLogger(cmd *cli.Command, logCfg *configuration.Log) {
 	log.SetDefault(logger)
 }
 
+// setUpEvents writes the issuance events to the target defined by the configuration or the flag.
+func setUpEvents(cmd *cli.Command, logCfg *configuration.Log) error {
+	target := cmd.String(flags.FlgLogEvents)
+
+	if logCfg != nil {
+		target = cmp.Or(logCfg.Events, target)
+	}
+
+	return internal.SetUpEventSink(target)
+}
+
 func getLogLeveler(lvl string) slog.Leveler {
 	switch strings.ToUpper(lvl) {
 	case "DEBUG":
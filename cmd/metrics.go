@@ -0,0 +1,18 @@
+package cmd
+
+import (
+	"github.com/go-acme/lego/v5/cmd/internal"
+	"github.com/go-acme/lego/v5/cmd/internal/flags"
+	"github.com/urfave/cli/v3"
+)
+
+// setUpMetrics serves the metrics if an address is defined.
+// The returned function stops the server.
+func setUpMetrics(cmd *cli.Command) (func(), error) {
+	address := cmd.String(flags.FlgMetricsAddress)
+	if address == "" {
+		return func() {}, nil
+	}
+
+	return internal.ServeMetrics(address)
+}
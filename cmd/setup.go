@@ -2,7 +2,9 @@ package cmd
 
 import (
 	"crypto/x509"
+	"errors"
 	"fmt"
+	"log/slog"
 	"net"
 	"net/http"
 	"strings"
@@ -14,8 +16,11 @@ import (
 	"github.com/go-acme/lego/v5/cmd/internal/configuration"
 	"github.com/go-acme/lego/v5/cmd/internal/flags"
 	"github.com/go-acme/lego/v5/cmd/internal/hook"
+	"github.com/go-acme/lego/v5/cmd/internal/root"
 	"github.com/go-acme/lego/v5/cmd/internal/storage"
+	"github.com/go-acme/lego/v5/internal/dotenv"
 	"github.com/go-acme/lego/v5/lego"
+	"github.com/go-acme/lego/v5/log"
 	"github.com/go-acme/lego/v5/registration"
 	"github.com/urfave/cli/v3"
 )
@@ -26,6 +31,80 @@ func newClient(cmd *cli.Command, account registration.User) (*lego.Client, error
 	return lego.NewClient(newClientConfig(cmd, account))
 }
 
+// newRegisteredClient creates a client for a registered account.
+// With a configuration file, the account (storage, server, key source) is defined by the configuration,
+// otherwise by the flags.
+// The returned function releases the resources of the client.
+func newRegisteredClient(cmd *cli.Command) (*lego.Client, func(), error) {
+	cfg, err := loadConfiguration(cmd)
+	if err == nil {
+		return root.NewAccountClient(cfg, getCommandAccountID(cmd, cfg))
+	}
+
+	nfErr := &configuration.FileNotFoundError{}
+	if !errors.As(err, &nfErr) {
+		return nil, nil, err
+	}
+
+	keyType, err := certcrypto.ToKeyType(cmd.String(flags.FlgKeyType))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	store := storage.New(cmd.String(flags.FlgPath))
+
+	account, err := store.Account.Get(cmd.String(flags.FlgServer), keyType, cmd.String(flags.FlgEmail), cmd.String(flags.FlgAccountID))
+	if err != nil {
+		return nil, nil, fmt.Errorf("set up account: %w", err)
+	}
+
+	if account.GetRegistration() == nil {
+		return nil, nil, fmt.Errorf("the account %s is not registered", account.GetID())
+	}
+
+	client, err := newClient(cmd, account)
+	if err != nil {
+		return nil, nil, fmt.Errorf("new client: %w", err)
+	}
+
+	return client, func() {}, nil
+}
+
+// getCommandAccountID returns the ID of the account defined by the flags,
+// or the only account of the configuration when the flags are not set.
+func getCommandAccountID(cmd *cli.Command, cfg *configuration.Configuration) string {
+	if !cmd.IsSet(flags.FlgAccountID) && !cmd.IsSet(flags.FlgEmail) && len(cfg.Accounts) == 1 {
+		for id := range cfg.Accounts {
+			return id
+		}
+	}
+
+	return storage.GetEffectiveAccountID(cmd.String(flags.FlgEmail), cmd.String(flags.FlgAccountID))
+}
+
+// newChallengeClient creates a client for a registered account, with the challenges defined by the flags.
+// The environment file is loaded before the setup of the challenges (DNS providers).
+func newChallengeClient(cmd *cli.Command) (*lego.Client, func(), error) {
+	_, err := dotenv.Load(cmd.String(flags.FlgEnvFile))
+	if err != nil {
+		return nil, nil, fmt.Errorf("set up environment: %w", err)
+	}
+
+	client, closeFn, err := newRegisteredClient(cmd)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = setupChallenges(cmd, client)
+	if err != nil {
+		closeFn()
+
+		return nil, nil, fmt.Errorf("setup challenges: %w", err)
+	}
+
+	return client, closeFn, nil
+}
+
 func newClientConfig(cmd *cli.Command, account registration.User) *lego.Config {
 	config := lego.NewConfig(account)
 	config.CADirURL = cmd.String(flags.FlgServer)
@@ -51,6 +130,8 @@ func newClientConfig(cmd *cli.Command, account registration.User) *lego.Config {
 
 	config.HTTPClient = internal.NewRetryableClient(config.HTTPClient)
 
+	config.Events = internal.Events()
+
 	return config
 }
 
@@ -79,6 +160,9 @@ func newObtainRequest(cmd *cli.Command, domains []string) (certificate.ObtainReq
 		EnableCommonName:               cmd.Bool(flags.FlgEnableCommonName),
 		Profile:                        cmd.String(flags.FlgProfile),
 		AlwaysDeactivateAuthorizations: cmd.Bool(flags.FlgAlwaysDeactivateAuthorizations),
+		ChallengeFallback:              cmd.Bool(flags.FlgChallengeFallback),
+		CheckCAA:                       cmd.Bool(flags.FlgCheckCAA),
+		MinAuthorizationValidity:       cmd.Duration(flags.FlgMinAuthzValidity),
 	}, nil
 }
 
@@ -92,6 +176,9 @@ func newObtainForCSRRequest(cmd *cli.Command, csr *x509.CertificateRequest) cert
 		EnableCommonName:               cmd.Bool(flags.FlgEnableCommonName),
 		Profile:                        cmd.String(flags.FlgProfile),
 		AlwaysDeactivateAuthorizations: cmd.Bool(flags.FlgAlwaysDeactivateAuthorizations),
+		ChallengeFallback:              cmd.Bool(flags.FlgChallengeFallback),
+		CheckCAA:                       cmd.Bool(flags.FlgCheckCAA),
+		MinAuthorizationValidity:       cmd.Duration(flags.FlgMinAuthzValidity),
 	}
 }
 
@@ -131,6 +218,19 @@ func parseAddress(cmd *cli.Command, flgName string) (string, string, error) {
 	return host, port, nil
 }
 
+// newCommandStorage creates the storage for the commands that work with or without a configuration file:
+// the storage defined by the configuration file if any, otherwise the "path" flag.
+func newCommandStorage(cmd *cli.Command, name string) (*storage.Storage, error) {
+	cfg, err := loadConfiguration(cmd)
+	if err != nil {
+		return storage.New(cmd.String(flags.FlgPath)), nil
+	}
+
+	log.Debug("Configuration loaded from a file.", slog.String("cmd", name))
+
+	return storage.NewFromConfiguration(cfg.Storage)
+}
+
 func loadConfiguration(cmd *cli.Command) (*configuration.Configuration, error) {
 	filename, err := getConfigurationPath(cmd)
 	if err != nil {
@@ -144,6 +244,11 @@ func loadConfiguration(cmd *cli.Command) (*configuration.Configuration, error) {
 
 	setUpLogger(cmd, cfg.Log)
 
+	err = setUpEvents(cmd, cfg.Log)
+	if err != nil {
+		return nil, err
+	}
+
 	configuration.ApplyDefaults(cfg)
 
 	err = configuration.Validate(cfg)
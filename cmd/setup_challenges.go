@@ -49,6 +49,13 @@ func setupChallenges(cmd *cli.Command, client *lego.Client) error {
 		}
 	}
 
+	if cmd.IsSet(flags.FlgDNSAccount) {
+		err := setupDNSAccount(cmd, client)
+		if err != nil {
+			return fmt.Errorf("DNS-ACCOUNT challenge provider: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -162,6 +169,9 @@ func setupDNS(cmd *cli.Command, client *lego.Client) error {
 			dns01.CondOptions(cmd.Bool(flags.FlgDNSPropagationDisableRNS),
 				dns01.DisableRecursiveNSsPropagationRequirement(),
 			),
+			dns01.CondOptions(cmd.Bool(flags.FlgDNSPropagationDNSSEC),
+				dns01.RequireDNSSEC(),
+			),
 		),
 	)
 }
@@ -194,6 +204,48 @@ func setupDNSPersist(cmd *cli.Command, client *lego.Client) error {
 			dnspersist01.CondOptions(cmd.Bool(flags.FlgDNSPersistPropagationDisableRNS),
 				dnspersist01.DisableRecursiveNSsPropagationRequirement(),
 			),
+			dnspersist01.CondOptions(cmd.Bool(flags.FlgDNSPersistPropagationDNSSEC),
+				dnspersist01.RequireDNSSEC(),
+			),
+		),
+	)
+}
+
+func setupDNSAccount(cmd *cli.Command, client *lego.Client) error {
+	provider, err := dns.NewDNSChallengeProviderByName(cmd.String(flags.FlgDNSAccount))
+	if err != nil {
+		return err
+	}
+
+	if !cmd.IsSet(flags.FlgDNS) {
+		// The dns-account-01 challenge uses the DNS-01 client.
+		opts := &dns01.Options{RecursiveNameservers: cmd.StringSlice(flags.FlgDNSAccountResolvers)}
+
+		if cmd.IsSet(flags.FlgDNSAccountTimeout) {
+			opts.Timeout = time.Duration(cmd.Int(flags.FlgDNSAccountTimeout)) * time.Second
+		}
+
+		opts.NetworkStack = getNetworkStack(cmd)
+
+		dns01.SetDefaultClient(dns01.NewClient(opts))
+	}
+
+	shouldWait := cmd.IsSet(flags.FlgDNSAccountPropagationWait)
+
+	return client.Challenge.SetDNSAccount01Provider(provider,
+		dns01.CondOptions(shouldWait,
+			dns01.PropagationWait(cmd.Duration(flags.FlgDNSAccountPropagationWait), true),
+		),
+		dns01.CondOptions(!shouldWait,
+			dns01.CondOptions(cmd.Bool(flags.FlgDNSAccountPropagationDisableANS),
+				dns01.DisableAuthoritativeNssPropagationRequirement(),
+			),
+			dns01.CondOptions(cmd.Bool(flags.FlgDNSAccountPropagationDisableRNS),
+				dns01.DisableRecursiveNSsPropagationRequirement(),
+			),
+			dns01.CondOptions(cmd.Bool(flags.FlgDNSAccountPropagationDNSSEC),
+				dns01.RequireDNSSEC(),
+			),
 		),
 	)
 }
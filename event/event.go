@@ -0,0 +1,126 @@
+// Package event provides the structured events emitted during the issuance of certificates.
+package event
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Type is the type of event.
+type Type string
+
+// Types of event.
+const (
+	// OrderCreated is emitted when an order has been created.
+	OrderCreated Type = "order.created"
+	// AuthorizationPending is emitted for each pending authorization of an order.
+	AuthorizationPending Type = "authorization.pending"
+	// AuthorizationReused is emitted for each valid authorization of an order, reused by the server.
+	AuthorizationReused Type = "authorization.reused"
+	// ChallengePresented is emitted when a challenge has been presented, before to request its validation.
+	ChallengePresented Type = "challenge.presented"
+	// ValidationSucceeded is emitted when the server has validated a challenge.
+	ValidationSucceeded Type = "validation.succeeded"
+	// ValidationFailed is emitted when the validation of a challenge has failed.
+	ValidationFailed Type = "validation.failed"
+	// OrderFinalized is emitted when an order has been finalized with a CSR.
+	OrderFinalized Type = "order.finalized"
+	// CertificateDownloaded is emitted when a certificate has been downloaded.
+	CertificateDownloaded Type = "certificate.downloaded"
+	// CertificateRenewed is emitted when a certificate has been renewed.
+	CertificateRenewed Type = "certificate.renewed"
+	// CertificateRevoked is emitted when a certificate has been revoked.
+	CertificateRevoked Type = "certificate.revoked"
+)
+
+// Event is an issuance event.
+// Only the fields related to the type of event are set.
+type Event struct {
+	Type Type      `json:"type"`
+	Time time.Time `json:"time"`
+
+	// Domains are the domains of the order or the certificate.
+	Domains []string `json:"domains,omitempty"`
+	// Domain is the domain of the authorization or the challenge.
+	Domain string `json:"domain,omitempty"`
+
+	ChallengeType    string `json:"challengeType,omitempty"`
+	OrderURL         string `json:"orderURL,omitempty"`
+	AuthorizationURL string `json:"authorizationURL,omitempty"`
+	ChallengeURL     string `json:"challengeURL,omitempty"`
+	CertificateURL   string `json:"certificateURL,omitempty"`
+
+	// Error is the reason of a failure.
+	Error string `json:"error,omitempty"`
+}
+
+// Subscriber handles the events.
+type Subscriber interface {
+	HandleEvent(ctx context.Context, evt Event)
+}
+
+// SubscriberFunc is an adapter to use a function as a Subscriber.
+type SubscriberFunc func(ctx context.Context, evt Event)
+
+// HandleEvent implements Subscriber.
+func (f SubscriberFunc) HandleEvent(ctx context.Context, evt Event) {
+	f(ctx, evt)
+}
+
+// Bus dispatches the events to the subscribers.
+//
+// The events are dispatched synchronously, in the order of the subscriptions:
+// a subscriber must not block.
+// A nil Bus discards the events.
+type Bus struct {
+	mu          sync.RWMutex
+	subscribers []*subscription
+}
+
+type subscription struct {
+	subscriber Subscriber
+}
+
+// NewBus creates a Bus.
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Subscribe registers a subscriber.
+// The returned function removes the subscriber.
+func (b *Bus) Subscribe(subscriber Subscriber) (unsubscribe func()) {
+	sub := &subscription{subscriber: subscriber}
+
+	b.mu.Lock()
+	b.subscribers = append(b.subscribers, sub)
+	b.mu.Unlock()
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		b.subscribers = slices.DeleteFunc(b.subscribers, func(s *subscription) bool { return s == sub })
+	}
+}
+
+// Publish dispatches an event to the subscribers.
+// The time of the event is set if it's missing.
+func (b *Bus) Publish(ctx context.Context, evt Event) {
+	if b == nil {
+		return
+	}
+
+	if evt.Time.IsZero() {
+		evt.Time = time.Now()
+	}
+
+	b.mu.RLock()
+	subscribers := slices.Clone(b.subscribers)
+	b.mu.RUnlock()
+
+	for _, sub := range subscribers {
+		sub.subscriber.HandleEvent(ctx, evt)
+	}
+}
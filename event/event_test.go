@@ -0,0 +1,71 @@
+package event
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBus_Publish(t *testing.T) {
+	bus := NewBus()
+
+	var first, second []Event
+
+	bus.Subscribe(SubscriberFunc(func(_ context.Context, evt Event) {
+		first = append(first, evt)
+	}))
+
+	unsubscribe := bus.Subscribe(SubscriberFunc(func(_ context.Context, evt Event) {
+		second = append(second, evt)
+	}))
+
+	bus.Publish(t.Context(), Event{Type: OrderCreated, Domains: []string{"example.com"}})
+
+	unsubscribe()
+
+	date := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	bus.Publish(t.Context(), Event{Type: OrderFinalized, Time: date})
+
+	require.Len(t, first, 2)
+	require.Len(t, second, 1)
+
+	assert.Equal(t, OrderCreated, first[0].Type)
+	assert.Equal(t, []string{"example.com"}, first[0].Domains)
+	assert.False(t, first[0].Time.IsZero())
+
+	assert.Equal(t, OrderFinalized, first[1].Type)
+	assert.Equal(t, date, first[1].Time)
+
+	assert.Equal(t, first[0], second[0])
+}
+
+func TestBus_Publish_nil(t *testing.T) {
+	var bus *Bus
+
+	assert.NotPanics(t, func() {
+		bus.Publish(t.Context(), Event{Type: OrderCreated})
+	})
+}
+
+func TestBus_Subscribe_reentrant(t *testing.T) {
+	bus := NewBus()
+
+	var count int
+
+	var unsubscribe func()
+
+	unsubscribe = bus.Subscribe(SubscriberFunc(func(_ context.Context, _ Event) {
+		count++
+
+		unsubscribe()
+	}))
+
+	bus.Publish(t.Context(), Event{Type: OrderCreated})
+	bus.Publish(t.Context(), Event{Type: OrderCreated})
+
+	assert.Equal(t, 1, count)
+}
@@ -0,0 +1,328 @@
+// Package dnsserver implements a minimal authoritative DNS server (UDP and TCP) answering TXT queries.
+// The records are stored in memory.
+package dnsserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-acme/lego/v5/challenge"
+	"github.com/go-acme/lego/v5/log"
+	"github.com/miekg/dns"
+)
+
+// DefaultTTL is the default TTL of the records.
+const DefaultTTL = 60
+
+// Options configures a Server.
+type Options struct {
+	// Address is the address to listen on (UDP and TCP).
+	// When the port is 0, the TCP listener uses the port of the UDP listener.
+	Address string
+
+	NetworkStack challenge.NetworkStack
+
+	// Zone is the zone served by the server (optional).
+	// The server answers the SOA and NS queries of the zone,
+	// and returns NXDOMAIN for the unknown names of the zone.
+	// Without a zone, the server only answers for the names of the records.
+	Zone string
+
+	// Nameserver is the name of the server, used by the SOA and NS records (optional).
+	Nameserver string
+
+	// TTL of the records (default: DefaultTTL).
+	TTL uint32
+}
+
+// Server is an authoritative DNS server answering TXT queries.
+type Server struct {
+	address    string
+	stack      challenge.NetworkStack
+	zone       string
+	nameserver string
+	ttl        uint32
+	serial     uint32
+
+	mu      sync.RWMutex
+	records map[string][]string
+
+	udp *dns.Server
+	tcp *dns.Server
+}
+
+// New creates a new Server.
+func New(opts Options) *Server {
+	if opts.TTL == 0 {
+		opts.TTL = DefaultTTL
+	}
+
+	s := &Server{
+		address: opts.Address,
+		stack:   opts.NetworkStack,
+		ttl:     opts.TTL,
+		serial:  uint32(time.Now().Unix()),
+		records: make(map[string][]string),
+	}
+
+	if opts.Zone != "" {
+		s.zone = normalizeName(opts.Zone)
+	}
+
+	if opts.Nameserver != "" {
+		s.nameserver = normalizeName(opts.Nameserver)
+	}
+
+	return s
+}
+
+// Start starts the UDP and TCP listeners.
+// The server is ready to answer when the method returns.
+func (s *Server) Start(ctx context.Context) error {
+	var lc net.ListenConfig
+
+	pc, err := lc.ListenPacket(ctx, s.stack.Network("udp"), s.address)
+	if err != nil {
+		return fmt.Errorf("could not start the DNS server (UDP): %w", err)
+	}
+
+	address := s.address
+
+	host, port, err := net.SplitHostPort(s.address)
+	if err == nil && port == "0" {
+		address = net.JoinHostPort(host, portOf(pc.LocalAddr()))
+	}
+
+	l, err := lc.Listen(ctx, s.stack.Network("tcp"), address)
+	if err != nil {
+		_ = pc.Close()
+
+		return fmt.Errorf("could not start the DNS server (TCP): %w", err)
+	}
+
+	s.udp = &dns.Server{PacketConn: pc, Handler: s}
+	s.tcp = &dns.Server{Listener: l, Handler: s}
+
+	for _, srv := range []*dns.Server{s.udp, s.tcp} {
+		started := make(chan struct{})
+		srv.NotifyStartedFunc = func() { close(started) }
+
+		errCh := make(chan error, 1)
+
+		go func() {
+			errS := srv.ActivateAndServe()
+			if errS != nil {
+				log.Warn("dnsserver: DNS server serve.", log.ErrorAttr(errS))
+			}
+
+			errCh <- errS
+		}()
+
+		select {
+		case <-started:
+		case errS := <-errCh:
+			_ = pc.Close()
+			_ = l.Close()
+
+			s.udp, s.tcp = nil, nil
+
+			return fmt.Errorf("could not start the DNS server: %w", errS)
+		}
+	}
+
+	return nil
+}
+
+// Shutdown stops the listeners.
+func (s *Server) Shutdown(ctx context.Context) error {
+	var errs []error
+
+	for _, srv := range []*dns.Server{s.udp, s.tcp} {
+		if srv == nil {
+			continue
+		}
+
+		errs = append(errs, srv.ShutdownContext(ctx))
+	}
+
+	s.udp, s.tcp = nil, nil
+
+	return errors.Join(errs...)
+}
+
+// Addr returns the address of the UDP listener.
+// The address of the TCP listener is the same.
+func (s *Server) Addr() net.Addr {
+	if s.udp == nil {
+		return nil
+	}
+
+	return s.udp.PacketConn.LocalAddr()
+}
+
+// AddTXT adds a TXT record.
+func (s *Server) AddTXT(name, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	name = normalizeName(name)
+
+	s.records[name] = append(s.records[name], value)
+}
+
+// DeleteTXT deletes a TXT record.
+func (s *Server) DeleteTXT(name, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	name = normalizeName(name)
+
+	if i := slices.Index(s.records[name], value); i >= 0 {
+		s.records[name] = slices.Delete(s.records[name], i, i+1)
+	}
+
+	if len(s.records[name]) == 0 {
+		delete(s.records, name)
+	}
+}
+
+// SetTXT replaces the TXT records of a name.
+func (s *Server) SetTXT(name string, values ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	name = normalizeName(name)
+
+	if len(values) == 0 {
+		delete(s.records, name)
+		return
+	}
+
+	s.records[name] = slices.Clone(values)
+}
+
+// Len returns the number of names with records.
+func (s *Server) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return len(s.records)
+}
+
+// ServeDNS implements dns.Handler.
+func (s *Server) ServeDNS(w dns.ResponseWriter, req *dns.Msg) {
+	m := new(dns.Msg)
+	m.SetReply(req)
+
+	if req.Opcode != dns.OpcodeQuery || len(req.Question) != 1 || req.Question[0].Qclass != dns.ClassINET {
+		m.SetRcode(req, dns.RcodeRefused)
+
+		_ = w.WriteMsg(m)
+
+		return
+	}
+
+	question := req.Question[0]
+	name := normalizeName(question.Name)
+
+	s.mu.RLock()
+	values, found := s.records[name]
+	values = slices.Clone(values)
+	s.mu.RUnlock()
+
+	switch {
+	case found, name == s.zone:
+		m.Authoritative = true
+		m.Answer = s.answer(question, values, name == s.apex(name))
+
+	case s.inZone(name):
+		m.Authoritative = true
+		m.Rcode = dns.RcodeNameError
+
+	default:
+		m.Rcode = dns.RcodeRefused
+	}
+
+	if m.Authoritative && len(m.Answer) == 0 {
+		m.Ns = []dns.RR{s.soa(s.apex(name))}
+	}
+
+	_ = w.WriteMsg(m)
+}
+
+func (s *Server) answer(question dns.Question, values []string, apex bool) []dns.RR {
+	var answer []dns.RR
+
+	switch question.Qtype {
+	case dns.TypeTXT:
+		for _, value := range values {
+			answer = append(answer, &dns.TXT{
+				Hdr: dns.RR_Header{Name: question.Name, Rrtype: dns.TypeTXT, Class: dns.ClassINET, Ttl: s.ttl},
+				Txt: []string{value},
+			})
+		}
+
+	case dns.TypeSOA:
+		if apex {
+			answer = append(answer, s.soa(question.Name))
+		}
+
+	case dns.TypeNS:
+		if apex && s.nameserver != "" {
+			answer = append(answer, &dns.NS{
+				Hdr: dns.RR_Header{Name: question.Name, Rrtype: dns.TypeNS, Class: dns.ClassINET, Ttl: s.ttl},
+				Ns:  s.nameserver,
+			})
+		}
+	}
+
+	return answer
+}
+
+func (s *Server) soa(name string) *dns.SOA {
+	ns := s.nameserver
+	if ns == "" {
+		ns = dns.Fqdn(name)
+	}
+
+	return &dns.SOA{
+		Hdr:     dns.RR_Header{Name: dns.Fqdn(name), Rrtype: dns.TypeSOA, Class: dns.ClassINET, Ttl: s.ttl},
+		Ns:      ns,
+		Mbox:    "hostmaster." + dns.Fqdn(name),
+		Serial:  s.serial,
+		Refresh: 3600,
+		Retry:   600,
+		Expire:  86400,
+		Minttl:  s.ttl,
+	}
+}
+
+// apex returns the apex of the zone of a name:
+// the zone of the server, or the name itself for the names outside the zone.
+func (s *Server) apex(name string) string {
+	if s.inZone(name) {
+		return s.zone
+	}
+
+	return name
+}
+
+func (s *Server) inZone(name string) bool {
+	return s.zone != "" && dns.IsSubDomain(s.zone, name)
+}
+
+func normalizeName(name string) string {
+	return strings.ToLower(dns.Fqdn(name))
+}
+
+func portOf(addr net.Addr) string {
+	_, port, _ := net.SplitHostPort(addr.String())
+
+	return port
+}
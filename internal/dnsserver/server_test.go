@@ -0,0 +1,196 @@
+package dnsserver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-acme/lego/v5/challenge"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer(t *testing.T) {
+	server := setupServer(t, Options{Zone: "acme.example.com", Nameserver: "ns.example.com"})
+
+	server.AddTXT("_acme-challenge.Example.com", "a")
+	server.AddTXT("_acme-challenge.example.com.", "b")
+	server.AddTXT("foo.acme.example.com", "c")
+
+	testCases := []struct {
+		desc     string
+		net      string
+		name     string
+		qtype    uint16
+		rcode    int
+		expected []string
+		ns       bool
+	}{
+		{
+			desc:     "TXT",
+			name:     "_acme-challenge.example.com.",
+			qtype:    dns.TypeTXT,
+			expected: []string{"a", "b"},
+		},
+		{
+			desc:     "TXT (TCP)",
+			net:      "tcp",
+			name:     "_acme-challenge.example.com.",
+			qtype:    dns.TypeTXT,
+			expected: []string{"a", "b"},
+		},
+		{
+			desc:     "TXT case-insensitive",
+			name:     "_ACME-challenge.example.COM.",
+			qtype:    dns.TypeTXT,
+			expected: []string{"a", "b"},
+		},
+		{
+			desc:     "TXT in zone",
+			name:     "foo.acme.example.com.",
+			qtype:    dns.TypeTXT,
+			expected: []string{"c"},
+		},
+		{
+			desc:  "other type",
+			name:  "_acme-challenge.example.com.",
+			qtype: dns.TypeA,
+			ns:    true,
+		},
+		{
+			desc:  "SOA of a record outside the zone",
+			name:  "_acme-challenge.example.com.",
+			qtype: dns.TypeSOA,
+		},
+		{
+			desc:  "SOA of the zone",
+			name:  "acme.example.com.",
+			qtype: dns.TypeSOA,
+		},
+		{
+			desc:  "NS of the zone",
+			name:  "acme.example.com.",
+			qtype: dns.TypeNS,
+		},
+		{
+			desc:  "unknown name in the zone",
+			name:  "bar.acme.example.com.",
+			qtype: dns.TypeTXT,
+			rcode: dns.RcodeNameError,
+			ns:    true,
+		},
+		{
+			desc:  "unknown name outside the zone",
+			name:  "example.org.",
+			qtype: dns.TypeTXT,
+			rcode: dns.RcodeRefused,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			resp := query(t, server, test.net, test.name, test.qtype)
+
+			require.Equal(t, test.rcode, resp.Rcode)
+			assert.Equal(t, test.rcode != dns.RcodeRefused, resp.Authoritative)
+
+			if test.ns {
+				require.Len(t, resp.Ns, 1)
+				assert.IsType(t, &dns.SOA{}, resp.Ns[0])
+			} else {
+				assert.Empty(t, resp.Ns)
+			}
+
+			if test.expected != nil {
+				assert.Equal(t, test.expected, txtValues(resp))
+
+				return
+			}
+
+			if test.rcode == dns.RcodeSuccess && !test.ns {
+				require.Len(t, resp.Answer, 1)
+				assert.Equal(t, test.qtype, resp.Answer[0].Header().Rrtype)
+			}
+		})
+	}
+}
+
+func TestServer_DeleteTXT(t *testing.T) {
+	server := setupServer(t, Options{})
+
+	server.AddTXT("_acme-challenge.example.com", "a")
+	server.AddTXT("_acme-challenge.example.com", "b")
+
+	server.DeleteTXT("_acme-challenge.example.com", "a")
+
+	resp := query(t, server, "", "_acme-challenge.example.com.", dns.TypeTXT)
+	assert.Equal(t, []string{"b"}, txtValues(resp))
+
+	server.DeleteTXT("_acme-challenge.example.com", "b")
+
+	assert.Equal(t, 0, server.Len())
+
+	resp = query(t, server, "", "_acme-challenge.example.com.", dns.TypeTXT)
+	assert.Equal(t, dns.RcodeRefused, resp.Rcode)
+}
+
+func TestServer_SetTXT(t *testing.T) {
+	server := setupServer(t, Options{})
+
+	server.AddTXT("_acme-challenge.example.com", "a")
+
+	server.SetTXT("_acme-challenge.example.com", "b", "c")
+
+	resp := query(t, server, "", "_acme-challenge.example.com.", dns.TypeTXT)
+	assert.Equal(t, []string{"b", "c"}, txtValues(resp))
+
+	server.SetTXT("_acme-challenge.example.com")
+
+	assert.Equal(t, 0, server.Len())
+}
+
+func setupServer(t *testing.T, opts Options) *Server {
+	t.Helper()
+
+	opts.Address = "127.0.0.1:0"
+	opts.NetworkStack = challenge.IPv4Only
+
+	server := New(opts)
+
+	err := server.Start(t.Context())
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_ = server.Shutdown(context.Background())
+	})
+
+	return server
+}
+
+func query(t *testing.T, server *Server, network, name string, qtype uint16) *dns.Msg {
+	t.Helper()
+
+	m := new(dns.Msg)
+	m.SetQuestion(name, qtype)
+
+	client := &dns.Client{Net: network}
+
+	resp, _, err := client.Exchange(m, server.Addr().String())
+	require.NoError(t, err)
+
+	return resp
+}
+
+func txtValues(msg *dns.Msg) []string {
+	var values []string
+
+	for _, rr := range msg.Answer {
+		if txt, ok := rr.(*dns.TXT); ok {
+			values = append(values, txt.Txt...)
+		}
+	}
+
+	return values
+}
@@ -56,16 +56,23 @@ func generate(ctx context.Context) error {
 	for _, args := range [][]string{
 		{"lego", "-h"},
 		{"lego", "run", "-h"},
+		{"lego", "daemon", "-h"},
 		{"lego", "accounts", "register", "-h"},
 		{"lego", "accounts", "recover", "-h"},
 		{"lego", "accounts", "keyrollover", "-h"},
 		{"lego", "accounts", "list", "-h"},
+		{"lego", "authorizations", "list", "-h"},
+		{"lego", "authorizations", "deactivate", "-h"},
+		{"lego", "preauthorize", "-h"},
 		{"lego", "certificates", "revoke", "-h"},
 		{"lego", "certificates", "list", "-h"},
 		{"lego", "archives", "restore", "-h"},
 		{"lego", "archives", "list", "-h"},
 		{"lego", "dnshelp", "-h"},
+		{"lego", "caa", "-h"},
+		{"lego", "plan", "-h"},
 		{"lego", "migrate", "-h"},
+		{"lego", "acmedns-server", "-h"},
 	} {
 		content, err := run(ctx, app, args)
 		if err != nil {
@@ -187,5 +187,10 @@ func (b *Builder) Build(t *testing.T, options ...Option) net.Addr {
 
 	waitLock.Lock()
 
+	// TCP based servers (ex: "tcp-tls").
+	if server.PacketConn == nil {
+		return server.Listener.Addr()
+	}
+
 	return server.PacketConn.LocalAddr()
 }
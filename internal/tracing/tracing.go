@@ -0,0 +1,87 @@
+// Package tracing provides the OpenTelemetry instrumentation.
+//
+// The spans are created with the global TracerProvider (otel.SetTracerProvider),
+// by default, the spans are discarded.
+package tracing
+
+import (
+	"context"
+	"net/http"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/go-acme/lego/v5"
+
+// Attribute keys.
+const (
+	AttrDomain        = attribute.Key("lego.domain")
+	AttrDomains       = attribute.Key("lego.domains")
+	AttrChallengeType = attribute.Key("lego.challenge.type")
+	AttrOrderURL      = attribute.Key("lego.order.url")
+	AttrFQDN          = attribute.Key("lego.dns.fqdn")
+	AttrNameserver    = attribute.Key("lego.dns.nameserver")
+	AttrRecordType    = attribute.Key("lego.dns.type")
+	AttrPropagated    = attribute.Key("lego.dns01.propagated")
+)
+
+// Start creates a span and a context containing the span.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End records the error, if any, and ends the span.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
+
+// WrapTransport wraps an HTTP transport with the otelhttp transport.
+// A nil transport is replaced by http.DefaultTransport.
+func WrapTransport(rt http.RoundTripper) http.RoundTripper {
+	if _, ok := rt.(*otelhttp.Transport); ok {
+		return rt
+	}
+
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+
+	return otelhttp.NewTransport(rt)
+}
+
+// provider is a challenge provider (challenge.Provider).
+type provider interface {
+	Present(ctx context.Context, domain, token, keyAuth string) error
+	CleanUp(ctx context.Context, domain, token, keyAuth string) error
+}
+
+// Present calls the Present method of a challenge provider inside a span.
+func Present(ctx context.Context, p provider, challengeType, domain, token, keyAuth string) error {
+	ctx, span := Start(ctx, "lego.provider.present", AttrChallengeType.String(challengeType), AttrDomain.String(domain))
+
+	err := p.Present(ctx, domain, token, keyAuth)
+
+	End(span, err)
+
+	return err
+}
+
+// CleanUp calls the CleanUp method of a challenge provider inside a span.
+func CleanUp(ctx context.Context, p provider, challengeType, domain, token, keyAuth string) error {
+	ctx, span := Start(ctx, "lego.provider.cleanup", AttrChallengeType.String(challengeType), AttrDomain.String(domain))
+
+	err := p.CleanUp(ctx, domain, token, keyAuth)
+
+	End(span, err)
+
+	return err
+}
@@ -0,0 +1,70 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type fakeProvider struct {
+	err error
+}
+
+func (f fakeProvider) Present(_ context.Context, _, _, _ string) error {
+	return f.err
+}
+
+func (f fakeProvider) CleanUp(_ context.Context, _, _, _ string) error {
+	return f.err
+}
+
+func setupRecorder(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+
+	recorder := tracetest.NewSpanRecorder()
+
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	return recorder
+}
+
+func TestPresent(t *testing.T) {
+	recorder := setupRecorder(t)
+
+	err := Present(t.Context(), fakeProvider{}, "dns-01", "example.com", "token", "keyAuth")
+	require.NoError(t, err)
+
+	err = CleanUp(t.Context(), fakeProvider{err: errors.New("oops")}, "dns-01", "example.com", "token", "keyAuth")
+	require.EqualError(t, err, "oops")
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+
+	assert.Equal(t, "lego.provider.present", spans[0].Name())
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+	assert.Contains(t, spans[0].Attributes(), AttrDomain.String("example.com"))
+	assert.Contains(t, spans[0].Attributes(), AttrChallengeType.String("dns-01"))
+
+	assert.Equal(t, "lego.provider.cleanup", spans[1].Name())
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+	assert.Equal(t, "oops", spans[1].Status().Description)
+}
+
+func TestWrapTransport(t *testing.T) {
+	rt := WrapTransport(nil)
+	require.NotNil(t, rt)
+
+	assert.Same(t, rt, WrapTransport(rt))
+	assert.NotSame(t, http.DefaultTransport, rt)
+}
@@ -0,0 +1,317 @@
+package acmetest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-acme/lego/v5/acme"
+	"github.com/go-jose/go-jose/v4"
+)
+
+type account struct {
+	id         string
+	url        string
+	key        *jose.JSONWebKey
+	thumbprint string
+	status     string
+	contact    []string
+	orders     []*order
+}
+
+// resource returns the ACME representation of the account.
+// The server mutex must be held.
+func (a *account) resource() acme.Account {
+	return acme.Account{
+		Status:               a.status,
+		Contact:              a.contact,
+		TermsOfServiceAgreed: true,
+		Orders:               a.url + "/orders",
+	}
+}
+
+// handleNewAccount creates an account.
+// https://www.rfc-editor.org/rfc/rfc8555.html#section-7.3
+func (s *Server) handleNewAccount(rw http.ResponseWriter, req *http.Request) {
+	signed, problem := s.verifyRequest(req)
+	if problem != nil {
+		writeProblem(rw, problem)
+		return
+	}
+
+	if signed.jwk == nil {
+		writeProblem(rw, newProblem(http.StatusBadRequest, acme.MalformedErrorType, "the request must be signed with a jwk"))
+		return
+	}
+
+	var payload acme.Account
+
+	err := json.Unmarshal(signed.payload, &payload)
+	if err != nil {
+		writeProblem(rw, newProblem(http.StatusBadRequest, acme.MalformedErrorType, "invalid payload: %v", err))
+		return
+	}
+
+	tp, err := thumbprint(signed.jwk)
+	if err != nil {
+		writeProblem(rw, newProblem(http.StatusBadRequest, acme.BadPublicKeyErrorType, "invalid jwk: %v", err))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing, ok := s.accountsByKey[tp]; ok {
+		rw.Header().Set("Location", existing.url)
+		writeJSON(rw, http.StatusOK, existing.resource())
+
+		return
+	}
+
+	if payload.OnlyReturnExisting {
+		writeProblem(rw, newProblem(http.StatusBadRequest, acme.AccountDoesNotExistErrorType, "no account exists for this key"))
+		return
+	}
+
+	switch {
+	case len(payload.ExternalAccountBinding) > 0:
+		problem = s.verifyExternalAccountBinding(payload.ExternalAccountBinding, tp)
+		if problem != nil {
+			writeProblem(rw, problem)
+			return
+		}
+
+	case s.meta.ExternalAccountRequired:
+		writeProblem(rw, newProblem(http.StatusUnauthorized, acme.ExternalAccountRequiredErrorType, "an external account binding is required"))
+		return
+	}
+
+	id := randomString()
+
+	acc := &account{
+		id:         id,
+		url:        s.url(pathAccount, id),
+		key:        signed.jwk,
+		thumbprint: tp,
+		status:     acme.StatusValid,
+		contact:    payload.Contact,
+	}
+
+	s.accounts[acc.url] = acc
+	s.accountsByKey[tp] = acc
+
+	rw.Header().Set("Location", acc.url)
+	writeJSON(rw, http.StatusCreated, acc.resource())
+}
+
+// verifyExternalAccountBinding verifies that the EAB is signed by a registered external account,
+// and that it binds the account key.
+// https://www.rfc-editor.org/rfc/rfc8555.html#section-7.3.4
+func (s *Server) verifyExternalAccountBinding(raw json.RawMessage, accountThumbprint string) *acme.ProblemDetails {
+	jws, err := jose.ParseSigned(string(raw), macAlgorithms)
+	if err != nil {
+		return newProblem(http.StatusBadRequest, acme.MalformedErrorType, "invalid external account binding: %v", err)
+	}
+
+	if len(jws.Signatures) != 1 {
+		return newProblem(http.StatusBadRequest, acme.MalformedErrorType, "the external account binding must have one signature")
+	}
+
+	header := jws.Signatures[0].Protected
+
+	hmacEncoded, ok := s.externalAccounts[header.KeyID]
+	if !ok {
+		return newProblem(http.StatusUnauthorized, acme.UnauthorizedErrorType, "unknown external account: %q", header.KeyID)
+	}
+
+	if u, _ := header.ExtraHeaders["url"].(string); u != s.url(pathNewAccount, "") {
+		return newProblem(http.StatusUnauthorized, acme.UnauthorizedErrorType, "the external account binding URL %q doesn't match the request URL", u)
+	}
+
+	hmacKey, err := base64.RawURLEncoding.DecodeString(hmacEncoded)
+	if err != nil {
+		hmacKey, err = base64.URLEncoding.DecodeString(hmacEncoded)
+		if err != nil {
+			return newProblem(http.StatusInternalServerError, acme.ServerInternalErrorType, "invalid HMAC key: %v", err)
+		}
+	}
+
+	payload, err := jws.Verify(hmacKey)
+	if err != nil {
+		return newProblem(http.StatusUnauthorized, acme.UnauthorizedErrorType, "external account binding verification error: %v", err)
+	}
+
+	var jwk jose.JSONWebKey
+
+	err = json.Unmarshal(payload, &jwk)
+	if err != nil {
+		return newProblem(http.StatusBadRequest, acme.MalformedErrorType, "invalid external account binding payload: %v", err)
+	}
+
+	tp, err := thumbprint(&jwk)
+	if err != nil || tp != accountThumbprint {
+		return newProblem(http.StatusUnauthorized, acme.UnauthorizedErrorType, "the external account binding doesn't match the account key")
+	}
+
+	return nil
+}
+
+// handleAccount retrieves, updates, or deactivates an account.
+// https://www.rfc-editor.org/rfc/rfc8555.html#section-7.3.2
+func (s *Server) handleAccount(rw http.ResponseWriter, req *http.Request) {
+	signed, problem := s.verifyAccountRequest(req)
+	if problem != nil {
+		writeProblem(rw, problem)
+		return
+	}
+
+	if signed.account.id != req.PathValue("id") {
+		writeProblem(rw, newProblem(http.StatusUnauthorized, acme.UnauthorizedErrorType, "the account doesn't match the request signer"))
+		return
+	}
+
+	var payload acme.Account
+
+	if len(signed.payload) > 0 {
+		err := json.Unmarshal(signed.payload, &payload)
+		if err != nil {
+			writeProblem(rw, newProblem(http.StatusBadRequest, acme.MalformedErrorType, "invalid payload: %v", err))
+			return
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	acc := signed.account
+
+	switch {
+	case payload.Status == acme.StatusDeactivated:
+		acc.status = acme.StatusDeactivated
+
+	case payload.Status != "":
+		writeProblem(rw, newProblem(http.StatusBadRequest, acme.MalformedErrorType, "invalid account status: %q", payload.Status))
+		return
+
+	case payload.Contact != nil:
+		acc.contact = payload.Contact
+	}
+
+	writeJSON(rw, http.StatusOK, acc.resource())
+}
+
+// handleAccountOrders lists the orders of an account.
+// https://www.rfc-editor.org/rfc/rfc8555.html#section-7.1.2.1
+func (s *Server) handleAccountOrders(rw http.ResponseWriter, req *http.Request) {
+	signed, problem := s.verifyAccountRequest(req)
+	if problem != nil {
+		writeProblem(rw, problem)
+		return
+	}
+
+	if signed.account.id != req.PathValue("id") {
+		writeProblem(rw, newProblem(http.StatusUnauthorized, acme.UnauthorizedErrorType, "the account doesn't match the request signer"))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	orders := make([]string, 0, len(signed.account.orders))
+
+	for _, o := range signed.account.orders {
+		orders = append(orders, o.url)
+	}
+
+	writeJSON(rw, http.StatusOK, map[string][]string{"orders": orders})
+}
+
+// handleKeyChange replaces the key of an account.
+// https://www.rfc-editor.org/rfc/rfc8555.html#section-7.3.5
+func (s *Server) handleKeyChange(rw http.ResponseWriter, req *http.Request) {
+	signed, problem := s.verifyAccountRequest(req)
+	if problem != nil {
+		writeProblem(rw, problem)
+		return
+	}
+
+	inner, err := jose.ParseSigned(string(signed.payload), signatureAlgorithms)
+	if err != nil {
+		writeProblem(rw, newProblem(http.StatusBadRequest, acme.MalformedErrorType, "invalid inner JWS: %v", err))
+		return
+	}
+
+	if len(inner.Signatures) != 1 || inner.Signatures[0].Protected.JSONWebKey == nil {
+		writeProblem(rw, newProblem(http.StatusBadRequest, acme.MalformedErrorType, "the inner JWS must have one signature with a jwk"))
+		return
+	}
+
+	header := inner.Signatures[0].Protected
+
+	if u, _ := header.ExtraHeaders["url"].(string); u != s.url(pathKeyChange, "") {
+		writeProblem(rw, newProblem(http.StatusBadRequest, acme.MalformedErrorType, "the inner JWS URL %q doesn't match the request URL", u))
+		return
+	}
+
+	newKey := header.JSONWebKey
+
+	payload, err := inner.Verify(newKey)
+	if err != nil {
+		writeProblem(rw, newProblem(http.StatusBadRequest, acme.MalformedErrorType, "inner JWS verification error: %v", err))
+		return
+	}
+
+	var keyChange acme.KeyChange
+
+	err = json.Unmarshal(payload, &keyChange)
+	if err != nil {
+		writeProblem(rw, newProblem(http.StatusBadRequest, acme.MalformedErrorType, "invalid key change payload: %v", err))
+		return
+	}
+
+	var oldKey jose.JSONWebKey
+
+	err = json.Unmarshal(keyChange.OldKey, &oldKey)
+	if err != nil {
+		writeProblem(rw, newProblem(http.StatusBadRequest, acme.MalformedErrorType, "invalid old key: %v", err))
+		return
+	}
+
+	oldThumbprint, err := thumbprint(&oldKey)
+	if err != nil {
+		writeProblem(rw, newProblem(http.StatusBadRequest, acme.MalformedErrorType, "invalid old key: %v", err))
+		return
+	}
+
+	newThumbprint, err := thumbprint(newKey)
+	if err != nil {
+		writeProblem(rw, newProblem(http.StatusBadRequest, acme.BadPublicKeyErrorType, "invalid new key: %v", err))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	acc := signed.account
+
+	if keyChange.Account != acc.url || oldThumbprint != acc.thumbprint {
+		writeProblem(rw, newProblem(http.StatusUnauthorized, acme.UnauthorizedErrorType, "the key change doesn't match the account"))
+		return
+	}
+
+	if existing, ok := s.accountsByKey[newThumbprint]; ok {
+		rw.Header().Set("Location", existing.url)
+		writeProblem(rw, newProblem(http.StatusConflict, acme.MalformedErrorType, "the new key is already used by an account"))
+
+		return
+	}
+
+	delete(s.accountsByKey, acc.thumbprint)
+
+	acc.key = newKey
+	acc.thumbprint = newThumbprint
+
+	s.accountsByKey[newThumbprint] = acc
+
+	writeJSON(rw, http.StatusOK, acc.resource())
+}
@@ -0,0 +1,330 @@
+package acmetest
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/go-acme/lego/v5/acme"
+	"github.com/go-acme/lego/v5/acme/api"
+	"github.com/go-jose/go-jose/v4"
+)
+
+// renewalInfoRetryAfter is the Retry-After of the renewalInfo responses.
+const renewalInfoRetryAfter = 6 * time.Hour
+
+// certificateAuthority is an in-memory CA with a root and an intermediate certificate.
+type certificateAuthority struct {
+	root *x509.Certificate
+
+	intermediate    *x509.Certificate
+	intermediateKey crypto.Signer
+}
+
+func newCertificateAuthority() (*certificateAuthority, error) {
+	rootKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("generating the root key: %w", err)
+	}
+
+	now := time.Now()
+
+	rootTemplate := &x509.Certificate{
+		SerialNumber:          randomSerialNumber(),
+		Subject:               pkix.Name{CommonName: "acmetest root"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(10 * 365 * 24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	root, err := createCertificate(rootTemplate, rootTemplate, rootKey.Public(), rootKey)
+	if err != nil {
+		return nil, fmt.Errorf("creating the root certificate: %w", err)
+	}
+
+	intermediateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("generating the intermediate key: %w", err)
+	}
+
+	intermediateTemplate := &x509.Certificate{
+		SerialNumber:          randomSerialNumber(),
+		Subject:               pkix.Name{CommonName: "acmetest intermediate"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(5 * 365 * 24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+	}
+
+	intermediate, err := createCertificate(intermediateTemplate, root, intermediateKey.Public(), rootKey)
+	if err != nil {
+		return nil, fmt.Errorf("creating the intermediate certificate: %w", err)
+	}
+
+	return &certificateAuthority{
+		root:            root,
+		intermediate:    intermediate,
+		intermediateKey: intermediateKey,
+	}, nil
+}
+
+type issuedCertificate struct {
+	id      string
+	url     string
+	account *account
+
+	cert  *x509.Certificate
+	ariID string
+
+	revoked  bool
+	replaced bool
+}
+
+// chain returns the PEM encoded certificate chain (leaf first).
+func (c *issuedCertificate) chain(intermediate *x509.Certificate) []byte {
+	buf := new(bytes.Buffer)
+
+	_ = pem.Encode(buf, &pem.Block{Type: "CERTIFICATE", Bytes: c.cert.Raw})
+	_ = pem.Encode(buf, &pem.Block{Type: "CERTIFICATE", Bytes: intermediate.Raw})
+
+	return buf.Bytes()
+}
+
+// issue creates a certificate for an order.
+// The server mutex must be held.
+func (s *Server) issue(o *order, csr *x509.CertificateRequest) (*issuedCertificate, error) {
+	notBefore := o.notBefore
+	if notBefore.IsZero() {
+		notBefore = time.Now()
+	}
+
+	notAfter := o.notAfter
+	if notAfter.IsZero() {
+		notAfter = notBefore.Add(s.certificateLifetime)
+	}
+
+	keyUsage := x509.KeyUsageDigitalSignature
+	if _, ok := csr.PublicKey.(*rsa.PublicKey); ok {
+		keyUsage |= x509.KeyUsageKeyEncipherment
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          randomSerialNumber(),
+		Subject:               pkix.Name{CommonName: csr.Subject.CommonName},
+		DNSNames:              csr.DNSNames,
+		IPAddresses:           csr.IPAddresses,
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
+		KeyUsage:              keyUsage,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+
+	cert, err := createCertificate(template, s.ca.intermediate, csr.PublicKey, s.ca.intermediateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	ariID, err := api.MakeARICertID(cert)
+	if err != nil {
+		return nil, err
+	}
+
+	issued := &issuedCertificate{
+		id:      cert.SerialNumber.Text(16),
+		account: o.account,
+		cert:    cert,
+		ariID:   ariID,
+	}
+
+	issued.url = s.url(pathCertificate, issued.id)
+
+	s.certificates[issued.id] = issued
+
+	return issued, nil
+}
+
+// findCertificateByARI returns the certificate identified by an ARI certificate ID.
+// The server mutex must be held.
+func (s *Server) findCertificateByARI(ariID string) (*issuedCertificate, bool) {
+	for _, issued := range s.certificates {
+		if issued.ariID == ariID {
+			return issued, true
+		}
+	}
+
+	return nil, false
+}
+
+// handleCertificate downloads a certificate chain.
+// https://www.rfc-editor.org/rfc/rfc8555.html#section-7.4.2
+func (s *Server) handleCertificate(rw http.ResponseWriter, req *http.Request) {
+	signed, problem := s.verifyAccountRequest(req)
+	if problem != nil {
+		writeProblem(rw, problem)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	issued, ok := s.certificates[req.PathValue("id")]
+	if !ok || issued.account != signed.account {
+		writeProblem(rw, newProblem(http.StatusNotFound, acme.MalformedErrorType, "unknown certificate"))
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/pem-certificate-chain")
+	rw.WriteHeader(http.StatusOK)
+
+	_, _ = rw.Write(issued.chain(s.ca.intermediate))
+}
+
+// handleRenewalInfo returns the suggested renewal window of a certificate.
+// The window starts at two-thirds of the certificate lifetime,
+// a revoked certificate must be renewed immediately.
+// https://www.rfc-editor.org/rfc/rfc9773.html#section-4
+func (s *Server) handleRenewalInfo(rw http.ResponseWriter, req *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	issued, ok := s.findCertificateByARI(req.PathValue("id"))
+	if !ok {
+		writeProblem(rw, newProblem(http.StatusNotFound, acme.MalformedErrorType, "unknown certificate"))
+		return
+	}
+
+	var window acme.Window
+
+	if issued.revoked {
+		now := time.Now()
+
+		window = acme.Window{Start: now.Add(-time.Hour), End: now}
+	} else {
+		lifetime := issued.cert.NotAfter.Sub(issued.cert.NotBefore)
+
+		window.Start = issued.cert.NotBefore.Add(lifetime * 2 / 3)
+		window.End = window.Start.Add(lifetime / 6)
+	}
+
+	rw.Header().Set("Retry-After", strconv.Itoa(int(renewalInfoRetryAfter.Seconds())))
+	writeJSON(rw, http.StatusOK, acme.RenewalInfo{SuggestedWindow: window})
+}
+
+// handleRevokeCert revokes a certificate.
+// The request must be signed by the account that requested the certificate, or by the certificate key.
+// https://www.rfc-editor.org/rfc/rfc8555.html#section-7.6
+func (s *Server) handleRevokeCert(rw http.ResponseWriter, req *http.Request) {
+	signed, problem := s.verifyRequest(req)
+	if problem != nil {
+		writeProblem(rw, problem)
+		return
+	}
+
+	var payload acme.RevokeCertMessage
+
+	err := json.Unmarshal(signed.payload, &payload)
+	if err != nil {
+		writeProblem(rw, newProblem(http.StatusBadRequest, acme.MalformedErrorType, "invalid payload: %v", err))
+		return
+	}
+
+	// The reasons are defined by RFC 5280, 7 is not used.
+	if payload.Reason != nil && (*payload.Reason > 10 || *payload.Reason == 7) {
+		writeProblem(rw, newProblem(http.StatusBadRequest, acme.BadRevocationReasonErrorType, "invalid revocation reason: %d", *payload.Reason))
+		return
+	}
+
+	der, err := base64.RawURLEncoding.DecodeString(payload.Certificate)
+	if err != nil {
+		writeProblem(rw, newProblem(http.StatusBadRequest, acme.MalformedErrorType, "decoding the certificate: %v", err))
+		return
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		writeProblem(rw, newProblem(http.StatusBadRequest, acme.MalformedErrorType, "parsing the certificate: %v", err))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	issued, ok := s.certificates[cert.SerialNumber.Text(16)]
+	if !ok || !slices.Equal(issued.cert.Raw, cert.Raw) {
+		writeProblem(rw, newProblem(http.StatusNotFound, acme.MalformedErrorType, "unknown certificate"))
+		return
+	}
+
+	if !canRevoke(signed, issued) {
+		writeProblem(rw, newProblem(http.StatusForbidden, acme.UnauthorizedErrorType, "the request is not authorized to revoke the certificate"))
+		return
+	}
+
+	if issued.revoked {
+		writeProblem(rw, newProblem(http.StatusBadRequest, acme.AlreadyRevokedErrorType, "the certificate is already revoked"))
+		return
+	}
+
+	issued.revoked = true
+
+	rw.WriteHeader(http.StatusOK)
+}
+
+// canRevoke checks that the request is signed by the account that requested the certificate, or by the certificate key.
+// The server mutex must be held.
+func canRevoke(signed *signedRequest, issued *issuedCertificate) bool {
+	if signed.account != nil {
+		return signed.account == issued.account
+	}
+
+	requestThumbprint, err := thumbprint(signed.jwk)
+	if err != nil {
+		return false
+	}
+
+	certThumbprint, err := publicKeyThumbprint(issued.cert.PublicKey)
+	if err != nil {
+		return false
+	}
+
+	return requestThumbprint == certThumbprint
+}
+
+func publicKeyThumbprint(publicKey any) (string, error) {
+	return thumbprint(&jose.JSONWebKey{Key: publicKey})
+}
+
+func createCertificate(template, parent *x509.Certificate, publicKey any, signer crypto.Signer) (*x509.Certificate, error) {
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, publicKey, signer)
+	if err != nil {
+		return nil, err
+	}
+
+	return x509.ParseCertificate(der)
+}
+
+func randomSerialNumber() *big.Int {
+	serial, _ := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+
+	return serial
+}
@@ -0,0 +1,137 @@
+package acmetest
+
+import (
+	"crypto"
+	"encoding/base64"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/go-acme/lego/v5/acme"
+	"github.com/go-jose/go-jose/v4"
+)
+
+var signatureAlgorithms = []jose.SignatureAlgorithm{
+	jose.RS256, jose.RS384, jose.RS512,
+	jose.PS256, jose.PS384, jose.PS512,
+	jose.ES256, jose.ES384, jose.ES512,
+	jose.EdDSA,
+}
+
+var macAlgorithms = []jose.SignatureAlgorithm{jose.HS256, jose.HS384, jose.HS512}
+
+// signedRequest is a verified JWS request.
+type signedRequest struct {
+	payload []byte
+
+	// account is the account identified by the "kid" header.
+	account *account
+
+	// jwk is the key embedded in the "jwk" header.
+	jwk *jose.JSONWebKey
+}
+
+// verifyRequest verifies the JWS of a request.
+// The request must be authenticated by an existing account ("kid") or by an embedded key ("jwk").
+func (s *Server) verifyRequest(req *http.Request) (*signedRequest, *acme.ProblemDetails) {
+	mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if mediaType != "application/jose+json" {
+		return nil, newProblem(http.StatusUnsupportedMediaType, acme.MalformedErrorType, "invalid Content-Type: %q", req.Header.Get("Content-Type"))
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, newProblem(http.StatusBadRequest, acme.MalformedErrorType, "reading the body: %v", err)
+	}
+
+	jws, err := jose.ParseSigned(string(body), signatureAlgorithms)
+	if err != nil {
+		return nil, newProblem(http.StatusBadRequest, acme.MalformedErrorType, "parsing the JWS: %v", err)
+	}
+
+	if len(jws.Signatures) != 1 {
+		return nil, newProblem(http.StatusBadRequest, acme.MalformedErrorType, "the JWS must have one signature")
+	}
+
+	header := jws.Signatures[0].Protected
+
+	if !s.useNonce(header.Nonce) {
+		return nil, newProblem(http.StatusBadRequest, acme.BadNonceErrorType, "invalid nonce: %q", header.Nonce)
+	}
+
+	if u, _ := header.ExtraHeaders["url"].(string); u != s.server.URL+req.URL.Path {
+		return nil, newProblem(http.StatusUnauthorized, acme.UnauthorizedErrorType, "the JWS URL %q doesn't match the request URL", u)
+	}
+
+	signed := &signedRequest{}
+
+	var key any
+
+	switch {
+	case header.JSONWebKey != nil && header.KeyID != "":
+		return nil, newProblem(http.StatusBadRequest, acme.MalformedErrorType, "the JWS must contain a jwk or a kid, not both")
+
+	case header.JSONWebKey != nil:
+		if !header.JSONWebKey.Valid() || !header.JSONWebKey.IsPublic() {
+			return nil, newProblem(http.StatusBadRequest, acme.BadPublicKeyErrorType, "invalid jwk")
+		}
+
+		signed.jwk = header.JSONWebKey
+		key = header.JSONWebKey
+
+	case header.KeyID != "":
+		s.mu.Lock()
+		acc, ok := s.accounts[header.KeyID]
+
+		var status string
+		if ok {
+			status = acc.status
+			key = acc.key
+		}
+		s.mu.Unlock()
+
+		if !ok {
+			return nil, newProblem(http.StatusBadRequest, acme.AccountDoesNotExistErrorType, "unknown account: %q", header.KeyID)
+		}
+
+		if status != acme.StatusValid {
+			return nil, newProblem(http.StatusUnauthorized, acme.UnauthorizedErrorType, "the account is %s", status)
+		}
+
+		signed.account = acc
+
+	default:
+		return nil, newProblem(http.StatusBadRequest, acme.MalformedErrorType, "the JWS must contain a jwk or a kid")
+	}
+
+	signed.payload, err = jws.Verify(key)
+	if err != nil {
+		return nil, newProblem(http.StatusBadRequest, acme.MalformedErrorType, "JWS verification error: %v", err)
+	}
+
+	return signed, nil
+}
+
+// verifyAccountRequest verifies the JWS of a request authenticated by an account.
+func (s *Server) verifyAccountRequest(req *http.Request) (*signedRequest, *acme.ProblemDetails) {
+	signed, problem := s.verifyRequest(req)
+	if problem != nil {
+		return nil, problem
+	}
+
+	if signed.account == nil {
+		return nil, newProblem(http.StatusBadRequest, acme.MalformedErrorType, "the request must be signed by an account (kid)")
+	}
+
+	return signed, nil
+}
+
+// thumbprint returns the base64url encoded SHA-256 thumbprint of a JWK.
+func thumbprint(jwk *jose.JSONWebKey) (string, error) {
+	tp, err := jwk.Thumbprint(crypto.SHA256)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(tp), nil
+}
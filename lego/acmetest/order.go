@@ -0,0 +1,651 @@
+package acmetest
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-acme/lego/v5/acme"
+	"github.com/go-acme/lego/v5/challenge"
+)
+
+type order struct {
+	id      string
+	url     string
+	account *account
+
+	status      string
+	expires     time.Time
+	identifiers []acme.Identifier
+	profile     string
+	notBefore   time.Time
+	notAfter    time.Time
+	replaces    string
+
+	authorizations []*authorization
+	certificate    *issuedCertificate
+	err            *acme.ProblemDetails
+}
+
+// updateStatus computes the status of a pending order from the status of its authorizations.
+// The server mutex must be held.
+func (o *order) updateStatus() {
+	if o.status != acme.StatusPending {
+		return
+	}
+
+	ready := true
+
+	for _, authz := range o.authorizations {
+		switch authz.status {
+		case acme.StatusValid:
+		case acme.StatusPending:
+			ready = false
+		default:
+			o.status = acme.StatusInvalid
+			o.err = newProblem(http.StatusForbidden, acme.UnauthorizedErrorType, "the authorization for %s is %s", authz.identifier.Value, authz.status)
+
+			return
+		}
+	}
+
+	if ready {
+		o.status = acme.StatusReady
+	}
+}
+
+// resource returns the ACME representation of the order.
+// The server mutex must be held.
+func (o *order) resource() acme.Order {
+	o.updateStatus()
+
+	res := acme.Order{
+		Status:      o.status,
+		Expires:     o.expires.Format(time.RFC3339),
+		Identifiers: o.identifiers,
+		Profile:     o.profile,
+		Error:       o.err,
+		Finalize:    o.url + "/finalize",
+		Replaces:    o.replaces,
+	}
+
+	if !o.notBefore.IsZero() {
+		res.NotBefore = o.notBefore.Format(time.RFC3339)
+	}
+
+	if !o.notAfter.IsZero() {
+		res.NotAfter = o.notAfter.Format(time.RFC3339)
+	}
+
+	for _, authz := range o.authorizations {
+		res.Authorizations = append(res.Authorizations, authz.url)
+	}
+
+	if o.certificate != nil {
+		res.Certificate = o.certificate.url
+	}
+
+	return res
+}
+
+type authorization struct {
+	id      string
+	url     string
+	account *account
+
+	status     string
+	expires    time.Time
+	identifier acme.Identifier
+	wildcard   bool
+
+	challenges []*authzChallenge
+}
+
+// resource returns the ACME representation of the authorization.
+// The server mutex must be held.
+func (a *authorization) resource() acme.Authorization {
+	res := acme.Authorization{
+		Status:     a.status,
+		Expires:    a.expires,
+		Identifier: a.identifier,
+		Wildcard:   a.wildcard,
+	}
+
+	for _, chlg := range a.challenges {
+		res.Challenges = append(res.Challenges, chlg.resource())
+	}
+
+	return res
+}
+
+type authzChallenge struct {
+	id            string
+	url           string
+	authorization *authorization
+
+	typ       string
+	token     string
+	status    string
+	validated time.Time
+	err       *acme.ProblemDetails
+}
+
+// resource returns the ACME representation of the challenge.
+// The server mutex must be held.
+func (c *authzChallenge) resource() acme.Challenge {
+	return acme.Challenge{
+		Type:      c.typ,
+		URL:       c.url,
+		Status:    c.status,
+		Validated: c.validated,
+		Error:     c.err,
+		Token:     c.token,
+	}
+}
+
+// handleNewOrder creates an order.
+// https://www.rfc-editor.org/rfc/rfc8555.html#section-7.4
+func (s *Server) handleNewOrder(rw http.ResponseWriter, req *http.Request) {
+	signed, problem := s.verifyAccountRequest(req)
+	if problem != nil {
+		writeProblem(rw, problem)
+		return
+	}
+
+	var payload acme.Order
+
+	err := json.Unmarshal(signed.payload, &payload)
+	if err != nil {
+		writeProblem(rw, newProblem(http.StatusBadRequest, acme.MalformedErrorType, "invalid payload: %v", err))
+		return
+	}
+
+	problem = checkIdentifiers(payload.Identifiers)
+	if problem != nil {
+		writeProblem(rw, problem)
+		return
+	}
+
+	if payload.Profile != "" {
+		if _, ok := s.meta.Profiles[payload.Profile]; !ok {
+			writeProblem(rw, newProblem(http.StatusBadRequest, acme.InvalidProfileErrorType, "unknown profile: %q", payload.Profile))
+			return
+		}
+	}
+
+	now := time.Now()
+
+	o := &order{
+		id:          randomString(),
+		account:     signed.account,
+		status:      acme.StatusPending,
+		expires:     now.Add(defaultOrderLifetime),
+		identifiers: payload.Identifiers,
+		profile:     payload.Profile,
+		replaces:    payload.Replaces,
+	}
+
+	o.url = s.url(pathOrder, o.id)
+
+	o.notBefore, o.notAfter, problem = parseValidity(payload.NotBefore, payload.NotAfter)
+	if problem != nil {
+		writeProblem(rw, problem)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if o.replaces != "" {
+		problem = s.checkReplaces(signed.account, o.replaces)
+		if problem != nil {
+			writeProblem(rw, problem)
+			return
+		}
+	}
+
+	for _, identifier := range payload.Identifiers {
+		o.authorizations = append(o.authorizations, s.getOrCreateAuthorization(signed.account, identifier, now))
+	}
+
+	s.orders[o.id] = o
+	signed.account.orders = append(signed.account.orders, o)
+
+	rw.Header().Set("Location", o.url)
+	writeJSON(rw, http.StatusCreated, o.resource())
+}
+
+func checkIdentifiers(identifiers []acme.Identifier) *acme.ProblemDetails {
+	if len(identifiers) == 0 {
+		return newProblem(http.StatusBadRequest, acme.MalformedErrorType, "the order must contain at least one identifier")
+	}
+
+	for _, identifier := range identifiers {
+		switch identifier.Type {
+		case "dns":
+			if identifier.Value == "" || strings.Contains(strings.TrimPrefix(identifier.Value, "*."), "*") {
+				return newProblem(http.StatusBadRequest, acme.RejectedIdentifierErrorType, "invalid DNS identifier: %q", identifier.Value)
+			}
+
+		case "ip":
+			if net.ParseIP(identifier.Value) == nil {
+				return newProblem(http.StatusBadRequest, acme.MalformedErrorType, "invalid IP identifier: %q", identifier.Value)
+			}
+
+		default:
+			return newProblem(http.StatusBadRequest, acme.UnsupportedIdentifierErrorType, "unsupported identifier type: %q", identifier.Type)
+		}
+	}
+
+	return nil
+}
+
+func parseValidity(notBefore, notAfter string) (time.Time, time.Time, *acme.ProblemDetails) {
+	var nb, na time.Time
+
+	var err error
+
+	if notBefore != "" {
+		nb, err = time.Parse(time.RFC3339, notBefore)
+		if err != nil {
+			return nb, na, newProblem(http.StatusBadRequest, acme.MalformedErrorType, "invalid notBefore: %v", err)
+		}
+	}
+
+	if notAfter != "" {
+		na, err = time.Parse(time.RFC3339, notAfter)
+		if err != nil {
+			return nb, na, newProblem(http.StatusBadRequest, acme.MalformedErrorType, "invalid notAfter: %v", err)
+		}
+	}
+
+	if !nb.IsZero() && !na.IsZero() && !na.After(nb) {
+		return nb, na, newProblem(http.StatusBadRequest, acme.MalformedErrorType, "notAfter must be after notBefore")
+	}
+
+	return nb, na, nil
+}
+
+// checkReplaces checks that the certificate replaced by an order can be replaced by the account.
+// The server mutex must be held.
+// https://www.rfc-editor.org/rfc/rfc9773.html#section-5
+func (s *Server) checkReplaces(acc *account, certID string) *acme.ProblemDetails {
+	issued, ok := s.findCertificateByARI(certID)
+	if !ok || issued.account != acc {
+		return newProblem(http.StatusBadRequest, acme.MalformedErrorType, "unknown replaced certificate: %q", certID)
+	}
+
+	if issued.replaced {
+		return newProblem(http.StatusConflict, acme.AlreadyReplacedErrorType, "the certificate %q has already been replaced", certID)
+	}
+
+	return nil
+}
+
+// handleNewAuthz creates an authorization (pre-authorization).
+// https://www.rfc-editor.org/rfc/rfc8555.html#section-7.4.1
+func (s *Server) handleNewAuthz(rw http.ResponseWriter, req *http.Request) {
+	if !s.newAuthz {
+		writeProblem(rw, newProblem(http.StatusNotFound, acme.MalformedErrorType, "pre-authorization not supported"))
+		return
+	}
+
+	signed, problem := s.verifyAccountRequest(req)
+	if problem != nil {
+		writeProblem(rw, problem)
+		return
+	}
+
+	var payload struct {
+		Identifier acme.Identifier `json:"identifier"`
+	}
+
+	err := json.Unmarshal(signed.payload, &payload)
+	if err != nil {
+		writeProblem(rw, newProblem(http.StatusBadRequest, acme.MalformedErrorType, "invalid payload: %v", err))
+		return
+	}
+
+	problem = checkIdentifiers([]acme.Identifier{payload.Identifier})
+	if problem != nil {
+		writeProblem(rw, problem)
+		return
+	}
+
+	if strings.HasPrefix(payload.Identifier.Value, "*.") {
+		writeProblem(rw, newProblem(http.StatusBadRequest, acme.MalformedErrorType, "the pre-authorization cannot be used for a wildcard domain"))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	authz := s.getOrCreateAuthorization(signed.account, payload.Identifier, time.Now())
+
+	rw.Header().Set("Location", authz.url)
+	writeJSON(rw, http.StatusCreated, authz.resource())
+}
+
+// getOrCreateAuthorization reuses a valid authorization of the account, or creates a new one.
+// The server mutex must be held.
+func (s *Server) getOrCreateAuthorization(acc *account, identifier acme.Identifier, now time.Time) *authorization {
+	wildcard := identifier.Type == "dns" && strings.HasPrefix(identifier.Value, "*.")
+
+	authzIdentifier := acme.Identifier{Type: identifier.Type, Value: strings.TrimPrefix(identifier.Value, "*.")}
+
+	for _, authz := range s.authorizations {
+		if authz.account == acc && authz.identifier == authzIdentifier && authz.wildcard == wildcard &&
+			authz.status == acme.StatusValid && authz.expires.After(now) {
+			return authz
+		}
+	}
+
+	authz := &authorization{
+		id:         randomString(),
+		account:    acc,
+		status:     acme.StatusPending,
+		expires:    now.Add(defaultAuthzLifetime),
+		identifier: authzIdentifier,
+		wildcard:   wildcard,
+	}
+
+	authz.url = s.url(pathAuthorization, authz.id)
+
+	var types []challenge.Type
+
+	switch {
+	case wildcard:
+		types = []challenge.Type{challenge.DNS01}
+	case identifier.Type == "ip":
+		types = []challenge.Type{challenge.HTTP01, challenge.TLSALPN01}
+	default:
+		types = []challenge.Type{challenge.HTTP01, challenge.DNS01, challenge.TLSALPN01}
+	}
+
+	if s.dnsAccount01 && identifier.Type != "ip" {
+		types = append(types, challenge.DNSAccount01)
+	}
+
+	for _, typ := range types {
+		chlg := &authzChallenge{
+			id:            randomString(),
+			authorization: authz,
+			typ:           string(typ),
+			token:         randomString(),
+			status:        acme.StatusPending,
+		}
+
+		chlg.url = s.url(pathChallenge, chlg.id)
+
+		authz.challenges = append(authz.challenges, chlg)
+		s.challenges[chlg.id] = chlg
+	}
+
+	s.authorizations[authz.id] = authz
+
+	return authz
+}
+
+// handleOrder retrieves an order.
+// https://www.rfc-editor.org/rfc/rfc8555.html#section-7.4
+func (s *Server) handleOrder(rw http.ResponseWriter, req *http.Request) {
+	signed, problem := s.verifyAccountRequest(req)
+	if problem != nil {
+		writeProblem(rw, problem)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o, ok := s.orders[req.PathValue("id")]
+	if !ok || o.account != signed.account {
+		writeProblem(rw, newProblem(http.StatusNotFound, acme.MalformedErrorType, "unknown order"))
+		return
+	}
+
+	writeJSON(rw, http.StatusOK, o.resource())
+}
+
+// handleFinalize finalizes an order, and issues the certificate.
+// https://www.rfc-editor.org/rfc/rfc8555.html#section-7.4
+func (s *Server) handleFinalize(rw http.ResponseWriter, req *http.Request) {
+	signed, problem := s.verifyAccountRequest(req)
+	if problem != nil {
+		writeProblem(rw, problem)
+		return
+	}
+
+	var payload acme.CSRMessage
+
+	err := json.Unmarshal(signed.payload, &payload)
+	if err != nil {
+		writeProblem(rw, newProblem(http.StatusBadRequest, acme.MalformedErrorType, "invalid payload: %v", err))
+		return
+	}
+
+	csr, problem := parseCSR(payload.Csr)
+	if problem != nil {
+		writeProblem(rw, problem)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o, ok := s.orders[req.PathValue("id")]
+	if !ok || o.account != signed.account {
+		writeProblem(rw, newProblem(http.StatusNotFound, acme.MalformedErrorType, "unknown order"))
+		return
+	}
+
+	o.updateStatus()
+
+	if o.status != acme.StatusReady {
+		writeProblem(rw, newProblem(http.StatusForbidden, acme.OrderNotReadyErrorType, "the order is %s", o.status))
+		return
+	}
+
+	if !slices.Equal(csrIdentifiers(csr), sortedIdentifiers(o.identifiers)) {
+		writeProblem(rw, newProblem(http.StatusBadRequest, acme.BadCSRErrorType, "the CSR identifiers don't match the order identifiers"))
+		return
+	}
+
+	tp, err := publicKeyThumbprint(csr.PublicKey)
+	if err != nil || tp == signed.account.thumbprint {
+		writeProblem(rw, newProblem(http.StatusBadRequest, acme.BadCSRErrorType, "invalid CSR public key"))
+		return
+	}
+
+	issued, err := s.issue(o, csr)
+	if err != nil {
+		writeProblem(rw, newProblem(http.StatusInternalServerError, acme.ServerInternalErrorType, "issuing the certificate: %v", err))
+		return
+	}
+
+	if o.replaces != "" {
+		if replaced, found := s.findCertificateByARI(o.replaces); found {
+			replaced.replaced = true
+		}
+	}
+
+	o.certificate = issued
+	o.status = acme.StatusValid
+
+	rw.Header().Set("Location", o.url)
+	writeJSON(rw, http.StatusOK, o.resource())
+}
+
+func parseCSR(raw string) (*x509.CertificateRequest, *acme.ProblemDetails) {
+	der, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, newProblem(http.StatusBadRequest, acme.BadCSRErrorType, "decoding the CSR: %v", err)
+	}
+
+	csr, err := x509.ParseCertificateRequest(der)
+	if err != nil {
+		return nil, newProblem(http.StatusBadRequest, acme.BadCSRErrorType, "parsing the CSR: %v", err)
+	}
+
+	err = csr.CheckSignature()
+	if err != nil {
+		return nil, newProblem(http.StatusBadRequest, acme.BadCSRErrorType, "invalid CSR signature: %v", err)
+	}
+
+	return csr, nil
+}
+
+// csrIdentifiers returns the sorted and deduplicated identifiers requested by a CSR.
+func csrIdentifiers(csr *x509.CertificateRequest) []acme.Identifier {
+	var identifiers []acme.Identifier
+
+	if csr.Subject.CommonName != "" {
+		if ip := net.ParseIP(csr.Subject.CommonName); ip != nil {
+			identifiers = append(identifiers, acme.Identifier{Type: "ip", Value: ip.String()})
+		} else {
+			identifiers = append(identifiers, acme.Identifier{Type: "dns", Value: csr.Subject.CommonName})
+		}
+	}
+
+	for _, name := range csr.DNSNames {
+		identifiers = append(identifiers, acme.Identifier{Type: "dns", Value: name})
+	}
+
+	for _, ip := range csr.IPAddresses {
+		identifiers = append(identifiers, acme.Identifier{Type: "ip", Value: ip.String()})
+	}
+
+	return sortedIdentifiers(identifiers)
+}
+
+// sortedIdentifiers returns the sorted and deduplicated identifiers (case-insensitive).
+func sortedIdentifiers(identifiers []acme.Identifier) []acme.Identifier {
+	var result []acme.Identifier
+
+	for _, identifier := range identifiers {
+		value := strings.ToLower(identifier.Value)
+		if ip := net.ParseIP(value); identifier.Type == "ip" && ip != nil {
+			value = ip.String()
+		}
+
+		result = append(result, acme.Identifier{Type: identifier.Type, Value: value})
+	}
+
+	slices.SortFunc(result, func(a, b acme.Identifier) int {
+		return strings.Compare(a.Type+":"+a.Value, b.Type+":"+b.Value)
+	})
+
+	return slices.Compact(result)
+}
+
+// handleAuthorization retrieves or deactivates an authorization.
+// https://www.rfc-editor.org/rfc/rfc8555.html#section-7.5
+func (s *Server) handleAuthorization(rw http.ResponseWriter, req *http.Request) {
+	signed, problem := s.verifyAccountRequest(req)
+	if problem != nil {
+		writeProblem(rw, problem)
+		return
+	}
+
+	var payload acme.Authorization
+
+	if len(signed.payload) > 0 {
+		err := json.Unmarshal(signed.payload, &payload)
+		if err != nil {
+			writeProblem(rw, newProblem(http.StatusBadRequest, acme.MalformedErrorType, "invalid payload: %v", err))
+			return
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	authz, ok := s.authorizations[req.PathValue("id")]
+	if !ok || authz.account != signed.account {
+		writeProblem(rw, newProblem(http.StatusNotFound, acme.MalformedErrorType, "unknown authorization"))
+		return
+	}
+
+	switch payload.Status {
+	case "":
+	case acme.StatusDeactivated:
+		if authz.status != acme.StatusPending && authz.status != acme.StatusValid {
+			writeProblem(rw, newProblem(http.StatusBadRequest, acme.MalformedErrorType, "the authorization is %s", authz.status))
+			return
+		}
+
+		authz.status = acme.StatusDeactivated
+
+	default:
+		writeProblem(rw, newProblem(http.StatusBadRequest, acme.MalformedErrorType, "invalid authorization status: %q", payload.Status))
+		return
+	}
+
+	writeJSON(rw, http.StatusOK, authz.resource())
+}
+
+// handleChallenge retrieves a challenge, or validates it when the client responds to it.
+// The validation is synchronous: the response contains the result of the validation.
+// https://www.rfc-editor.org/rfc/rfc8555.html#section-7.5.1
+func (s *Server) handleChallenge(rw http.ResponseWriter, req *http.Request) {
+	signed, problem := s.verifyAccountRequest(req)
+	if problem != nil {
+		writeProblem(rw, problem)
+		return
+	}
+
+	s.mu.Lock()
+
+	chlg, ok := s.challenges[req.PathValue("id")]
+	if !ok || chlg.authorization.account != signed.account {
+		s.mu.Unlock()
+		writeProblem(rw, newProblem(http.StatusNotFound, acme.MalformedErrorType, "unknown challenge"))
+
+		return
+	}
+
+	authz := chlg.authorization
+
+	rw.Header().Add("Link", link(authz.url, "up"))
+
+	// POST-as-GET, or the challenge has already been processed.
+	if len(signed.payload) == 0 || chlg.status != acme.StatusPending || authz.status != acme.StatusPending {
+		defer s.mu.Unlock()
+
+		writeJSON(rw, http.StatusOK, chlg.resource())
+
+		return
+	}
+
+	chlg.status = acme.StatusProcessing
+
+	typ := chlg.typ
+	token := chlg.token
+	identifier := authz.identifier
+	keyAuth := chlg.token + "." + signed.account.thumbprint
+	accountURL := signed.account.url
+
+	s.mu.Unlock()
+
+	problem = s.validate(req.Context(), typ, identifier, accountURL, token, keyAuth)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if problem != nil {
+		chlg.status = acme.StatusInvalid
+		chlg.err = problem
+		authz.status = acme.StatusInvalid
+	} else {
+		chlg.status = acme.StatusValid
+		chlg.validated = time.Now()
+		authz.status = acme.StatusValid
+	}
+
+	writeJSON(rw, http.StatusOK, chlg.resource())
+}
@@ -0,0 +1,110 @@
+package acmetest
+
+import (
+	"context"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Resolver resolves the names used by the validations.
+// *net.Resolver implements this interface.
+type Resolver interface {
+	// LookupHost returns the addresses of a host (http-01, tls-alpn-01).
+	LookupHost(ctx context.Context, host string) ([]string, error)
+
+	// LookupTXT returns the TXT records of a name (dns-01).
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+// defaultResolver uses the system resolver.
+type defaultResolver struct{}
+
+func (defaultResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	return net.DefaultResolver.LookupHost(ctx, host)
+}
+
+func (defaultResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	return net.DefaultResolver.LookupTXT(ctx, name)
+}
+
+var _ Resolver = (*StaticResolver)(nil)
+
+// StaticResolver is an in-memory Resolver.
+// The names are case-insensitive, and the trailing dot is optional.
+type StaticResolver struct {
+	mu    sync.RWMutex
+	hosts map[string][]string
+	txt   map[string][]string
+}
+
+// NewStaticResolver creates a StaticResolver.
+func NewStaticResolver() *StaticResolver {
+	return &StaticResolver{
+		hosts: make(map[string][]string),
+		txt:   make(map[string][]string),
+	}
+}
+
+// SetHost sets the addresses of a host.
+func (r *StaticResolver) SetHost(host string, addresses ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.hosts[normalizeName(host)] = addresses
+}
+
+// AddTXT adds a TXT record.
+func (r *StaticResolver) AddTXT(name, value string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	name = normalizeName(name)
+
+	r.txt[name] = append(r.txt[name], value)
+}
+
+// DeleteTXT deletes a TXT record.
+func (r *StaticResolver) DeleteTXT(name, value string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	name = normalizeName(name)
+
+	r.txt[name] = slices.DeleteFunc(r.txt[name], func(v string) bool { return v == value })
+
+	if len(r.txt[name]) == 0 {
+		delete(r.txt, name)
+	}
+}
+
+// LookupHost implements Resolver.
+func (r *StaticResolver) LookupHost(_ context.Context, host string) ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	addresses, ok := r.hosts[normalizeName(host)]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+
+	return slices.Clone(addresses), nil
+}
+
+// LookupTXT implements Resolver.
+func (r *StaticResolver) LookupTXT(_ context.Context, name string) ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	records, ok := r.txt[normalizeName(name)]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+
+	return slices.Clone(records), nil
+}
+
+func normalizeName(name string) string {
+	return strings.ToLower(strings.TrimSuffix(name, "."))
+}
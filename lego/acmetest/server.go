@@ -0,0 +1,333 @@
+// Package acmetest provides an in-memory ACME server (RFC 8555) for tests.
+//
+// The server implements the directory, the nonces, the accounts (with the external account binding),
+// the orders, the authorizations, the pre-authorizations (opt-in),
+// the http-01, dns-01, dns-account-01 (opt-in), and tls-alpn-01 validations,
+// the renewal information (ARI), and the revocation.
+//
+// The validations are done synchronously when the client responds to a challenge,
+// the DNS lookups use a configurable Resolver.
+package acmetest
+
+import (
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v5/acme"
+)
+
+// Paths of the ACME resources.
+const (
+	pathDirectory     = "/directory"
+	pathNewNonce      = "/new-nonce"
+	pathNewAccount    = "/new-account"
+	pathAccount       = "/account/"
+	pathKeyChange     = "/key-change"
+	pathNewOrder      = "/new-order"
+	pathNewAuthz      = "/new-authz"
+	pathOrder         = "/order/"
+	pathAuthorization = "/authz/"
+	pathChallenge     = "/challenge/"
+	pathCertificate   = "/certificate/"
+	pathRenewalInfo   = "/renewal-info"
+	pathRevokeCert    = "/revoke-cert"
+)
+
+const (
+	defaultHTTPPort = 80
+	defaultTLSPort  = 443
+
+	defaultCertificateLifetime = 90 * 24 * time.Hour
+	defaultOrderLifetime       = 24 * time.Hour
+	defaultAuthzLifetime       = 7 * 24 * time.Hour
+)
+
+// Option configures a Server.
+type Option func(*Server)
+
+// WithResolver sets the resolver used by the validations.
+// By default, the system resolver is used.
+func WithResolver(resolver Resolver) Option {
+	return func(s *Server) {
+		s.resolver = resolver
+	}
+}
+
+// WithHTTPPort sets the port used for the http-01 validations (default: 80).
+func WithHTTPPort(port int) Option {
+	return func(s *Server) {
+		s.httpPort = port
+	}
+}
+
+// WithTLSPort sets the port used for the tls-alpn-01 validations (default: 443).
+func WithTLSPort(port int) Option {
+	return func(s *Server) {
+		s.tlsPort = port
+	}
+}
+
+// WithExternalAccount registers an external account binding (EAB).
+// The HMAC key is base64url encoded, like the value provided to the client.
+func WithExternalAccount(kid, hmacEncoded string) Option {
+	return func(s *Server) {
+		s.externalAccounts[kid] = hmacEncoded
+	}
+}
+
+// WithExternalAccountRequired requires an external account binding to create an account.
+func WithExternalAccountRequired() Option {
+	return func(s *Server) {
+		s.meta.ExternalAccountRequired = true
+	}
+}
+
+// WithProfiles sets the certificate profiles advertised by the directory.
+func WithProfiles(profiles map[string]string) Option {
+	return func(s *Server) {
+		s.meta.Profiles = profiles
+	}
+}
+
+// WithCertificateLifetime sets the lifetime of the issued certificates (default: 90 days).
+func WithCertificateLifetime(lifetime time.Duration) Option {
+	return func(s *Server) {
+		s.certificateLifetime = lifetime
+	}
+}
+
+// WithDNSAccount01 offers the dns-account-01 challenge along with the dns-01 challenge.
+func WithDNSAccount01() Option {
+	return func(s *Server) {
+		s.dnsAccount01 = true
+	}
+}
+
+// WithNewAuthz advertises the newAuthz endpoint (pre-authorization).
+func WithNewAuthz() Option {
+	return func(s *Server) {
+		s.newAuthz = true
+	}
+}
+
+// Server is an in-memory ACME server.
+type Server struct {
+	server *httptest.Server
+
+	resolver Resolver
+	httpPort int
+	tlsPort  int
+
+	dnsAccount01 bool
+	newAuthz     bool
+
+	meta             acme.Meta
+	externalAccounts map[string]string
+
+	certificateLifetime time.Duration
+
+	ca *certificateAuthority
+
+	mu             sync.Mutex
+	nonces         map[string]struct{}
+	accounts       map[string]*account
+	accountsByKey  map[string]*account
+	orders         map[string]*order
+	authorizations map[string]*authorization
+	challenges     map[string]*authzChallenge
+	certificates   map[string]*issuedCertificate
+}
+
+// NewServer starts an ACME server.
+// The server is closed at the end of the test.
+func NewServer(t testing.TB, opts ...Option) *Server {
+	t.Helper()
+
+	ca, err := newCertificateAuthority()
+	if err != nil {
+		t.Fatalf("acmetest: %v", err)
+	}
+
+	s := &Server{
+		resolver:            defaultResolver{},
+		httpPort:            defaultHTTPPort,
+		tlsPort:             defaultTLSPort,
+		externalAccounts:    make(map[string]string),
+		certificateLifetime: defaultCertificateLifetime,
+		ca:                  ca,
+		nonces:              make(map[string]struct{}),
+		accounts:            make(map[string]*account),
+		accountsByKey:       make(map[string]*account),
+		orders:              make(map[string]*order),
+		authorizations:      make(map[string]*authorization),
+		challenges:          make(map[string]*authzChallenge),
+		certificates:        make(map[string]*issuedCertificate),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.server = httptest.NewTLSServer(s.handler())
+	t.Cleanup(s.server.Close)
+
+	return s
+}
+
+// URL returns the URL of the directory.
+func (s *Server) URL() string {
+	return s.server.URL + pathDirectory
+}
+
+// Client returns an HTTP client that trusts the TLS certificate of the server.
+func (s *Server) Client() *http.Client {
+	return s.server.Client()
+}
+
+// Roots returns a pool containing the root certificate of the issued certificates.
+func (s *Server) Roots() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(s.ca.root)
+
+	return pool
+}
+
+// Intermediates returns a pool containing the intermediate certificate of the issued certificates.
+func (s *Server) Intermediates() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(s.ca.intermediate)
+
+	return pool
+}
+
+// IsRevoked returns true if the certificate has been revoked.
+func (s *Server) IsRevoked(cert *x509.Certificate) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	issued, ok := s.certificates[cert.SerialNumber.Text(16)]
+
+	return ok && issued.revoked
+}
+
+func (s *Server) handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET "+pathDirectory, s.handleDirectory)
+	mux.HandleFunc("HEAD "+pathNewNonce, s.handleNewNonce)
+	mux.HandleFunc("GET "+pathNewNonce, s.handleNewNonce)
+	mux.HandleFunc("POST "+pathNewAccount, s.handleNewAccount)
+	mux.HandleFunc("POST "+pathAccount+"{id}", s.handleAccount)
+	mux.HandleFunc("POST "+pathAccount+"{id}/orders", s.handleAccountOrders)
+	mux.HandleFunc("POST "+pathKeyChange, s.handleKeyChange)
+	mux.HandleFunc("POST "+pathNewOrder, s.handleNewOrder)
+	mux.HandleFunc("POST "+pathOrder+"{id}", s.handleOrder)
+	mux.HandleFunc("POST "+pathOrder+"{id}/finalize", s.handleFinalize)
+	mux.HandleFunc("POST "+pathNewAuthz, s.handleNewAuthz)
+	mux.HandleFunc("POST "+pathAuthorization+"{id}", s.handleAuthorization)
+	mux.HandleFunc("POST "+pathChallenge+"{id}", s.handleChallenge)
+	mux.HandleFunc("POST "+pathCertificate+"{id}", s.handleCertificate)
+	mux.HandleFunc("GET "+pathRenewalInfo+"/{id}", s.handleRenewalInfo)
+	mux.HandleFunc("POST "+pathRevokeCert, s.handleRevokeCert)
+
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Cache-Control", "no-store")
+		rw.Header().Set("Replay-Nonce", s.newNonce())
+		rw.Header().Add("Link", link(s.URL(), "index"))
+
+		mux.ServeHTTP(rw, req)
+	})
+}
+
+func (s *Server) handleDirectory(rw http.ResponseWriter, _ *http.Request) {
+	directory := acme.Directory{
+		NewNonceURL:   s.server.URL + pathNewNonce,
+		NewAccountURL: s.server.URL + pathNewAccount,
+		NewOrderURL:   s.server.URL + pathNewOrder,
+		RevokeCertURL: s.server.URL + pathRevokeCert,
+		KeyChangeURL:  s.server.URL + pathKeyChange,
+		RenewalInfo:   s.server.URL + pathRenewalInfo,
+		Meta:          s.meta,
+	}
+
+	if s.newAuthz {
+		directory.NewAuthzURL = s.server.URL + pathNewAuthz
+	}
+
+	writeJSON(rw, http.StatusOK, directory)
+}
+
+func (s *Server) handleNewNonce(rw http.ResponseWriter, req *http.Request) {
+	if req.Method == http.MethodGet {
+		rw.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	rw.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) newNonce() string {
+	nonce := randomString()
+
+	s.mu.Lock()
+	s.nonces[nonce] = struct{}{}
+	s.mu.Unlock()
+
+	return nonce
+}
+
+// useNonce consumes a nonce, it returns false if the nonce is unknown or already used.
+func (s *Server) useNonce(nonce string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.nonces[nonce]
+	delete(s.nonces, nonce)
+
+	return ok
+}
+
+func (s *Server) url(path, id string) string {
+	return s.server.URL + path + id
+}
+
+func writeJSON(rw http.ResponseWriter, status int, body any) {
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(status)
+
+	_ = json.NewEncoder(rw).Encode(body)
+}
+
+func writeProblem(rw http.ResponseWriter, problem *acme.ProblemDetails) {
+	rw.Header().Set("Content-Type", "application/problem+json")
+	rw.WriteHeader(problem.HTTPStatus)
+
+	_ = json.NewEncoder(rw).Encode(problem)
+}
+
+func newProblem(status int, errorType, format string, args ...any) *acme.ProblemDetails {
+	return &acme.ProblemDetails{
+		Type:       errorType,
+		Detail:     fmt.Sprintf(format, args...),
+		HTTPStatus: status,
+	}
+}
+
+func link(uri, rel string) string {
+	return fmt.Sprintf("<%s>;rel=%q", uri, rel)
+}
+
+func randomString() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,703 @@
+package acmetest
+
+import (
+	"context"
+	"crypto"
+	"crypto/x509"
+	"net"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v5/acme"
+	"github.com/go-acme/lego/v5/certcrypto"
+	"github.com/go-acme/lego/v5/certificate"
+	"github.com/go-acme/lego/v5/challenge/dns01"
+	"github.com/go-acme/lego/v5/challenge/http01"
+	"github.com/go-acme/lego/v5/challenge/tlsalpn01"
+	"github.com/go-acme/lego/v5/event"
+	"github.com/go-acme/lego/v5/lego"
+	"github.com/go-acme/lego/v5/metrics"
+	"github.com/go-acme/lego/v5/registration"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_http01(t *testing.T) {
+	port := freePort(t)
+
+	resolver := NewStaticResolver()
+	resolver.SetHost("example.com", "127.0.0.1")
+
+	server := NewServer(t, WithResolver(resolver), WithHTTPPort(port))
+
+	client := newClient(t, server)
+
+	err := client.Challenge.SetHTTP01Provider(http01.NewProviderServer("127.0.0.1", strconv.Itoa(port)))
+	require.NoError(t, err)
+
+	resource, err := client.Certificate.Obtain(t.Context(), certificate.ObtainRequest{
+		Domains: []string{"example.com"},
+		KeyType: certcrypto.EC256,
+		Bundle:  true,
+	})
+	require.NoError(t, err)
+
+	cert := verifyCertificate(t, server, resource, "example.com")
+
+	renewalInfo, err := client.Certificate.GetRenewalInfo(t.Context(), cert)
+	require.NoError(t, err)
+
+	assert.True(t, renewalInfo.SuggestedWindow.Start.After(time.Now()))
+	assert.True(t, renewalInfo.SuggestedWindow.End.Before(cert.NotAfter))
+
+	err = client.Certificate.Revoke(t.Context(), resource.Certificate)
+	require.NoError(t, err)
+
+	assert.True(t, server.IsRevoked(cert))
+
+	err = client.Certificate.Revoke(t.Context(), resource.Certificate)
+	require.ErrorContains(t, err, acme.AlreadyRevokedErrorType)
+
+	renewalInfo, err = client.Certificate.GetRenewalInfo(t.Context(), cert)
+	require.NoError(t, err)
+
+	assert.False(t, renewalInfo.SuggestedWindow.End.After(time.Now()))
+}
+
+func TestServer_dns01(t *testing.T) {
+	t.Setenv("LEGO_DISABLE_CNAME_SUPPORT", "true")
+
+	resolver := NewStaticResolver()
+
+	server := NewServer(t, WithResolver(resolver))
+
+	client := newClient(t, server)
+
+	err := client.Challenge.SetDNS01Provider(&dnsProvider{resolver: resolver},
+		dns01.WrapPreCheck(func(_ context.Context, _, _, _ string, _ dns01.PreCheckFunc) (bool, error) {
+			return true, nil
+		}),
+	)
+	require.NoError(t, err)
+
+	resource, err := client.Certificate.Obtain(t.Context(), certificate.ObtainRequest{
+		Domains: []string{"example.com", "*.example.com"},
+		KeyType: certcrypto.EC256,
+		Bundle:  true,
+	})
+	require.NoError(t, err)
+
+	verifyCertificate(t, server, resource, "example.com", "*.example.com")
+}
+
+func TestServer_dns01_sharedRecord(t *testing.T) {
+	t.Setenv("LEGO_DISABLE_CNAME_SUPPORT", "true")
+
+	recorder := &propagationRecorder{}
+
+	metrics.SetDefault(recorder)
+	t.Cleanup(func() { metrics.SetDefault(nil) })
+
+	resolver := NewStaticResolver()
+
+	server := NewServer(t, WithResolver(resolver))
+
+	privateKey, err := certcrypto.GeneratePrivateKey(certcrypto.EC256)
+	require.NoError(t, err)
+
+	user := &testUser{email: "test@example.com", privateKey: privateKey}
+
+	config := lego.NewConfig(user)
+	config.CADirURL = server.URL()
+	config.HTTPClient = server.Client()
+	config.Certificate.AuthorizationCache = certificate.NewAuthorizationCache()
+
+	client, err := lego.NewClient(config)
+	require.NoError(t, err)
+
+	user.registration, err = client.Registration.Register(t.Context(), registration.RegisterOptions{TermsOfServiceAgreed: true})
+	require.NoError(t, err)
+
+	err = client.Challenge.SetDNS01Provider(&dnsProvider{resolver: resolver},
+		dns01.WrapPreCheck(func(_ context.Context, _, _, _ string, _ dns01.PreCheckFunc) (bool, error) {
+			return true, nil
+		}),
+	)
+	require.NoError(t, err)
+
+	// The apex domain and the wildcard domain share the record `_acme-challenge.example.com.`.
+	for _, domain := range []string{"example.com", "*.example.com"} {
+		resource, errO := client.Certificate.Obtain(t.Context(), certificate.ObtainRequest{
+			Domains: []string{domain},
+			KeyType: certcrypto.EC256,
+			Bundle:  true,
+		})
+		require.NoError(t, errO)
+
+		verifyCertificate(t, server, resource, domain)
+	}
+
+	// The record has propagated for the first certificate: the value of the second certificate is checked without waiting.
+	assert.Equal(t, int32(1), recorder.propagations.Load())
+}
+
+func TestServer_dnsaccount01(t *testing.T) {
+	t.Setenv("LEGO_DISABLE_CNAME_SUPPORT", "true")
+
+	resolver := NewStaticResolver()
+
+	server := NewServer(t, WithResolver(resolver), WithDNSAccount01())
+
+	// Two accounts validating the same domain.
+	for range 2 {
+		client := newClient(t, server)
+
+		err := client.Challenge.SetDNSAccount01Provider(&dnsProvider{resolver: resolver},
+			dns01.WrapPreCheck(func(_ context.Context, _, _, _ string, _ dns01.PreCheckFunc) (bool, error) {
+				return true, nil
+			}),
+		)
+		require.NoError(t, err)
+
+		resource, err := client.Certificate.Obtain(t.Context(), certificate.ObtainRequest{
+			Domains: []string{"example.com", "*.example.com"},
+			KeyType: certcrypto.EC256,
+			Bundle:  true,
+		})
+		require.NoError(t, err)
+
+		verifyCertificate(t, server, resource, "example.com", "*.example.com")
+	}
+}
+
+func TestServer_dns01_invalid(t *testing.T) {
+	t.Setenv("LEGO_DISABLE_CNAME_SUPPORT", "true")
+
+	server := NewServer(t, WithResolver(NewStaticResolver()))
+
+	client := newClient(t, server)
+
+	// The provider doesn't create the records.
+	err := client.Challenge.SetDNS01Provider(&dnsProvider{resolver: NewStaticResolver()},
+		dns01.WrapPreCheck(func(_ context.Context, _, _, _ string, _ dns01.PreCheckFunc) (bool, error) {
+			return true, nil
+		}),
+	)
+	require.NoError(t, err)
+
+	_, err = client.Certificate.Obtain(t.Context(), certificate.ObtainRequest{
+		Domains: []string{"example.com"},
+		KeyType: certcrypto.EC256,
+	})
+	require.ErrorContains(t, err, acme.DNSErrorType)
+}
+
+func TestServer_challengeFallback(t *testing.T) {
+	t.Setenv("LEGO_DISABLE_CNAME_SUPPORT", "true")
+
+	port := freePort(t)
+
+	// No address for the host: the http-01 validation fails.
+	resolver := NewStaticResolver()
+
+	server := NewServer(t, WithResolver(resolver), WithHTTPPort(port))
+
+	client := newClient(t, server)
+
+	err := client.Challenge.SetHTTP01Provider(http01.NewProviderServer("127.0.0.1", strconv.Itoa(port)))
+	require.NoError(t, err)
+
+	err = client.Challenge.SetDNS01Provider(&dnsProvider{resolver: resolver},
+		dns01.WrapPreCheck(func(_ context.Context, _, _, _ string, _ dns01.PreCheckFunc) (bool, error) {
+			return true, nil
+		}),
+	)
+	require.NoError(t, err)
+
+	_, err = client.Certificate.Obtain(t.Context(), certificate.ObtainRequest{
+		Domains: []string{"example.com"},
+		KeyType: certcrypto.EC256,
+	})
+	require.ErrorContains(t, err, acme.DNSErrorType)
+
+	resource, err := client.Certificate.Obtain(t.Context(), certificate.ObtainRequest{
+		Domains:           []string{"example.com"},
+		KeyType:           certcrypto.EC256,
+		Bundle:            true,
+		ChallengeFallback: true,
+	})
+	require.NoError(t, err)
+
+	verifyCertificate(t, server, resource, "example.com")
+}
+
+func TestServer_challengeFallback_noOtherSolver(t *testing.T) {
+	t.Setenv("LEGO_DISABLE_CNAME_SUPPORT", "true")
+
+	port := freePort(t)
+
+	// No address for the host: the http-01 validation fails.
+	resolver := NewStaticResolver()
+
+	server := NewServer(t, WithResolver(resolver), WithHTTPPort(port))
+
+	client := newClient(t, server)
+
+	err := client.Challenge.SetHTTP01Provider(http01.NewProviderServer("127.0.0.1", strconv.Itoa(port)))
+	require.NoError(t, err)
+
+	// The validation error is returned: no new order without another solver.
+	_, err = client.Certificate.Obtain(t.Context(), certificate.ObtainRequest{
+		Domains:           []string{"example.com"},
+		KeyType:           certcrypto.EC256,
+		ChallengeFallback: true,
+	})
+	require.ErrorContains(t, err, acme.DNSErrorType)
+	require.NotContains(t, err.Error(), "could not determine solvers")
+}
+
+func TestServer_minAuthorizationValidity(t *testing.T) {
+	port := freePort(t)
+
+	resolver := NewStaticResolver()
+	resolver.SetHost("example.com", "127.0.0.1")
+
+	server := NewServer(t, WithResolver(resolver), WithHTTPPort(port))
+
+	client := newClient(t, server)
+
+	var events []event.Type
+
+	client.Subscribe(event.SubscriberFunc(func(_ context.Context, evt event.Event) {
+		events = append(events, evt.Type)
+	}))
+
+	err := client.Challenge.SetHTTP01Provider(http01.NewProviderServer("127.0.0.1", strconv.Itoa(port)))
+	require.NoError(t, err)
+
+	request := certificate.ObtainRequest{
+		Domains: []string{"example.com"},
+		KeyType: certcrypto.EC256,
+		Bundle:  true,
+	}
+
+	_, err = client.Certificate.Obtain(t.Context(), request)
+	require.NoError(t, err)
+
+	// The authorization is valid for 7 days.
+	request.MinAuthorizationValidity = 8 * 24 * time.Hour
+
+	events = nil
+
+	resource, err := client.Certificate.Obtain(t.Context(), request)
+	require.NoError(t, err)
+
+	verifyCertificate(t, server, resource, "example.com")
+
+	expected := []event.Type{
+		event.OrderCreated,
+		event.AuthorizationReused,
+		event.OrderCreated,
+		event.AuthorizationPending,
+		event.ChallengePresented,
+		event.ValidationSucceeded,
+		event.OrderFinalized,
+		event.CertificateDownloaded,
+	}
+
+	assert.Equal(t, expected, events)
+}
+
+func TestServer_authorizations(t *testing.T) {
+	port := freePort(t)
+
+	resolver := NewStaticResolver()
+	resolver.SetHost("example.com", "127.0.0.1")
+
+	server := NewServer(t, WithResolver(resolver), WithHTTPPort(port))
+
+	client := newClient(t, server)
+
+	err := client.Challenge.SetHTTP01Provider(http01.NewProviderServer("127.0.0.1", strconv.Itoa(port)))
+	require.NoError(t, err)
+
+	certRes, err := client.Certificate.Obtain(t.Context(), certificate.ObtainRequest{
+		Domains: []string{"example.com"},
+		KeyType: certcrypto.EC256,
+	})
+	require.NoError(t, err)
+
+	require.NotEmpty(t, certRes.OrderURL)
+
+	authorizations, err := client.Certificate.ListAuthorizations(t.Context(), certRes.OrderURL)
+	require.NoError(t, err)
+
+	require.Len(t, authorizations, 1)
+
+	assert.Equal(t, "example.com", authorizations[0].Identifier.Value)
+	assert.Equal(t, acme.StatusValid, authorizations[0].Status)
+	assert.NotEmpty(t, authorizations[0].URL)
+
+	// The listing doesn't modify the authorizations.
+	authorizations, err = client.Certificate.ListAuthorizations(t.Context(), certRes.OrderURL)
+	require.NoError(t, err)
+
+	require.Len(t, authorizations, 1)
+
+	assert.Equal(t, acme.StatusValid, authorizations[0].Status)
+
+	err = client.Certificate.DeactivateAuthorization(t.Context(), authorizations[0].URL)
+	require.NoError(t, err)
+
+	authz, err := client.Certificate.GetAuthorization(t.Context(), authorizations[0].URL)
+	require.NoError(t, err)
+
+	assert.Equal(t, acme.StatusDeactivated, authz.Status)
+
+	authorizations, err = client.Certificate.ListAuthorizations(t.Context(), certRes.OrderURL)
+	require.NoError(t, err)
+
+	require.Len(t, authorizations, 1)
+
+	assert.Equal(t, authz.URL, authorizations[0].URL)
+	assert.Equal(t, acme.StatusDeactivated, authorizations[0].Status)
+}
+
+func TestServer_preAuthorize(t *testing.T) {
+	testCases := []struct {
+		desc string
+		opts []Option
+	}{
+		{
+			desc: "newAuthz",
+			opts: []Option{WithNewAuthz()},
+		},
+		{
+			desc: "fallback to an order",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			port := freePort(t)
+
+			resolver := NewStaticResolver()
+			resolver.SetHost("example.com", "127.0.0.1")
+
+			server := NewServer(t, append(test.opts, WithResolver(resolver), WithHTTPPort(port))...)
+
+			client := newClient(t, server)
+
+			err := client.Challenge.SetHTTP01Provider(http01.NewProviderServer("127.0.0.1", strconv.Itoa(port)))
+			require.NoError(t, err)
+
+			authorizations, err := client.Certificate.PreAuthorize(t.Context(), []string{"example.com"})
+			require.NoError(t, err)
+
+			require.Len(t, authorizations, 1)
+
+			assert.Equal(t, "example.com", authorizations[0].Identifier.Value)
+			assert.Equal(t, acme.StatusValid, authorizations[0].Status)
+			assert.NotEmpty(t, authorizations[0].URL)
+			assert.False(t, authorizations[0].Expires.IsZero())
+
+			var events []event.Type
+
+			client.Subscribe(event.SubscriberFunc(func(_ context.Context, evt event.Event) {
+				events = append(events, evt.Type)
+			}))
+
+			resource, err := client.Certificate.Obtain(t.Context(), certificate.ObtainRequest{
+				Domains: []string{"example.com"},
+				KeyType: certcrypto.EC256,
+				Bundle:  true,
+			})
+			require.NoError(t, err)
+
+			verifyCertificate(t, server, resource, "example.com")
+
+			// The pre-authorization is reused: no challenge is solved.
+			assert.Contains(t, events, event.AuthorizationReused)
+			assert.NotContains(t, events, event.ChallengePresented)
+		})
+	}
+}
+
+func TestServer_tlsalpn01(t *testing.T) {
+	port := freePort(t)
+
+	resolver := NewStaticResolver()
+	resolver.SetHost("example.org", "127.0.0.1")
+
+	server := NewServer(t, WithResolver(resolver), WithTLSPort(port))
+
+	client := newClient(t, server)
+
+	err := client.Challenge.SetTLSALPN01Provider(tlsalpn01.NewProviderServer("127.0.0.1", strconv.Itoa(port)))
+	require.NoError(t, err)
+
+	resource, err := client.Certificate.Obtain(t.Context(), certificate.ObtainRequest{
+		Domains: []string{"example.org"},
+		KeyType: certcrypto.EC256,
+		Bundle:  true,
+	})
+	require.NoError(t, err)
+
+	verifyCertificate(t, server, resource, "example.org")
+}
+
+func TestServer_events(t *testing.T) {
+	port := freePort(t)
+
+	resolver := NewStaticResolver()
+	resolver.SetHost("example.com", "127.0.0.1")
+
+	server := NewServer(t, WithResolver(resolver), WithHTTPPort(port))
+
+	client := newClient(t, server)
+
+	var events []event.Event
+
+	client.Subscribe(event.SubscriberFunc(func(_ context.Context, evt event.Event) {
+		events = append(events, evt)
+	}))
+
+	err := client.Challenge.SetHTTP01Provider(http01.NewProviderServer("127.0.0.1", strconv.Itoa(port)))
+	require.NoError(t, err)
+
+	resource, err := client.Certificate.Obtain(t.Context(), certificate.ObtainRequest{
+		Domains: []string{"example.com"},
+		KeyType: certcrypto.EC256,
+		Bundle:  true,
+	})
+	require.NoError(t, err)
+
+	// The authorization is already valid: no challenge.
+	renewed, err := client.Certificate.Renew(t.Context(), *resource, &certificate.RenewOptions{Bundle: true})
+	require.NoError(t, err)
+
+	// The renewal through Obtain (ex: the CLI).
+	reissued, err := client.Certificate.Obtain(t.Context(), certificate.ObtainRequest{
+		Domains: []string{"example.com"},
+		KeyType: certcrypto.EC256,
+		Bundle:  true,
+		Renewal: true,
+	})
+	require.NoError(t, err)
+
+	err = client.Certificate.Revoke(t.Context(), renewed.Certificate)
+	require.NoError(t, err)
+
+	expected := []event.Type{
+		event.OrderCreated,
+		event.AuthorizationPending,
+		event.ChallengePresented,
+		event.ValidationSucceeded,
+		event.OrderFinalized,
+		event.CertificateDownloaded,
+		event.OrderCreated,
+		event.AuthorizationReused,
+		event.OrderFinalized,
+		event.CertificateDownloaded,
+		event.CertificateRenewed,
+		event.OrderCreated,
+		event.AuthorizationReused,
+		event.OrderFinalized,
+		event.CertificateDownloaded,
+		event.CertificateRenewed,
+		event.CertificateRevoked,
+	}
+
+	var types []event.Type
+	for _, evt := range events {
+		types = append(types, evt.Type)
+
+		assert.False(t, evt.Time.IsZero())
+	}
+
+	require.Equal(t, expected, types)
+
+	assert.Equal(t, "example.com", events[2].Domain)
+	assert.Equal(t, "http-01", events[2].ChallengeType)
+	assert.Equal(t, renewed.CertURL, events[9].CertificateURL)
+	assert.Equal(t, []string{"example.com"}, events[10].Domains)
+	assert.Equal(t, reissued.CertURL, events[15].CertificateURL)
+}
+
+func TestServer_externalAccountBinding(t *testing.T) {
+	const (
+		kid         = "kid-1"
+		hmacEncoded = "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY"
+	)
+
+	server := NewServer(t, WithExternalAccount(kid, hmacEncoded), WithExternalAccountRequired())
+
+	client, user := newUnregisteredClient(t, server)
+
+	_, err := client.Registration.Register(t.Context(), registration.RegisterOptions{TermsOfServiceAgreed: true})
+	require.ErrorContains(t, err, acme.ExternalAccountRequiredErrorType)
+
+	_, err = client.Registration.RegisterWithExternalAccountBinding(t.Context(), registration.RegisterEABOptions{
+		TermsOfServiceAgreed: true,
+		Kid:                  kid,
+		HmacEncoded:          "ZmVkY2JhOTg3NjU0MzIxMGZlZGNiYTk4NzY1NDMyMTA",
+	})
+	require.ErrorContains(t, err, acme.UnauthorizedErrorType)
+
+	reg, err := client.Registration.RegisterWithExternalAccountBinding(t.Context(), registration.RegisterEABOptions{
+		TermsOfServiceAgreed: true,
+		Kid:                  kid,
+		HmacEncoded:          hmacEncoded,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, acme.StatusValid, reg.Status)
+
+	sameKeyClient, _ := newUserClient(t, server, user.privateKey)
+
+	existing, err := sameKeyClient.Registration.ResolveAccountByKey(t.Context())
+	require.NoError(t, err)
+
+	assert.Equal(t, reg.Location, existing.Location)
+}
+
+func TestServer_keyRollover(t *testing.T) {
+	server := NewServer(t)
+
+	client, user := newUnregisteredClient(t, server)
+
+	reg, err := client.Registration.Register(t.Context(), registration.RegisterOptions{TermsOfServiceAgreed: true})
+	require.NoError(t, err)
+
+	user.registration = reg
+
+	newKey, err := certcrypto.GeneratePrivateKey(certcrypto.EC256)
+	require.NoError(t, err)
+
+	err = client.Registration.KeyRollover(t.Context(), newKey)
+	require.NoError(t, err)
+
+	// The old key is not associated with an account anymore.
+	oldKeyClient, _ := newUserClient(t, server, user.privateKey)
+
+	_, err = oldKeyClient.Registration.ResolveAccountByKey(t.Context())
+	require.ErrorContains(t, err, acme.AccountDoesNotExistErrorType)
+}
+
+func verifyCertificate(t *testing.T, server *Server, resource *certificate.Resource, domains ...string) *x509.Certificate {
+	t.Helper()
+
+	certs, err := certcrypto.ParsePEMBundle(resource.Certificate)
+	require.NoError(t, err)
+	require.Len(t, certs, 2)
+
+	assert.ElementsMatch(t, domains, certs[0].DNSNames)
+
+	_, err = certs[0].Verify(x509.VerifyOptions{
+		Roots:         server.Roots(),
+		Intermediates: server.Intermediates(),
+	})
+	require.NoError(t, err)
+
+	return certs[0]
+}
+
+func newClient(t *testing.T, server *Server) *lego.Client {
+	t.Helper()
+
+	client, user := newUnregisteredClient(t, server)
+
+	reg, err := client.Registration.Register(t.Context(), registration.RegisterOptions{TermsOfServiceAgreed: true})
+	require.NoError(t, err)
+
+	user.registration = reg
+
+	return client
+}
+
+func newUnregisteredClient(t *testing.T, server *Server) (*lego.Client, *testUser) {
+	t.Helper()
+
+	privateKey, err := certcrypto.GeneratePrivateKey(certcrypto.EC256)
+	require.NoError(t, err)
+
+	return newUserClient(t, server, privateKey)
+}
+
+func newUserClient(t *testing.T, server *Server, privateKey crypto.Signer) (*lego.Client, *testUser) {
+	t.Helper()
+
+	user := &testUser{email: "test@example.com", privateKey: privateKey}
+
+	config := lego.NewConfig(user)
+	config.CADirURL = server.URL()
+	config.HTTPClient = server.Client()
+
+	client, err := lego.NewClient(config)
+	require.NoError(t, err)
+
+	return client, user
+}
+
+func freePort(t *testing.T) int {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	require.NoError(t, listener.Close())
+
+	return port
+}
+
+type testUser struct {
+	email        string
+	registration *acme.ExtendedAccount
+	privateKey   crypto.Signer
+}
+
+func (u *testUser) GetEmail() string                       { return u.email }
+func (u *testUser) GetRegistration() *acme.ExtendedAccount { return u.registration }
+func (u *testUser) GetPrivateKey() crypto.Signer           { return u.privateKey }
+
+// propagationRecorder counts the DNS propagation waits.
+type propagationRecorder struct {
+	metrics.Noop
+
+	propagations atomic.Int32
+}
+
+func (r *propagationRecorder) ObservePropagation(_ time.Duration, _ error) {
+	r.propagations.Add(1)
+}
+
+// dnsProvider creates the dns-01 records in a StaticResolver.
+type dnsProvider struct {
+	resolver *StaticResolver
+}
+
+func (p *dnsProvider) Present(ctx context.Context, domain, _, keyAuth string) error {
+	info := dns01.GetChallengeInfo(ctx, domain, keyAuth)
+
+	p.resolver.AddTXT(info.EffectiveFQDN, info.Value)
+
+	return nil
+}
+
+func (p *dnsProvider) CleanUp(ctx context.Context, domain, _, keyAuth string) error {
+	info := dns01.GetChallengeInfo(ctx, domain, keyAuth)
+
+	p.resolver.DeleteTXT(info.EffectiveFQDN, info.Value)
+
+	return nil
+}
+
+func (p *dnsProvider) Timeout() (timeout, interval time.Duration) {
+	return time.Second, 10 * time.Millisecond
+}
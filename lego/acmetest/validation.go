@@ -0,0 +1,247 @@
+package acmetest
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/asn1"
+	"encoding/base32"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-acme/lego/v5/acme"
+	"github.com/go-acme/lego/v5/challenge"
+	"github.com/go-acme/lego/v5/challenge/http01"
+	"github.com/go-acme/lego/v5/challenge/tlsalpn01"
+	"github.com/miekg/dns"
+)
+
+// validationTimeout is the timeout of a validation request.
+const validationTimeout = 10 * time.Second
+
+// idPeAcmeIdentifierV1 is the OID of the acmeIdentifier extension.
+// https://www.rfc-editor.org/rfc/rfc8737.html#section-3
+var idPeAcmeIdentifierV1 = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 1, 31}
+
+// validate validates a challenge.
+func (s *Server) validate(ctx context.Context, typ string, identifier acme.Identifier, accountURL, token, keyAuth string) *acme.ProblemDetails {
+	ctx, cancel := context.WithTimeout(ctx, validationTimeout)
+	defer cancel()
+
+	switch challenge.Type(typ) {
+	case challenge.HTTP01:
+		return s.validateHTTP01(ctx, identifier, token, keyAuth)
+
+	case challenge.DNS01:
+		return s.validateDNS01(ctx, "_acme-challenge."+identifier.Value, keyAuth)
+
+	case challenge.DNSAccount01:
+		return s.validateDNS01(ctx, accountLabel(accountURL)+"._acme-challenge."+identifier.Value, keyAuth)
+
+	case challenge.TLSALPN01:
+		return s.validateTLSALPN01(ctx, identifier, keyAuth)
+
+	default:
+		return newProblem(http.StatusBadRequest, acme.MalformedErrorType, "unsupported challenge type: %s", typ)
+	}
+}
+
+// validateHTTP01 fetches the key authorization from the HTTP server of the identifier.
+// The redirects are not followed.
+// https://www.rfc-editor.org/rfc/rfc8555.html#section-8.3
+func (s *Server) validateHTTP01(ctx context.Context, identifier acme.Identifier, token, keyAuth string) *acme.ProblemDetails {
+	addresses, problem := s.lookupAddresses(ctx, identifier)
+	if problem != nil {
+		return problem
+	}
+
+	client := &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	var errs []error
+
+	for _, address := range addresses {
+		endpoint := fmt.Sprintf("http://%s%s", net.JoinHostPort(address, strconv.Itoa(s.httpPort)), http01.ChallengePath(token))
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, http.NoBody)
+		if err != nil {
+			return newProblem(http.StatusInternalServerError, acme.ServerInternalErrorType, "creating the request: %v", err)
+		}
+
+		// The default port is omitted from the Host header.
+		req.Host = strings.TrimSuffix(net.JoinHostPort(identifier.Value, strconv.Itoa(s.httpPort)), ":"+strconv.Itoa(defaultHTTPPort))
+
+		body, err := fetch(client, req)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		if strings.TrimSpace(string(body)) != keyAuth {
+			return newProblem(http.StatusForbidden, acme.IncorrectResponseErrorType,
+				"the key authorization from %s doesn't match: %q", endpoint, body)
+		}
+
+		return nil
+	}
+
+	return newProblem(http.StatusBadRequest, acme.ConnectionErrorType, "http-01 validation of %s: %v", identifier.Value, errors.Join(errs...))
+}
+
+func fetch(client *http.Client, req *http.Request) ([]byte, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: unexpected status code %d", req.URL, resp.StatusCode)
+	}
+
+	return io.ReadAll(io.LimitReader(resp.Body, 4096))
+}
+
+// validateDNS01 looks up the TXT records of the validation domain name.
+// It's also used by the dns-account-01 challenge, only the validation domain name is different.
+// https://www.rfc-editor.org/rfc/rfc8555.html#section-8.4
+func (s *Server) validateDNS01(ctx context.Context, name, keyAuth string) *acme.ProblemDetails {
+	records, err := s.resolver.LookupTXT(ctx, name)
+	if err != nil {
+		return newProblem(http.StatusBadRequest, acme.DNSErrorType, "looking up the TXT records of %s: %v", name, err)
+	}
+
+	digest := sha256.Sum256([]byte(keyAuth))
+	expected := base64.RawURLEncoding.EncodeToString(digest[:])
+
+	if !slices.Contains(records, expected) {
+		return newProblem(http.StatusForbidden, acme.IncorrectResponseErrorType, "no TXT record of %s matches the key authorization", name)
+	}
+
+	return nil
+}
+
+// accountLabel returns the account label of the dns-account-01 validation domain name.
+// https://datatracker.ietf.org/doc/draft-ietf-acme-dns-account-label/
+func accountLabel(accountURL string) string {
+	digest := sha256.Sum256([]byte(accountURL))
+
+	return "_" + strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(digest[:10]))
+}
+
+// validateTLSALPN01 checks the certificate provided by the TLS server of the identifier with the acme-tls/1 protocol.
+// https://www.rfc-editor.org/rfc/rfc8737.html#section-3
+func (s *Server) validateTLSALPN01(ctx context.Context, identifier acme.Identifier, keyAuth string) *acme.ProblemDetails {
+	addresses, problem := s.lookupAddresses(ctx, identifier)
+	if problem != nil {
+		return problem
+	}
+
+	serverName := identifier.Value
+	if identifier.Type == "ip" {
+		// https://www.rfc-editor.org/rfc/rfc8738.html#section-6
+		reverse, err := dns.ReverseAddr(identifier.Value)
+		if err != nil {
+			return newProblem(http.StatusBadRequest, acme.MalformedErrorType, "invalid IP identifier: %v", err)
+		}
+
+		serverName = strings.TrimSuffix(reverse, ".")
+	}
+
+	digest := sha256.Sum256([]byte(keyAuth))
+
+	expected, err := asn1.Marshal(digest[:])
+	if err != nil {
+		return newProblem(http.StatusInternalServerError, acme.ServerInternalErrorType, "encoding the key authorization: %v", err)
+	}
+
+	var errs []error
+
+	for _, address := range addresses {
+		dialer := &tls.Dialer{
+			Config: &tls.Config{
+				ServerName: serverName,
+				NextProtos: []string{tlsalpn01.ACMETLS1Protocol},
+				// The certificate is self-signed: it's verified below.
+				InsecureSkipVerify: true,
+			},
+		}
+
+		conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(address, strconv.Itoa(s.tlsPort)))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		state := conn.(*tls.Conn).ConnectionState()
+
+		_ = conn.Close()
+
+		return checkTLSALPN01(state, identifier, expected)
+	}
+
+	return newProblem(http.StatusBadRequest, acme.ConnectionErrorType, "tls-alpn-01 validation of %s: %v", identifier.Value, errors.Join(errs...))
+}
+
+func checkTLSALPN01(state tls.ConnectionState, identifier acme.Identifier, expected []byte) *acme.ProblemDetails {
+	if state.NegotiatedProtocol != tlsalpn01.ACMETLS1Protocol {
+		return newProblem(http.StatusForbidden, acme.TLSErrorType, "the server didn't negotiate the %s protocol", tlsalpn01.ACMETLS1Protocol)
+	}
+
+	if len(state.PeerCertificates) == 0 {
+		return newProblem(http.StatusForbidden, acme.TLSErrorType, "the server didn't provide a certificate")
+	}
+
+	cert := state.PeerCertificates[0]
+
+	matches := slices.Contains(cert.DNSNames, identifier.Value) ||
+		slices.ContainsFunc(cert.IPAddresses, func(ip net.IP) bool { return ip.Equal(net.ParseIP(identifier.Value)) })
+	if !matches {
+		return newProblem(http.StatusForbidden, acme.IncorrectResponseErrorType, "the certificate doesn't contain the identifier %s", identifier.Value)
+	}
+
+	for _, ext := range cert.Extensions {
+		if !ext.Id.Equal(idPeAcmeIdentifierV1) {
+			continue
+		}
+
+		if !ext.Critical || !bytes.Equal(ext.Value, expected) {
+			return newProblem(http.StatusForbidden, acme.IncorrectResponseErrorType, "the acmeIdentifier extension doesn't match the key authorization")
+		}
+
+		return nil
+	}
+
+	return newProblem(http.StatusForbidden, acme.IncorrectResponseErrorType, "the certificate doesn't contain the acmeIdentifier extension")
+}
+
+// lookupAddresses returns the addresses of an identifier.
+func (s *Server) lookupAddresses(ctx context.Context, identifier acme.Identifier) ([]string, *acme.ProblemDetails) {
+	if identifier.Type == "ip" {
+		return []string{identifier.Value}, nil
+	}
+
+	addresses, err := s.resolver.LookupHost(ctx, identifier.Value)
+	if err != nil {
+		return nil, newProblem(http.StatusBadRequest, acme.DNSErrorType, "looking up the addresses of %s: %v", identifier.Value, err)
+	}
+
+	if len(addresses) == 0 {
+		return nil, newProblem(http.StatusBadRequest, acme.DNSErrorType, "no address found for %s", identifier.Value)
+	}
+
+	return addresses, nil
+}
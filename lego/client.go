@@ -8,6 +8,7 @@ import (
 	"github.com/go-acme/lego/v5/acme/api"
 	"github.com/go-acme/lego/v5/certificate"
 	"github.com/go-acme/lego/v5/challenge/resolver"
+	"github.com/go-acme/lego/v5/event"
 	"github.com/go-acme/lego/v5/registration"
 )
 
@@ -17,6 +18,7 @@ type Client struct {
 	Challenge    *resolver.SolverManager
 	Registration *registration.Registrar
 	core         *api.Core
+	events       *event.Bus
 }
 
 // NewClient creates a new ACME client on behalf of the user.
@@ -51,13 +53,22 @@ func NewClient(config *Config) (*Client, error) {
 		return nil, err
 	}
 
+	events := config.Events
+	if events == nil {
+		events = event.NewBus()
+	}
+
 	solversManager := resolver.NewSolversManager(core)
+	solversManager.SetEvents(events)
 
 	prober := resolver.NewProber(solversManager)
 
 	options := certificate.CertifierOptions{
 		Timeout:             config.Certificate.Timeout,
 		OverallRequestLimit: config.Certificate.OverallRequestLimit,
+		RequestLimiter:      config.Certificate.RequestLimiter,
+		AuthorizationCache:  config.Certificate.AuthorizationCache,
+		Events:              events,
 	}
 
 	certifier := certificate.NewCertifier(core, prober, options)
@@ -67,9 +78,16 @@ func NewClient(config *Config) (*Client, error) {
 		Challenge:    solversManager,
 		Registration: registration.NewRegistrar(core, config.User),
 		core:         core,
+		events:       events,
 	}, nil
 }
 
+// Subscribe registers a subscriber to the issuance events.
+// The returned function removes the subscriber.
+func (c *Client) Subscribe(subscriber event.Subscriber) (unsubscribe func()) {
+	return c.events.Subscribe(subscriber)
+}
+
 // GetServerMetadata returns the current server metadata from the Directory.
 func (c *Client) GetServerMetadata() acme.Meta {
 	return c.core.GetDirectory().Meta
@@ -12,7 +12,9 @@ import (
 	"time"
 
 	"github.com/go-acme/lego/v5/certificate"
+	"github.com/go-acme/lego/v5/event"
 	"github.com/go-acme/lego/v5/registration"
+	"golang.org/x/time/rate"
 )
 
 const (
@@ -40,6 +42,10 @@ type Config struct {
 	UserAgent   string
 	HTTPClient  *http.Client
 	Certificate CertificateConfig
+
+	// Events is the bus used to publish the issuance events.
+	// If nil, the client creates its own bus.
+	Events *event.Bus
 }
 
 func NewConfig(user registration.User) *Config {
@@ -57,6 +63,16 @@ func NewConfig(user registration.User) *Config {
 type CertificateConfig struct {
 	Timeout             time.Duration
 	OverallRequestLimit int
+
+	// RequestLimiter limits the requests related to the authorizations (optional).
+	// It can be shared between several clients to apply the limit to all of them
+	// (see certificate.NewRequestLimiter).
+	RequestLimiter *rate.Limiter
+
+	// AuthorizationCache shares the authorizations between the orders of several certificates (optional).
+	// It can be shared between several clients of the same account
+	// (see certificate.NewAuthorizationCache).
+	AuthorizationCache *certificate.AuthorizationCache
 }
 
 // createDefaultHTTPClient Creates an HTTP client with a reasonable timeout value
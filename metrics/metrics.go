@@ -0,0 +1,72 @@
+// Package metrics provides the instrumentation of the ACME client.
+//
+// By default, the metrics are discarded.
+// A Recorder (e.g. the Prometheus implementation from the metrics/prometheus package) can be set with [SetDefault].
+package metrics
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Recorder records the metrics.
+type Recorder interface {
+	// ObserveRequest records the duration of an ACME request.
+	// The status code is 0 if the request has failed without response.
+	ObserveRequest(endpoint, method string, statusCode int, duration time.Duration)
+
+	// IncRateLimited counts the rate-limit errors (acme.RateLimitedError) returned by an ACME endpoint.
+	IncRateLimited(endpoint string)
+
+	// ObservePropagation records the duration of a DNS propagation wait (dns-01).
+	ObservePropagation(duration time.Duration, err error)
+
+	// SetCertificateExpiry records the expiry of a certificate.
+	SetCertificateExpiry(certID string, notAfter time.Time)
+
+	// IncRenewal counts the renewals of a certificate.
+	IncRenewal(certID string, err error)
+
+	// SetRenewalWindow records the suggested renewal window (ARI) of a certificate.
+	SetRenewalWindow(certID string, start, end time.Time)
+}
+
+var _ Recorder = Noop{}
+
+// Noop is a Recorder that discards the metrics.
+type Noop struct{}
+
+func (Noop) ObserveRequest(_, _ string, _ int, _ time.Duration) {}
+
+func (Noop) IncRateLimited(_ string) {}
+
+func (Noop) ObservePropagation(_ time.Duration, _ error) {}
+
+func (Noop) SetCertificateExpiry(_ string, _ time.Time) {}
+
+func (Noop) IncRenewal(_ string, _ error) {}
+
+func (Noop) SetRenewalWindow(_ string, _, _ time.Time) {}
+
+type holder struct {
+	recorder Recorder
+}
+
+var defaultRecorder atomic.Pointer[holder]
+
+func init() {
+	defaultRecorder.Store(&holder{recorder: Noop{}})
+}
+
+// Default returns the default [Recorder].
+func Default() Recorder { return defaultRecorder.Load().recorder }
+
+// SetDefault makes r the default [Recorder].
+// A nil Recorder discards the metrics.
+func SetDefault(r Recorder) {
+	if r == nil {
+		r = Noop{}
+	}
+
+	defaultRecorder.Store(&holder{recorder: r})
+}
@@ -0,0 +1,23 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetDefault(t *testing.T) {
+	t.Cleanup(func() { SetDefault(nil) })
+
+	assert.Equal(t, Noop{}, Default())
+
+	recorder := &struct{ Noop }{}
+
+	SetDefault(recorder)
+
+	assert.Same(t, recorder, Default())
+
+	SetDefault(nil)
+
+	assert.Equal(t, Noop{}, Default())
+}
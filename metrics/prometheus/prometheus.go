@@ -0,0 +1,141 @@
+// Package prometheus provides a metrics.Recorder based on Prometheus.
+package prometheus
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/go-acme/lego/v5/metrics"
+	prom "github.com/prometheus/client_golang/prometheus"
+)
+
+const namespace = "lego"
+
+const (
+	resultSuccess = "success"
+	resultFailure = "failure"
+)
+
+var _ metrics.Recorder = (*Recorder)(nil)
+
+// Recorder is a metrics.Recorder based on Prometheus.
+type Recorder struct {
+	certificateExpiry  *prom.GaugeVec
+	renewals           *prom.CounterVec
+	renewalWindowStart *prom.GaugeVec
+	renewalWindowEnd   *prom.GaugeVec
+	requestDuration    *prom.HistogramVec
+	rateLimited        *prom.CounterVec
+	propagation        *prom.HistogramVec
+}
+
+// NewRecorder creates a Recorder and registers its collectors.
+func NewRecorder(registerer prom.Registerer) (*Recorder, error) {
+	r := &Recorder{
+		certificateExpiry: prom.NewGaugeVec(prom.GaugeOpts{
+			Namespace: namespace,
+			Name:      "certificate_expiry_timestamp_seconds",
+			Help:      "The expiry date of the certificate (Unix time).",
+		}, []string{"cert_id"}),
+		renewals: prom.NewCounterVec(prom.CounterOpts{
+			Namespace: namespace,
+			Name:      "certificate_renewals_total",
+			Help:      "The number of renewals of the certificate.",
+		}, []string{"cert_id", "result"}),
+		renewalWindowStart: prom.NewGaugeVec(prom.GaugeOpts{
+			Namespace: namespace,
+			Name:      "certificate_renewal_window_start_timestamp_seconds",
+			Help:      "The start of the suggested renewal window (ARI) of the certificate (Unix time).",
+		}, []string{"cert_id"}),
+		renewalWindowEnd: prom.NewGaugeVec(prom.GaugeOpts{
+			Namespace: namespace,
+			Name:      "certificate_renewal_window_end_timestamp_seconds",
+			Help:      "The end of the suggested renewal window (ARI) of the certificate (Unix time).",
+		}, []string{"cert_id"}),
+		requestDuration: prom.NewHistogramVec(prom.HistogramOpts{
+			Namespace: namespace,
+			Name:      "acme_request_duration_seconds",
+			Help:      "The duration of the requests to the ACME server.",
+			Buckets:   prom.DefBuckets,
+		}, []string{"endpoint", "method", "code"}),
+		rateLimited: prom.NewCounterVec(prom.CounterOpts{
+			Namespace: namespace,
+			Name:      "acme_rate_limited_total",
+			Help:      "The number of rate-limit errors returned by the ACME server.",
+		}, []string{"endpoint"}),
+		propagation: prom.NewHistogramVec(prom.HistogramOpts{
+			Namespace: namespace,
+			Name:      "dns_propagation_duration_seconds",
+			Help:      "The duration of the DNS propagation waits (dns-01).",
+			Buckets:   []float64{1, 5, 10, 30, 60, 120, 300, 600},
+		}, []string{"result"}),
+	}
+
+	collectors := []prom.Collector{
+		r.certificateExpiry,
+		r.renewals,
+		r.renewalWindowStart,
+		r.renewalWindowEnd,
+		r.requestDuration,
+		r.rateLimited,
+		r.propagation,
+	}
+
+	var errs []error
+
+	for _, collector := range collectors {
+		errs = append(errs, registerer.Register(collector))
+	}
+
+	err := errors.Join(errs...)
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// ObserveRequest implements metrics.Recorder.
+func (r *Recorder) ObserveRequest(endpoint, method string, statusCode int, duration time.Duration) {
+	code := strconv.Itoa(statusCode)
+	if statusCode == 0 {
+		code = "error"
+	}
+
+	r.requestDuration.WithLabelValues(endpoint, method, code).Observe(duration.Seconds())
+}
+
+// IncRateLimited implements metrics.Recorder.
+func (r *Recorder) IncRateLimited(endpoint string) {
+	r.rateLimited.WithLabelValues(endpoint).Inc()
+}
+
+// ObservePropagation implements metrics.Recorder.
+func (r *Recorder) ObservePropagation(duration time.Duration, err error) {
+	r.propagation.WithLabelValues(result(err)).Observe(duration.Seconds())
+}
+
+// SetCertificateExpiry implements metrics.Recorder.
+func (r *Recorder) SetCertificateExpiry(certID string, notAfter time.Time) {
+	r.certificateExpiry.WithLabelValues(certID).Set(float64(notAfter.Unix()))
+}
+
+// IncRenewal implements metrics.Recorder.
+func (r *Recorder) IncRenewal(certID string, err error) {
+	r.renewals.WithLabelValues(certID, result(err)).Inc()
+}
+
+// SetRenewalWindow implements metrics.Recorder.
+func (r *Recorder) SetRenewalWindow(certID string, start, end time.Time) {
+	r.renewalWindowStart.WithLabelValues(certID).Set(float64(start.Unix()))
+	r.renewalWindowEnd.WithLabelValues(certID).Set(float64(end.Unix()))
+}
+
+func result(err error) string {
+	if err != nil {
+		return resultFailure
+	}
+
+	return resultSuccess
+}
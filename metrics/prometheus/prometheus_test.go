@@ -0,0 +1,73 @@
+package prometheus
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	prom "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorder(t *testing.T) {
+	registry := prom.NewRegistry()
+
+	recorder, err := NewRecorder(registry)
+	require.NoError(t, err)
+
+	notAfter := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	recorder.SetCertificateExpiry("example.com", notAfter)
+	recorder.IncRenewal("example.com", nil)
+	recorder.IncRenewal("example.com", errors.New("oops"))
+	recorder.IncRenewal("example.com", nil)
+	recorder.SetRenewalWindow("example.com", notAfter.Add(-48*time.Hour), notAfter.Add(-24*time.Hour))
+	recorder.IncRateLimited("newOrder")
+	recorder.ObserveRequest("newOrder", "POST", 201, 100*time.Millisecond)
+	recorder.ObserveRequest("newOrder", "POST", 0, time.Second)
+	recorder.ObservePropagation(20*time.Second, nil)
+
+	expected := `
+# HELP lego_certificate_expiry_timestamp_seconds The expiry date of the certificate (Unix time).
+# TYPE lego_certificate_expiry_timestamp_seconds gauge
+lego_certificate_expiry_timestamp_seconds{cert_id="example.com"} 1.767323045e+09
+# HELP lego_certificate_renewals_total The number of renewals of the certificate.
+# TYPE lego_certificate_renewals_total counter
+lego_certificate_renewals_total{cert_id="example.com",result="failure"} 1
+lego_certificate_renewals_total{cert_id="example.com",result="success"} 2
+# HELP lego_certificate_renewal_window_start_timestamp_seconds The start of the suggested renewal window (ARI) of the certificate (Unix time).
+# TYPE lego_certificate_renewal_window_start_timestamp_seconds gauge
+lego_certificate_renewal_window_start_timestamp_seconds{cert_id="example.com"} 1.767150245e+09
+# HELP lego_certificate_renewal_window_end_timestamp_seconds The end of the suggested renewal window (ARI) of the certificate (Unix time).
+# TYPE lego_certificate_renewal_window_end_timestamp_seconds gauge
+lego_certificate_renewal_window_end_timestamp_seconds{cert_id="example.com"} 1.767236645e+09
+# HELP lego_acme_rate_limited_total The number of rate-limit errors returned by the ACME server.
+# TYPE lego_acme_rate_limited_total counter
+lego_acme_rate_limited_total{endpoint="newOrder"} 1
+`
+
+	err = testutil.GatherAndCompare(registry, strings.NewReader(expected),
+		"lego_certificate_expiry_timestamp_seconds",
+		"lego_certificate_renewals_total",
+		"lego_certificate_renewal_window_start_timestamp_seconds",
+		"lego_certificate_renewal_window_end_timestamp_seconds",
+		"lego_acme_rate_limited_total",
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, testutil.CollectAndCount(recorder.requestDuration))
+	assert.Equal(t, 1, testutil.CollectAndCount(recorder.propagation))
+}
+
+func TestNewRecorder_alreadyRegistered(t *testing.T) {
+	registry := prom.NewRegistry()
+
+	_, err := NewRecorder(registry)
+	require.NoError(t, err)
+
+	_, err = NewRecorder(registry)
+	require.Error(t, err)
+}
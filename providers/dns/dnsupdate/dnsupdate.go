@@ -65,11 +65,15 @@ const (
 )
 
 const (
-	actionRemove = "REMOVE"
-	actionInsert = "INSERT"
+	actionRemove  = "REMOVE"
+	actionInsert  = "INSERT"
+	actionReplace = "REPLACE"
 )
 
-var _ challenge.ProviderTimeout = (*DNSProvider)(nil)
+var (
+	_ challenge.ProviderTimeout = (*DNSProvider)(nil)
+	_ dns01.RecordProvider      = (*DNSProvider)(nil)
+)
 
 // Config is used to configure the creation of the DNSProvider.
 type Config struct {
@@ -244,6 +248,35 @@ func (d *DNSProvider) changeRecord(ctx context.Context, action, fqdn, value stri
 		return fmt.Errorf("unexpected action: %s", action)
 	}
 
+	return d.update(ctx, m, action, zone)
+}
+
+// SetRecords replaces the records of the type rtype of the FQDN (the RRset) with the records.
+// An empty list of records removes the RRset.
+func (d *DNSProvider) SetRecords(ctx context.Context, fqdn string, rtype uint16, records []dns.RR) error {
+	zone, err := d.findZone(ctx, fqdn)
+	if err != nil {
+		return fmt.Errorf("dnsupdate: %w", err)
+	}
+
+	m := new(dns.Msg).SetUpdate(zone)
+
+	m.RemoveRRset([]dns.RR{&dns.ANY{Hdr: dns.RR_Header{Name: fqdn, Rrtype: rtype, Class: dns.ClassINET}}})
+
+	if len(records) > 0 {
+		m.Insert(records)
+	}
+
+	err = d.update(ctx, m, actionReplace, zone)
+	if err != nil {
+		return fmt.Errorf("dnsupdate: failed to replace: %w", err)
+	}
+
+	return nil
+}
+
+// update sends the dynamic update packet.
+func (d *DNSProvider) update(ctx context.Context, m *dns.Msg, action, zone string) error {
 	// Setup client
 	c := &dns.Client{Timeout: d.config.DNSTimeout}
 
@@ -251,18 +284,14 @@ func (d *DNSProvider) changeRecord(ctx context.Context, action, fqdn, value stri
 	if d.config.TSIGAlgorithm == tsig.GSS {
 		c.Net = "tcp"
 
-		var gssClient *gss.Client
-
-		gssClient, err = gss.NewClient(c)
+		gssClient, err := gss.NewClient(c)
 		if err != nil {
 			return fmt.Errorf("create GSS client: %w", err)
 		}
 
 		defer func() { _ = gssClient.Close() }()
 
-		var keyName string
-
-		keyName, err = d.negotiate(gssClient)
+		keyName, err := d.negotiate(gssClient)
 		if err != nil {
 			return err
 		}
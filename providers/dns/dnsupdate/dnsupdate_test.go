@@ -550,3 +550,50 @@ func Test_parseNameserver_error(t *testing.T) {
 		})
 	}
 }
+
+func TestDNSProvider_SetRecords_updatePacket(t *testing.T) {
+	dns01.DefaultClient().ClearFqdnCache()
+
+	defer envTest.RestoreEnv()
+
+	envTest.ClearEnv()
+
+	reqChan := make(chan *dns.Msg, 1)
+
+	addr := dnsmock.NewServer().
+		Query("www.example.com. SOA", dnsmock.SOA(fakeZone)).
+		Update(fakeZone+" SOA", func(w dns.ResponseWriter, req *dns.Msg) {
+			dnsmock.Noop(w, req)
+
+			reqChan <- req
+		}).
+		Build(t)
+
+	config := NewDefaultConfig()
+	config.Nameserver = addr.String()
+
+	provider, err := NewDNSProviderConfig(config)
+	require.NoError(t, err)
+
+	caaRR := &dns.CAA{
+		Hdr:   dns.RR_Header{Name: "www.example.com.", Rrtype: dns.TypeCAA, Class: dns.ClassINET, Ttl: 3600},
+		Tag:   "issue",
+		Value: "letsencrypt.org",
+	}
+
+	err = provider.SetRecords(t.Context(), "www.example.com.", dns.TypeCAA, []dns.RR{caaRR})
+	require.NoError(t, err)
+
+	select {
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for request")
+
+	case rcvMsg := <-reqChan:
+		require.Len(t, rcvMsg.Ns, 2)
+
+		// Removal of the RRset.
+		assert.Equal(t, dns.RR_Header{Name: "www.example.com.", Rrtype: dns.TypeCAA, Class: dns.ClassANY}, *rcvMsg.Ns[0].Header())
+
+		assert.Equal(t, caaRR.String(), rcvMsg.Ns[1].String())
+	}
+}
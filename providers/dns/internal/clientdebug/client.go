@@ -10,6 +10,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/go-acme/lego/v5/internal/tracing"
 	"github.com/go-acme/lego/v5/platform/env"
 )
 
@@ -117,8 +118,20 @@ func (d *DumpTransport) redact(content []byte) string {
 	return d.replacer.Replace(data)
 }
 
-// Wrap wraps an HTTP client Transport with the [DumpTransport].
+// Wrap returns a shallow copy of an HTTP client with the Transport wrapped by the OpenTelemetry transport,
+// and by the [DumpTransport] if LEGO_DEBUG_DNS_API_HTTP_CLIENT is true.
+// The client is not modified.
 func Wrap(client *http.Client, opts ...Option) *http.Client {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	wrapped := *client
+
+	client = &wrapped
+
+	client.Transport = tracing.WrapTransport(client.Transport)
+
 	val, found := os.LookupEnv("LEGO_DEBUG_DNS_API_HTTP_CLIENT")
 	if !found {
 		return client
@@ -136,6 +136,21 @@ func withWriter(w io.Writer) Option {
 	}
 }
 
+func TestWrap_copy(t *testing.T) {
+	transport := &http.Transport{}
+
+	client := &http.Client{Transport: transport, Timeout: 10 * time.Second}
+
+	wrapped := Wrap(client)
+
+	assert.NotSame(t, client, wrapped)
+	assert.NotEqual(t, http.RoundTripper(transport), wrapped.Transport)
+	assert.Equal(t, 10*time.Second, wrapped.Timeout)
+
+	// The client is not modified.
+	assert.Same(t, transport, client.Transport)
+}
+
 func setupTest(t *testing.T, buf io.Writer, opts ...Option) (*httptest.Server, *http.Client, *http.Request) {
 	t.Helper()
 